@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+// runCommand runs a maintenance subcommand instead of starting the server.
+func runCommand(ctx context.Context, args []string, projectionService service.ProjectionService, snapshotService service.SnapshotService, db *gorm.DB) error {
+	switch args[0] {
+	case "rebuild-balances":
+		flags := flag.NewFlagSet("rebuild-balances", flag.ContinueOnError)
+		dryRun := flags.Bool("dry-run", false, "report divergences without swapping the rebuilt projection in")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		var report *service.ProjectionRebuildReport
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			report, err = projectionService.RebuildAccountBalances(ctx, tx, *dryRun)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		return printJSON(report)
+	case "verify-snapshot":
+		flags := flag.NewFlagSet("verify-snapshot", flag.ContinueOnError)
+		eventID := flags.Uint("event-id", 0, "event ID of the snapshot to verify (default latest)")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		var snapshotEventID *uint
+		if *eventID != 0 {
+			snapshotEventID = eventID
+		}
+
+		var verification *service.SnapshotVerification
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			verification, err = snapshotService.VerifySnapshot(ctx, tx, snapshotEventID)
+			return err
+		}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			return err
+		}
+
+		if err := printJSON(verification); err != nil {
+			return err
+		}
+		if !verification.IsValid {
+			return service.ErrSnapshotDiverged
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q, expected rebuild-balances or verify-snapshot", args[0])
+	}
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
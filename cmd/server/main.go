@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
 	"log/slog"
+	"os"
+	"time"
 
+	"github.com/dirdr/goits/internal/alert"
 	"github.com/dirdr/goits/internal/config"
+	"github.com/dirdr/goits/internal/fx"
 	"github.com/dirdr/goits/internal/handler"
+	"github.com/dirdr/goits/internal/publisher"
 	"github.com/dirdr/goits/internal/service"
 	"github.com/dirdr/goits/internal/storage"
+	"github.com/dirdr/goits/internal/webhook"
+	"github.com/dirdr/goits/internal/worker"
 	"github.com/dirdr/goits/pkg/logger"
 
 	_ "github.com/dirdr/goits/docs"
@@ -41,12 +49,83 @@ func main() {
 	accountBalanceRepo := storage.NewGormAccountBalanceRepository(db)
 	transferEventRepo := storage.NewGormTransferEventRepository(db)
 	journalRepo := storage.NewGormJournalRepository(db)
+	eventStore := storage.NewGormEventStore(db)
+	holdRepo := storage.NewGormHoldRepository(db)
+	scheduledTransferRepo := storage.NewGormScheduledTransferRepository(db)
+	standingOrderRepo := storage.NewGormStandingOrderRepository(db)
+	projectionRepo := storage.NewGormProjectionRepository(db)
+	snapshotRepo := storage.NewGormSnapshotRepository(db)
+	outboxRepo := storage.NewGormOutboxRepository(db)
+	webhookRepo := storage.NewGormWebhookRepository(db)
+	hashChainRepo := storage.NewGormHashChainRepository(db)
+	integrityRunRepo := storage.NewGormIntegrityRunRepository(db)
+	repairRepo := storage.NewGormProjectionRepairRepository(db)
 
-	accountService := service.NewAccountService(accountRepo, accountBalanceRepo)
-	transactionService := service.NewTransactionService(accountRepo, accountBalanceRepo, transferEventRepo, journalRepo)
-	integrityService := service.NewIntegrityService(journalRepo)
+	rateProvider, err := initRateProvider(cfg.FX)
+	if err != nil {
+		appLogger.Error("Failed to load FX rates", "error", err)
+		return
+	}
+
+	transactionService := service.NewTransactionService(accountRepo, accountBalanceRepo, transferEventRepo, journalRepo, eventStore, outboxRepo, rateProvider)
+	accountService := service.NewAccountService(accountRepo, accountBalanceRepo, journalRepo, eventStore, snapshotRepo, outboxRepo, transactionService)
+	holdService := service.NewHoldService(accountRepo, accountBalanceRepo, transferEventRepo, journalRepo, eventStore, outboxRepo, holdRepo)
+	scheduledTransferService := service.NewScheduledTransferService(accountRepo, scheduledTransferRepo, transactionService)
+	standingOrderService := service.NewStandingOrderService(accountRepo, standingOrderRepo, transactionService)
+	integrityService := service.NewIntegrityService(accountRepo, accountBalanceRepo, journalRepo, transferEventRepo, hashChainRepo, eventStore, integrityRunRepo, repairRepo)
+	projectionService := service.NewProjectionService(accountBalanceRepo, journalRepo, eventStore, snapshotRepo, projectionRepo)
+	snapshotService := service.NewSnapshotService(journalRepo, eventStore, snapshotRepo)
+	activityService := service.NewActivityService(accountRepo, accountBalanceRepo, transferEventRepo, journalRepo)
+	webhookService := service.NewWebhookService(webhookRepo, webhook.NewHTTPSender(nil), service.WebhookDeliveryPolicy{
+		MinBackoff:   cfg.Webhooks.MinBackoff,
+		MaxBackoff:   cfg.Webhooks.MaxBackoff,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		DisableAfter: cfg.Webhooks.DisableAfter,
+	})
+
+	if len(os.Args) > 1 {
+		if err := runCommand(context.Background(), os.Args[1:], projectionService, snapshotService, db); err != nil {
+			appLogger.Error("Command failed", "command", os.Args[1], "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	holdExpirer := worker.NewHoldExpirer(holdService, db, appLogger, cfg.Holds.ExpiryInterval)
+	go holdExpirer.Run(context.Background())
 
-	r := handler.GetRouter(accountService, transactionService, integrityService, appLogger, db)
+	transferScheduler := worker.NewTransferScheduler(scheduledTransferService, db, appLogger, cfg.Scheduler.Interval)
+	go transferScheduler.Run(context.Background())
+
+	standingOrderScheduler := worker.NewStandingOrderScheduler(standingOrderService, db, appLogger, cfg.Scheduler.Interval)
+	go standingOrderScheduler.Run(context.Background())
+
+	if cfg.Snapshots.Frequency > 0 {
+		snapshotter := worker.NewSnapshotter(snapshotService, db, appLogger, cfg.Snapshots.Interval, cfg.Snapshots.Frequency, cfg.Snapshots.Verify)
+		go snapshotter.Run(context.Background())
+	}
+
+	outboxPublisher, err := initPublisher(cfg.Outbox)
+	if err != nil {
+		appLogger.Error("Failed to initialize outbox publisher", "error", err)
+		return
+	}
+	outboxService := service.NewOutboxService(outboxRepo, webhookRepo, outboxPublisher, cfg.Outbox.MinBackoff, cfg.Outbox.MaxBackoff, cfg.Outbox.MaxAttempts)
+	outboxRelay := worker.NewOutboxRelay(outboxService, db, appLogger, cfg.Outbox.RelayInterval)
+	go outboxRelay.Run(context.Background())
+
+	webhookDispatcher := worker.NewWebhookDispatcher(webhookService, db, appLogger, cfg.Webhooks.DispatchInterval)
+	go webhookDispatcher.Run(context.Background())
+
+	hashChainSealer := worker.NewHashChainSealer(integrityService, db, appLogger, cfg.Integrity.SealInterval)
+	go hashChainSealer.Run(context.Background())
+
+	if cfg.Integrity.MonitorInterval > 0 {
+		integrityMonitor := worker.NewIntegrityMonitor(integrityService, initAlerter(cfg.Integrity, appLogger), db, appLogger, cfg.Integrity.MonitorInterval, cfg.Integrity.Checks)
+		go integrityMonitor.Run(context.Background())
+	}
+
+	r := handler.GetRouter(accountService, transactionService, holdService, scheduledTransferService, standingOrderService, integrityService, projectionService, snapshotService, webhookService, activityService, appLogger, db)
 
 	appLogger.Info("Server starting", "port", cfg.Server.Port)
 	if err := r.Run(cfg.Server.Port); err != nil {
@@ -54,6 +133,36 @@ func main() {
 	}
 }
 
+func initRateProvider(cfg config.FXConfig) (fx.RateProvider, error) {
+	if cfg.RatesFile == "" {
+		return fx.NewStaticRateProvider("static", time.Now(), nil), nil
+	}
+	return fx.NewFileRateProvider(cfg.RatesFile)
+}
+
+// initPublisher returns nil when no outbox publisher is configured.
+func initPublisher(cfg config.OutboxConfig) (publisher.Publisher, error) {
+	switch cfg.Publisher {
+	case "webhook":
+		return publisher.NewWebhookPublisher(cfg.WebhookURL, nil), nil
+	case "file":
+		filePublisher, err := publisher.NewFilePublisher(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return filePublisher, nil
+	default:
+		return nil, nil
+	}
+}
+
+func initAlerter(cfg config.IntegrityConfig, log *slog.Logger) alert.Alerter {
+	if cfg.Alerter == "webhook" {
+		return alert.NewWebhookAlerter(cfg.AlertWebhookURL, nil)
+	}
+	return alert.NewLogAlerter(log)
+}
+
 func initLogger() *slog.Logger {
 	logger := logger.New("info")
 	slog.SetDefault(logger)
@@ -17,7 +17,7 @@ const docTemplate = `{
     "paths": {
         "/accounts": {
             "post": {
-                "description": "Creates a new account with a specified ID and initial balance.",
+                "description": "Creates a new account with a specified ID, ISO 4217 currency (defaults to USD) and initial balance. The type defaults to customer; fx_position accounts (one per currency) back currency conversions and equity accounts (one per currency, opened empty and provisioned on first use) fund the initial balances of new accounts through an AccountFunded transfer. IDs from 1000000000000000 up are reserved for system accounts.",
                 "consumes": [
                     "application/json"
                 ],
@@ -55,6 +55,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "409": {
+                        "description": "Account already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -69,7 +78,7 @@ const docTemplate = `{
         },
         "/accounts/{account_id}": {
             "get": {
-                "description": "Retrieves an account's details and current balance by its ID.",
+                "description": "Retrieves an account's details by its ID, with its ledger balance, the amount reserved by authorized holds and the resulting available balance.",
                 "consumes": [
                     "application/json"
                 ],
@@ -126,9 +135,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions": {
-            "post": {
-                "description": "Processes a transfer of funds between two accounts.",
+        "/accounts/{account_id}/balance": {
+            "get": {
+                "description": "Computes the account's balance from its latest snapshot (or opening event) and the journal entries after it, rather than from the projected balance. as_of is either an RFC 3339 timestamp or a transfer event ID; the balance then includes every entry created at or before the timestamp, or posted by that event or an earlier one. Without as_of, the current balance is computed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -136,23 +145,104 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "accounts"
                 ],
-                "summary": "Create a new transaction",
+                "summary": "Get an account's balance at a point in time",
                 "parameters": [
                     {
-                        "description": "Transaction creation request",
-                        "name": "transaction",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or transfer event ID",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateTransactionRequest"
+                            "$ref": "#/definitions/service.HistoricalBalance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/accounts/{account_id}/events": {
+            "get": {
+                "description": "Pushes, as server-sent events, every transfer touching the account (a \"transfer\" event) followed by the account's balance right after it (a \"balance\" event). Transfers are pushed in commit order once sealed into the hash chain, and the SSE id is their chain sequence: reconnect with Last-Event-ID, or the last_event_id query parameter, to resume right after it without missing updates. Without either, the stream starts with the next transfer.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Stream account activity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chain sequence of the transfer to resume after",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chain sequence of the transfer to resume after, for clients that cannot set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Stream of transfer and balance events",
                         "schema": {
                             "type": "string"
                         }
@@ -166,6 +256,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -177,48 +276,3701 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.CreateAccountRequest": {
-            "type": "object",
-            "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
-                "initial_balance": {
-                    "type": "number"
+        },
+        "/accounts/{account_id}/transactions": {
+            "get": {
+                "description": "Lists every journal leg touching the account, newest first, with the running balance after each leg. Use next_cursor to fetch the following page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "List account transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include legs created at or after this RFC 3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include legs created before this RFC 3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ListAccountTransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "handler.CreateTransactionRequest": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "number"
-                },
-                "destination_account_id": {
-                    "type": "integer"
-                },
-                "source_account_id": {
-                    "type": "integer"
+        "/admin/projections/account-balances/rebuild": {
+            "post": {
+                "description": "Replays the event log, from the latest snapshot on, into a fresh account_balances projection, reports every account whose balance or last event ID differs from the live projection, and swaps the rebuilt projection in. Postings are blocked while the rebuild runs. With dry_run, only the report is produced.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rebuild the account balances projection",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Compare without swapping (default false)",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ProjectionRebuildReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Event log cannot rebuild every account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "handler.GetAccountResponse": {
-            "type": "object",
-            "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
-                "balance": {
-                    "type": "number"
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "version": {
-                    "type": "integer"
+        "/admin/snapshots/verify": {
+            "get": {
+                "description": "Compares the account states recorded by a snapshot with a full replay of the event log up to the same transfer event, and reports every account that differs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Verify an account snapshot",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID of the snapshot to verify (default latest)",
+                        "name": "event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SnapshotVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Event log cannot replay every account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/events/stream": {
+            "get": {
+                "description": "Pushes, as server-sent events, every transfer (a \"transfer\" event) followed by the balances of the accounts it touched (one \"balance\" event per account). Transfers are pushed in commit order once sealed into the hash chain, and the SSE id is their chain sequence: reconnect with Last-Event-ID, or the last_event_id query parameter, to resume right after it without missing updates. Without either, the stream starts with the next transfer.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream all activity",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Chain sequence of the transfer to resume after",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chain sequence of the transfer to resume after, for clients that cannot set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of transfer and balance events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/holds": {
+            "post": {
+                "description": "Reserves funds on the source account for a later capture to the destination account. Held funds stay in the ledger balance but are no longer available for other transfers. Holds that are neither captured nor voided are released once expired; expires_in_seconds defaults to 7 days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Authorize a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-generated key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Hold authorization request",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created hold"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency key reused with a different request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{hold_id}": {
+            "get": {
+                "description": "Retrieves a hold and its current status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Get hold by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "hold_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{hold_id}/capture": {
+            "post": {
+                "description": "Transfers the captured amount from the source to the destination account and releases the hold. Omit the amount to capture the full hold; after a partial capture the remainder is released.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Capture a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-generated key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "hold_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Capture request",
+                        "name": "capture",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CaptureHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTransactionResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the capture transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Hold no longer authorized or idempotency key reused",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{hold_id}/void": {
+            "post": {
+                "description": "Releases an authorized hold without moving any funds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Void a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "hold_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Hold no longer authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/accounts": {
+            "get": {
+                "description": "Recomputes the balance of every account from its opening balance and journal entries and compares it with account_balances, listing the accounts whose balance or last event ID drifted. Accounts without an AccountOpened event cannot be reconciled and are listed as well.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Reconcile account balances against the journal",
+                "responses": {
+                    "200": {
+                        "description": "Account reconciliation report",
+                        "schema": {
+                            "$ref": "#/definitions/service.AccountReconciliationReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/accounts/{account_id}": {
+            "get": {
+                "description": "Recomputes the balance of the account from its opening balance and journal entries and compares it with its account_balances row.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Reconcile one account balance against the journal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account reconciliation",
+                        "schema": {
+                            "$ref": "#/definitions/service.AccountReconciliation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/check": {
+            "get": {
+                "description": "Verifies that, for every currency, the total debits equal total credits in the journal entries. The top-level totals sum every currency and are kept for backward compatibility; is_valid holds only when every currency balances.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Check double bookkeeping integrity",
+                "responses": {
+                    "200": {
+                        "description": "Integrity check result",
+                        "schema": {
+                            "$ref": "#/definitions/service.IntegrityResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/hash-chains": {
+            "get": {
+                "description": "Walks the hash chains of transfer_events and journal_entries, recomputing the hash of every sealed row and checking that it links to the row before it, and reports the first broken link of each chain. A broken link means a row was edited or deleted outside of the application. Rows are sealed in the background shortly after they commit (every HASH_CHAIN_SEAL_INTERVAL).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Verify the hash chains of the ledger",
+                "responses": {
+                    "200": {
+                        "description": "Hash chain verification result",
+                        "schema": {
+                            "$ref": "#/definitions/service.HashChainVerification"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/linkage": {
+            "get": {
+                "description": "Checks that every transfer event has the debit and credit journal entries it implies (one of each for a plain transfer), linked by source event ID, with matching amounts and transaction, and lists the orphaned events, the journal entries pointing to a missing event and those pointing to a missing account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Verify the links between transfer events and journal entries",
+                "responses": {
+                    "200": {
+                        "description": "Linkage report",
+                        "schema": {
+                            "$ref": "#/definitions/service.LinkageReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/repairs": {
+            "get": {
+                "description": "Lists the audit of applied account balance repairs, most recent first, with the old and new values and the operator.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "List account balance repairs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only repairs of this account",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of repairs (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ProjectionRepair"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Recalculates the balance and last event ID of every account whose account_balances row drifted from its journal, or only account_id, and reports the repairs. Unless dry_run is false, nothing is written; otherwise every repair is applied with optimistic locking and audited with the operator. Accounts missing their opening event or balance row are reported as unrepairable; a projection rebuild handles them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Repair drifting account balances",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Report the repairs without applying them (default true)",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Account to repair and operator",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RepairAccountsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ProjectionRepairReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/runs": {
+            "get": {
+                "description": "Lists the runs of the background integrity monitor, most recent first, with the status and report of every check. A run failed when a check found the ledger inconsistent, and is in error when a check could not be run.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "List integrity monitor runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "passed, failed or error",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of runs (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.IntegrityRun"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/runs/{run_id}": {
+            "get": {
+                "description": "Retrieves a run of the background integrity monitor with the status and report of every check.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Get integrity monitor run by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Run ID",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.IntegrityRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/transactions": {
+            "get": {
+                "description": "Checks, for every transfer event, that the journal entries of its transaction balance within each currency and are exactly the legs the event implies (accounts, directions and amounts), and lists the unbalanced or malformed transactions. Unlike the global check, two offsetting bad transactions cannot hide each other.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Verify every transaction of the journal",
+                "responses": {
+                    "200": {
+                        "description": "Transaction verification report",
+                        "schema": {
+                            "$ref": "#/definitions/service.TransactionVerificationReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-transfers": {
+            "get": {
+                "description": "Lists scheduled transfers in execution order, pending ones by default. Failed transfers carry the reason they could not be executed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-transfers"
+                ],
+                "summary": "List scheduled transfers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending (default), executed, failed or cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include transfers from this account",
+                        "name": "source_account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of transfers (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ScheduledTransfer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-transfers/{schedule_id}": {
+            "get": {
+                "description": "Retrieves a scheduled transfer with its status, the resulting transfer ID once executed, or the failure reason.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-transfers"
+                ],
+                "summary": "Get scheduled transfer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScheduledTransfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-transfers/{schedule_id}/cancel": {
+            "post": {
+                "description": "Cancels a pending scheduled transfer so that it is never executed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-transfers"
+                ],
+                "summary": "Cancel a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScheduledTransfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled transfer no longer pending",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/standing-orders": {
+            "get": {
+                "description": "Lists standing orders, oldest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "List standing orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "active, completed or cancelled (default: all)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include standing orders from this account",
+                        "name": "source_account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of standing orders (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.StandingOrder"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a recurring transfer between two accounts of the same currency. The schedule is a five-field cron expression evaluated in UTC, a shorthand such as @daily, @weekly or @monthly, or a fixed interval written \"@every 24h\". The first activation is the first one at or after both start_at and now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Create a standing order",
+                "parameters": [
+                    {
+                        "description": "Standing order details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateStandingOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StandingOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/standing-orders/{standing_order_id}": {
+            "get": {
+                "description": "Retrieves a standing order with its status and next activation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Get standing order by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "standing_order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StandingOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels an active standing order so that it never runs again. Executions that already happened are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Cancel a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "standing_order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StandingOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Standing order no longer active",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes the amount, schedule or end of an active standing order. Omitted fields are left unchanged. A new schedule restarts from its first activation at or after now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Update a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "standing_order_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateStandingOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StandingOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Standing order no longer active",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/standing-orders/{standing_order_id}/executions": {
+            "get": {
+                "description": "Lists the activations of a standing order, most recent first, with the resulting transfer ID or the failure reason.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "List executions of a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "standing_order_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of executions (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.StandingOrderExecution"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "post": {
+                "description": "Processes a transfer of funds between two accounts. The amount is expressed in the source account's currency; transfers between currencies must set allow_conversion. When execute_at is set, the transfer is stored as pending and executed by the scheduler once due; funds are only checked at that time. Requests carrying an Idempotency-Key header are executed at most once; replays return the original outcome.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create a new transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-generated key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Transaction creation request",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTransactionResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created transaction"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScheduledTransfer"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the scheduled transfer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency key reused with a different request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/multi-leg": {
+            "post": {
+                "description": "Atomically posts N debit and credit legs under a single transfer ID, e.g. paying a merchant, a platform fee and a tax from one account. All accounts must share a currency, debits must equal credits, and every debited account must hold sufficient funds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create a multi-leg transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-generated key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Multi-leg transaction creation request",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateMultiLegTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTransactionResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency key reused with a different request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{transfer_id}": {
+            "get": {
+                "description": "Retrieves a transfer event together with the journal entries it produced.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get transaction by transfer ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "transfer_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.TransferDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{transfer_id}/reverse": {
+            "post": {
+                "description": "Appends a TransferReversed event with compensating journal entries. Omit the amount to reverse everything not reversed yet; partial reversals are rejected for multi-leg transfers and may never exceed the remaining amount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Reverse a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-generated key making the request safe to retry",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transfer ID of the transaction to reverse",
+                        "name": "transfer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reversal request",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReverseTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTransactionResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the reversal transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency key reused with a different request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{delivery_id}": {
+            "get": {
+                "description": "Retrieves a delivery with the history of its attempts: when each was made, the response status code and the error, if any.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook delivery by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "description": "Queues a delivery to be sent again right away, whatever its status, with a fresh budget of attempts. The request carries the same message ID as before.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription is disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/subscriptions": {
+            "get": {
+                "description": "Lists every webhook subscription, oldest first, including disabled ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a URL that receives a POST for every transfer or account event of the given types, from now on. Requests carry the X-Webhook-Timestamp header (Unix seconds) and the X-Webhook-Signature header \"v1=\" followed by the hex HMAC-SHA256, keyed with the secret, of the timestamp, a dot and the body. Receivers should reject stale timestamps and deduplicate on X-Webhook-Message-ID. The secret is never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription details; event types among AccountOpened, AccountFunded, TransferProcessed, MultiLegTransferProcessed, HoldAuthorized, HoldCaptured, HoldVoided, HoldExpired and TransferReversed",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateWebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/subscriptions/{subscription_id}": {
+            "get": {
+                "description": "Retrieves a webhook subscription with its status and count of consecutive failed deliveries.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook subscription by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a subscription together with its deliveries and their attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes the URL, event types or secret of a subscription, or disables or enables it. Omitted fields are left unchanged. Enabling a subscription resets its count of consecutive failures; deliveries still pending are then sent again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateWebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/subscriptions/{subscription_id}/deliveries": {
+            "get": {
+                "description": "Lists the deliveries of a subscription, most recent first, with their status and number of attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List deliveries of a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AccountType": {
+            "type": "string",
+            "enum": [
+                "customer",
+                "fx_position",
+                "equity"
+            ],
+            "x-enum-varnames": [
+                "AccountTypeCustomer",
+                "AccountTypeFXPosition",
+                "AccountTypeEquity"
+            ]
+        },
+        "domain.Conversion": {
+            "type": "object",
+            "properties": {
+                "destination_amount": {
+                    "type": "number"
+                },
+                "destination_currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "rate_source": {
+                    "type": "string"
+                },
+                "rate_timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EntryType": {
+            "type": "string",
+            "enum": [
+                "debit",
+                "credit"
+            ],
+            "x-enum-varnames": [
+                "Debit",
+                "Credit"
+            ]
+        },
+        "domain.Hold": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "capture_transfer_id": {
+                    "type": "string"
+                },
+                "captured_amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "destination_account_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "hold_id": {
+                    "type": "string"
+                },
+                "source_account_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.HoldStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.HoldStatus": {
+            "type": "string",
+            "enum": [
+                "authorized",
+                "captured",
+                "voided",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "HoldStatusAuthorized",
+                "HoldStatusCaptured",
+                "HoldStatusVoided",
+                "HoldStatusExpired"
+            ]
+        },
+        "domain.IntegrityCheckResult": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "report": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.IntegrityStatus"
+                }
+            }
+        },
+        "domain.IntegrityRun": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.IntegrityCheckResult"
+                    }
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "run_id": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.IntegrityStatus"
+                }
+            }
+        },
+        "domain.IntegrityStatus": {
+            "type": "string",
+            "enum": [
+                "passed",
+                "failed",
+                "error"
+            ],
+            "x-enum-varnames": [
+                "IntegrityStatusPassed",
+                "IntegrityStatusFailed",
+                "IntegrityStatusError"
+            ]
+        },
+        "domain.JournalEntry": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "entry_id": {
+                    "type": "integer"
+                },
+                "source_event_id": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.EntryType"
+                }
+            }
+        },
+        "domain.Posting": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.EntryType"
+                }
+            }
+        },
+        "domain.ProjectionRepair": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "new_balance": {
+                    "type": "number"
+                },
+                "new_last_event_id": {
+                    "type": "integer"
+                },
+                "new_version": {
+                    "type": "integer"
+                },
+                "old_balance": {
+                    "type": "number"
+                },
+                "old_last_event_id": {
+                    "type": "integer"
+                },
+                "old_version": {
+                    "type": "integer"
+                },
+                "operator": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "repair_id": {
+                    "type": "integer"
+                },
+                "repaired_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ScheduledTransfer": {
+            "type": "object",
+            "properties": {
+                "allow_conversion": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination_account_id": {
+                    "type": "integer"
+                },
+                "execute_at": {
+                    "type": "string"
+                },
+                "executed_at": {
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "string"
+                },
+                "source_account_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.ScheduledTransferStatus"
+                },
+                "transfer_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ScheduledTransferStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "executed",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "ScheduledTransferStatusPending",
+                "ScheduledTransferStatusExecuted",
+                "ScheduledTransferStatusFailed",
+                "ScheduledTransferStatusCancelled"
+            ]
+        },
+        "domain.StandingOrder": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination_account_id": {
+                    "type": "integer"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "source_account_id": {
+                    "type": "integer"
+                },
+                "standing_order_id": {
+                    "type": "string"
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.StandingOrderStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StandingOrderExecution": {
+            "type": "object",
+            "properties": {
+                "executed_at": {
+                    "type": "string"
+                },
+                "execution_id": {
+                    "type": "integer"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "scheduled_for": {
+                    "type": "string"
+                },
+                "standing_order_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.StandingOrderExecutionStatus"
+                },
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StandingOrderExecutionStatus": {
+            "type": "string",
+            "enum": [
+                "executed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StandingOrderExecutionStatusExecuted",
+                "StandingOrderExecutionStatusFailed"
+            ]
+        },
+        "domain.StandingOrderStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "completed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StandingOrderStatusActive",
+                "StandingOrderStatusCompleted",
+                "StandingOrderStatusCancelled"
+            ]
+        },
+        "domain.TransferEvent": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "conversion": {
+                    "$ref": "#/definitions/domain.Conversion"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "from_account_id": {
+                    "type": "integer"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "legs": {
+                    "description": "Legs lists the postings of a multi-leg transfer, for which FromAccountID\nand ToAccountID are zero and Amount is the total debited.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Posting"
+                    }
+                },
+                "request_fingerprint": {
+                    "type": "string"
+                },
+                "reverses_transfer_id": {
+                    "description": "ReversesTransferID links a TransferReversed event to the transfer it\ncompensates. Amount is then the part of the original amount reversed.",
+                    "type": "string"
+                },
+                "to_account_id": {
+                    "type": "integer"
+                },
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookDeliveryAttempt"
+                    }
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.WebhookDeliveryStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookDeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt_id": {
+                    "type": "integer"
+                },
+                "attempted_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryStatusPending",
+                "WebhookDeliveryStatusDelivered",
+                "WebhookDeliveryStatusFailed"
+            ]
+        },
+        "domain.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "disabled_reason": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.WebhookSubscriptionStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookSubscriptionStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "disabled"
+            ],
+            "x-enum-varnames": [
+                "WebhookSubscriptionStatusActive",
+                "WebhookSubscriptionStatusDisabled"
+            ]
+        },
+        "handler.AccountTransactionResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "counterparty_account_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "direction": {
+                    "$ref": "#/definitions/domain.EntryType"
+                },
+                "entry_id": {
+                    "type": "integer"
+                },
+                "running_balance": {
+                    "type": "number"
+                },
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CaptureHoldRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "handler.CreateAccountRequest": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "initial_balance": {
+                    "type": "number"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AccountType"
+                }
+            }
+        },
+        "handler.CreateHoldRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "destination_account_id": {
+                    "type": "integer"
+                },
+                "expires_in_seconds": {
+                    "type": "integer"
+                },
+                "source_account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateMultiLegTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.PostingRequest"
+                    }
+                }
+            }
+        },
+        "handler.CreateStandingOrderRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "destination_account_id": {
+                    "type": "integer"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "source_account_id": {
+                    "type": "integer"
+                },
+                "start_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "allow_conversion": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "destination_account_id": {
+                    "type": "integer"
+                },
+                "execute_at": {
+                    "description": "ExecuteAt defers the transfer to the given time instead of executing\nit immediately.",
+                    "type": "string"
+                },
+                "source_account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateTransactionResponse": {
+            "type": "object",
+            "properties": {
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateWebhookSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.GetAccountResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "available_balance": {
+                    "type": "number"
+                },
+                "balance": {
+                    "description": "Balance is the ledger balance; AvailableBalance excludes funds reserved\nby authorized holds.",
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "held_amount": {
+                    "type": "number"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AccountType"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ListAccountTransactionsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.AccountTransactionResponse"
+                    }
+                }
+            }
+        },
+        "handler.PostingRequest": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "direction": {
+                    "$ref": "#/definitions/domain.EntryType"
+                }
+            }
+        },
+        "handler.RepairAccountsRequest": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "operator": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ReverseTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "handler.UpdateStandingOrderRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UpdateWebhookSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.WebhookSubscriptionStatus"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.AccountReconciliation": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "expected_balance": {
+                    "type": "number"
+                },
+                "expected_last_event_id": {
+                    "type": "integer"
+                },
+                "is_valid": {
+                    "type": "boolean"
+                },
+                "journal_entries": {
+                    "type": "integer"
+                },
+                "journal_net": {
+                    "type": "number"
+                },
+                "opening_balance": {
+                    "type": "number"
+                },
+                "projected_balance": {
+                    "type": "number"
+                },
+                "projected_last_event_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "$ref": "#/definitions/service.ReconciliationReason"
+                }
+            }
+        },
+        "service.AccountReconciliationReport": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
+                },
+                "drifting": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.AccountReconciliation"
+                    }
+                },
+                "is_valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.BalanceDivergence": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "live_balance": {
+                    "type": "number"
+                },
+                "live_last_event_id": {
+                    "type": "integer"
+                },
+                "missing_from_live": {
+                    "type": "boolean"
+                },
+                "rebuilt_balance": {
+                    "type": "number"
+                },
+                "rebuilt_last_event_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BrokenLink": {
+            "type": "object",
+            "properties": {
+                "actual_hash": {
+                    "type": "string"
+                },
+                "expected_hash": {
+                    "type": "string"
+                },
+                "previous_row_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "$ref": "#/definitions/service.BrokenLinkReason"
+                },
+                "row_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BrokenLinkReason": {
+            "type": "string",
+            "enum": [
+                "prev_hash_mismatch",
+                "hash_mismatch",
+                "head_mismatch"
+            ],
+            "x-enum-varnames": [
+                "BrokenLinkPrevHash",
+                "BrokenLinkHash",
+                "BrokenLinkHead"
+            ]
+        },
+        "service.CurrencyIntegrity": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "is_valid": {
+                    "type": "boolean"
+                },
+                "total_credits": {
+                    "type": "number"
+                },
+                "total_debits": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.EventLinkageIssue": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.LinkageAmount"
+                    }
+                },
+                "credits": {
+                    "type": "integer"
+                },
+                "debits": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "expected_credits": {
+                    "type": "integer"
+                },
+                "expected_debits": {
+                    "type": "integer"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.LinkageIssueReason"
+                    }
+                },
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.HashChainResult": {
+            "type": "object",
+            "properties": {
+                "broken_link": {
+                    "$ref": "#/definitions/service.BrokenLink"
+                },
+                "chain": {
+                    "type": "string"
+                },
+                "is_valid": {
+                    "type": "boolean"
+                },
+                "last_id": {
+                    "type": "integer"
+                },
+                "rows_verified": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.HashChainVerification": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.HashChainResult"
+                    }
+                },
+                "is_valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.HistoricalBalance": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "as_of": {
+                    "type": "string"
+                },
+                "as_of_event_id": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "last_event_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.IntegrityResult": {
+            "type": "object",
+            "properties": {
+                "currencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.CurrencyIntegrity"
+                    }
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "is_valid": {
+                    "type": "boolean"
+                },
+                "total_credits": {
+                    "type": "number"
+                },
+                "total_debits": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.LinkageAmount": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "expected": {
+                    "type": "number"
+                },
+                "posted": {
+                    "type": "number"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.EntryType"
+                }
+            }
+        },
+        "service.LinkageIssueReason": {
+            "type": "string",
+            "enum": [
+                "orphaned_event",
+                "leg_count_mismatch",
+                "amount_mismatch",
+                "transaction_id_mismatch"
+            ],
+            "x-enum-varnames": [
+                "LinkageOrphanedEvent",
+                "LinkageLegCountMismatch",
+                "LinkageAmountMismatch",
+                "LinkageTransactionMismatch"
+            ]
+        },
+        "service.LinkageReport": {
+            "type": "object",
+            "properties": {
+                "entries_with_missing_account": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.JournalEntry"
+                    }
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.EventLinkageIssue"
+                    }
+                },
+                "events_checked": {
+                    "type": "integer"
+                },
+                "is_valid": {
+                    "type": "boolean"
+                },
+                "orphaned_entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.JournalEntry"
+                    }
+                }
+            }
+        },
+        "service.ProjectionRebuildReport": {
+            "type": "object",
+            "properties": {
+                "accounts_rebuilt": {
+                    "type": "integer"
+                },
+                "divergences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BalanceDivergence"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "events_replayed": {
+                    "type": "integer"
+                },
+                "snapshot_event_id": {
+                    "type": "integer"
+                },
+                "swapped": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.ProjectionRepairReport": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "repairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ProjectionRepair"
+                    }
+                },
+                "unrepairable": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.AccountReconciliation"
+                    }
+                }
+            }
+        },
+        "service.ReconciliationReason": {
+            "type": "string",
+            "enum": [
+                "balance_mismatch",
+                "last_event_id_mismatch",
+                "missing_balance",
+                "missing_opening_event"
+            ],
+            "x-enum-varnames": [
+                "ReconciliationBalanceMismatch",
+                "ReconciliationLastEventMismatch",
+                "ReconciliationMissingBalance",
+                "ReconciliationMissingOpening"
+            ]
+        },
+        "service.SnapshotDivergence": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "replayed_balance": {
+                    "type": "number"
+                },
+                "replayed_last_event_id": {
+                    "type": "integer"
+                },
+                "snapshot_balance": {
+                    "type": "number"
+                },
+                "snapshot_last_event_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.SnapshotVerification": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
+                },
+                "divergences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SnapshotDivergence"
+                    }
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "events_replayed": {
+                    "type": "integer"
+                },
+                "is_valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.TransactionIssue": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "missing_legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TransactionLeg"
+                    }
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TransactionIssueReason"
+                    }
+                },
+                "transaction_id": {
+                    "type": "string"
+                },
+                "unbalanced": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.CurrencyIntegrity"
+                    }
+                },
+                "unexpected_legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TransactionLeg"
+                    }
+                }
+            }
+        },
+        "service.TransactionIssueReason": {
+            "type": "string",
+            "enum": [
+                "unbalanced",
+                "legs_mismatch",
+                "missing_original_transfer"
+            ],
+            "x-enum-varnames": [
+                "TransactionUnbalanced",
+                "TransactionLegsMismatch",
+                "TransactionMissingOriginal"
+            ]
+        },
+        "service.TransactionLeg": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.EntryType"
+                }
+            }
+        },
+        "service.TransactionVerificationReport": {
+            "type": "object",
+            "properties": {
+                "invalid": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TransactionIssue"
+                    }
+                },
+                "is_valid": {
+                    "type": "boolean"
+                },
+                "transactions_checked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.TransferDetails": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.JournalEntry"
+                    }
+                },
+                "event": {
+                    "$ref": "#/definitions/domain.TransferEvent"
+                },
+                "reversals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TransferEvent"
+                    }
                 }
             }
         }
@@ -0,0 +1,13 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+// Alerter notifies operators of an integrity run that did not pass, because a
+// check found the ledger inconsistent or could not be run.
+type Alerter interface {
+	Alert(ctx context.Context, run domain.IntegrityRun) error
+}
@@ -0,0 +1,26 @@
+package alert
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+// LogAlerter reports integrity runs as error logs.
+type LogAlerter struct {
+	log *slog.Logger
+}
+
+func NewLogAlerter(log *slog.Logger) *LogAlerter {
+	return &LogAlerter{log: log}
+}
+
+func (a *LogAlerter) Alert(ctx context.Context, run domain.IntegrityRun) error {
+	attrs := []any{"status", run.Status, "checks", run.UnhealthyChecks()}
+	if run.RunID != 0 {
+		attrs = append(attrs, "run_id", run.RunID)
+	}
+	a.log.ErrorContext(ctx, "Integrity run did not pass", attrs...)
+	return nil
+}
@@ -0,0 +1,32 @@
+package alert
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+// MemoryAlerter keeps the runs it was alerted of in memory, for tests.
+type MemoryAlerter struct {
+	mu   sync.Mutex
+	runs []domain.IntegrityRun
+}
+
+func NewMemoryAlerter() *MemoryAlerter {
+	return &MemoryAlerter{}
+}
+
+func (a *MemoryAlerter) Alert(ctx context.Context, run domain.IntegrityRun) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.runs = append(a.runs, run)
+	return nil
+}
+
+// Runs returns the runs alerted so far, in order.
+func (a *MemoryAlerter) Runs() []domain.IntegrityRun {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]domain.IntegrityRun(nil), a.runs...)
+}
@@ -0,0 +1,57 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookAlerter POSTs every integrity run as JSON to a fixed URL. Any
+// response other than 2xx is a failed alert.
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookAlerter(url string, client *http.Client) *WebhookAlerter {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookAlerter{
+		url:    url,
+		client: client,
+	}
+}
+
+func (a *WebhookAlerter) Alert(ctx context.Context, run domain.IntegrityRun) error {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode integrity run %d: %w", run.RunID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call alert webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
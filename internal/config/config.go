@@ -3,12 +3,24 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	FX        FXConfig
+	Holds     HoldsConfig
+	Scheduler SchedulerConfig
+	Snapshots SnapshotsConfig
+	Outbox    OutboxConfig
+	Webhooks  WebhooksConfig
+	Integrity IntegrityConfig
 }
 
 type DatabaseConfig struct {
@@ -25,7 +37,154 @@ type ServerConfig struct {
 	Port string
 }
 
+type FXConfig struct {
+	// RatesFile points to a JSON rate table. When empty, cross-currency
+	// transfers are rejected for lack of a rate.
+	RatesFile string
+}
+
+type HoldsConfig struct {
+	// ExpiryInterval is how often authorized holds past their expiry are
+	// released.
+	ExpiryInterval time.Duration
+}
+
+type SchedulerConfig struct {
+	// Interval is how often due scheduled transfers and standing orders are
+	// executed.
+	Interval time.Duration
+}
+
+type SnapshotsConfig struct {
+	// Interval is how often the snapshotter checks whether a snapshot is due.
+	Interval time.Duration
+	// Frequency is how many transfer events are posted between two
+	// snapshots. Zero disables snapshots; event replay then starts from the
+	// beginning of the log.
+	Frequency int
+	// Verify checks every snapshot against a full replay before storing it.
+	Verify bool
+}
+
+type OutboxConfig struct {
+	// Publisher selects where outbox messages are published besides webhook
+	// subscriptions: "webhook", "file", or empty for none.
+	Publisher string
+	// WebhookURL receives every message as a POST when Publisher is webhook.
+	WebhookURL string
+	// File is appended one JSON line per message when Publisher is file.
+	File string
+	// RelayInterval is how often pending messages are published.
+	RelayInterval time.Duration
+	// MinBackoff and MaxBackoff bound the delay before a failed message is
+	// retried; it doubles with every failed attempt.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is how many times a message is published before it is
+	// dead-lettered.
+	MaxAttempts int
+}
+
+type WebhooksConfig struct {
+	// DispatchInterval is how often due deliveries to webhook subscriptions
+	// are sent.
+	DispatchInterval time.Duration
+	// MinBackoff and MaxBackoff bound the delay before a failed delivery is
+	// retried; it doubles with every failed attempt.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is how many times a delivery is attempted before it is
+	// marked failed.
+	MaxAttempts int
+	// DisableAfter is how many consecutive failed attempts disable a
+	// subscription.
+	DisableAfter int
+}
+
+type IntegrityConfig struct {
+	// MonitorInterval is how often the integrity monitor runs the checks. Zero
+	// disables the monitor.
+	MonitorInterval time.Duration
+	// Checks lists the checks the monitor runs, among domain.IntegrityChecks.
+	Checks []string
+	// Alerter selects how runs that did not pass are reported: "log" or
+	// "webhook".
+	Alerter string
+	// AlertWebhookURL receives every such run as a POST when Alerter is
+	// webhook.
+	AlertWebhookURL string
+	// SealInterval is how often committed rows are appended to the hash
+	// chains.
+	SealInterval time.Duration
+}
+
 func LoadConfig() (*Config, error) {
+	holdExpiryInterval, err := time.ParseDuration(getEnv("HOLD_EXPIRY_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HOLD_EXPIRY_INTERVAL: %w", err)
+	}
+	schedulerInterval, err := time.ParseDuration(getEnv("SCHEDULER_INTERVAL", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_INTERVAL: %w", err)
+	}
+	snapshotInterval, err := time.ParseDuration(getEnv("SNAPSHOT_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNAPSHOT_INTERVAL: %w", err)
+	}
+	snapshotFrequency, err := strconv.Atoi(getEnv("SNAPSHOT_FREQUENCY", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNAPSHOT_FREQUENCY: %w", err)
+	}
+	snapshotVerify, err := strconv.ParseBool(getEnv("SNAPSHOT_VERIFY", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNAPSHOT_VERIFY: %w", err)
+	}
+	outboxRelayInterval, err := time.ParseDuration(getEnv("OUTBOX_RELAY_INTERVAL", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RELAY_INTERVAL: %w", err)
+	}
+	outboxMinBackoff, err := time.ParseDuration(getEnv("OUTBOX_MIN_BACKOFF", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_MIN_BACKOFF: %w", err)
+	}
+	outboxMaxBackoff, err := time.ParseDuration(getEnv("OUTBOX_MAX_BACKOFF", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_MAX_BACKOFF: %w", err)
+	}
+	outboxMaxAttempts, err := strconv.Atoi(getEnv("OUTBOX_MAX_ATTEMPTS", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_MAX_ATTEMPTS: %w", err)
+	}
+	webhookDispatchInterval, err := time.ParseDuration(getEnv("WEBHOOK_DISPATCH_INTERVAL", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL: %w", err)
+	}
+	webhookMinBackoff, err := time.ParseDuration(getEnv("WEBHOOK_MIN_BACKOFF", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_MIN_BACKOFF: %w", err)
+	}
+	webhookMaxBackoff, err := time.ParseDuration(getEnv("WEBHOOK_MAX_BACKOFF", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_MAX_BACKOFF: %w", err)
+	}
+	webhookMaxAttempts, err := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS: %w", err)
+	}
+	webhookDisableAfter, err := strconv.Atoi(getEnv("WEBHOOK_DISABLE_AFTER", "20"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DISABLE_AFTER: %w", err)
+	}
+
+	integrityMonitorInterval, err := time.ParseDuration(getEnv("INTEGRITY_MONITOR_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INTEGRITY_MONITOR_INTERVAL: %w", err)
+	}
+	hashChainSealInterval, err := time.ParseDuration(getEnv("HASH_CHAIN_SEAL_INTERVAL", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HASH_CHAIN_SEAL_INTERVAL: %w", err)
+	}
+
 	cfg := &Config{
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "postgres"),
@@ -39,6 +198,43 @@ func LoadConfig() (*Config, error) {
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
+		FX: FXConfig{
+			RatesFile: getEnv("FX_RATES_FILE", ""),
+		},
+		Holds: HoldsConfig{
+			ExpiryInterval: holdExpiryInterval,
+		},
+		Scheduler: SchedulerConfig{
+			Interval: schedulerInterval,
+		},
+		Snapshots: SnapshotsConfig{
+			Interval:  snapshotInterval,
+			Frequency: snapshotFrequency,
+			Verify:    snapshotVerify,
+		},
+		Outbox: OutboxConfig{
+			Publisher:     getEnv("OUTBOX_PUBLISHER", ""),
+			WebhookURL:    getEnv("OUTBOX_WEBHOOK_URL", ""),
+			File:          getEnv("OUTBOX_FILE", ""),
+			RelayInterval: outboxRelayInterval,
+			MinBackoff:    outboxMinBackoff,
+			MaxBackoff:    outboxMaxBackoff,
+			MaxAttempts:   outboxMaxAttempts,
+		},
+		Webhooks: WebhooksConfig{
+			DispatchInterval: webhookDispatchInterval,
+			MinBackoff:       webhookMinBackoff,
+			MaxBackoff:       webhookMaxBackoff,
+			MaxAttempts:      webhookMaxAttempts,
+			DisableAfter:     webhookDisableAfter,
+		},
+		Integrity: IntegrityConfig{
+			MonitorInterval: integrityMonitorInterval,
+			Checks:          strings.Split(getEnv("INTEGRITY_CHECKS", strings.Join(domain.IntegrityChecks, ",")), ","),
+			Alerter:         getEnv("INTEGRITY_ALERTER", "log"),
+			AlertWebhookURL: getEnv("INTEGRITY_ALERT_WEBHOOK_URL", ""),
+			SealInterval:    hashChainSealInterval,
+		},
 	}
 
 	if err := validateConfig(cfg); err != nil {
@@ -58,6 +254,72 @@ func validateConfig(cfg *Config) error {
 	if cfg.Database.DBName == "" {
 		return fmt.Errorf("DB_DBNAME environment variable is required")
 	}
+	if cfg.Holds.ExpiryInterval <= 0 {
+		return fmt.Errorf("HOLD_EXPIRY_INTERVAL must be positive")
+	}
+	if cfg.Scheduler.Interval <= 0 {
+		return fmt.Errorf("SCHEDULER_INTERVAL must be positive")
+	}
+	if cfg.Snapshots.Interval <= 0 {
+		return fmt.Errorf("SNAPSHOT_INTERVAL must be positive")
+	}
+	if cfg.Snapshots.Frequency < 0 {
+		return fmt.Errorf("SNAPSHOT_FREQUENCY cannot be negative")
+	}
+	switch cfg.Outbox.Publisher {
+	case "":
+	case "webhook":
+		if cfg.Outbox.WebhookURL == "" {
+			return fmt.Errorf("OUTBOX_WEBHOOK_URL is required when OUTBOX_PUBLISHER is webhook")
+		}
+	case "file":
+		if cfg.Outbox.File == "" {
+			return fmt.Errorf("OUTBOX_FILE is required when OUTBOX_PUBLISHER is file")
+		}
+	default:
+		return fmt.Errorf("OUTBOX_PUBLISHER must be webhook or file, got %q", cfg.Outbox.Publisher)
+	}
+	if cfg.Outbox.RelayInterval <= 0 {
+		return fmt.Errorf("OUTBOX_RELAY_INTERVAL must be positive")
+	}
+	if cfg.Outbox.MinBackoff <= 0 || cfg.Outbox.MaxBackoff < cfg.Outbox.MinBackoff {
+		return fmt.Errorf("OUTBOX_MIN_BACKOFF must be positive and at most OUTBOX_MAX_BACKOFF")
+	}
+	if cfg.Outbox.MaxAttempts <= 0 {
+		return fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be positive")
+	}
+	if cfg.Webhooks.DispatchInterval <= 0 {
+		return fmt.Errorf("WEBHOOK_DISPATCH_INTERVAL must be positive")
+	}
+	if cfg.Webhooks.MinBackoff <= 0 || cfg.Webhooks.MaxBackoff < cfg.Webhooks.MinBackoff {
+		return fmt.Errorf("WEBHOOK_MIN_BACKOFF must be positive and at most WEBHOOK_MAX_BACKOFF")
+	}
+	if cfg.Webhooks.MaxAttempts <= 0 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be positive")
+	}
+	if cfg.Webhooks.DisableAfter <= 0 {
+		return fmt.Errorf("WEBHOOK_DISABLE_AFTER must be positive")
+	}
+	if cfg.Integrity.MonitorInterval < 0 {
+		return fmt.Errorf("INTEGRITY_MONITOR_INTERVAL cannot be negative")
+	}
+	if cfg.Integrity.SealInterval <= 0 {
+		return fmt.Errorf("HASH_CHAIN_SEAL_INTERVAL must be positive")
+	}
+	for _, check := range cfg.Integrity.Checks {
+		if !slices.Contains(domain.IntegrityChecks, check) {
+			return fmt.Errorf("INTEGRITY_CHECKS must be a comma-separated list of %s, got %q", strings.Join(domain.IntegrityChecks, ", "), check)
+		}
+	}
+	switch cfg.Integrity.Alerter {
+	case "log":
+	case "webhook":
+		if cfg.Integrity.AlertWebhookURL == "" {
+			return fmt.Errorf("INTEGRITY_ALERT_WEBHOOK_URL is required when INTEGRITY_ALERTER is webhook")
+		}
+	default:
+		return fmt.Errorf("INTEGRITY_ALERTER must be log or webhook, got %q", cfg.Integrity.Alerter)
+	}
 	if !strings.HasPrefix(cfg.Server.Port, ":") {
 		cfg.Server.Port = ":" + cfg.Server.Port
 	}
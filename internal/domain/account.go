@@ -6,16 +6,44 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+type AccountType string
+
+const (
+	AccountTypeCustomer AccountType = "customer"
+	// AccountTypeFXPosition accounts hold the bank's position in one currency and
+	// absorb both sides of currency conversions. They may go negative.
+	AccountTypeFXPosition AccountType = "fx_position"
+	// AccountTypeEquity accounts fund the initial balances of new accounts in
+	// one currency, so that every unit of balance comes from a journal entry.
+	// They may go negative.
+	AccountTypeEquity AccountType = "equity"
+)
+
+// SystemAccountIDMin starts the range of IDs reserved for the system accounts
+// the ledger provisions itself. Clients cannot open accounts in it.
+const SystemAccountIDMin uint = 1_000_000_000_000_000
+
 type Account struct {
-	ID        uint      `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint        `json:"id"`
+	Type      AccountType `json:"type"`
+	Currency  string      `json:"currency"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
 }
 
+// AccountBalance carries the ledger balance, i.e. the sum of posted journal
+// entries, alongside the amount reserved by authorized holds.
 type AccountBalance struct {
 	AccountID   uint            `json:"account_id"`
 	Balance     decimal.Decimal `json:"balance"`
+	HeldAmount  decimal.Decimal `json:"held_amount"`
 	Version     int             `json:"version"`
 	LastEventID uint            `json:"last_event_id"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 }
+
+// AvailableBalance is the ledger balance minus funds reserved by holds; it is
+// what an account can still spend.
+func (b *AccountBalance) AvailableBalance() decimal.Decimal {
+	return b.Balance.Sub(b.HeldAmount)
+}
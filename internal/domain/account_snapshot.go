@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalancePoint selects a point in an account's history: either a time or the
+// ID of the last transfer event to include. Exactly one of them is set.
+type BalancePoint struct {
+	Time    *time.Time
+	EventID *uint
+}
+
+// AccountSnapshot is the state of an account once every transfer event up to
+// and including EventID is applied. Snapshots are taken for all accounts at
+// once, at the same EventID, but only accounts that changed since the previous
+// snapshot get a new row: the state of an account at a snapshot is its latest
+// row at or before it.
+//
+// LastEventID is the last transfer event that touched the account and
+// LastEntryAt the creation time of its latest journal entry.
+type AccountSnapshot struct {
+	AccountID   uint
+	EventID     uint
+	Balance     decimal.Decimal
+	LastEventID uint
+	LastEntryAt time.Time
+	CreatedAt   time.Time
+}
+
+// AccountJournalRange selects the journal entries of an account posted by
+// transfer events after AfterEventID, up to and including a point. When
+// AfterChainSeq is set, only the transfer events sealed after it or not
+// sealed yet are selected.
+type AccountJournalRange struct {
+	AccountID     uint
+	AfterEventID  uint
+	AfterChainSeq *uint
+	Until         BalancePoint
+}
+
+// JournalSum aggregates the entries of an AccountJournalRange. LastEventID is 0
+// when the range is empty.
+type JournalSum struct {
+	Net         decimal.Decimal
+	Count       int
+	LastEventID uint
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// AccountActivity is a transfer event as streamed to activity subscribers,
+// with the balance changes it caused on the accounts they follow.
+type AccountActivity struct {
+	Transfer TransferEvent   `json:"transfer"`
+	Balances []BalanceChange `json:"balances"`
+}
+
+// BalanceChange is the ledger balance of an account right after a transfer
+// event, and the amount that event moved it by.
+type BalanceChange struct {
+	AccountID uint            `json:"account_id"`
+	EventID   uint            `json:"event_id"`
+	Currency  string          `json:"currency"`
+	Change    decimal.Decimal `json:"change"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+// ActivityCursor is the position of an activity stream: every transfer event
+// up to ChainSeq in the hash chain was streamed. The chain is appended to in
+// commit order, so a transfer event committed after the cursor always gets a
+// greater ChainSeq and is never skipped.
+type ActivityCursor struct {
+	ChainSeq uint
+}
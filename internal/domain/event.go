@@ -6,12 +6,54 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const (
+	EventTypeTransferProcessed         = "TransferProcessed"
+	EventTypeMultiLegTransferProcessed = "MultiLegTransferProcessed"
+	EventTypeHoldCaptured              = "HoldCaptured"
+	EventTypeTransferReversed          = "TransferReversed"
+	EventTypeAccountFunded             = "AccountFunded"
+)
+
 type TransferEvent struct {
-	EventID       uint            `json:"event_id"`
-	TransferID    string          `json:"transfer_id"`
-	FromAccountID uint            `json:"from_account_id"`
-	ToAccountID   uint            `json:"to_account_id"`
-	Amount        decimal.Decimal `json:"amount"`
-	EventType     string          `json:"event_type"`
-	CreatedAt     time.Time       `json:"created_at"`
+	EventID            uint            `json:"event_id"`
+	TransferID         string          `json:"transfer_id"`
+	FromAccountID      uint            `json:"from_account_id"`
+	ToAccountID        uint            `json:"to_account_id"`
+	Amount             decimal.Decimal `json:"amount"`
+	Currency           string          `json:"currency"`
+	EventType          string          `json:"event_type"`
+	IdempotencyKey     string          `json:"idempotency_key,omitempty"`
+	RequestFingerprint string          `json:"request_fingerprint,omitempty"`
+	Conversion         *Conversion     `json:"conversion,omitempty"`
+	// Legs lists the postings of a multi-leg transfer, for which FromAccountID
+	// and ToAccountID are zero and Amount is the total debited.
+	Legs []Posting `json:"legs,omitempty"`
+	// ReversesTransferID links a TransferReversed event to the transfer it
+	// compensates. Amount is then the part of the original amount reversed.
+	ReversesTransferID string    `json:"reverses_transfer_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	// SchemaVersion is the version of the row the event was read from.
+	SchemaVersion int `json:"-"`
+	// ChainSeq, PrevHash and Hash link the event into the transfer_events hash
+	// chain once it is sealed.
+	ChainSeq uint   `json:"-"`
+	PrevHash string `json:"-"`
+	Hash     string `json:"-"`
+}
+
+// Posting is one leg of a multi-leg transfer.
+type Posting struct {
+	AccountID uint            `json:"account_id"`
+	Type      EntryType       `json:"type"`
+	Amount    decimal.Decimal `json:"amount"`
+}
+
+// Conversion records the exchange applied to a cross-currency transfer so it can
+// be audited and replayed without querying the rate provider again.
+type Conversion struct {
+	Rate                decimal.Decimal `json:"rate"`
+	RateSource          string          `json:"rate_source"`
+	RateTimestamp       time.Time       `json:"rate_timestamp"`
+	DestinationAmount   decimal.Decimal `json:"destination_amount"`
+	DestinationCurrency string          `json:"destination_currency"`
 }
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Upcaster rewrites an event payload stored under one schema version into the
+// shape of the next version.
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+// EventSchema describes how the payload of one event type is stored. Version is
+// the schema version new events are written with; Upcasters[v] rewrites a
+// payload of version v into version v+1, so an event stored under any past
+// version decodes into the current Go type.
+type EventSchema struct {
+	Version   int
+	New       func() Event
+	Upcasters map[int]Upcaster
+}
+
+// Upcast rewrites payload from version to the current version of the schema,
+// one upcaster at a time.
+func (s EventSchema) Upcast(version int, payload json.RawMessage) (json.RawMessage, error) {
+	if version < 1 || version > s.Version {
+		return nil, fmt.Errorf("unsupported schema version %d, current is %d", version, s.Version)
+	}
+
+	for v := version; v < s.Version; v++ {
+		upcaster, ok := s.Upcasters[v]
+		if !ok {
+			return nil, fmt.Errorf("no upcaster from schema version %d", v)
+		}
+		var err error
+		payload, err = upcaster(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast from schema version %d: %w", v, err)
+		}
+	}
+	return payload, nil
+}
+
+// eventSchemas registers every known event type. When the payload of an event
+// changes shape, bump its Version, add the upcaster from the previous version
+// and a fixture of the new version to test/domain/testdata/events: stored
+// events are never rewritten, so every past version must keep decoding.
+var eventSchemas = map[string]EventSchema{
+	EventTypeAccountOpened: {Version: 2, New: func() Event { return &AccountOpened{} }, Upcasters: map[int]Upcaster{
+		// Version 1 accounts opened with their initial balance already on them.
+		1: func(payload json.RawMessage) (json.RawMessage, error) {
+			var opened map[string]json.RawMessage
+			if err := json.Unmarshal(payload, &opened); err != nil {
+				return nil, err
+			}
+			opened["opening_balance"] = opened["initial_balance"]
+			return json.Marshal(opened)
+		},
+	}},
+	EventTypeAccountFrozen:             {Version: 1, New: func() Event { return &AccountFrozen{} }},
+	EventTypeAccountUnfrozen:           {Version: 1, New: func() Event { return &AccountUnfrozen{} }},
+	EventTypeTransferProcessed:         {Version: 1, New: func() Event { return &TransferProcessed{} }},
+	EventTypeMultiLegTransferProcessed: {Version: 1, New: func() Event { return &MultiLegTransferProcessed{} }},
+	EventTypeHoldCaptured:              {Version: 1, New: func() Event { return &HoldCaptured{} }},
+	EventTypeHoldAuthorized:            {Version: 1, New: func() Event { return &HoldAuthorized{} }},
+	EventTypeHoldVoided:                {Version: 1, New: func() Event { return &HoldVoided{} }},
+	EventTypeHoldExpired:               {Version: 1, New: func() Event { return &HoldExpired{} }},
+	EventTypeTransferReversed:          {Version: 1, New: func() Event { return &TransferReversed{} }},
+	EventTypeAccountFunded:             {Version: 1, New: func() Event { return &AccountFunded{} }},
+}
+
+// TransferEventUpcaster rewrites a transfer_events row read under one schema
+// version into the shape of the next version.
+type TransferEventUpcaster func(event *TransferEvent) error
+
+// TransferEventRowSchema describes the shape of a transfer_events row. Rows
+// carry the version they were written with and are never rewritten, so a row of
+// a past version is upcast on read, like a stored event payload.
+type TransferEventRowSchema struct {
+	Version   int
+	Upcasters map[int]TransferEventUpcaster
+}
+
+// TransferEventSchema is the schema transfer_events rows are written with.
+// When a column changes meaning, bump its Version and add the upcaster from the
+// previous version and a fixture of the new version to
+// test/domain/testdata/transfer_events.
+var TransferEventSchema = TransferEventRowSchema{Version: 1}
+
+// UpcastTransferEvent rewrites event, read from a transfer_events row written
+// under schema version, into the current shape of the row.
+func UpcastTransferEvent(version int, event *TransferEvent) error {
+	if version < 1 || version > TransferEventSchema.Version {
+		return fmt.Errorf("unsupported schema version %d, current is %d", version, TransferEventSchema.Version)
+	}
+
+	for v := version; v < TransferEventSchema.Version; v++ {
+		upcaster, ok := TransferEventSchema.Upcasters[v]
+		if !ok {
+			return fmt.Errorf("no upcaster from schema version %d", v)
+		}
+		if err := upcaster(event); err != nil {
+			return fmt.Errorf("failed to upcast from schema version %d: %w", v, err)
+		}
+	}
+	event.SchemaVersion = TransferEventSchema.Version
+	return nil
+}
+
+// LookupEventSchema returns the schema registered for eventType.
+func LookupEventSchema(eventType string) (EventSchema, bool) {
+	schema, ok := eventSchemas[eventType]
+	return schema, ok
+}
+
+// RegisteredEventTypes lists the event types with a registered schema, sorted.
+func RegisteredEventTypes() []string {
+	eventTypes := make([]string, 0, len(eventSchemas))
+	for eventType := range eventSchemas {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
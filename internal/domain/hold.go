@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type HoldStatus string
+
+const (
+	HoldStatusAuthorized HoldStatus = "authorized"
+	HoldStatusCaptured   HoldStatus = "captured"
+	HoldStatusVoided     HoldStatus = "voided"
+	HoldStatusExpired    HoldStatus = "expired"
+)
+
+// Hold reserves funds on the source account until it is captured, voided or
+// expires. While authorized, its amount counts towards the account's
+// AccountBalance.HeldAmount but no journal entries exist for it.
+type Hold struct {
+	HoldID               string          `json:"hold_id"`
+	SourceAccountID      uint            `json:"source_account_id"`
+	DestinationAccountID uint            `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	Status               HoldStatus      `json:"status"`
+	CapturedAmount       decimal.Decimal `json:"captured_amount"`
+	CaptureTransferID    string          `json:"capture_transfer_id,omitempty"`
+	IdempotencyKey       string          `json:"-"`
+	RequestFingerprint   string          `json:"-"`
+	ExpiresAt            time.Time       `json:"expires_at"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Integrity checks the monitor can run.
+const (
+	IntegrityCheckDoubleBookkeeping = "double_bookkeeping"
+	IntegrityCheckHashChains        = "hash_chains"
+	IntegrityCheckAccounts          = "accounts"
+	IntegrityCheckTransactions      = "transactions"
+	IntegrityCheckLinkage           = "linkage"
+)
+
+// IntegrityChecks lists every integrity check, in the order they are run.
+var IntegrityChecks = []string{
+	IntegrityCheckDoubleBookkeeping,
+	IntegrityCheckHashChains,
+	IntegrityCheckAccounts,
+	IntegrityCheckTransactions,
+	IntegrityCheckLinkage,
+}
+
+type IntegrityStatus string
+
+const (
+	IntegrityStatusPassed IntegrityStatus = "passed"
+	// IntegrityStatusFailed means a check found the ledger inconsistent.
+	IntegrityStatusFailed IntegrityStatus = "failed"
+	// IntegrityStatusError means a check could not be run to completion.
+	IntegrityStatusError IntegrityStatus = "error"
+)
+
+// IntegrityCheckResult is the outcome of one check of a run. Report is the
+// report the check returned, as served by its integrity endpoint.
+type IntegrityCheckResult struct {
+	Check  string          `json:"check"`
+	Status IntegrityStatus `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Report json.RawMessage `json:"report,omitempty" swaggertype:"object"`
+}
+
+// IntegrityRun is one run of the integrity monitor over a set of checks. It
+// failed when any check failed, and is in error when any check could not run.
+// RunID is zero until the run is recorded.
+type IntegrityRun struct {
+	RunID      uint                   `json:"run_id,omitempty"`
+	Status     IntegrityStatus        `json:"status"`
+	Checks     []IntegrityCheckResult `json:"checks"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt time.Time              `json:"finished_at"`
+}
+
+// NewIntegrityRun builds the run of the given checks, deriving its status from
+// theirs.
+func NewIntegrityRun(checks []IntegrityCheckResult, startedAt, finishedAt time.Time) *IntegrityRun {
+	run := &IntegrityRun{
+		Status:     IntegrityStatusPassed,
+		Checks:     checks,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+	}
+	for _, check := range checks {
+		switch {
+		case check.Status == IntegrityStatusError:
+			run.Status = IntegrityStatusError
+		case check.Status == IntegrityStatusFailed && run.Status == IntegrityStatusPassed:
+			run.Status = IntegrityStatusFailed
+		}
+	}
+	return run
+}
+
+// UnhealthyChecks lists the checks of the run that did not pass.
+func (r *IntegrityRun) UnhealthyChecks() []string {
+	var checks []string
+	for _, check := range r.Checks {
+		if check.Status != IntegrityStatusPassed {
+			checks = append(checks, check.Check)
+		}
+	}
+	return checks
+}
+
+// IntegrityRunQuery selects runs, most recent first. A zero Status keeps runs
+// of any status.
+type IntegrityRunQuery struct {
+	Status IntegrityStatus
+	Limit  int
+}
@@ -18,7 +18,45 @@ type JournalEntry struct {
 	TransactionID string          `json:"transaction_id"`
 	AccountID     uint            `json:"account_id"`
 	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
 	Type          EntryType       `json:"type"`
 	SourceEventID uint            `json:"source_event_id"`
 	CreatedAt     time.Time       `json:"created_at"`
+	// ChainSeq, PrevHash and Hash link the entry into the journal_entries hash
+	// chain once it is sealed.
+	ChainSeq uint   `json:"-"`
+	PrevHash string `json:"-"`
+	Hash     string `json:"-"`
+}
+
+// AccountJournalEntry is a journal leg seen from the point of view of the
+// account it touches.
+type AccountJournalEntry struct {
+	JournalEntry
+	CounterpartyAccountID uint            `json:"counterparty_account_id"`
+	RunningBalance        decimal.Decimal `json:"running_balance"`
+}
+
+// JournalEntryCursor is a keyset position in an account's journal, ordered by
+// creation time then entry ID.
+type JournalEntryCursor struct {
+	CreatedAt time.Time
+	EntryID   uint
+}
+
+type AccountJournalQuery struct {
+	AccountID uint
+	From      *time.Time
+	To        *time.Time
+	Before    *JournalEntryCursor
+	Limit     int
+}
+
+// JournalEventRange selects the journal entries posted by the transfer events
+// after AfterEventID, up to and including UpToEventID. A non-zero AccountID
+// keeps the entries of that account only.
+type JournalEventRange struct {
+	AfterEventID uint
+	UpToEventID  uint
+	AccountID    uint
 }
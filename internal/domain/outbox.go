@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusPublished OutboxStatus = "published"
+	OutboxStatusDead      OutboxStatus = "dead"
+)
+
+// OutboxMessage publishes a transfer, account or hold event downstream. It is written
+// in the transaction that records the event, so a message exists exactly when
+// its event committed, and is relayed until a publisher accepts it or it failed
+// too many times, in which case it is dead-lettered.
+//
+// AccountIDs are the accounts the event concerns: messages sharing an account
+// are published in MessageID order. TransferEventID is nil for account and hold
+// events.
+type OutboxMessage struct {
+	MessageID       uint            `json:"message_id"`
+	TransferEventID *uint           `json:"transfer_event_id,omitempty"`
+	EventType       string          `json:"event_type"`
+	AccountIDs      []uint          `json:"account_ids"`
+	Payload         json.RawMessage `json:"payload" swaggertype:"object"`
+	Status          OutboxStatus    `json:"-"`
+	Attempts        int             `json:"-"`
+	NextAttemptAt   time.Time       `json:"-"`
+	LastError       string          `json:"-"`
+	CreatedAt       time.Time       `json:"created_at"`
+	PublishedAt     *time.Time      `json:"-"`
+}
+
+// NewOutboxMessage builds the pending message publishing a saved transfer
+// event. The request fingerprint is internal and left out of the payload.
+func NewOutboxMessage(event *TransferEvent, accountIDs []uint) (*OutboxMessage, error) {
+	published := *event
+	published.RequestFingerprint = ""
+	payload, err := json.Marshal(published)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transfer event %d: %w", event.EventID, err)
+	}
+
+	eventID := event.EventID
+	return &OutboxMessage{
+		TransferEventID: &eventID,
+		EventType:       event.EventType,
+		AccountIDs:      uniqueSortedIDs(accountIDs),
+		Payload:         payload,
+		Status:          OutboxStatusPending,
+		NextAttemptAt:   event.CreatedAt,
+		CreatedAt:       event.CreatedAt,
+	}, nil
+}
+
+// NewAccountOutboxMessage builds the pending message publishing an event that
+// concerns one account, such as AccountOpened or HoldAuthorized, recorded at
+// occurredAt.
+func NewAccountOutboxMessage(event Event, accountID uint, occurredAt time.Time) (*OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+	}
+
+	return &OutboxMessage{
+		EventType:     event.EventType(),
+		AccountIDs:    []uint{accountID},
+		Payload:       payload,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: occurredAt,
+		CreatedAt:     occurredAt,
+	}, nil
+}
+
+func uniqueSortedIDs(accountIDs []uint) []uint {
+	seen := make(map[uint]bool, len(accountIDs))
+	ids := make([]uint, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProjectionRepair audits one account_balances row that was recalculated from
+// the journal because it drifted from it: what it held before, what it was
+// set to, why, and by whom.
+type ProjectionRepair struct {
+	RepairID       uint            `json:"repair_id"`
+	AccountID      uint            `json:"account_id"`
+	Reason         string          `json:"reason"`
+	OldBalance     decimal.Decimal `json:"old_balance"`
+	NewBalance     decimal.Decimal `json:"new_balance"`
+	OldLastEventID uint            `json:"old_last_event_id"`
+	NewLastEventID uint            `json:"new_last_event_id"`
+	OldVersion     int             `json:"old_version"`
+	NewVersion     int             `json:"new_version"`
+	Operator       string          `json:"operator"`
+	RepairedAt     time.Time       `json:"repaired_at"`
+}
+
+// ProjectionRepairQuery selects repairs, most recent first. A zero AccountID
+// keeps repairs of every account.
+type ProjectionRepairQuery struct {
+	AccountID uint
+	Limit     int
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduledTransferStatusPending   ScheduledTransferStatus = "pending"
+	ScheduledTransferStatusExecuted  ScheduledTransferStatus = "executed"
+	ScheduledTransferStatusFailed    ScheduledTransferStatus = "failed"
+	ScheduledTransferStatusCancelled ScheduledTransferStatus = "cancelled"
+)
+
+// ScheduledTransfer is a transfer request parked until ExecuteAt. Once run it
+// either references the resulting transfer or records why it failed. Attempts
+// that fail transiently push NextAttemptAt back.
+type ScheduledTransfer struct {
+	ScheduleID           string                  `json:"schedule_id"`
+	SourceAccountID      uint                    `json:"source_account_id"`
+	DestinationAccountID uint                    `json:"destination_account_id"`
+	Amount               decimal.Decimal         `json:"amount"`
+	AllowConversion      bool                    `json:"allow_conversion"`
+	ExecuteAt            time.Time               `json:"execute_at"`
+	Status               ScheduledTransferStatus `json:"status"`
+	TransferID           string                  `json:"transfer_id,omitempty"`
+	FailureReason        string                  `json:"failure_reason,omitempty"`
+	ExecutedAt           *time.Time              `json:"executed_at,omitempty"`
+	Attempts             int                     `json:"attempts"`
+	LastError            string                  `json:"last_error,omitempty"`
+	NextAttemptAt        *time.Time              `json:"next_attempt_at,omitempty"`
+	IdempotencyKey       string                  `json:"-"`
+	RequestFingerprint   string                  `json:"-"`
+	CreatedAt            time.Time               `json:"created_at"`
+	UpdatedAt            time.Time               `json:"updated_at"`
+}
+
+type ScheduledTransferQuery struct {
+	Status          ScheduledTransferStatus
+	SourceAccountID uint
+	Limit           int
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderStatusActive    StandingOrderStatus = "active"
+	StandingOrderStatusCompleted StandingOrderStatus = "completed"
+	StandingOrderStatusCancelled StandingOrderStatus = "cancelled"
+)
+
+// StandingOrder materializes a transfer at every activation of Schedule
+// between StartAt and the optional EndAt. NextRunAt is nil once no activation
+// is left. Attempts counts the transient failures of the activation at
+// NextRunAt, which is retried from NextAttemptAt.
+type StandingOrder struct {
+	StandingOrderID      string              `json:"standing_order_id"`
+	SourceAccountID      uint                `json:"source_account_id"`
+	DestinationAccountID uint                `json:"destination_account_id"`
+	Amount               decimal.Decimal     `json:"amount"`
+	Schedule             string              `json:"schedule"`
+	StartAt              time.Time           `json:"start_at"`
+	EndAt                *time.Time          `json:"end_at,omitempty"`
+	NextRunAt            *time.Time          `json:"next_run_at,omitempty"`
+	Attempts             int                 `json:"attempts"`
+	LastError            string              `json:"last_error,omitempty"`
+	NextAttemptAt        *time.Time          `json:"next_attempt_at,omitempty"`
+	Status               StandingOrderStatus `json:"status"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+type StandingOrderExecutionStatus string
+
+const (
+	StandingOrderExecutionStatusExecuted StandingOrderExecutionStatus = "executed"
+	StandingOrderExecutionStatusFailed   StandingOrderExecutionStatus = "failed"
+)
+
+// StandingOrderExecution records the outcome of one activation of a standing
+// order. There is at most one execution per activation.
+type StandingOrderExecution struct {
+	ExecutionID     uint                         `json:"execution_id"`
+	StandingOrderID string                       `json:"standing_order_id"`
+	ScheduledFor    time.Time                    `json:"scheduled_for"`
+	Status          StandingOrderExecutionStatus `json:"status"`
+	TransferID      string                       `json:"transfer_id,omitempty"`
+	FailureReason   string                       `json:"failure_reason,omitempty"`
+	ExecutedAt      time.Time                    `json:"executed_at"`
+}
+
+type StandingOrderQuery struct {
+	Status          StandingOrderStatus
+	SourceAccountID uint
+	Limit           int
+}
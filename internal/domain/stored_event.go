@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	AggregateTypeAccount  = "account"
+	AggregateTypeTransfer = "transfer"
+	AggregateTypeHold     = "hold"
+)
+
+const (
+	EventTypeAccountOpened   = "AccountOpened"
+	EventTypeAccountFrozen   = "AccountFrozen"
+	EventTypeAccountUnfrozen = "AccountUnfrozen"
+)
+
+const (
+	EventTypeHoldAuthorized = "HoldAuthorized"
+	EventTypeHoldVoided     = "HoldVoided"
+	EventTypeHoldExpired    = "HoldExpired"
+)
+
+// Event is a typed domain event. Each event belongs to the stream of one
+// aggregate, in which it is assigned a sequence number when appended.
+type Event interface {
+	EventType() string
+	AggregateType() string
+	AggregateID() string
+}
+
+// EventEnvelope is the stored form of an Event: its JSON payload together with
+// the stream it belongs to and its position in that stream.
+type EventEnvelope struct {
+	EventID       uint              `json:"event_id"`
+	EventType     string            `json:"event_type"`
+	AggregateType string            `json:"aggregate_type"`
+	AggregateID   string            `json:"aggregate_id"`
+	Sequence      int64             `json:"sequence"`
+	SchemaVersion int               `json:"schema_version"`
+	Payload       json.RawMessage   `json:"payload" swaggertype:"object"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+}
+
+// NewEventEnvelope encodes event under the current schema version of its type.
+// The sequence is assigned by the event store.
+func NewEventEnvelope(event Event, metadata map[string]string, occurredAt time.Time) (*EventEnvelope, error) {
+	schema, ok := eventSchemas[event.EventType()]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", event.EventType())
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+	}
+
+	return &EventEnvelope{
+		EventType:     event.EventType(),
+		AggregateType: event.AggregateType(),
+		AggregateID:   event.AggregateID(),
+		SchemaVersion: schema.Version,
+		Payload:       payload,
+		Metadata:      metadata,
+		OccurredAt:    occurredAt,
+	}, nil
+}
+
+// Decode returns the typed event carried by the envelope, upcasting payloads
+// stored under an older schema version to the current shape of the event.
+func (e *EventEnvelope) Decode() (Event, error) {
+	schema, ok := eventSchemas[e.EventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", e.EventType)
+	}
+
+	payload, err := schema.Upcast(e.SchemaVersion, e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upcast %s event %d: %w", e.EventType, e.EventID, err)
+	}
+
+	event := schema.New()
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event %d: %w", e.EventType, e.EventID, err)
+	}
+	return event, nil
+}
+
+// AccountOpened records the initial balance requested for the account and the
+// balance it opened with, before any journal entry. Since schema version 2 a
+// positive initial balance is funded by the AccountFunded transfer named by
+// FundingTransferID and the account opens empty; version 1 accounts opened with
+// their initial balance already on them.
+type AccountOpened struct {
+	AccountID         uint            `json:"account_id"`
+	Type              AccountType     `json:"type"`
+	Currency          string          `json:"currency"`
+	InitialBalance    decimal.Decimal `json:"initial_balance"`
+	OpeningBalance    decimal.Decimal `json:"opening_balance"`
+	FundingTransferID string          `json:"funding_transfer_id,omitempty"`
+}
+
+func (AccountOpened) EventType() string     { return EventTypeAccountOpened }
+func (AccountOpened) AggregateType() string { return AggregateTypeAccount }
+func (e AccountOpened) AggregateID() string { return accountAggregateID(e.AccountID) }
+
+// AccountFrozen and AccountUnfrozen are part of the account stream vocabulary;
+// accounts cannot be frozen through the API yet.
+type AccountFrozen struct {
+	AccountID uint   `json:"account_id"`
+	Reason    string `json:"reason"`
+}
+
+func (AccountFrozen) EventType() string     { return EventTypeAccountFrozen }
+func (AccountFrozen) AggregateType() string { return AggregateTypeAccount }
+func (e AccountFrozen) AggregateID() string { return accountAggregateID(e.AccountID) }
+
+type AccountUnfrozen struct {
+	AccountID uint `json:"account_id"`
+}
+
+func (AccountUnfrozen) EventType() string     { return EventTypeAccountUnfrozen }
+func (AccountUnfrozen) AggregateType() string { return AggregateTypeAccount }
+func (e AccountUnfrozen) AggregateID() string { return accountAggregateID(e.AccountID) }
+
+type TransferProcessed struct {
+	TransferID    string          `json:"transfer_id"`
+	FromAccountID uint            `json:"from_account_id"`
+	ToAccountID   uint            `json:"to_account_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+	Conversion    *Conversion     `json:"conversion,omitempty"`
+}
+
+func (TransferProcessed) EventType() string     { return EventTypeTransferProcessed }
+func (TransferProcessed) AggregateType() string { return AggregateTypeTransfer }
+func (e TransferProcessed) AggregateID() string { return e.TransferID }
+
+type MultiLegTransferProcessed struct {
+	TransferID string          `json:"transfer_id"`
+	Legs       []Posting       `json:"legs"`
+	Amount     decimal.Decimal `json:"amount"`
+	Currency   string          `json:"currency"`
+}
+
+func (MultiLegTransferProcessed) EventType() string     { return EventTypeMultiLegTransferProcessed }
+func (MultiLegTransferProcessed) AggregateType() string { return AggregateTypeTransfer }
+func (e MultiLegTransferProcessed) AggregateID() string { return e.TransferID }
+
+type HoldCaptured struct {
+	TransferID    string          `json:"transfer_id"`
+	HoldID        string          `json:"hold_id"`
+	FromAccountID uint            `json:"from_account_id"`
+	ToAccountID   uint            `json:"to_account_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+}
+
+func (HoldCaptured) EventType() string     { return EventTypeHoldCaptured }
+func (HoldCaptured) AggregateType() string { return AggregateTypeTransfer }
+func (e HoldCaptured) AggregateID() string { return e.TransferID }
+
+// HoldAuthorized, HoldVoided and HoldExpired belong to the stream of the hold.
+// A captured hold is recorded by the HoldCaptured transfer instead.
+type HoldAuthorized struct {
+	HoldID               string          `json:"hold_id"`
+	SourceAccountID      uint            `json:"source_account_id"`
+	DestinationAccountID uint            `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	ExpiresAt            time.Time       `json:"expires_at"`
+}
+
+func (HoldAuthorized) EventType() string     { return EventTypeHoldAuthorized }
+func (HoldAuthorized) AggregateType() string { return AggregateTypeHold }
+func (e HoldAuthorized) AggregateID() string { return e.HoldID }
+
+type HoldVoided struct {
+	HoldID          string          `json:"hold_id"`
+	SourceAccountID uint            `json:"source_account_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+}
+
+func (HoldVoided) EventType() string     { return EventTypeHoldVoided }
+func (HoldVoided) AggregateType() string { return AggregateTypeHold }
+func (e HoldVoided) AggregateID() string { return e.HoldID }
+
+type HoldExpired struct {
+	HoldID          string          `json:"hold_id"`
+	SourceAccountID uint            `json:"source_account_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+}
+
+func (HoldExpired) EventType() string     { return EventTypeHoldExpired }
+func (HoldExpired) AggregateType() string { return AggregateTypeHold }
+func (e HoldExpired) AggregateID() string { return e.HoldID }
+
+// TransferReversed belongs to the stream of the reversed transfer, so a
+// transfer's stream records every reversal applied to it.
+type TransferReversed struct {
+	TransferID         string          `json:"transfer_id"`
+	ReversalTransferID string          `json:"reversal_transfer_id"`
+	Amount             decimal.Decimal `json:"amount"`
+	Currency           string          `json:"currency"`
+}
+
+func (TransferReversed) EventType() string     { return EventTypeTransferReversed }
+func (TransferReversed) AggregateType() string { return AggregateTypeTransfer }
+func (e TransferReversed) AggregateID() string { return e.TransferID }
+
+// AccountFunded moves the initial balance of a new account out of the equity
+// account of its currency.
+type AccountFunded struct {
+	TransferID       string          `json:"transfer_id"`
+	AccountID        uint            `json:"account_id"`
+	FundingAccountID uint            `json:"funding_account_id"`
+	Amount           decimal.Decimal `json:"amount"`
+	Currency         string          `json:"currency"`
+}
+
+func (AccountFunded) EventType() string     { return EventTypeAccountFunded }
+func (AccountFunded) AggregateType() string { return AggregateTypeTransfer }
+func (e AccountFunded) AggregateID() string { return e.TransferID }
+
+func accountAggregateID(accountID uint) string {
+	return strconv.FormatUint(uint64(accountID), 10)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type WebhookSubscriptionStatus string
+
+const (
+	WebhookSubscriptionStatusActive   WebhookSubscriptionStatus = "active"
+	WebhookSubscriptionStatusDisabled WebhookSubscriptionStatus = "disabled"
+)
+
+// WebhookEventTypes lists the event types a webhook subscription can receive.
+var WebhookEventTypes = []string{
+	EventTypeAccountOpened,
+	EventTypeAccountFunded,
+	EventTypeTransferProcessed,
+	EventTypeMultiLegTransferProcessed,
+	EventTypeHoldAuthorized,
+	EventTypeHoldCaptured,
+	EventTypeHoldVoided,
+	EventTypeHoldExpired,
+	EventTypeTransferReversed,
+}
+
+// WebhookSubscription receives a signed POST for every outbox message of one
+// of its EventTypes. The secret signing the requests is never returned by the
+// API. A subscription whose deliveries keep failing is disabled, with the
+// reason, until it is enabled again.
+type WebhookSubscription struct {
+	SubscriptionID      string                    `json:"subscription_id"`
+	URL                 string                    `json:"url"`
+	EventTypes          []string                  `json:"event_types"`
+	Secret              string                    `json:"-"`
+	Status              WebhookSubscriptionStatus `json:"status"`
+	ConsecutiveFailures int                       `json:"consecutive_failures"`
+	DisabledReason      string                    `json:"disabled_reason,omitempty"`
+	DisabledAt          *time.Time                `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time                 `json:"created_at"`
+	UpdatedAt           time.Time                 `json:"updated_at"`
+}
+
+// Subscribes reports whether the subscription receives events of eventType.
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	return slices.Contains(s.EventTypes, eventType)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one outbox message to deliver to one subscription. Payload
+// is the request body, the JSON encoding of the message. A delivery is pending
+// until the endpoint accepts it or it ran out of attempts.
+type WebhookDelivery struct {
+	DeliveryID     uint                     `json:"delivery_id"`
+	SubscriptionID string                   `json:"subscription_id"`
+	MessageID      uint                     `json:"message_id"`
+	EventType      string                   `json:"event_type"`
+	Payload        json.RawMessage          `json:"payload" swaggertype:"object"`
+	Status         WebhookDeliveryStatus    `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  time.Time                `json:"next_attempt_at"`
+	LastError      string                   `json:"last_error,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`
+	AttemptHistory []WebhookDeliveryAttempt `json:"attempt_history,omitempty"`
+}
+
+// WebhookDeliveryAttempt records one request of a delivery. StatusCode is zero
+// when no response was received.
+type WebhookDeliveryAttempt struct {
+	AttemptID   uint      `json:"attempt_id"`
+	DeliveryID  uint      `json:"delivery_id"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// NewWebhookDelivery builds the pending delivery of message to subscription,
+// due right away.
+func NewWebhookDelivery(subscription *WebhookSubscription, message *OutboxMessage, now time.Time) (*WebhookDelivery, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox message %d: %w", message.MessageID, err)
+	}
+
+	return &WebhookDelivery{
+		SubscriptionID: subscription.SubscriptionID,
+		MessageID:      message.MessageID,
+		EventType:      message.EventType,
+		Payload:        payload,
+		Status:         WebhookDeliveryStatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate is a quote for converting one unit of Base into Quote.
+type Rate struct {
+	Base      string
+	Quote     string
+	Value     decimal.Decimal
+	Source    string
+	Timestamp time.Time
+}
+
+// Convert applies the rate to an amount expressed in the base currency.
+func (r *Rate) Convert(amount decimal.Decimal) decimal.Decimal {
+	return amount.Mul(r.Value).Round(8)
+}
+
+type RateProvider interface {
+	GetRate(ctx context.Context, base, quote string) (*Rate, error)
+}
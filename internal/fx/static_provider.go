@@ -0,0 +1,88 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StaticRateProvider serves a fixed table of rates keyed by "BASE/QUOTE".
+// A missing pair is derived from its inverse when available.
+type StaticRateProvider struct {
+	source string
+	asOf   time.Time
+	rates  map[string]decimal.Decimal
+}
+
+func NewStaticRateProvider(source string, asOf time.Time, rates map[string]decimal.Decimal) *StaticRateProvider {
+	normalized := make(map[string]decimal.Decimal, len(rates))
+	for pair, rate := range rates {
+		normalized[strings.ToUpper(pair)] = rate
+	}
+	return &StaticRateProvider{
+		source: source,
+		asOf:   asOf,
+		rates:  normalized,
+	}
+}
+
+type rateFile struct {
+	Source string                     `json:"source"`
+	AsOf   time.Time                  `json:"as_of"`
+	Rates  map[string]decimal.Decimal `json:"rates"`
+}
+
+// NewFileRateProvider loads a JSON rate table such as:
+//
+//	{"source": "ecb", "as_of": "2026-01-02T16:00:00Z", "rates": {"EUR/USD": "1.0842"}}
+func NewFileRateProvider(path string) (*StaticRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate file: %w", err)
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rate file: %w", err)
+	}
+
+	for pair, rate := range file.Rates {
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("rate for %s must be positive", pair)
+		}
+	}
+
+	source := file.Source
+	if source == "" {
+		source = "file:" + path
+	}
+
+	return NewStaticRateProvider(source, file.AsOf, file.Rates), nil
+}
+
+func (p *StaticRateProvider) GetRate(ctx context.Context, base, quote string) (*Rate, error) {
+	base = strings.ToUpper(base)
+	quote = strings.ToUpper(quote)
+
+	value, ok := p.rates[base+"/"+quote]
+	if !ok {
+		inverse, ok := p.rates[quote+"/"+base]
+		if !ok || inverse.IsZero() {
+			return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+		}
+		value = decimal.NewFromInt(1).DivRound(inverse, 12)
+	}
+
+	return &Rate{
+		Base:      base,
+		Quote:     quote,
+		Value:     value,
+		Source:    p.source,
+		Timestamp: p.asOf,
+	}, nil
+}
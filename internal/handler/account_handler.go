@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
 	"github.com/dirdr/goits/internal/service"
@@ -11,6 +17,11 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	defaultTransactionsPageSize = 50
+	maxTransactionsPageSize     = 200
+)
+
 type AccountHandler struct {
 	accountService service.AccountService
 	log            *slog.Logger
@@ -27,13 +38,14 @@ func NewAccountHandler(accountService service.AccountService, log *slog.Logger,
 
 // CreateAccount godoc
 // @Summary Create a new account
-// @Description Creates a new account with a specified ID and initial balance.
+// @Description Creates a new account with a specified ID, ISO 4217 currency (defaults to USD) and initial balance. The type defaults to customer; fx_position accounts (one per currency) back currency conversions and equity accounts (one per currency, opened empty and provisioned on first use) fund the initial balances of new accounts through an AccountFunded transfer. IDs from 1000000000000000 up are reserved for system accounts.
 // @Tags accounts
 // @Accept json
 // @Produce json
 // @Param account body CreateAccountRequest true "Account creation request"
 // @Success 201 {string} string "Created"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Account already exists"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /accounts [post]
 func (h *AccountHandler) CreateAccount(c *gin.Context) {
@@ -44,12 +56,21 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 		return
 	}
 
-	var account *domain.Account
-	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
-		var err error
-		account, err = h.accountService.CreateAccount(c.Request.Context(), tx, uint(req.AccountID), req.InitialBalance)
-		return err
+	// Funding an initial balance moves the equity account of the currency,
+	// which concurrent openings race for.
+	account, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.Account, error) {
+		return h.accountService.CreateAccount(c.Request.Context(), tx, uint(req.AccountID), req.InitialBalance, req.Currency, req.Type)
 	})
+	if errors.Is(err, service.ErrInvalidAccount) {
+		h.log.Warn("Invalid account", "account_id", req.AccountID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrAccountExists) {
+		h.log.Warn("Account already exists", "account_id", req.AccountID, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to create account", "account_id", req.AccountID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -62,7 +83,7 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 
 // GetAccount godoc
 // @Summary Get account by ID
-// @Description Retrieves an account's details and current balance by its ID.
+// @Description Retrieves an account's details by its ID, with its ledger balance, the amount reserved by authorized holds and the resulting available balance.
 // @Tags accounts
 // @Accept json
 // @Produce json
@@ -108,12 +129,241 @@ func (h *AccountHandler) GetAccount(c *gin.Context) {
 	}
 
 	res := GetAccountResponse{
-		AccountID: account.ID,
-		Balance:   balance.Balance,
-		Version:   balance.Version,
-		UpdatedAt: balance.UpdatedAt,
+		AccountID:        account.ID,
+		Type:             account.Type,
+		Currency:         account.Currency,
+		Balance:          balance.Balance,
+		HeldAmount:       balance.HeldAmount,
+		AvailableBalance: balance.AvailableBalance(),
+		Version:          balance.Version,
+		UpdatedAt:        balance.UpdatedAt,
 	}
 
 	h.log.Info("Account retrieved successfully", "account_id", account.ID)
 	c.JSON(http.StatusOK, res)
 }
+
+// ListAccountTransactions godoc
+// @Summary List account transactions
+// @Description Lists every journal leg touching the account, newest first, with the running balance after each leg. Use next_cursor to fetch the following page.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param from query string false "Only include legs created at or after this RFC 3339 timestamp"
+// @Param to query string false "Only include legs created before this RFC 3339 timestamp"
+// @Param cursor query string false "Opaque cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Success 200 {object} ListAccountTransactionsResponse
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /accounts/{account_id}/transactions [get]
+func (h *AccountHandler) ListAccountTransactions(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+	accountID, err := strconv.ParseUint(accountIDStr, 10, 64)
+	if err != nil || accountID == 0 {
+		h.log.Error("Invalid account ID format - must be a positive integer", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account ID must be a positive integer"})
+		return
+	}
+
+	query, err := parseAccountJournalQuery(c, uint(accountID))
+	if err != nil {
+		h.log.Error("Invalid query for ListAccountTransactions", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var page *service.AccountTransactionsPage
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		page, err = h.accountService.ListAccountTransactions(c.Request.Context(), tx, query)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to list account transactions", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if page == nil {
+		h.log.Info("Account not found", "account_id", accountIDStr)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	res := ListAccountTransactionsResponse{
+		Transactions: make([]AccountTransactionResponse, 0, len(page.Entries)),
+	}
+	for _, entry := range page.Entries {
+		res.Transactions = append(res.Transactions, AccountTransactionResponse{
+			EntryID:               entry.EntryID,
+			TransferID:            entry.TransactionID,
+			Direction:             entry.Type,
+			Amount:                entry.Amount,
+			Currency:              entry.Currency,
+			CounterpartyAccountID: entry.CounterpartyAccountID,
+			RunningBalance:        entry.RunningBalance,
+			CreatedAt:             entry.CreatedAt,
+		})
+	}
+	if page.NextCursor != nil {
+		res.NextCursor = encodeJournalCursor(*page.NextCursor)
+	}
+
+	h.log.Info("Account transactions listed successfully", "account_id", accountID, "count", len(res.Transactions))
+	c.JSON(http.StatusOK, res)
+}
+
+// GetAccountBalanceAt godoc
+// @Summary Get an account's balance at a point in time
+// @Description Computes the account's balance from its latest snapshot (or opening event) and the journal entries after it, rather than from the projected balance. as_of is either an RFC 3339 timestamp or a transfer event ID; the balance then includes every entry created at or before the timestamp, or posted by that event or an earlier one. Without as_of, the current balance is computed.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param as_of query string false "RFC 3339 timestamp or transfer event ID"
+// @Success 200 {object} service.HistoricalBalance
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /accounts/{account_id}/balance [get]
+func (h *AccountHandler) GetAccountBalanceAt(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+	accountID, err := strconv.ParseUint(accountIDStr, 10, 64)
+	if err != nil || accountID == 0 {
+		h.log.Error("Invalid account ID format - must be a positive integer", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account ID must be a positive integer"})
+		return
+	}
+
+	point, err := parseBalancePoint(c.Query("as_of"))
+	if err != nil {
+		h.log.Error("Invalid query for GetAccountBalanceAt", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var balance *service.HistoricalBalance
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		balance, err = h.accountService.GetAccountBalanceAt(c.Request.Context(), tx, uint(accountID), point)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to get account balance", "account_id", accountIDStr, "as_of", c.Query("as_of"), "error", err)
+		switch {
+		case errors.Is(err, service.ErrAccountNotOpenedYet):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrIncompleteEventLog):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if balance == nil {
+		h.log.Info("Account not found", "account_id", accountIDStr)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	h.log.Info("Account balance computed successfully", "account_id", accountID, "as_of", c.Query("as_of"))
+	c.JSON(http.StatusOK, balance)
+}
+
+// parseBalancePoint reads as_of: an integer is a transfer event ID, anything
+// else must be an RFC 3339 timestamp. An empty value means now.
+func parseBalancePoint(v string) (domain.BalancePoint, error) {
+	if v == "" {
+		now := time.Now().UTC()
+		return domain.BalancePoint{Time: &now}, nil
+	}
+
+	if eventID, err := strconv.ParseUint(v, 10, 64); err == nil {
+		id := uint(eventID)
+		return domain.BalancePoint{EventID: &id}, nil
+	}
+
+	asOf, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return domain.BalancePoint{}, errors.New("as_of must be an RFC 3339 timestamp or a transfer event ID")
+	}
+	return domain.BalancePoint{Time: &asOf}, nil
+}
+
+func parseAccountJournalQuery(c *gin.Context, accountID uint) (domain.AccountJournalQuery, error) {
+	query := domain.AccountJournalQuery{
+		AccountID: accountID,
+		Limit:     defaultTransactionsPageSize,
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxTransactionsPageSize {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxTransactionsPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, errors.New("from must be an RFC 3339 timestamp")
+		}
+		query.From = &from
+	}
+
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, errors.New("to must be an RFC 3339 timestamp")
+		}
+		query.To = &to
+	}
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return query, errors.New("from must be before to")
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := decodeJournalCursor(v)
+		if err != nil {
+			return query, errors.New("cursor is invalid")
+		}
+		query.Before = &cursor
+	}
+
+	return query, nil
+}
+
+func encodeJournalCursor(cursor domain.JournalEntryCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixNano(), cursor.EntryID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeJournalCursor(s string) (domain.JournalEntryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return domain.JournalEntryCursor{}, err
+	}
+
+	createdAtStr, entryIDStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return domain.JournalEntryCursor{}, errors.New("malformed cursor")
+	}
+
+	createdAt, err := strconv.ParseInt(createdAtStr, 10, 64)
+	if err != nil {
+		return domain.JournalEntryCursor{}, err
+	}
+	entryID, err := strconv.ParseUint(entryIDStr, 10, 64)
+	if err != nil {
+		return domain.JournalEntryCursor{}, err
+	}
+
+	return domain.JournalEntryCursor{CreatedAt: time.Unix(0, createdAt).UTC(), EntryID: uint(entryID)}, nil
+}
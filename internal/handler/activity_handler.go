@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	activityPollInterval = 500 * time.Millisecond
+	activityKeepAlive    = 15 * time.Second
+	activityPollLimit    = 100
+)
+
+type ActivityHandler struct {
+	activityService service.ActivityService
+	log             *slog.Logger
+	db              *gorm.DB
+}
+
+func NewActivityHandler(activityService service.ActivityService, log *slog.Logger, db *gorm.DB) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+		log:             log,
+		db:              db,
+	}
+}
+
+// StreamAccountEvents godoc
+// @Summary Stream account activity
+// @Description Pushes, as server-sent events, every transfer touching the account (a "transfer" event) followed by the account's balance right after it (a "balance" event). Transfers are pushed in commit order once sealed into the hash chain, and the SSE id is their chain sequence: reconnect with Last-Event-ID, or the last_event_id query parameter, to resume right after it without missing updates. Without either, the stream starts with the next transfer.
+// @Tags accounts
+// @Produce text/event-stream
+// @Param account_id path string true "Account ID"
+// @Param Last-Event-ID header int false "Chain sequence of the transfer to resume after"
+// @Param last_event_id query int false "Chain sequence of the transfer to resume after, for clients that cannot set headers"
+// @Success 200 {string} string "Stream of transfer and balance events"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /accounts/{account_id}/events [get]
+func (h *ActivityHandler) StreamAccountEvents(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+	accountID, err := strconv.ParseUint(accountIDStr, 10, 64)
+	if err != nil || accountID == 0 {
+		h.log.Error("Invalid account ID format - must be a positive integer", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account ID must be a positive integer"})
+		return
+	}
+
+	h.streamActivity(c, uint(accountID))
+}
+
+// StreamEvents godoc
+// @Summary Stream all activity
+// @Description Pushes, as server-sent events, every transfer (a "transfer" event) followed by the balances of the accounts it touched (one "balance" event per account). Transfers are pushed in commit order once sealed into the hash chain, and the SSE id is their chain sequence: reconnect with Last-Event-ID, or the last_event_id query parameter, to resume right after it without missing updates. Without either, the stream starts with the next transfer.
+// @Tags events
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "Chain sequence of the transfer to resume after"
+// @Param last_event_id query int false "Chain sequence of the transfer to resume after, for clients that cannot set headers"
+// @Success 200 {string} string "Stream of transfer and balance events"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/stream [get]
+func (h *ActivityHandler) StreamEvents(c *gin.Context) {
+	h.streamActivity(c, 0)
+}
+
+// streamActivity writes the activity of accountID, or of every account when it
+// is 0, until the client goes away.
+func (h *ActivityHandler) streamActivity(c *gin.Context, accountID uint) {
+	ctx := c.Request.Context()
+
+	lastChainSeq, err := parseLastEventID(c)
+	if err != nil {
+		h.log.Error("Invalid Last-Event-ID", "account_id", accountID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var cursor domain.ActivityCursor
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		cursor, err = h.activityService.OpenActivityStream(ctx, tx, accountID, lastChainSeq)
+		return err
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to open activity stream", "account_id", accountID, "error", err)
+		if errors.Is(err, service.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	h.log.Info("Activity stream opened", "account_id", accountID, "after_chain_seq", cursor.ChainSeq)
+
+	poll := time.NewTicker(activityPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(activityKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var page *service.ActivityPage
+		err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			page, err = h.activityService.PollActivity(ctx, tx, service.ActivityQuery{
+				AccountID: accountID,
+				Cursor:    cursor,
+				Limit:     activityPollLimit,
+			})
+			return err
+		}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			if ctx.Err() == nil {
+				h.log.Error("Failed to poll activity", "account_id", accountID, "after_chain_seq", cursor.ChainSeq, "error", err)
+			}
+			return
+		}
+
+		for _, activity := range page.Activities {
+			if err := writeActivity(c.Writer, activity); err != nil {
+				h.log.Info("Activity stream closed", "account_id", accountID, "error", err)
+				return
+			}
+		}
+		c.Writer.Flush()
+		cursor = page.Cursor
+
+		if page.More {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			h.log.Info("Activity stream closed", "account_id", accountID, "after_chain_seq", cursor.ChainSeq)
+			return
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-poll.C:
+		}
+	}
+}
+
+// writeActivity tags only the last event of the group with the SSE id, so a
+// client cut off in the middle resumes before the whole group.
+func writeActivity(w io.Writer, activity domain.AccountActivity) error {
+	type message struct {
+		event string
+		data  any
+	}
+	messages := []message{{"transfer", activity.Transfer}}
+	for _, balance := range activity.Balances {
+		messages = append(messages, message{"balance", balance})
+	}
+
+	for i, m := range messages {
+		data, err := json.Marshal(m.data)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", m.event, err)
+		}
+		if i == len(messages)-1 {
+			if _, err := fmt.Fprintf(w, "id: %d\n", activity.Transfer.ChainSeq); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.event, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseLastEventID reads the Last-Event-ID header or the last_event_id query
+// parameter. It returns nil for a fresh stream.
+func parseLastEventID(c *gin.Context) (*uint, error) {
+	v := c.GetHeader("Last-Event-ID")
+	if v == "" {
+		v = c.Query("last_event_id")
+	}
+	if v == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("last event ID must be a non-negative integer")
+	}
+	chainSeq := uint(id)
+	return &chainSeq, nil
+}
@@ -3,23 +3,116 @@ package handler
 import (
 	"time"
 
+	"github.com/dirdr/goits/internal/domain"
 	"github.com/shopspring/decimal"
 )
 
 type CreateAccountRequest struct {
-	AccountID      uint            `json:"account_id"`
-	InitialBalance decimal.Decimal `json:"initial_balance"`
+	AccountID      uint               `json:"account_id"`
+	InitialBalance decimal.Decimal    `json:"initial_balance"`
+	Currency       string             `json:"currency"`
+	Type           domain.AccountType `json:"type"`
 }
 
 type GetAccountResponse struct {
-	AccountID uint            `json:"account_id"`
-	Balance   decimal.Decimal `json:"balance"`
-	Version   int             `json:"version"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	AccountID uint               `json:"account_id"`
+	Type      domain.AccountType `json:"type"`
+	Currency  string             `json:"currency"`
+	// Balance is the ledger balance; AvailableBalance excludes funds reserved
+	// by authorized holds.
+	Balance          decimal.Decimal `json:"balance"`
+	HeldAmount       decimal.Decimal `json:"held_amount"`
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	Version          int             `json:"version"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+type AccountTransactionResponse struct {
+	EntryID               uint             `json:"entry_id"`
+	TransferID            string           `json:"transfer_id"`
+	Direction             domain.EntryType `json:"direction"`
+	Amount                decimal.Decimal  `json:"amount"`
+	Currency              string           `json:"currency"`
+	CounterpartyAccountID uint             `json:"counterparty_account_id"`
+	RunningBalance        decimal.Decimal  `json:"running_balance"`
+	CreatedAt             time.Time        `json:"created_at"`
+}
+
+type ListAccountTransactionsResponse struct {
+	Transactions []AccountTransactionResponse `json:"transactions"`
+	NextCursor   string                       `json:"next_cursor,omitempty"`
 }
 
 type CreateTransactionRequest struct {
 	SourceAccountID      uint            `json:"source_account_id"`
 	DestinationAccountID uint            `json:"destination_account_id"`
 	Amount               decimal.Decimal `json:"amount"`
+	AllowConversion      bool            `json:"allow_conversion"`
+	// ExecuteAt defers the transfer to the given time instead of executing
+	// it immediately.
+	ExecuteAt *time.Time `json:"execute_at,omitempty"`
+}
+
+type CreateMultiLegTransactionRequest struct {
+	Legs []PostingRequest `json:"legs"`
+}
+
+type PostingRequest struct {
+	AccountID uint             `json:"account_id"`
+	Direction domain.EntryType `json:"direction"`
+	Amount    decimal.Decimal  `json:"amount"`
+}
+
+type ReverseTransactionRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type CreateTransactionResponse struct {
+	TransferID string `json:"transfer_id"`
+}
+
+type CreateHoldRequest struct {
+	SourceAccountID      uint            `json:"source_account_id"`
+	DestinationAccountID uint            `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	ExpiresInSeconds     int64           `json:"expires_in_seconds"`
+}
+
+type CaptureHoldRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type CreateStandingOrderRequest struct {
+	SourceAccountID      uint            `json:"source_account_id"`
+	DestinationAccountID uint            `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Schedule             string          `json:"schedule"`
+	StartAt              *time.Time      `json:"start_at,omitempty"`
+	EndAt                *time.Time      `json:"end_at,omitempty"`
+}
+
+type UpdateStandingOrderRequest struct {
+	Amount   *decimal.Decimal `json:"amount,omitempty"`
+	Schedule *string          `json:"schedule,omitempty"`
+	EndAt    *time.Time       `json:"end_at,omitempty"`
+}
+
+type CreateWebhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+type UpdateWebhookSubscriptionRequest struct {
+	URL        *string                           `json:"url,omitempty"`
+	EventTypes []string                          `json:"event_types,omitempty"`
+	Secret     *string                           `json:"secret,omitempty"`
+	Status     *domain.WebhookSubscriptionStatus `json:"status,omitempty"`
+}
+
+// RepairAccountsRequest restricts a repair to AccountID when set. Operator is
+// recorded in the audit of each repair and is required unless dry_run is set.
+type RepairAccountsRequest struct {
+	AccountID uint   `json:"account_id"`
+	Operator  string `json:"operator"`
 }
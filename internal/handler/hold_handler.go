@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HoldHandler struct {
+	holdService service.HoldService
+	log         *slog.Logger
+	db          *gorm.DB
+}
+
+func NewHoldHandler(holdService service.HoldService, log *slog.Logger, db *gorm.DB) *HoldHandler {
+	return &HoldHandler{
+		holdService: holdService,
+		log:         log,
+		db:          db,
+	}
+}
+
+// CreateHold godoc
+// @Summary Authorize a hold
+// @Description Reserves funds on the source account for a later capture to the destination account. Held funds stay in the ledger balance but are no longer available for other transfers. Holds that are neither captured nor voided are released once expired; expires_in_seconds defaults to 7 days.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client-generated key making the request safe to retry"
+// @Param hold body CreateHoldRequest true "Hold authorization request"
+// @Success 201 {object} domain.Hold
+// @Header 201 {string} Location "URL of the created hold"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Idempotency key reused with a different request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /holds [post]
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	var req CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Error("Invalid request body for CreateHold", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idempotencyKey, ok := readIdempotencyKey(c, h.log)
+	if !ok {
+		return
+	}
+
+	hold, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.Hold, error) {
+		return h.holdService.AuthorizeHold(c.Request.Context(), tx, service.HoldRequest{
+			SourceAccountID:      req.SourceAccountID,
+			DestinationAccountID: req.DestinationAccountID,
+			Amount:               req.Amount,
+			ExpiresIn:            time.Duration(req.ExpiresInSeconds) * time.Second,
+			IdempotencyKey:       idempotencyKey,
+		})
+	})
+	if errors.Is(err, service.ErrCurrencyMismatch) {
+		h.log.Error("Rejected cross-currency hold", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		h.log.Warn("Idempotency key reused with a different request", "idempotency_key", idempotencyKey)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to authorize hold", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Hold authorized successfully", "hold_id", hold.HoldID, "source_account_id", hold.SourceAccountID, "amount", hold.Amount, "expires_at", hold.ExpiresAt)
+	c.Header("Location", "/holds/"+hold.HoldID)
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHold godoc
+// @Summary Get hold by ID
+// @Description Retrieves a hold and its current status.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param hold_id path string true "Hold ID"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /holds/{hold_id} [get]
+func (h *HoldHandler) GetHold(c *gin.Context) {
+	holdID, ok := h.holdID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdService.GetHold(c.Request.Context(), holdID)
+	if err != nil {
+		h.log.Error("Failed to get hold", "hold_id", holdID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if hold == nil {
+		h.log.Info("Hold not found", "hold_id", holdID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		return
+	}
+
+	h.log.Info("Hold retrieved successfully", "hold_id", holdID)
+	c.JSON(http.StatusOK, hold)
+}
+
+// CaptureHold godoc
+// @Summary Capture a hold
+// @Description Transfers the captured amount from the source to the destination account and releases the hold. Omit the amount to capture the full hold; after a partial capture the remainder is released.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client-generated key making the request safe to retry"
+// @Param hold_id path string true "Hold ID"
+// @Param capture body CaptureHoldRequest false "Capture request"
+// @Success 201 {object} CreateTransactionResponse
+// @Header 201 {string} Location "URL of the capture transaction"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Hold no longer authorized or idempotency key reused"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /holds/{hold_id}/capture [post]
+func (h *HoldHandler) CaptureHold(c *gin.Context) {
+	holdID, ok := h.holdID(c)
+	if !ok {
+		return
+	}
+
+	var req CaptureHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("Invalid request body for CaptureHold", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idempotencyKey, ok := readIdempotencyKey(c, h.log)
+	if !ok {
+		return
+	}
+
+	event, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.TransferEvent, error) {
+		return h.holdService.CaptureHold(c.Request.Context(), tx, service.CaptureRequest{
+			HoldID:         holdID,
+			Amount:         req.Amount,
+			IdempotencyKey: idempotencyKey,
+		})
+	})
+	if errors.Is(err, service.ErrHoldNotFound) {
+		h.log.Info("Hold to capture not found", "hold_id", holdID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		return
+	}
+	if errors.Is(err, service.ErrCaptureExceedsHold) {
+		h.log.Error("Rejected hold capture", "hold_id", holdID, "amount", req.Amount, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrHoldNotAuthorized) || errors.Is(err, service.ErrHoldExpired) || errors.Is(err, service.ErrIdempotencyKeyReused) {
+		h.log.Warn("Hold cannot be captured", "hold_id", holdID, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to capture hold", "hold_id", holdID, "amount", req.Amount, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Hold captured successfully", "hold_id", holdID, "transfer_id", event.TransferID, "amount", event.Amount)
+	c.Header("Location", "/transactions/"+event.TransferID)
+	c.JSON(http.StatusCreated, CreateTransactionResponse{TransferID: event.TransferID})
+}
+
+// VoidHold godoc
+// @Summary Void a hold
+// @Description Releases an authorized hold without moving any funds.
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param hold_id path string true "Hold ID"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Hold no longer authorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /holds/{hold_id}/void [post]
+func (h *HoldHandler) VoidHold(c *gin.Context) {
+	holdID, ok := h.holdID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.Hold, error) {
+		return h.holdService.VoidHold(c.Request.Context(), tx, holdID)
+	})
+	if errors.Is(err, service.ErrHoldNotFound) {
+		h.log.Info("Hold to void not found", "hold_id", holdID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		return
+	}
+	if errors.Is(err, service.ErrHoldNotAuthorized) {
+		h.log.Warn("Hold cannot be voided", "hold_id", holdID, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to void hold", "hold_id", holdID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Hold voided successfully", "hold_id", holdID, "amount", hold.Amount)
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *HoldHandler) holdID(c *gin.Context) (string, bool) {
+	holdID := c.Param("hold_id")
+	if _, err := uuid.Parse(holdID); err != nil {
+		h.log.Error("Invalid hold ID format - must be a UUID", "hold_id", holdID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hold ID must be a valid UUID"})
+		return "", false
+	}
+	return holdID, true
+}
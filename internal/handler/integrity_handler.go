@@ -1,14 +1,26 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/dirdr/goits/internal/domain"
 	"github.com/dirdr/goits/internal/service"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// Page sizes of the integrity run and repair listings.
+const (
+	defaultIntegrityRunsPageSize = 50
+	maxIntegrityRunsPageSize     = 200
+)
+
 type IntegrityHandler struct {
 	integrityService service.IntegrityService
 	log              *slog.Logger
@@ -25,7 +37,7 @@ func NewIntegrityHandler(integrityService service.IntegrityService, log *slog.Lo
 
 // CheckIntegrity godoc
 // @Summary Check double bookkeeping integrity
-// @Description Verifies that the total debits equal total credits in the journal entries.
+// @Description Verifies that, for every currency, the total debits equal total credits in the journal entries. The top-level totals sum every currency and are kept for backward compatibility; is_valid holds only when every currency balances.
 // @Tags integrity
 // @Accept json
 // @Produce json
@@ -40,16 +52,435 @@ func (h *IntegrityHandler) CheckIntegrity(c *gin.Context) {
 		return
 	}
 
-	if result.IsValid {
-		h.log.Info("Double bookkeeping integrity verified successfully",
-			"total_debits", result.TotalDebits,
-			"total_credits", result.TotalCredits)
-	} else {
-		h.log.Warn("Double bookkeeping integrity check failed",
-			"total_debits", result.TotalDebits,
-			"total_credits", result.TotalCredits,
-			"difference", result.Difference)
+	for _, currency := range result.Currencies {
+		if currency.IsValid {
+			h.log.Info("Double bookkeeping integrity verified successfully",
+				"currency", currency.Currency,
+				"total_debits", currency.TotalDebits,
+				"total_credits", currency.TotalCredits)
+		} else {
+			h.log.Warn("Double bookkeeping integrity check failed",
+				"currency", currency.Currency,
+				"total_debits", currency.TotalDebits,
+				"total_credits", currency.TotalCredits,
+				"difference", currency.Difference)
+		}
 	}
 
 	c.JSON(http.StatusOK, result)
 }
+
+// VerifyHashChains godoc
+// @Summary Verify the hash chains of the ledger
+// @Description Walks the hash chains of transfer_events and journal_entries, recomputing the hash of every sealed row and checking that it links to the row before it, and reports the first broken link of each chain. A broken link means a row was edited or deleted outside of the application. Rows are sealed in the background shortly after they commit (every HASH_CHAIN_SEAL_INTERVAL).
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.HashChainVerification "Hash chain verification result"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/hash-chains [get]
+func (h *IntegrityHandler) VerifyHashChains(c *gin.Context) {
+	var verification *service.HashChainVerification
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		verification, err = h.integrityService.VerifyHashChains(c.Request.Context(), tx)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to verify hash chains", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, chain := range verification.Chains {
+		if chain.IsValid {
+			h.log.Info("Hash chain verified successfully", "chain", chain.Chain, "rows", chain.RowsVerified, "last_id", chain.LastID)
+		} else {
+			h.log.Warn("Hash chain is broken",
+				"chain", chain.Chain,
+				"row_id", chain.BrokenLink.RowID,
+				"previous_row_id", chain.BrokenLink.PreviousRowID,
+				"reason", chain.BrokenLink.Reason)
+		}
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// ReconcileAccounts godoc
+// @Summary Reconcile account balances against the journal
+// @Description Recomputes the balance of every account from its opening balance and journal entries and compares it with account_balances, listing the accounts whose balance or last event ID drifted. Accounts without an AccountOpened event cannot be reconciled and are listed as well.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.AccountReconciliationReport "Account reconciliation report"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/accounts [get]
+func (h *IntegrityHandler) ReconcileAccounts(c *gin.Context) {
+	var report *service.AccountReconciliationReport
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = h.integrityService.ReconcileAccounts(c.Request.Context(), tx)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to reconcile accounts", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if report.IsValid {
+		h.log.Info("Account balances reconciled successfully", "accounts", report.AccountsChecked)
+	} else {
+		for _, account := range report.Drifting {
+			h.log.Warn("Account balance drifted from the journal",
+				"account_id", account.AccountID,
+				"reason", account.Reason,
+				"expected_balance", account.ExpectedBalance,
+				"projected_balance", account.ProjectedBalance)
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ReconcileAccount godoc
+// @Summary Reconcile one account balance against the journal
+// @Description Recomputes the balance of the account from its opening balance and journal entries and compares it with its account_balances row.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Success 200 {object} service.AccountReconciliation "Account reconciliation"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/accounts/{account_id} [get]
+func (h *IntegrityHandler) ReconcileAccount(c *gin.Context) {
+	accountIDStr := c.Param("account_id")
+	accountID, err := strconv.ParseUint(accountIDStr, 10, 64)
+	if err != nil || accountID == 0 {
+		h.log.Error("Invalid account ID format - must be a positive integer", "account_id", accountIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account ID must be a positive integer"})
+		return
+	}
+
+	var reconciliation *service.AccountReconciliation
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		reconciliation, err = h.integrityService.ReconcileAccount(c.Request.Context(), tx, uint(accountID))
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to reconcile account", "account_id", accountID, "error", err)
+		if errors.Is(err, service.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if reconciliation.IsValid {
+		h.log.Info("Account balance reconciled successfully", "account_id", accountID)
+	} else {
+		h.log.Warn("Account balance drifted from the journal",
+			"account_id", accountID,
+			"reason", reconciliation.Reason,
+			"expected_balance", reconciliation.ExpectedBalance,
+			"projected_balance", reconciliation.ProjectedBalance)
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
+
+// VerifyTransactions godoc
+// @Summary Verify every transaction of the journal
+// @Description Checks, for every transfer event, that the journal entries of its transaction balance within each currency and are exactly the legs the event implies (accounts, directions and amounts), and lists the unbalanced or malformed transactions. Unlike the global check, two offsetting bad transactions cannot hide each other.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.TransactionVerificationReport "Transaction verification report"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/transactions [get]
+func (h *IntegrityHandler) VerifyTransactions(c *gin.Context) {
+	var report *service.TransactionVerificationReport
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = h.integrityService.VerifyTransactions(c.Request.Context(), tx)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to verify transactions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if report.IsValid {
+		h.log.Info("Transactions verified successfully", "transactions", report.TransactionsChecked)
+	} else {
+		for _, issue := range report.Invalid {
+			h.log.Warn("Transaction failed verification",
+				"transaction_id", issue.TransactionID,
+				"event_id", issue.EventID,
+				"reasons", issue.Reasons)
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// VerifyLinkage godoc
+// @Summary Verify the links between transfer events and journal entries
+// @Description Checks that every transfer event has the debit and credit journal entries it implies (one of each for a plain transfer), linked by source event ID, with matching amounts and transaction, and lists the orphaned events, the journal entries pointing to a missing event and those pointing to a missing account.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.LinkageReport "Linkage report"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/linkage [get]
+func (h *IntegrityHandler) VerifyLinkage(c *gin.Context) {
+	var report *service.LinkageReport
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = h.integrityService.VerifyLinkage(c.Request.Context(), tx)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to verify linkage", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if report.IsValid {
+		h.log.Info("Linkage verified successfully", "events", report.EventsChecked)
+	} else {
+		for _, issue := range report.Events {
+			h.log.Warn("Transfer event failed linkage verification",
+				"event_id", issue.EventID,
+				"transfer_id", issue.TransferID,
+				"reasons", issue.Reasons)
+		}
+		h.log.Warn("Journal entries failed linkage verification",
+			"orphaned_entries", len(report.OrphanedEntries),
+			"entries_with_missing_account", len(report.EntriesWithMissingAccount))
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListIntegrityRuns godoc
+// @Summary List integrity monitor runs
+// @Description Lists the runs of the background integrity monitor, most recent first, with the status and report of every check. A run failed when a check found the ledger inconsistent, and is in error when a check could not be run.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Param status query string false "passed, failed or error"
+// @Param limit query int false "Maximum number of runs (default 50, max 200)"
+// @Success 200 {array} domain.IntegrityRun
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/runs [get]
+func (h *IntegrityHandler) ListIntegrityRuns(c *gin.Context) {
+	query, err := parseIntegrityRunQuery(c)
+	if err != nil {
+		h.log.Error("Invalid query for ListIntegrityRuns", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	runs, err := h.integrityService.ListIntegrityRuns(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to list integrity runs", "status", query.Status, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Integrity runs listed successfully", "status", query.Status, "count", len(runs))
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetIntegrityRun godoc
+// @Summary Get integrity monitor run by ID
+// @Description Retrieves a run of the background integrity monitor with the status and report of every check.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Param run_id path int true "Run ID"
+// @Success 200 {object} domain.IntegrityRun
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/runs/{run_id} [get]
+func (h *IntegrityHandler) GetIntegrityRun(c *gin.Context) {
+	runIDStr := c.Param("run_id")
+	runID, err := strconv.ParseUint(runIDStr, 10, 64)
+	if err != nil || runID == 0 {
+		h.log.Error("Invalid run ID format - must be a positive integer", "run_id", runIDStr, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Run ID must be a positive integer"})
+		return
+	}
+
+	run, err := h.integrityService.GetIntegrityRun(c.Request.Context(), uint(runID))
+	if err != nil {
+		h.log.Error("Failed to get integrity run", "run_id", runID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if run == nil {
+		h.log.Info("Integrity run not found", "run_id", runID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Integrity run not found"})
+		return
+	}
+
+	h.log.Info("Integrity run retrieved successfully", "run_id", runID)
+	c.JSON(http.StatusOK, run)
+}
+
+func parseIntegrityRunQuery(c *gin.Context) (domain.IntegrityRunQuery, error) {
+	query := domain.IntegrityRunQuery{Limit: defaultIntegrityRunsPageSize}
+
+	if v := c.Query("status"); v != "" {
+		status := domain.IntegrityStatus(v)
+		switch status {
+		case domain.IntegrityStatusPassed, domain.IntegrityStatusFailed, domain.IntegrityStatusError:
+			query.Status = status
+		default:
+			return query, errors.New("status must be one of passed, failed or error")
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxIntegrityRunsPageSize {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxIntegrityRunsPageSize)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+// RepairAccounts godoc
+// @Summary Repair drifting account balances
+// @Description Recalculates the balance and last event ID of every account whose account_balances row drifted from its journal, or only account_id, and reports the repairs. Unless dry_run is false, nothing is written; otherwise every repair is applied with optimistic locking and audited with the operator. Accounts missing their opening event or balance row are reported as unrepairable; a projection rebuild handles them.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Report the repairs without applying them (default true)"
+// @Param request body RepairAccountsRequest false "Account to repair and operator"
+// @Success 200 {object} service.ProjectionRepairReport
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/repairs [post]
+func (h *IntegrityHandler) RepairAccounts(c *gin.Context) {
+	dryRun := true
+	if v := c.Query("dry_run"); v != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			h.log.Error("Invalid dry_run parameter", "dry_run", v, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be a boolean"})
+			return
+		}
+	}
+
+	var req RepairAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("Invalid request body for RepairAccounts", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var report *service.ProjectionRepairReport
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = h.integrityService.RepairAccounts(c.Request.Context(), tx, service.ProjectionRepairRequest{
+			AccountID: req.AccountID,
+			Operator:  req.Operator,
+			DryRun:    dryRun,
+		})
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		h.log.Error("Failed to repair account balances", "account_id", req.AccountID, "dry_run", dryRun, "error", err)
+		switch {
+		case errors.Is(err, service.ErrRepairOperatorRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAccountNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	for _, repair := range report.Repairs {
+		h.log.Warn("Account balance repaired from the journal",
+			"account_id", repair.AccountID,
+			"reason", repair.Reason,
+			"old_balance", repair.OldBalance,
+			"new_balance", repair.NewBalance,
+			"old_last_event_id", repair.OldLastEventID,
+			"new_last_event_id", repair.NewLastEventID,
+			"operator", repair.Operator,
+			"dry_run", dryRun)
+	}
+	h.log.Info("Account balances repaired", "accounts", report.AccountsChecked, "repairs", len(report.Repairs), "unrepairable", len(report.Unrepairable), "dry_run", dryRun)
+	c.JSON(http.StatusOK, report)
+}
+
+// ListProjectionRepairs godoc
+// @Summary List account balance repairs
+// @Description Lists the audit of applied account balance repairs, most recent first, with the old and new values and the operator.
+// @Tags integrity
+// @Accept json
+// @Produce json
+// @Param account_id query int false "Only repairs of this account"
+// @Param limit query int false "Maximum number of repairs (default 50, max 200)"
+// @Success 200 {array} domain.ProjectionRepair
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrity/repairs [get]
+func (h *IntegrityHandler) ListProjectionRepairs(c *gin.Context) {
+	query, err := parseProjectionRepairQuery(c)
+	if err != nil {
+		h.log.Error("Invalid query for ListProjectionRepairs", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	repairs, err := h.integrityService.ListProjectionRepairs(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to list projection repairs", "account_id", query.AccountID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Projection repairs listed successfully", "account_id", query.AccountID, "count", len(repairs))
+	c.JSON(http.StatusOK, repairs)
+}
+
+func parseProjectionRepairQuery(c *gin.Context) (domain.ProjectionRepairQuery, error) {
+	query := domain.ProjectionRepairQuery{Limit: defaultIntegrityRunsPageSize}
+
+	if v := c.Query("account_id"); v != "" {
+		accountID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || accountID == 0 {
+			return query, errors.New("account_id must be a positive integer")
+		}
+		query.AccountID = uint(accountID)
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxIntegrityRunsPageSize {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxIntegrityRunsPageSize)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
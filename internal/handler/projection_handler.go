@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProjectionHandler struct {
+	projectionService service.ProjectionService
+	log               *slog.Logger
+	db                *gorm.DB
+}
+
+func NewProjectionHandler(projectionService service.ProjectionService, log *slog.Logger, db *gorm.DB) *ProjectionHandler {
+	return &ProjectionHandler{
+		projectionService: projectionService,
+		log:               log,
+		db:                db,
+	}
+}
+
+// RebuildAccountBalances godoc
+// @Summary Rebuild the account balances projection
+// @Description Replays the event log, from the latest snapshot on, into a fresh account_balances projection, reports every account whose balance or last event ID differs from the live projection, and swaps the rebuilt projection in. Postings are blocked while the rebuild runs. With dry_run, only the report is produced.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Compare without swapping (default false)"
+// @Success 200 {object} service.ProjectionRebuildReport
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Event log cannot rebuild every account"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/projections/account-balances/rebuild [post]
+func (h *ProjectionHandler) RebuildAccountBalances(c *gin.Context) {
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			h.log.Error("Invalid dry_run parameter", "dry_run", v, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be a boolean"})
+			return
+		}
+	}
+
+	var report *service.ProjectionRebuildReport
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = h.projectionService.RebuildAccountBalances(c.Request.Context(), tx, dryRun)
+		return err
+	})
+	if errors.Is(err, service.ErrIncompleteEventLog) {
+		h.log.Warn("Account balances cannot be rebuilt from the event log", "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to rebuild account balances", "dry_run", dryRun, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, divergence := range report.Divergences {
+		h.log.Warn("Account balance diverged from the event log",
+			"account_id", divergence.AccountID,
+			"live_balance", divergence.LiveBalance,
+			"rebuilt_balance", divergence.RebuiltBalance,
+			"live_last_event_id", divergence.LiveLastEventID,
+			"rebuilt_last_event_id", divergence.RebuiltLastEventID)
+	}
+	h.log.Info("Account balances rebuilt", "accounts", report.AccountsRebuilt, "events", report.EventsReplayed, "divergences", len(report.Divergences), "swapped", report.Swapped)
+	c.JSON(http.StatusOK, report)
+}
@@ -14,22 +14,81 @@ import (
 func GetRouter(
 	accountService service.AccountService,
 	transactionService service.TransactionService,
+	holdService service.HoldService,
+	scheduledTransferService service.ScheduledTransferService,
+	standingOrderService service.StandingOrderService,
 	integrityService service.IntegrityService,
+	projectionService service.ProjectionService,
+	snapshotService service.SnapshotService,
+	webhookService service.WebhookService,
+	activityService service.ActivityService,
 	log *slog.Logger,
 	db *gorm.DB,
 ) *gin.Engine {
 	r := gin.Default()
 
 	accountHandler := NewAccountHandler(accountService, log, db)
-	transactionHandler := NewTransactionHandler(transactionService, log, db)
+	transactionHandler := NewTransactionHandler(transactionService, scheduledTransferService, log, db)
+	holdHandler := NewHoldHandler(holdService, log, db)
+	scheduledTransferHandler := NewScheduledTransferHandler(scheduledTransferService, log, db)
+	standingOrderHandler := NewStandingOrderHandler(standingOrderService, log, db)
 	integrityHandler := NewIntegrityHandler(integrityService, log, db)
+	projectionHandler := NewProjectionHandler(projectionService, log, db)
+	snapshotHandler := NewSnapshotHandler(snapshotService, log, db)
+	webhookHandler := NewWebhookHandler(webhookService, log, db)
+	activityHandler := NewActivityHandler(activityService, log, db)
 
 	r.POST("/accounts", accountHandler.CreateAccount)
 	r.GET("/accounts/:account_id", accountHandler.GetAccount)
+	r.GET("/accounts/:account_id/transactions", accountHandler.ListAccountTransactions)
+	r.GET("/accounts/:account_id/balance", accountHandler.GetAccountBalanceAt)
+	r.GET("/accounts/:account_id/events", activityHandler.StreamAccountEvents)
+
+	r.GET("/events/stream", activityHandler.StreamEvents)
 
 	r.POST("/transactions", transactionHandler.CreateTransaction)
+	r.POST("/transactions/multi-leg", transactionHandler.CreateMultiLegTransaction)
+	r.GET("/transactions/:transfer_id", transactionHandler.GetTransaction)
+	r.POST("/transactions/:transfer_id/reverse", transactionHandler.ReverseTransaction)
+
+	r.POST("/holds", holdHandler.CreateHold)
+	r.GET("/holds/:hold_id", holdHandler.GetHold)
+	r.POST("/holds/:hold_id/capture", holdHandler.CaptureHold)
+	r.POST("/holds/:hold_id/void", holdHandler.VoidHold)
+
+	r.GET("/scheduled-transfers", scheduledTransferHandler.ListScheduledTransfers)
+	r.GET("/scheduled-transfers/:schedule_id", scheduledTransferHandler.GetScheduledTransfer)
+	r.POST("/scheduled-transfers/:schedule_id/cancel", scheduledTransferHandler.CancelScheduledTransfer)
+
+	r.POST("/standing-orders", standingOrderHandler.CreateStandingOrder)
+	r.GET("/standing-orders", standingOrderHandler.ListStandingOrders)
+	r.GET("/standing-orders/:standing_order_id", standingOrderHandler.GetStandingOrder)
+	r.PATCH("/standing-orders/:standing_order_id", standingOrderHandler.UpdateStandingOrder)
+	r.DELETE("/standing-orders/:standing_order_id", standingOrderHandler.CancelStandingOrder)
+	r.GET("/standing-orders/:standing_order_id/executions", standingOrderHandler.ListStandingOrderExecutions)
+
+	r.POST("/webhooks/subscriptions", webhookHandler.CreateWebhookSubscription)
+	r.GET("/webhooks/subscriptions", webhookHandler.ListWebhookSubscriptions)
+	r.GET("/webhooks/subscriptions/:subscription_id", webhookHandler.GetWebhookSubscription)
+	r.PATCH("/webhooks/subscriptions/:subscription_id", webhookHandler.UpdateWebhookSubscription)
+	r.DELETE("/webhooks/subscriptions/:subscription_id", webhookHandler.DeleteWebhookSubscription)
+	r.GET("/webhooks/subscriptions/:subscription_id/deliveries", webhookHandler.ListWebhookDeliveries)
+	r.GET("/webhooks/deliveries/:delivery_id", webhookHandler.GetWebhookDelivery)
+	r.POST("/webhooks/deliveries/:delivery_id/redeliver", webhookHandler.RedeliverWebhook)
 
 	r.GET("/integrity/check", integrityHandler.CheckIntegrity)
+	r.GET("/integrity/hash-chains", integrityHandler.VerifyHashChains)
+	r.GET("/integrity/transactions", integrityHandler.VerifyTransactions)
+	r.GET("/integrity/linkage", integrityHandler.VerifyLinkage)
+	r.GET("/integrity/accounts", integrityHandler.ReconcileAccounts)
+	r.GET("/integrity/accounts/:account_id", integrityHandler.ReconcileAccount)
+	r.GET("/integrity/runs", integrityHandler.ListIntegrityRuns)
+	r.GET("/integrity/runs/:run_id", integrityHandler.GetIntegrityRun)
+	r.POST("/integrity/repairs", integrityHandler.RepairAccounts)
+	r.GET("/integrity/repairs", integrityHandler.ListProjectionRepairs)
+
+	r.POST("/admin/projections/account-balances/rebuild", projectionHandler.RebuildAccountBalances)
+	r.GET("/admin/snapshots/verify", snapshotHandler.VerifySnapshot)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultScheduledTransfersPageSize = 50
+	maxScheduledTransfersPageSize     = 200
+)
+
+type ScheduledTransferHandler struct {
+	scheduledTransferService service.ScheduledTransferService
+	log                      *slog.Logger
+	db                       *gorm.DB
+}
+
+func NewScheduledTransferHandler(scheduledTransferService service.ScheduledTransferService, log *slog.Logger, db *gorm.DB) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{
+		scheduledTransferService: scheduledTransferService,
+		log:                      log,
+		db:                       db,
+	}
+}
+
+// ListScheduledTransfers godoc
+// @Summary List scheduled transfers
+// @Description Lists scheduled transfers in execution order, pending ones by default. Failed transfers carry the reason they could not be executed.
+// @Tags scheduled-transfers
+// @Accept json
+// @Produce json
+// @Param status query string false "pending (default), executed, failed or cancelled"
+// @Param source_account_id query int false "Only include transfers from this account"
+// @Param limit query int false "Maximum number of transfers (default 50, max 200)"
+// @Success 200 {array} domain.ScheduledTransfer
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /scheduled-transfers [get]
+func (h *ScheduledTransferHandler) ListScheduledTransfers(c *gin.Context) {
+	query, err := parseScheduledTransferQuery(c)
+	if err != nil {
+		h.log.Error("Invalid query for ListScheduledTransfers", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduled, err := h.scheduledTransferService.ListScheduledTransfers(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to list scheduled transfers", "status", query.Status, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Scheduled transfers listed successfully", "status", query.Status, "count", len(scheduled))
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// GetScheduledTransfer godoc
+// @Summary Get scheduled transfer by ID
+// @Description Retrieves a scheduled transfer with its status, the resulting transfer ID once executed, or the failure reason.
+// @Tags scheduled-transfers
+// @Accept json
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} domain.ScheduledTransfer
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /scheduled-transfers/{schedule_id} [get]
+func (h *ScheduledTransferHandler) GetScheduledTransfer(c *gin.Context) {
+	scheduleID, ok := h.scheduleID(c)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.scheduledTransferService.GetScheduledTransfer(c.Request.Context(), scheduleID)
+	if err != nil {
+		h.log.Error("Failed to get scheduled transfer", "schedule_id", scheduleID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if scheduled == nil {
+		h.log.Info("Scheduled transfer not found", "schedule_id", scheduleID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scheduled transfer not found"})
+		return
+	}
+
+	h.log.Info("Scheduled transfer retrieved successfully", "schedule_id", scheduleID)
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// CancelScheduledTransfer godoc
+// @Summary Cancel a scheduled transfer
+// @Description Cancels a pending scheduled transfer so that it is never executed.
+// @Tags scheduled-transfers
+// @Accept json
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} domain.ScheduledTransfer
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Scheduled transfer no longer pending"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /scheduled-transfers/{schedule_id}/cancel [post]
+func (h *ScheduledTransferHandler) CancelScheduledTransfer(c *gin.Context) {
+	scheduleID, ok := h.scheduleID(c)
+	if !ok {
+		return
+	}
+
+	scheduled, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.ScheduledTransfer, error) {
+		return h.scheduledTransferService.CancelScheduledTransfer(c.Request.Context(), tx, scheduleID)
+	})
+	if errors.Is(err, service.ErrScheduledTransferNotFound) {
+		h.log.Info("Scheduled transfer to cancel not found", "schedule_id", scheduleID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scheduled transfer not found"})
+		return
+	}
+	if errors.Is(err, service.ErrScheduledTransferNotPending) {
+		h.log.Warn("Scheduled transfer cannot be cancelled", "schedule_id", scheduleID, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to cancel scheduled transfer", "schedule_id", scheduleID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Scheduled transfer cancelled successfully", "schedule_id", scheduleID)
+	c.JSON(http.StatusOK, scheduled)
+}
+
+func (h *ScheduledTransferHandler) scheduleID(c *gin.Context) (string, bool) {
+	scheduleID := c.Param("schedule_id")
+	if _, err := uuid.Parse(scheduleID); err != nil {
+		h.log.Error("Invalid schedule ID format - must be a UUID", "schedule_id", scheduleID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Schedule ID must be a valid UUID"})
+		return "", false
+	}
+	return scheduleID, true
+}
+
+func parseScheduledTransferQuery(c *gin.Context) (domain.ScheduledTransferQuery, error) {
+	query := domain.ScheduledTransferQuery{
+		Status: domain.ScheduledTransferStatusPending,
+		Limit:  defaultScheduledTransfersPageSize,
+	}
+
+	if v := c.Query("status"); v != "" {
+		status := domain.ScheduledTransferStatus(v)
+		switch status {
+		case domain.ScheduledTransferStatusPending, domain.ScheduledTransferStatusExecuted,
+			domain.ScheduledTransferStatusFailed, domain.ScheduledTransferStatusCancelled:
+			query.Status = status
+		default:
+			return query, errors.New("status must be one of pending, executed, failed or cancelled")
+		}
+	}
+
+	if v := c.Query("source_account_id"); v != "" {
+		accountID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || accountID == 0 {
+			return query, errors.New("source_account_id must be a positive integer")
+		}
+		query.SourceAccountID = uint(accountID)
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxScheduledTransfersPageSize {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxScheduledTransfersPageSize)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
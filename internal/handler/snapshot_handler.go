@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SnapshotHandler struct {
+	snapshotService service.SnapshotService
+	log             *slog.Logger
+	db              *gorm.DB
+}
+
+func NewSnapshotHandler(snapshotService service.SnapshotService, log *slog.Logger, db *gorm.DB) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+		log:             log,
+		db:              db,
+	}
+}
+
+// VerifySnapshot godoc
+// @Summary Verify an account snapshot
+// @Description Compares the account states recorded by a snapshot with a full replay of the event log up to the same transfer event, and reports every account that differs.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param event_id query int false "Event ID of the snapshot to verify (default latest)"
+// @Success 200 {object} service.SnapshotVerification
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Event log cannot replay every account"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/snapshots/verify [get]
+func (h *SnapshotHandler) VerifySnapshot(c *gin.Context) {
+	var eventID *uint
+	if v := c.Query("event_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			h.log.Error("Invalid event_id parameter", "event_id", v, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "event_id must be a positive integer"})
+			return
+		}
+		snapshotEventID := uint(id)
+		eventID = &snapshotEventID
+	}
+
+	var verification *service.SnapshotVerification
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		verification, err = h.snapshotService.VerifySnapshot(c.Request.Context(), tx, eventID)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		h.log.Error("Failed to verify snapshot", "event_id", c.Query("event_id"), "error", err)
+		switch {
+		case errors.Is(err, service.ErrSnapshotNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrIncompleteEventLog):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	for _, divergence := range verification.Divergences {
+		h.log.Warn("Snapshot diverged from a full replay",
+			"event_id", verification.EventID,
+			"account_id", divergence.AccountID,
+			"snapshot_balance", divergence.SnapshotBalance,
+			"replayed_balance", divergence.ReplayedBalance,
+			"snapshot_last_event_id", divergence.SnapshotLastEventID,
+			"replayed_last_event_id", divergence.ReplayedLastEventID)
+	}
+	h.log.Info("Snapshot verified", "event_id", verification.EventID, "accounts", verification.AccountsChecked, "divergences", len(verification.Divergences))
+	c.JSON(http.StatusOK, verification)
+}
@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultStandingOrdersPageSize = 50
+	maxStandingOrdersPageSize     = 200
+)
+
+type StandingOrderHandler struct {
+	standingOrderService service.StandingOrderService
+	log                  *slog.Logger
+	db                   *gorm.DB
+}
+
+func NewStandingOrderHandler(standingOrderService service.StandingOrderService, log *slog.Logger, db *gorm.DB) *StandingOrderHandler {
+	return &StandingOrderHandler{
+		standingOrderService: standingOrderService,
+		log:                  log,
+		db:                   db,
+	}
+}
+
+// CreateStandingOrder godoc
+// @Summary Create a standing order
+// @Description Creates a recurring transfer between two accounts of the same currency. The schedule is a five-field cron expression evaluated in UTC, a shorthand such as @daily, @weekly or @monthly, or a fixed interval written "@every 24h". The first activation is the first one at or after both start_at and now.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param request body CreateStandingOrderRequest true "Standing order details"
+// @Success 201 {object} domain.StandingOrder
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /standing-orders [post]
+func (h *StandingOrderHandler) CreateStandingOrder(c *gin.Context) {
+	var req CreateStandingOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Error("Invalid request body for CreateStandingOrder", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	serviceReq := service.StandingOrderRequest{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Schedule:             req.Schedule,
+		EndAt:                req.EndAt,
+	}
+	if req.StartAt != nil {
+		serviceReq.StartAt = *req.StartAt
+	}
+
+	order, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.StandingOrder, error) {
+		return h.standingOrderService.CreateStandingOrder(c.Request.Context(), tx, serviceReq)
+	})
+	if errors.Is(err, service.ErrInvalidSchedule) || errors.Is(err, service.ErrCurrencyMismatch) {
+		h.log.Error("Rejected standing order", "schedule", req.Schedule, "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to create standing order", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Standing order created successfully", "standing_order_id", order.StandingOrderID, "schedule", order.Schedule, "next_run_at", order.NextRunAt)
+	c.Header("Location", "/standing-orders/"+order.StandingOrderID)
+	c.JSON(http.StatusCreated, order)
+}
+
+// ListStandingOrders godoc
+// @Summary List standing orders
+// @Description Lists standing orders, oldest first.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param status query string false "active, completed or cancelled (default: all)"
+// @Param source_account_id query int false "Only include standing orders from this account"
+// @Param limit query int false "Maximum number of standing orders (default 50, max 200)"
+// @Success 200 {array} domain.StandingOrder
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /standing-orders [get]
+func (h *StandingOrderHandler) ListStandingOrders(c *gin.Context) {
+	query, err := parseStandingOrderQuery(c)
+	if err != nil {
+		h.log.Error("Invalid query for ListStandingOrders", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := h.standingOrderService.ListStandingOrders(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to list standing orders", "status", query.Status, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Standing orders listed successfully", "status", query.Status, "count", len(orders))
+	c.JSON(http.StatusOK, orders)
+}
+
+// GetStandingOrder godoc
+// @Summary Get standing order by ID
+// @Description Retrieves a standing order with its status and next activation.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param standing_order_id path string true "Standing order ID"
+// @Success 200 {object} domain.StandingOrder
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /standing-orders/{standing_order_id} [get]
+func (h *StandingOrderHandler) GetStandingOrder(c *gin.Context) {
+	standingOrderID, ok := h.standingOrderID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.standingOrderService.GetStandingOrder(c.Request.Context(), standingOrderID)
+	if err != nil {
+		h.log.Error("Failed to get standing order", "standing_order_id", standingOrderID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if order == nil {
+		h.log.Info("Standing order not found", "standing_order_id", standingOrderID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Standing order not found"})
+		return
+	}
+
+	h.log.Info("Standing order retrieved successfully", "standing_order_id", standingOrderID)
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateStandingOrder godoc
+// @Summary Update a standing order
+// @Description Changes the amount, schedule or end of an active standing order. Omitted fields are left unchanged. A new schedule restarts from its first activation at or after now.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param standing_order_id path string true "Standing order ID"
+// @Param request body UpdateStandingOrderRequest true "Fields to change"
+// @Success 200 {object} domain.StandingOrder
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Standing order no longer active"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /standing-orders/{standing_order_id} [patch]
+func (h *StandingOrderHandler) UpdateStandingOrder(c *gin.Context) {
+	standingOrderID, ok := h.standingOrderID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateStandingOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Error("Invalid request body for UpdateStandingOrder", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.StandingOrder, error) {
+		return h.standingOrderService.UpdateStandingOrder(c.Request.Context(), tx, service.StandingOrderUpdate{
+			StandingOrderID: standingOrderID,
+			Amount:          req.Amount,
+			Schedule:        req.Schedule,
+			EndAt:           req.EndAt,
+		})
+	})
+	if !h.handleStandingOrderError(c, standingOrderID, "update", err) {
+		return
+	}
+
+	h.log.Info("Standing order updated successfully", "standing_order_id", standingOrderID, "next_run_at", order.NextRunAt)
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelStandingOrder godoc
+// @Summary Cancel a standing order
+// @Description Cancels an active standing order so that it never runs again. Executions that already happened are kept.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param standing_order_id path string true "Standing order ID"
+// @Success 200 {object} domain.StandingOrder
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Standing order no longer active"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /standing-orders/{standing_order_id} [delete]
+func (h *StandingOrderHandler) CancelStandingOrder(c *gin.Context) {
+	standingOrderID, ok := h.standingOrderID(c)
+	if !ok {
+		return
+	}
+
+	order, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.StandingOrder, error) {
+		return h.standingOrderService.CancelStandingOrder(c.Request.Context(), tx, standingOrderID)
+	})
+	if !h.handleStandingOrderError(c, standingOrderID, "cancel", err) {
+		return
+	}
+
+	h.log.Info("Standing order cancelled successfully", "standing_order_id", standingOrderID)
+	c.JSON(http.StatusOK, order)
+}
+
+// ListStandingOrderExecutions godoc
+// @Summary List executions of a standing order
+// @Description Lists the activations of a standing order, most recent first, with the resulting transfer ID or the failure reason.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param standing_order_id path string true "Standing order ID"
+// @Param limit query int false "Maximum number of executions (default 50, max 200)"
+// @Success 200 {array} domain.StandingOrderExecution
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /standing-orders/{standing_order_id}/executions [get]
+func (h *StandingOrderHandler) ListStandingOrderExecutions(c *gin.Context) {
+	standingOrderID, ok := h.standingOrderID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := parseStandingOrderLimit(c)
+	if err != nil {
+		h.log.Error("Invalid query for ListStandingOrderExecutions", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	executions, err := h.standingOrderService.ListStandingOrderExecutions(c.Request.Context(), standingOrderID, limit)
+	if errors.Is(err, service.ErrStandingOrderNotFound) {
+		h.log.Info("Standing order not found", "standing_order_id", standingOrderID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Standing order not found"})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to list standing order executions", "standing_order_id", standingOrderID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Standing order executions listed successfully", "standing_order_id", standingOrderID, "count", len(executions))
+	c.JSON(http.StatusOK, executions)
+}
+
+// handleStandingOrderError writes the response for a failed change to a
+// standing order and reports whether the caller may proceed.
+func (h *StandingOrderHandler) handleStandingOrderError(c *gin.Context, standingOrderID, action string, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrStandingOrderNotFound):
+		h.log.Info("Standing order to "+action+" not found", "standing_order_id", standingOrderID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Standing order not found"})
+	case errors.Is(err, service.ErrStandingOrderNotActive):
+		h.log.Warn("Standing order cannot be changed", "standing_order_id", standingOrderID, "action", action, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSchedule):
+		h.log.Error("Rejected standing order change", "standing_order_id", standingOrderID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.log.Error("Failed to "+action+" standing order", "standing_order_id", standingOrderID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
+func (h *StandingOrderHandler) standingOrderID(c *gin.Context) (string, bool) {
+	standingOrderID := c.Param("standing_order_id")
+	if _, err := uuid.Parse(standingOrderID); err != nil {
+		h.log.Error("Invalid standing order ID format - must be a UUID", "standing_order_id", standingOrderID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Standing order ID must be a valid UUID"})
+		return "", false
+	}
+	return standingOrderID, true
+}
+
+func parseStandingOrderQuery(c *gin.Context) (domain.StandingOrderQuery, error) {
+	query := domain.StandingOrderQuery{}
+
+	if v := c.Query("status"); v != "" {
+		status := domain.StandingOrderStatus(v)
+		switch status {
+		case domain.StandingOrderStatusActive, domain.StandingOrderStatusCompleted, domain.StandingOrderStatusCancelled:
+			query.Status = status
+		default:
+			return query, errors.New("status must be one of active, completed or cancelled")
+		}
+	}
+
+	if v := c.Query("source_account_id"); v != "" {
+		accountID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || accountID == 0 {
+			return query, errors.New("source_account_id must be a positive integer")
+		}
+		query.SourceAccountID = uint(accountID)
+	}
+
+	limit, err := parseStandingOrderLimit(c)
+	if err != nil {
+		return query, err
+	}
+	query.Limit = limit
+
+	return query, nil
+}
+
+func parseStandingOrderLimit(c *gin.Context) (int, error) {
+	v := c.Query("limit")
+	if v == "" {
+		return defaultStandingOrdersPageSize, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 || limit > maxStandingOrdersPageSize {
+		return 0, fmt.Errorf("limit must be an integer between 1 and %d", maxStandingOrdersPageSize)
+	}
+	return limit, nil
+}
@@ -1,46 +1,57 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/dirdr/goits/internal/domain"
 	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/storage"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type TransactionHandler struct {
-	transactionService service.TransactionService
-	log                *slog.Logger
-	db                 *gorm.DB
+	transactionService       service.TransactionService
+	scheduledTransferService service.ScheduledTransferService
+	log                      *slog.Logger
+	db                       *gorm.DB
 }
 
 const (
-	maxRetries = 3
-	baseDelay  = 10 * time.Millisecond
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
 )
 
-func NewTransactionHandler(transactionService service.TransactionService, log *slog.Logger, db *gorm.DB) *TransactionHandler {
+func NewTransactionHandler(transactionService service.TransactionService, scheduledTransferService service.ScheduledTransferService, log *slog.Logger, db *gorm.DB) *TransactionHandler {
 	return &TransactionHandler{
-		transactionService: transactionService,
-		log:                log,
-		db:                 db,
+		transactionService:       transactionService,
+		scheduledTransferService: scheduledTransferService,
+		log:                      log,
+		db:                       db,
 	}
 }
 
 // CreateTransaction handles the submission of a new transaction.
 // CreateTransaction godoc
 // @Summary Create a new transaction
-// @Description Processes a transfer of funds between two accounts.
+// @Description Processes a transfer of funds between two accounts. The amount is expressed in the source account's currency; transfers between currencies must set allow_conversion. When execute_at is set, the transfer is stored as pending and executed by the scheduler once due; funds are only checked at that time. Requests carrying an Idempotency-Key header are executed at most once; replays return the original outcome.
 // @Tags transactions
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Client-generated key making the request safe to retry"
 // @Param transaction body CreateTransactionRequest true "Transaction creation request"
-// @Success 201 {string} string "Created"
+// @Success 201 {object} CreateTransactionResponse
+// @Header 201 {string} Location "URL of the created transaction"
+// @Success 202 {object} domain.ScheduledTransfer
+// @Header 202 {string} Location "URL of the scheduled transfer"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Idempotency key reused with a different request"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /transactions [post]
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
@@ -51,61 +62,261 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	err := h.processTransactionWithRetry(c, req)
+	idempotencyKey, ok := h.idempotencyKey(c)
+	if !ok {
+		return
+	}
+
+	transfer := service.TransferRequest{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		IdempotencyKey:       idempotencyKey,
+		AllowConversion:      req.AllowConversion,
+	}
+	if req.ExecuteAt != nil {
+		h.scheduleTransaction(c, transfer, *req.ExecuteAt)
+		return
+	}
+
+	event, err := h.processTransactionWithRetry(c, func(tx *gorm.DB) (*domain.TransferEvent, error) {
+		return h.transactionService.ProcessTransfer(c.Request.Context(), tx, transfer)
+	})
+	if errors.Is(err, service.ErrCurrencyMismatch) {
+		h.log.Error("Rejected cross-currency transaction", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		h.log.Warn("Idempotency key reused with a different request", "idempotency_key", idempotencyKey)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to process transaction", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	h.log.Info("Transaction processed successfully", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount)
-	c.Status(http.StatusCreated)
+	h.log.Info("Transaction processed successfully", "transfer_id", event.TransferID, "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount)
+	c.Header("Location", "/transactions/"+event.TransferID)
+	c.JSON(http.StatusCreated, CreateTransactionResponse{TransferID: event.TransferID})
 }
 
-func (h *TransactionHandler) processTransactionWithRetry(c *gin.Context, req CreateTransactionRequest) error {
-	var lastErr error
+func (h *TransactionHandler) scheduleTransaction(c *gin.Context, transfer service.TransferRequest, executeAt time.Time) {
+	scheduled, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.ScheduledTransfer, error) {
+		return h.scheduledTransferService.ScheduleTransfer(c.Request.Context(), tx, service.ScheduledTransferRequest{
+			Transfer:  transfer,
+			ExecuteAt: executeAt,
+		})
+	})
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		h.log.Warn("Idempotency key reused with a different request", "idempotency_key", transfer.IdempotencyKey)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to schedule transaction", "source_account_id", transfer.SourceAccountID, "destination_account_id", transfer.DestinationAccountID, "execute_at", executeAt, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Transaction scheduled successfully", "schedule_id", scheduled.ScheduleID, "source_account_id", transfer.SourceAccountID, "destination_account_id", transfer.DestinationAccountID, "execute_at", scheduled.ExecuteAt)
+	c.Header("Location", "/scheduled-transfers/"+scheduled.ScheduleID)
+	c.JSON(http.StatusAccepted, scheduled)
+}
+
+// CreateMultiLegTransaction godoc
+// @Summary Create a multi-leg transaction
+// @Description Atomically posts N debit and credit legs under a single transfer ID, e.g. paying a merchant, a platform fee and a tax from one account. All accounts must share a currency, debits must equal credits, and every debited account must hold sufficient funds.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client-generated key making the request safe to retry"
+// @Param transaction body CreateMultiLegTransactionRequest true "Multi-leg transaction creation request"
+// @Success 201 {object} CreateTransactionResponse
+// @Header 201 {string} Location "URL of the created transaction"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Idempotency key reused with a different request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /transactions/multi-leg [post]
+func (h *TransactionHandler) CreateMultiLegTransaction(c *gin.Context) {
+	var req CreateMultiLegTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Error("Invalid request body for CreateMultiLegTransaction", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idempotencyKey, ok := h.idempotencyKey(c)
+	if !ok {
+		return
+	}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
-			return h.transactionService.ProcessTransfer(c.Request.Context(), tx, req.SourceAccountID, req.DestinationAccountID, req.Amount)
+	legs := make([]domain.Posting, 0, len(req.Legs))
+	for _, leg := range req.Legs {
+		legs = append(legs, domain.Posting{
+			AccountID: leg.AccountID,
+			Type:      leg.Direction,
+			Amount:    leg.Amount,
 		})
+	}
+
+	event, err := h.processTransactionWithRetry(c, func(tx *gorm.DB) (*domain.TransferEvent, error) {
+		return h.transactionService.ProcessMultiLegTransfer(c.Request.Context(), tx, service.MultiLegTransferRequest{
+			Legs:           legs,
+			IdempotencyKey: idempotencyKey,
+		})
+	})
+	if errors.Is(err, service.ErrUnbalancedLegs) || errors.Is(err, service.ErrCurrencyMismatch) {
+		h.log.Error("Rejected multi-leg transaction", "legs", len(legs), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		h.log.Warn("Idempotency key reused with a different request", "idempotency_key", idempotencyKey)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to process multi-leg transaction", "legs", len(legs), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		if err == nil {
-			return nil
-		}
+	h.log.Info("Multi-leg transaction processed successfully", "transfer_id", event.TransferID, "legs", len(legs), "amount", event.Amount)
+	c.Header("Location", "/transactions/"+event.TransferID)
+	c.JSON(http.StatusCreated, CreateTransactionResponse{TransferID: event.TransferID})
+}
+
+// ReverseTransaction godoc
+// @Summary Reverse a transaction
+// @Description Appends a TransferReversed event with compensating journal entries. Omit the amount to reverse everything not reversed yet; partial reversals are rejected for multi-leg transfers and may never exceed the remaining amount.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client-generated key making the request safe to retry"
+// @Param transfer_id path string true "Transfer ID of the transaction to reverse"
+// @Param reversal body ReverseTransactionRequest false "Reversal request"
+// @Success 201 {object} CreateTransactionResponse
+// @Header 201 {string} Location "URL of the reversal transaction"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Idempotency key reused with a different request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /transactions/{transfer_id}/reverse [post]
+func (h *TransactionHandler) ReverseTransaction(c *gin.Context) {
+	transferID := c.Param("transfer_id")
+	if _, err := uuid.Parse(transferID); err != nil {
+		h.log.Error("Invalid transfer ID format - must be a UUID", "transfer_id", transferID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfer ID must be a valid UUID"})
+		return
+	}
 
-		lastErr = err
+	var req ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("Invalid request body for ReverseTransaction", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idempotencyKey, ok := h.idempotencyKey(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.processTransactionWithRetry(c, func(tx *gorm.DB) (*domain.TransferEvent, error) {
+		return h.transactionService.ReverseTransfer(c.Request.Context(), tx, service.ReversalRequest{
+			TransferID:     transferID,
+			Amount:         req.Amount,
+			IdempotencyKey: idempotencyKey,
+		})
+	})
+	if errors.Is(err, service.ErrTransferNotFound) {
+		h.log.Info("Transaction to reverse not found", "transfer_id", transferID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidReversal) || errors.Is(err, service.ErrReversalExceedsRemaining) {
+		h.log.Error("Rejected transaction reversal", "transfer_id", transferID, "amount", req.Amount, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		h.log.Warn("Idempotency key reused with a different request", "idempotency_key", idempotencyKey)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to reverse transaction", "transfer_id", transferID, "amount", req.Amount, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		if !h.isRetryableError(err) {
-			return err
-		}
+	h.log.Info("Transaction reversed successfully", "transfer_id", transferID, "reversal_transfer_id", event.TransferID, "amount", event.Amount)
+	c.Header("Location", "/transactions/"+event.TransferID)
+	c.JSON(http.StatusCreated, CreateTransactionResponse{TransferID: event.TransferID})
+}
 
-		if attempt == maxRetries-1 {
-			return fmt.Errorf("transaction failed after %d attempts due to concurrent modifications: %w", maxRetries, lastErr)
-		}
+// GetTransaction godoc
+// @Summary Get transaction by transfer ID
+// @Description Retrieves a transfer event together with the journal entries it produced.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param transfer_id path string true "Transfer ID"
+// @Success 200 {object} service.TransferDetails
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /transactions/{transfer_id} [get]
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	transferID := c.Param("transfer_id")
+	if _, err := uuid.Parse(transferID); err != nil {
+		h.log.Error("Invalid transfer ID format - must be a UUID", "transfer_id", transferID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfer ID must be a valid UUID"})
+		return
+	}
 
-		delay := h.calculateBackoffDelay(attempt)
-		h.log.Debug("Retrying transaction after optimistic locking conflict",
-			"attempt", attempt+1,
-			"delay", delay,
-			"source_account_id", req.SourceAccountID,
-			"destination_account_id", req.DestinationAccountID,
-			"error", err)
+	details, err := h.transactionService.GetTransfer(c.Request.Context(), transferID)
+	if err != nil {
+		h.log.Error("Failed to get transaction", "transfer_id", transferID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		select {
-		case <-c.Request.Context().Done():
-			return c.Request.Context().Err()
-		case <-time.After(delay):
-		}
+	if details == nil {
+		h.log.Info("Transaction not found", "transfer_id", transferID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
 	}
 
-	return fmt.Errorf("unexpected error: retry loop exited without return")
+	h.log.Info("Transaction retrieved successfully", "transfer_id", transferID)
+	c.JSON(http.StatusOK, details)
 }
 
-func (h *TransactionHandler) isRetryableError(err error) bool {
-	return strings.Contains(err.Error(), "optimistic locking failed")
+// processTransactionWithRetry runs process in its own database transaction,
+// retrying with exponential backoff when it loses an optimistic locking race.
+func (h *TransactionHandler) processTransactionWithRetry(c *gin.Context, process func(tx *gorm.DB) (*domain.TransferEvent, error)) (*domain.TransferEvent, error) {
+	return runWithRetry(c, h.db, h.log, process)
 }
 
-func (h *TransactionHandler) calculateBackoffDelay(attempt int) time.Duration {
-	return time.Duration(1<<attempt) * baseDelay
+func (h *TransactionHandler) idempotencyKey(c *gin.Context) (string, bool) {
+	return readIdempotencyKey(c, h.log)
+}
+
+func runWithRetry[T any](c *gin.Context, db *gorm.DB, log *slog.Logger, process func(tx *gorm.DB) (T, error)) (T, error) {
+	return storage.RunInTransactionWithRetry(c.Request.Context(), db, log, process)
+}
+
+// readIdempotencyKey reads the optional Idempotency-Key header, answering 400
+// and returning false when it is too long.
+func readIdempotencyKey(c *gin.Context, log *slog.Logger) (string, bool) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		log.Error("Idempotency key too long", "length", len(key))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s header must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)})
+		return "", false
+	}
+	return key, true
 }
@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWebhookDeliveriesPageSize = 50
+	maxWebhookDeliveriesPageSize     = 200
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	log            *slog.Logger
+	db             *gorm.DB
+}
+
+func NewWebhookHandler(webhookService service.WebhookService, log *slog.Logger, db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		log:            log,
+		db:             db,
+	}
+}
+
+// CreateWebhookSubscription godoc
+// @Summary Create a webhook subscription
+// @Description Registers a URL that receives a POST for every transfer or account event of the given types, from now on. Requests carry the X-Webhook-Timestamp header (Unix seconds) and the X-Webhook-Signature header "v1=" followed by the hex HMAC-SHA256, keyed with the secret, of the timestamp, a dot and the body. Receivers should reject stale timestamps and deduplicate on X-Webhook-Message-ID. The secret is never returned.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body CreateWebhookSubscriptionRequest true "Subscription details; event types among AccountOpened, AccountFunded, TransferProcessed, MultiLegTransferProcessed, HoldAuthorized, HoldCaptured, HoldVoided, HoldExpired and TransferReversed"
+// @Success 201 {object} domain.WebhookSubscription
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/subscriptions [post]
+func (h *WebhookHandler) CreateWebhookSubscription(c *gin.Context) {
+	var req CreateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Error("Invalid request body for CreateWebhookSubscription", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.WebhookSubscription, error) {
+		return h.webhookService.CreateWebhookSubscription(c.Request.Context(), tx, service.WebhookSubscriptionRequest{
+			URL:        req.URL,
+			EventTypes: req.EventTypes,
+			Secret:     req.Secret,
+		})
+	})
+	if errors.Is(err, service.ErrInvalidWebhookSubscription) {
+		h.log.Error("Rejected webhook subscription", "url", req.URL, "event_types", req.EventTypes, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to create webhook subscription", "url", req.URL, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Webhook subscription created successfully", "subscription_id", subscription.SubscriptionID, "url", subscription.URL, "event_types", subscription.EventTypes)
+	c.Header("Location", "/webhooks/subscriptions/"+subscription.SubscriptionID)
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// ListWebhookSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description Lists every webhook subscription, oldest first, including disabled ones.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.WebhookSubscription
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/subscriptions [get]
+func (h *WebhookHandler) ListWebhookSubscriptions(c *gin.Context) {
+	subscriptions, err := h.webhookService.ListWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		h.log.Error("Failed to list webhook subscriptions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Webhook subscriptions listed successfully", "count", len(subscriptions))
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetWebhookSubscription godoc
+// @Summary Get webhook subscription by ID
+// @Description Retrieves a webhook subscription with its status and count of consecutive failed deliveries.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Success 200 {object} domain.WebhookSubscription
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/subscriptions/{subscription_id} [get]
+func (h *WebhookHandler) GetWebhookSubscription(c *gin.Context) {
+	subscriptionID, ok := h.subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.GetWebhookSubscription(c.Request.Context(), subscriptionID)
+	if err != nil {
+		h.log.Error("Failed to get webhook subscription", "subscription_id", subscriptionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if subscription == nil {
+		h.log.Info("Webhook subscription not found", "subscription_id", subscriptionID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+		return
+	}
+
+	h.log.Info("Webhook subscription retrieved successfully", "subscription_id", subscriptionID)
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateWebhookSubscription godoc
+// @Summary Update a webhook subscription
+// @Description Changes the URL, event types or secret of a subscription, or disables or enables it. Omitted fields are left unchanged. Enabling a subscription resets its count of consecutive failures; deliveries still pending are then sent again.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param request body UpdateWebhookSubscriptionRequest true "Fields to change"
+// @Success 200 {object} domain.WebhookSubscription
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/subscriptions/{subscription_id} [patch]
+func (h *WebhookHandler) UpdateWebhookSubscription(c *gin.Context) {
+	subscriptionID, ok := h.subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Error("Invalid request body for UpdateWebhookSubscription", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.WebhookSubscription, error) {
+		return h.webhookService.UpdateWebhookSubscription(c.Request.Context(), tx, service.WebhookSubscriptionUpdate{
+			SubscriptionID: subscriptionID,
+			URL:            req.URL,
+			EventTypes:     req.EventTypes,
+			Secret:         req.Secret,
+			Status:         req.Status,
+		})
+	})
+	if !h.handleSubscriptionError(c, subscriptionID, "update", err) {
+		return
+	}
+
+	h.log.Info("Webhook subscription updated successfully", "subscription_id", subscriptionID, "status", subscription.Status)
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteWebhookSubscription godoc
+// @Summary Delete a webhook subscription
+// @Description Deletes a subscription together with its deliveries and their attempts.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/subscriptions/{subscription_id} [delete]
+func (h *WebhookHandler) DeleteWebhookSubscription(c *gin.Context) {
+	subscriptionID, ok := h.subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	_, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (struct{}, error) {
+		return struct{}{}, h.webhookService.DeleteWebhookSubscription(c.Request.Context(), tx, subscriptionID)
+	})
+	if !h.handleSubscriptionError(c, subscriptionID, "delete", err) {
+		return
+	}
+
+	h.log.Info("Webhook subscription deleted successfully", "subscription_id", subscriptionID)
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List deliveries of a webhook subscription
+// @Description Lists the deliveries of a subscription, most recent first, with their status and number of attempts.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 200)"
+// @Success 200 {array} domain.WebhookDelivery
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/subscriptions/{subscription_id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	subscriptionID, ok := h.subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := parseWebhookDeliveriesLimit(c)
+	if err != nil {
+		h.log.Error("Invalid query for ListWebhookDeliveries", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := h.webhookService.ListWebhookDeliveries(c.Request.Context(), subscriptionID, limit)
+	if errors.Is(err, service.ErrWebhookSubscriptionNotFound) {
+		h.log.Info("Webhook subscription not found", "subscription_id", subscriptionID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to list webhook deliveries", "subscription_id", subscriptionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Webhook deliveries listed successfully", "subscription_id", subscriptionID, "count", len(deliveries))
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetWebhookDelivery godoc
+// @Summary Get webhook delivery by ID
+// @Description Retrieves a delivery with the history of its attempts: when each was made, the response status code and the error, if any.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} domain.WebhookDelivery
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetWebhookDelivery(c *gin.Context) {
+	deliveryID, ok := h.deliveryID(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.GetWebhookDelivery(c.Request.Context(), deliveryID)
+	if err != nil {
+		h.log.Error("Failed to get webhook delivery", "delivery_id", deliveryID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if delivery == nil {
+		h.log.Info("Webhook delivery not found", "delivery_id", deliveryID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		return
+	}
+
+	h.log.Info("Webhook delivery retrieved successfully", "delivery_id", deliveryID)
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook
+// @Description Queues a delivery to be sent again right away, whatever its status, with a fresh budget of attempts. The request carries the same message ID as before.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202 {object} domain.WebhookDelivery
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Subscription is disabled"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /webhooks/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	deliveryID, ok := h.deliveryID(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := runWithRetry(c, h.db, h.log, func(tx *gorm.DB) (*domain.WebhookDelivery, error) {
+		return h.webhookService.RedeliverWebhook(c.Request.Context(), tx, deliveryID, time.Now())
+	})
+	switch {
+	case errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		h.log.Info("Webhook delivery to redeliver not found", "delivery_id", deliveryID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		return
+	case errors.Is(err, service.ErrWebhookSubscriptionDisabled):
+		h.log.Warn("Webhook delivery cannot be redelivered", "delivery_id", deliveryID, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.log.Error("Failed to redeliver webhook", "delivery_id", deliveryID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.log.Info("Webhook delivery queued for redelivery", "delivery_id", deliveryID, "subscription_id", delivery.SubscriptionID)
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// handleSubscriptionError writes the response for a failed change to a
+// subscription and reports whether the caller may proceed.
+func (h *WebhookHandler) handleSubscriptionError(c *gin.Context, subscriptionID, action string, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrWebhookSubscriptionNotFound):
+		h.log.Info("Webhook subscription to "+action+" not found", "subscription_id", subscriptionID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+	case errors.Is(err, service.ErrInvalidWebhookSubscription):
+		h.log.Error("Rejected webhook subscription change", "subscription_id", subscriptionID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.log.Error("Failed to "+action+" webhook subscription", "subscription_id", subscriptionID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
+func (h *WebhookHandler) subscriptionID(c *gin.Context) (string, bool) {
+	subscriptionID := c.Param("subscription_id")
+	if _, err := uuid.Parse(subscriptionID); err != nil {
+		h.log.Error("Invalid subscription ID format - must be a UUID", "subscription_id", subscriptionID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subscription ID must be a valid UUID"})
+		return "", false
+	}
+	return subscriptionID, true
+}
+
+func (h *WebhookHandler) deliveryID(c *gin.Context) (uint, bool) {
+	v := c.Param("delivery_id")
+	deliveryID, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || deliveryID == 0 {
+		h.log.Error("Invalid delivery ID format - must be a positive integer", "delivery_id", v, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Delivery ID must be a positive integer"})
+		return 0, false
+	}
+	return uint(deliveryID), true
+}
+
+func parseWebhookDeliveriesLimit(c *gin.Context) (int, error) {
+	v := c.Query("limit")
+	if v == "" {
+		return defaultWebhookDeliveriesPageSize, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 || limit > maxWebhookDeliveriesPageSize {
+		return 0, fmt.Errorf("limit must be an integer between 1 and %d", maxWebhookDeliveriesPageSize)
+	}
+	return limit, nil
+}
@@ -0,0 +1,51 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+// FilePublisher appends every message as one JSON line (NDJSON) to a file,
+// synced to disk before Publish returns.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox file: %w", err)
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+func (p *FilePublisher) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message %d: %w", message.MessageID, err)
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write outbox message %d: %w", message.MessageID, err)
+	}
+	if err := p.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync outbox file: %w", err)
+	}
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.file.Close()
+}
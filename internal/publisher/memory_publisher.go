@@ -0,0 +1,42 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+// MemoryPublisher keeps published messages in memory, for tests and local
+// runs. When Fail is set, it is called first and a non-nil error fails the
+// delivery.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []domain.OutboxMessage
+
+	Fail func(message domain.OutboxMessage) error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Fail != nil {
+		if err := p.Fail(message); err != nil {
+			return err
+		}
+	}
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+// Messages returns the messages published so far, in publication order.
+func (p *MemoryPublisher) Messages() []domain.OutboxMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]domain.OutboxMessage(nil), p.messages...)
+}
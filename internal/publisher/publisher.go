@@ -0,0 +1,14 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+// Publisher delivers outbox messages downstream. Delivery is at least once: a
+// message whose Publish call failed, or whose outcome could not be recorded,
+// is published again, so consumers should deduplicate on MessageID.
+type Publisher interface {
+	Publish(ctx context.Context, message domain.OutboxMessage) error
+}
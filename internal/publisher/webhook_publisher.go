@@ -0,0 +1,59 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookPublisher POSTs every message as JSON to a fixed URL. Any response
+// other than 2xx is a failed delivery.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string, client *http.Client) *WebhookPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookPublisher{
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message %d: %w", message.MessageID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Message-ID", strconv.FormatUint(uint64(message.MessageID), 10))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
 	"github.com/shopspring/decimal"
@@ -12,19 +13,150 @@ type AccountRepository interface {
 	CreateAccount(ctx context.Context, tx *gorm.DB, account *domain.Account) error
 	GetAccountByID(ctx context.Context, tx *gorm.DB, accountID uint) (*domain.Account, error)
 	AccountExists(ctx context.Context, tx *gorm.DB, accountID uint) (bool, error)
+	GetSystemAccount(ctx context.Context, tx *gorm.DB, accountType domain.AccountType, currency string) (*domain.Account, error)
+	// LockSystemAccounts serializes the provisioning of system accounts until
+	// tx ends.
+	LockSystemAccounts(ctx context.Context, tx *gorm.DB) error
+	NextSystemAccountID(ctx context.Context, tx *gorm.DB) (uint, error)
 }
 
 type AccountBalanceRepository interface {
 	GetAccountBalance(ctx context.Context, tx *gorm.DB, accountID uint) (*domain.AccountBalance, error)
 	UpsertAccountBalance(ctx context.Context, tx *gorm.DB, balance *domain.AccountBalance) error
 	UpdateAccountBalanceWithVersion(ctx context.Context, tx *gorm.DB, balance *domain.AccountBalance, expectedVersion int) error
+	ListAccountBalances(ctx context.Context, tx *gorm.DB) ([]domain.AccountBalance, error)
 }
 
 type TransferEventRepository interface {
 	SaveTransferEvent(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent) error
+	GetTransferEventByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.TransferEvent, error)
+	GetTransferEventByTransferID(ctx context.Context, tx *gorm.DB, transferID string) (*domain.TransferEvent, error)
+	LockTransferEvent(ctx context.Context, tx *gorm.DB, transferID string) (*domain.TransferEvent, error)
+	GetReversalsOfTransfer(ctx context.Context, tx *gorm.DB, transferID string) ([]domain.TransferEvent, error)
+	GetLastTransferEventChainSeq(ctx context.Context, tx *gorm.DB) (uint, error)
+	ListTransferEventsInChainOrder(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.TransferEvent, error)
+	ListTransferEventsAfter(ctx context.Context, tx *gorm.DB, afterEventID uint, limit int) ([]domain.TransferEvent, error)
+	ListSealedTransferEvents(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.TransferEvent, error)
+}
+
+// OutboxRepository stores the messages publishing transfer and account events.
+// Messages are saved in the transaction of their event.
+type OutboxRepository interface {
+	SaveOutboxMessage(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage) error
+	TryLockOutboxRelay(ctx context.Context, tx *gorm.DB) (bool, error)
+	ListPendingOutboxMessages(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.OutboxMessage, error)
+	UpdateOutboxMessageDelivery(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage) error
+}
+
+// WebhookRepository stores webhook subscriptions and the deliveries of outbox
+// messages to them, with the history of their attempts.
+type WebhookRepository interface {
+	SaveWebhookSubscription(ctx context.Context, tx *gorm.DB, subscription *domain.WebhookSubscription) error
+	UpdateWebhookSubscription(ctx context.Context, tx *gorm.DB, subscription *domain.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) (*domain.WebhookSubscription, error)
+	LockWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) (*domain.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, tx *gorm.DB) ([]domain.WebhookSubscription, error)
+	ListActiveWebhookSubscriptions(ctx context.Context, tx *gorm.DB) ([]domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) error
+	SaveWebhookDeliveries(ctx context.Context, tx *gorm.DB, deliveries []*domain.WebhookDelivery) (int, error)
+	GetWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error)
+	LockWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error)
+	ClaimWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, tx *gorm.DB, subscriptionID string, limit int) ([]domain.WebhookDelivery, error)
+	ListDueWebhookDeliveries(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, tx *gorm.DB, delivery *domain.WebhookDelivery) error
+	SaveWebhookDeliveryAttempt(ctx context.Context, tx *gorm.DB, attempt *domain.WebhookDeliveryAttempt) error
+	ListWebhookDeliveryAttempts(ctx context.Context, tx *gorm.DB, deliveryID uint) ([]domain.WebhookDeliveryAttempt, error)
+}
+
+type EventStore interface {
+	AppendEvents(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string, expectedSequence int64, events []*domain.EventEnvelope) error
+	GetStreamSequence(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string) (int64, error)
+	LoadStream(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string, afterSequence int64) ([]domain.EventEnvelope, error)
+	ListEvents(ctx context.Context, tx *gorm.DB, afterEventID uint, limit int) ([]domain.EventEnvelope, error)
+	ListEventsByType(ctx context.Context, tx *gorm.DB, eventType string, afterEventID uint, limit int) ([]domain.EventEnvelope, error)
 }
 
 type JournalRepository interface {
 	SaveJournalEntry(ctx context.Context, tx *gorm.DB, entry *domain.JournalEntry) error
-	GetTotalsByEntryType(ctx context.Context, tx *gorm.DB) (map[domain.EntryType]decimal.Decimal, error)
+	GetJournalEntriesByTransactionID(ctx context.Context, tx *gorm.DB, transactionID string) ([]domain.JournalEntry, error)
+	ListJournalEntriesByTransactionIDs(ctx context.Context, tx *gorm.DB, transactionIDs []string) ([]domain.JournalEntry, error)
+	ListOrphanedJournalEntries(ctx context.Context, tx *gorm.DB, limit int) ([]domain.JournalEntry, error)
+	ListJournalEntriesWithMissingAccount(ctx context.Context, tx *gorm.DB, limit int) ([]domain.JournalEntry, error)
+	ListAccountJournalEntries(ctx context.Context, tx *gorm.DB, query domain.AccountJournalQuery) ([]domain.AccountJournalEntry, error)
+	GetAccountNetChangeAfter(ctx context.Context, tx *gorm.DB, accountID uint, cursor domain.JournalEntryCursor) (decimal.Decimal, error)
+	GetTotalsByCurrencyAndEntryType(ctx context.Context, tx *gorm.DB) (map[string]map[domain.EntryType]decimal.Decimal, error)
+	ListJournalEntriesInEventOrder(ctx context.Context, tx *gorm.DB, afterEventID, afterEntryID uint, limit int) ([]domain.JournalEntry, error)
+	ListJournalEntriesInEventRange(ctx context.Context, tx *gorm.DB, r domain.JournalEventRange) ([]domain.JournalEntry, error)
+	ListJournalEntriesOfEvents(ctx context.Context, tx *gorm.DB, eventIDs []uint, accountID uint) ([]domain.JournalEntry, error)
+	ListJournalEntriesAfter(ctx context.Context, tx *gorm.DB, afterEntryID uint, limit int) ([]domain.JournalEntry, error)
+	ListSealedJournalEntries(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.JournalEntry, error)
+	SumAccountJournalEntries(ctx context.Context, tx *gorm.DB, r domain.AccountJournalRange) (*domain.JournalSum, error)
+	SumJournalEntriesByAccount(ctx context.Context, tx *gorm.DB) (map[uint]domain.JournalSum, error)
+	GetLastSourceEventIDBefore(ctx context.Context, tx *gorm.DB, cutoff time.Time) (uint, error)
+}
+
+// HashChainRepository manages the hash chains over transfer_events and
+// journal_entries. Rows are saved unsealed and appended to their chain later.
+type HashChainRepository interface {
+	GetHashChainHead(ctx context.Context, tx *gorm.DB, chain string) (*domain.HashChainHead, error)
+	SealHashChain(ctx context.Context, tx *gorm.DB, chain string, limit int) (int, error)
+}
+
+type SnapshotRepository interface {
+	SaveAccountSnapshots(ctx context.Context, tx *gorm.DB, snapshots []domain.AccountSnapshot) error
+	GetLatestSnapshotEventID(ctx context.Context, tx *gorm.DB, atOrBefore *uint) (uint, error)
+	ListAccountSnapshotsAt(ctx context.Context, tx *gorm.DB, eventID uint) ([]domain.AccountSnapshot, error)
+	GetLatestAccountSnapshot(ctx context.Context, tx *gorm.DB, accountID uint, point domain.BalancePoint) (*domain.AccountSnapshot, error)
+}
+
+// ProjectionRepository rebuilds the account_balances projection into a fresh
+// table and swaps it in, all within the caller's transaction.
+type ProjectionRepository interface {
+	LockAccountBalances(ctx context.Context, tx *gorm.DB) error
+	CreateAccountBalancesRebuildTable(ctx context.Context, tx *gorm.DB) error
+	SaveRebuiltAccountBalances(ctx context.Context, tx *gorm.DB, balances []domain.AccountBalance) error
+	SwapRebuiltAccountBalances(ctx context.Context, tx *gorm.DB) error
+}
+
+type HoldRepository interface {
+	SaveHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error
+	UpdateHoldStatus(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error
+	GetHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error)
+	LockHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error)
+	GetHoldByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.Hold, error)
+	ListExpiredHolds(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.Hold, error)
+}
+
+type ScheduledTransferRepository interface {
+	SaveScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer) error
+	UpdateScheduledTransferStatus(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer) error
+	GetScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error)
+	LockScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error)
+	GetScheduledTransferByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.ScheduledTransfer, error)
+	ListScheduledTransfers(ctx context.Context, tx *gorm.DB, query domain.ScheduledTransferQuery) ([]domain.ScheduledTransfer, error)
+	ListDueScheduledTransfers(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.ScheduledTransfer, error)
+}
+
+type StandingOrderRepository interface {
+	SaveStandingOrder(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder) error
+	UpdateStandingOrder(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder) error
+	GetStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error)
+	LockStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error)
+	ClaimStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error)
+	ListStandingOrders(ctx context.Context, tx *gorm.DB, query domain.StandingOrderQuery) ([]domain.StandingOrder, error)
+	ListDueStandingOrders(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.StandingOrder, error)
+	SaveStandingOrderExecution(ctx context.Context, tx *gorm.DB, execution *domain.StandingOrderExecution) error
+	ListStandingOrderExecutions(ctx context.Context, tx *gorm.DB, standingOrderID string, limit int) ([]domain.StandingOrderExecution, error)
+}
+
+type IntegrityRunRepository interface {
+	SaveIntegrityRun(ctx context.Context, tx *gorm.DB, run *domain.IntegrityRun) error
+	GetIntegrityRun(ctx context.Context, tx *gorm.DB, runID uint) (*domain.IntegrityRun, error)
+	ListIntegrityRuns(ctx context.Context, tx *gorm.DB, query domain.IntegrityRunQuery) ([]domain.IntegrityRun, error)
+}
+
+type ProjectionRepairRepository interface {
+	SaveProjectionRepair(ctx context.Context, tx *gorm.DB, repair *domain.ProjectionRepair) error
+	ListProjectionRepairs(ctx context.Context, tx *gorm.DB, query domain.ProjectionRepairQuery) ([]domain.ProjectionRepair, error)
 }
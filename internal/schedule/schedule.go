@@ -0,0 +1,210 @@
+// Package schedule parses the recurrence rules of standing orders: standard
+// five-field cron expressions evaluated in UTC, the @hourly, @daily, @weekly,
+// @monthly and @yearly shorthands, and fixed intervals written "@every 36h".
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds how far ahead a cron expression is evaluated, so that
+// expressions that can never fire (e.g. "0 0 31 2 *") do not loop forever.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+type Schedule interface {
+	// First returns the first activation at or after start.
+	First(start time.Time) time.Time
+	// Next returns the first activation strictly after t. The zero time means
+	// the schedule never fires again.
+	Next(t time.Time) time.Time
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads a schedule specification.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if interval, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval: %w", err)
+		}
+		if d < time.Minute {
+			return nil, errors.New("interval must be at least one minute")
+		}
+		return every{interval: d}, nil
+	}
+
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	return parseCron(spec)
+}
+
+type every struct {
+	interval time.Duration
+}
+
+func (s every) First(start time.Time) time.Time {
+	return start
+}
+
+func (s every) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// cron keeps, for each field, the set of allowed values as a bitmask.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record an unrestricted field, which changes how day of
+	// month and day of week combine: when both are restricted, either matches.
+	domAny, dowAny bool
+}
+
+func parseCron(spec string) (Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression must have %d fields, got %d", len(cronFields), len(parts))
+	}
+
+	masks := make([]uint64, len(cronFields))
+	for i, f := range cronFields {
+		mask, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", f.name, parts[i], err)
+		}
+		masks[i] = mask
+	}
+
+	return &cron{
+		minute: masks[0],
+		hour:   masks[1],
+		dom:    masks[2],
+		month:  masks[3],
+		dow:    masks[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// parseField accepts comma-separated lists of "*", "n", "n-m", each optionally
+// followed by "/step". Day of week also accepts 7 for Sunday.
+func parseField(s string, f field) (uint64, error) {
+	var mask uint64
+	for _, term := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(term, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("step %q must be a positive integer", stepPart)
+			}
+		}
+
+		upper := f.max
+		if f.name == "day of week" {
+			upper = 7
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			loStr, hiStr, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("%q is not a number", loStr)
+			}
+			if hi, err = strconv.Atoi(hiStr); err != nil {
+				return 0, fmt.Errorf("%q is not a number", hiStr)
+			}
+		default:
+			v, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%q is not a number", rangePart)
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > upper || lo > hi {
+			return 0, fmt.Errorf("values must be between %d and %d", f.min, upper)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v%(f.max+1))
+		}
+	}
+	return mask, nil
+}
+
+func (s *cron) First(start time.Time) time.Time {
+	return s.Next(start.Add(-time.Nanosecond))
+}
+
+func (s *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *cron) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
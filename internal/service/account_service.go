@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/dirdr/goits/internal/domain"
 	"github.com/dirdr/goits/internal/repository"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
@@ -15,18 +17,68 @@ import (
 type accountService struct {
 	accountRepo        repository.AccountRepository
 	accountBalanceRepo repository.AccountBalanceRepository
+	journalRepo        repository.JournalRepository
+	eventStore         repository.EventStore
+	snapshotRepo       repository.SnapshotRepository
+	outboxRepo         repository.OutboxRepository
+	transactionService TransactionService
 }
 
-func NewAccountService(accountRepo repository.AccountRepository, accountBalanceRepo repository.AccountBalanceRepository) AccountService {
+func NewAccountService(accountRepo repository.AccountRepository, accountBalanceRepo repository.AccountBalanceRepository, journalRepo repository.JournalRepository, eventStore repository.EventStore, snapshotRepo repository.SnapshotRepository, outboxRepo repository.OutboxRepository, transactionService TransactionService) AccountService {
 	return &accountService{
 		accountRepo:        accountRepo,
 		accountBalanceRepo: accountBalanceRepo,
+		journalRepo:        journalRepo,
+		eventStore:         eventStore,
+		snapshotRepo:       snapshotRepo,
+		outboxRepo:         outboxRepo,
+		transactionService: transactionService,
 	}
 }
 
-func (s *accountService) CreateAccount(ctx context.Context, tx *gorm.DB, accountID uint, initialBalance decimal.Decimal) (*domain.Account, error) {
+// CreateAccount opens the account with a zero balance. A positive initial
+// balance is then funded by an AccountFunded transfer from the equity account
+// of the currency, so that it is carried by journal entries like any other
+// movement and replays to the same balance.
+func (s *accountService) CreateAccount(ctx context.Context, tx *gorm.DB, accountID uint, initialBalance decimal.Decimal, currency string, accountType domain.AccountType) (*domain.Account, error) {
+	if accountID >= domain.SystemAccountIDMin {
+		return nil, fmt.Errorf("%w: account IDs from %d are reserved", ErrInvalidAccount, domain.SystemAccountIDMin)
+	}
+	return s.openAccount(ctx, tx, accountID, initialBalance, currency, accountType)
+}
+
+func (s *accountService) openAccount(ctx context.Context, tx *gorm.DB, accountID uint, initialBalance decimal.Decimal, currency string, accountType domain.AccountType) (*domain.Account, error) {
 	if initialBalance.IsNegative() {
-		return nil, errors.New("initial balance cannot be negative")
+		return nil, fmt.Errorf("%w: initial balance cannot be negative", ErrInvalidAccount)
+	}
+
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	currency, err := domain.NormalizeCurrency(currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAccount, err)
+	}
+
+	switch accountType {
+	case "":
+		accountType = domain.AccountTypeCustomer
+	case domain.AccountTypeCustomer, domain.AccountTypeFXPosition, domain.AccountTypeEquity:
+	default:
+		return nil, fmt.Errorf("%w: unsupported account type %q", ErrInvalidAccount, accountType)
+	}
+	if accountType == domain.AccountTypeEquity && initialBalance.IsPositive() {
+		return nil, fmt.Errorf("%w: equity accounts must open with a zero balance", ErrInvalidAccount)
+	}
+
+	if accountType != domain.AccountTypeCustomer {
+		existing, err := s.accountRepo.GetSystemAccount(ctx, tx, accountType, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for existing system account: %w", err)
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("%w: a %s account already exists for %s", ErrAccountExists, accountType, currency)
+		}
 	}
 
 	exists, err := s.accountRepo.AccountExists(ctx, tx, accountID)
@@ -34,11 +86,13 @@ func (s *accountService) CreateAccount(ctx context.Context, tx *gorm.DB, account
 		return nil, fmt.Errorf("failed to check for existing account: %w", err)
 	}
 	if exists {
-		return nil, errors.New("account with this ID already exists")
+		return nil, ErrAccountExists
 	}
 
 	account := &domain.Account{
 		ID:        accountID,
+		Type:      accountType,
+		Currency:  currency,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -50,7 +104,7 @@ func (s *accountService) CreateAccount(ctx context.Context, tx *gorm.DB, account
 
 	balance := &domain.AccountBalance{
 		AccountID:   accountID,
-		Balance:     initialBalance,
+		Balance:     decimal.Zero,
 		Version:     1,
 		LastEventID: 0,
 		UpdatedAt:   time.Now(),
@@ -61,9 +115,83 @@ func (s *accountService) CreateAccount(ctx context.Context, tx *gorm.DB, account
 		return nil, fmt.Errorf("failed to create initial balance: %w", err)
 	}
 
+	opened := domain.AccountOpened{
+		AccountID:      account.ID,
+		Type:           account.Type,
+		Currency:       account.Currency,
+		InitialBalance: initialBalance,
+		OpeningBalance: decimal.Zero,
+	}
+	if initialBalance.IsPositive() {
+		opened.FundingTransferID = uuid.New().String()
+	}
+	err = appendEvent(ctx, tx, s.eventStore, opened, 0, nil, account.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := domain.NewAccountOutboxMessage(opened, account.ID, account.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = s.outboxRepo.SaveOutboxMessage(ctx, tx, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save outbox message: %w", err)
+	}
+
+	if initialBalance.IsPositive() {
+		equity, err := s.equityAccount(ctx, tx, currency)
+		if err != nil {
+			return nil, err
+		}
+		_, err = s.transactionService.FundAccount(ctx, tx, FundingRequest{
+			TransferID:     opened.FundingTransferID,
+			FundingAccount: equity,
+			Balance:        balance,
+			Amount:         initialBalance,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fund account: %w", err)
+		}
+	}
+
 	return account, nil
 }
 
+// equityAccount provisions the equity account of the currency on first use,
+// under the system accounts lock.
+func (s *accountService) equityAccount(ctx context.Context, tx *gorm.DB, currency string) (*domain.Account, error) {
+	equity, err := s.accountRepo.GetSystemAccount(ctx, tx, domain.AccountTypeEquity, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get equity account: %w", err)
+	}
+	if equity != nil {
+		return equity, nil
+	}
+
+	err = s.accountRepo.LockSystemAccounts(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock system accounts: %w", err)
+	}
+	equity, err = s.accountRepo.GetSystemAccount(ctx, tx, domain.AccountTypeEquity, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get equity account: %w", err)
+	}
+	if equity != nil {
+		return equity, nil
+	}
+
+	accountID, err := s.accountRepo.NextSystemAccountID(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate equity account ID: %w", err)
+	}
+	equity, err = s.openAccount(ctx, tx, accountID, decimal.Zero, currency, domain.AccountTypeEquity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision equity account for %s: %w", currency, err)
+	}
+	return equity, nil
+}
+
 func (s *accountService) GetAccountByID(ctx context.Context, accountID uint) (*domain.Account, error) {
 	account, err := s.accountRepo.GetAccountByID(ctx, nil, accountID)
 	if err != nil {
@@ -79,3 +207,140 @@ func (s *accountService) GetAccountBalance(ctx context.Context, accountID uint)
 	}
 	return balance, nil
 }
+
+// ListAccountTransactions returns one page of the account's journal legs, newest
+// first. Running balances are anchored on the current projected balance, so tx
+// should be a snapshot (repeatable read) transaction for consistent results.
+func (s *accountService) ListAccountTransactions(ctx context.Context, tx *gorm.DB, query domain.AccountJournalQuery) (*AccountTransactionsPage, error) {
+	if query.Limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	exists, err := s.accountRepo.AccountExists(ctx, tx, query.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check account: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	limit := query.Limit
+	query.Limit = limit + 1
+	entries, err := s.journalRepo.ListAccountJournalEntries(ctx, tx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account journal entries: %w", err)
+	}
+
+	page := &AccountTransactionsPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		last := page.Entries[limit-1]
+		page.NextCursor = &domain.JournalEntryCursor{CreatedAt: last.CreatedAt, EntryID: last.EntryID}
+	}
+
+	if len(page.Entries) == 0 {
+		return page, nil
+	}
+
+	balance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, query.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	if balance == nil {
+		return nil, errors.New("account balance not found")
+	}
+
+	newest := page.Entries[0]
+	netChangeAfter, err := s.journalRepo.GetAccountNetChangeAfter(ctx, tx, query.AccountID, domain.JournalEntryCursor{CreatedAt: newest.CreatedAt, EntryID: newest.EntryID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account net change: %w", err)
+	}
+
+	running := balance.Balance.Sub(netChangeAfter)
+	for i := range page.Entries {
+		page.Entries[i].RunningBalance = running
+		if page.Entries[i].Type == domain.Credit {
+			running = running.Sub(page.Entries[i].Amount)
+		} else {
+			running = running.Add(page.Entries[i].Amount)
+		}
+	}
+
+	return page, nil
+}
+
+// GetAccountBalanceAt computes the balance of the account at a point in its
+// history from its opening event and journal entries, starting from its latest
+// snapshot before that point. It returns nil when the account does not exist.
+// tx should be a snapshot (repeatable read) transaction so that the snapshot
+// and the entries summed after it are consistent.
+func (s *accountService) GetAccountBalanceAt(ctx context.Context, tx *gorm.DB, accountID uint, point domain.BalancePoint) (*HistoricalBalance, error) {
+	account, err := s.accountRepo.GetAccountByID(ctx, tx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account == nil {
+		return nil, nil
+	}
+
+	opened, err := s.loadAccountOpened(ctx, tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if point.Time != nil && point.Time.Before(opened.OccurredAt) {
+		return nil, ErrAccountNotOpenedYet
+	}
+
+	event, err := opened.Decode()
+	if err != nil {
+		return nil, err
+	}
+	balance := &HistoricalBalance{
+		AccountID:   accountID,
+		Currency:    account.Currency,
+		Balance:     event.(*domain.AccountOpened).OpeningBalance,
+		AsOf:        point.Time,
+		AsOfEventID: point.EventID,
+	}
+
+	snapshot, err := s.snapshotRepo.GetLatestAccountSnapshot(ctx, tx, accountID, point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account snapshot: %w", err)
+	}
+	var afterEventID uint
+	if snapshot != nil {
+		balance.Balance = snapshot.Balance
+		balance.LastEventID = snapshot.LastEventID
+		afterEventID = snapshot.EventID
+	}
+
+	sum, err := s.journalRepo.SumAccountJournalEntries(ctx, tx, domain.AccountJournalRange{
+		AccountID:    accountID,
+		AfterEventID: afterEventID,
+		Until:        point,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum account journal entries: %w", err)
+	}
+
+	balance.Balance = balance.Balance.Add(sum.Net)
+	if sum.LastEventID > balance.LastEventID {
+		balance.LastEventID = sum.LastEventID
+	}
+
+	return balance, nil
+}
+
+func (s *accountService) loadAccountOpened(ctx context.Context, tx *gorm.DB, accountID uint) (*domain.EventEnvelope, error) {
+	events, err := s.eventStore.LoadStream(ctx, tx, domain.AggregateTypeAccount, strconv.FormatUint(uint64(accountID), 10), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account stream: %w", err)
+	}
+
+	for i := range events {
+		if events[i].EventType == domain.EventTypeAccountOpened {
+			return &events[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: account %d has no %s event", ErrIncompleteEventLog, accountID, domain.EventTypeAccountOpened)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type activityService struct {
+	accountRepo       repository.AccountRepository
+	balanceRepo       repository.AccountBalanceRepository
+	transferEventRepo repository.TransferEventRepository
+	journalRepo       repository.JournalRepository
+}
+
+func NewActivityService(accountRepo repository.AccountRepository, balanceRepo repository.AccountBalanceRepository, transferEventRepo repository.TransferEventRepository, journalRepo repository.JournalRepository) ActivityService {
+	return &activityService{
+		accountRepo:       accountRepo,
+		balanceRepo:       balanceRepo,
+		transferEventRepo: transferEventRepo,
+		journalRepo:       journalRepo,
+	}
+}
+
+func (s *activityService) OpenActivityStream(ctx context.Context, tx *gorm.DB, accountID uint, lastChainSeq *uint) (domain.ActivityCursor, error) {
+	if accountID != 0 {
+		exists, err := s.accountRepo.AccountExists(ctx, tx, accountID)
+		if err != nil {
+			return domain.ActivityCursor{}, fmt.Errorf("failed to check account existence: %w", err)
+		}
+		if !exists {
+			return domain.ActivityCursor{}, ErrAccountNotFound
+		}
+	}
+
+	if lastChainSeq != nil {
+		return domain.ActivityCursor{ChainSeq: *lastChainSeq}, nil
+	}
+
+	chainSeq, err := s.transferEventRepo.GetLastTransferEventChainSeq(ctx, tx)
+	if err != nil {
+		return domain.ActivityCursor{}, fmt.Errorf("failed to get last transfer event: %w", err)
+	}
+	return domain.ActivityCursor{ChainSeq: chainSeq}, nil
+}
+
+// PollActivity advances the cursor over the transfer events sealed since the
+// previous poll and returns those touching the followed accounts. The balance
+// after each event is derived backwards from the account_balances projection,
+// which tx sees in the same state as the journal.
+func (s *activityService) PollActivity(ctx context.Context, tx *gorm.DB, query ActivityQuery) (*ActivityPage, error) {
+	sealed, err := s.transferEventRepo.ListTransferEventsInChainOrder(ctx, tx, query.Cursor.ChainSeq, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfer events: %w", err)
+	}
+
+	page := &ActivityPage{Cursor: query.Cursor, More: len(sealed) == query.Limit}
+	if len(sealed) == 0 {
+		return page, nil
+	}
+	page.Cursor = domain.ActivityCursor{ChainSeq: sealed[len(sealed)-1].ChainSeq}
+
+	eventIDs := make([]uint, 0, len(sealed))
+	for _, event := range sealed {
+		eventIDs = append(eventIDs, event.EventID)
+	}
+	entries, err := s.journalRepo.ListJournalEntriesOfEvents(ctx, tx, eventIDs, query.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return page, nil
+	}
+
+	entriesByEvent := make(map[uint][]domain.JournalEntry)
+	for _, entry := range entries {
+		entriesByEvent[entry.SourceEventID] = append(entriesByEvent[entry.SourceEventID], entry)
+	}
+	var events []domain.TransferEvent
+	for _, event := range sealed {
+		if len(entriesByEvent[event.EventID]) > 0 {
+			events = append(events, event)
+		}
+	}
+
+	balances, err := s.balancesAt(ctx, tx, entries, page.Cursor.ChainSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the events backwards, peeling each one's change off the balances
+	// to get the balance right before it, that is after the previous event.
+	page.Activities = make([]domain.AccountActivity, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		event.RequestFingerprint = ""
+
+		changes := make(map[uint]decimal.Decimal)
+		currencies := make(map[uint]string)
+		for _, entry := range entriesByEvent[event.EventID] {
+			if entry.Type == domain.Credit {
+				changes[entry.AccountID] = changes[entry.AccountID].Add(entry.Amount)
+			} else {
+				changes[entry.AccountID] = changes[entry.AccountID].Sub(entry.Amount)
+			}
+			currencies[entry.AccountID] = entry.Currency
+		}
+
+		activity := domain.AccountActivity{Transfer: event}
+		for _, accountID := range sortedAccountIDs(changes) {
+			activity.Balances = append(activity.Balances, domain.BalanceChange{
+				AccountID: accountID,
+				EventID:   event.EventID,
+				Currency:  currencies[accountID],
+				Change:    changes[accountID],
+				Balance:   balances[accountID],
+			})
+			balances[accountID] = balances[accountID].Sub(changes[accountID])
+		}
+		page.Activities[i] = activity
+	}
+
+	return page, nil
+}
+
+// balancesAt returns the ledger balance of the accounts of entries right after
+// the transfer event sealed at chainSeq.
+func (s *activityService) balancesAt(ctx context.Context, tx *gorm.DB, entries []domain.JournalEntry, chainSeq uint) (map[uint]decimal.Decimal, error) {
+	balances := make(map[uint]decimal.Decimal)
+	for _, entry := range entries {
+		if _, ok := balances[entry.AccountID]; ok {
+			continue
+		}
+
+		balance, err := s.balanceRepo.GetAccountBalance(ctx, tx, entry.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account balance: %w", err)
+		}
+		if balance == nil {
+			return nil, fmt.Errorf("balance of account %d not found", entry.AccountID)
+		}
+
+		since, err := s.journalRepo.SumAccountJournalEntries(ctx, tx, domain.AccountJournalRange{
+			AccountID:     entry.AccountID,
+			AfterChainSeq: &chainSeq,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to sum journal entries: %w", err)
+		}
+
+		balances[entry.AccountID] = balance.Balance.Sub(since.Net)
+	}
+	return balances, nil
+}
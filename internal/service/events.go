@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"gorm.io/gorm"
+)
+
+// appendEvent fails with an optimistic locking error when the stream no longer
+// holds expectedSequence events.
+func appendEvent(ctx context.Context, tx *gorm.DB, eventStore repository.EventStore, event domain.Event, expectedSequence int64, metadata map[string]string, now time.Time) error {
+	envelope, err := domain.NewEventEnvelope(event, metadata, now)
+	if err != nil {
+		return err
+	}
+
+	err = eventStore.AppendEvents(ctx, tx, event.AggregateType(), event.AggregateID(), expectedSequence, []*domain.EventEnvelope{envelope})
+	if err != nil {
+		return fmt.Errorf("failed to append %s event: %w", event.EventType(), err)
+	}
+	return nil
+}
+
+// transferEventMetadata links a stored event to the transfer_events row it was
+// recorded with.
+func transferEventMetadata(transferEvent *domain.TransferEvent) map[string]string {
+	metadata := map[string]string{
+		"transfer_event_id": strconv.FormatUint(uint64(transferEvent.EventID), 10),
+	}
+	if transferEvent.IdempotencyKey != "" {
+		metadata["idempotency_key"] = transferEvent.IdempotencyKey
+	}
+	return metadata
+}
+
+// saveTransferEvent records the transfer event together with the outbox message
+// publishing it, so the message commits exactly when the event does.
+func (s *transactionService) saveTransferEvent(ctx context.Context, tx *gorm.DB, transferEvent *domain.TransferEvent, accountIDs []uint) error {
+	err := s.transferEventRepo.SaveTransferEvent(ctx, tx, transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to save %s event: %w", transferEvent.EventType, err)
+	}
+
+	message, err := domain.NewOutboxMessage(transferEvent, accountIDs)
+	if err != nil {
+		return err
+	}
+	err = s.outboxRepo.SaveOutboxMessage(ctx, tx, message)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message: %w", err)
+	}
+	return nil
+}
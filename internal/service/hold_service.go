@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const defaultHoldLifetime = 7 * 24 * time.Hour
+
+type holdService struct {
+	accountRepo        repository.AccountRepository
+	accountBalanceRepo repository.AccountBalanceRepository
+	eventStore         repository.EventStore
+	outboxRepo         repository.OutboxRepository
+	holdRepo           repository.HoldRepository
+	// postings reuses the posting helpers of transactionService to book
+	// captures.
+	postings *transactionService
+}
+
+func NewHoldService(
+	accountRepo repository.AccountRepository,
+	accountBalanceRepo repository.AccountBalanceRepository,
+	transferEventRepo repository.TransferEventRepository,
+	journalRepo repository.JournalRepository,
+	eventStore repository.EventStore,
+	outboxRepo repository.OutboxRepository,
+	holdRepo repository.HoldRepository,
+) HoldService {
+	return &holdService{
+		accountRepo:        accountRepo,
+		accountBalanceRepo: accountBalanceRepo,
+		eventStore:         eventStore,
+		outboxRepo:         outboxRepo,
+		holdRepo:           holdRepo,
+		postings: &transactionService{
+			accountRepo:        accountRepo,
+			accountBalanceRepo: accountBalanceRepo,
+			transferEventRepo:  transferEventRepo,
+			journalRepo:        journalRepo,
+			eventStore:         eventStore,
+			outboxRepo:         outboxRepo,
+		},
+	}
+}
+
+// AuthorizeHold reserves the amount on the source account without posting any
+// journal entries. The reservation lowers the available balance only.
+func (s *holdService) AuthorizeHold(ctx context.Context, tx *gorm.DB, req HoldRequest) (*domain.Hold, error) {
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("hold amount must be positive")
+	}
+	if req.SourceAccountID == req.DestinationAccountID {
+		return nil, errors.New("source and destination accounts cannot be the same")
+	}
+	if req.ExpiresIn < 0 {
+		return nil, errors.New("hold lifetime cannot be negative")
+	}
+
+	fingerprint := holdFingerprint(req)
+	if req.IdempotencyKey != "" {
+		existing, err := s.holdRepo.GetHoldByIdempotencyKey(ctx, tx, req.IdempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+		if existing != nil {
+			if existing.RequestFingerprint != fingerprint {
+				return nil, ErrIdempotencyKeyReused
+			}
+			return existing, nil
+		}
+	}
+
+	sourceAccount, err := s.accountRepo.GetAccountByID(ctx, tx, req.SourceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check source account: %w", err)
+	}
+	if sourceAccount == nil {
+		return nil, errors.New("source account not found")
+	}
+
+	destinationAccount, err := s.accountRepo.GetAccountByID(ctx, tx, req.DestinationAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check destination account: %w", err)
+	}
+	if destinationAccount == nil {
+		return nil, errors.New("destination account not found")
+	}
+	if sourceAccount.Currency != destinationAccount.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	sourceBalance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, req.SourceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source account balance: %w", err)
+	}
+	if sourceBalance == nil {
+		return nil, errors.New("source account balance not found")
+	}
+	if sourceBalance.AvailableBalance().LessThan(req.Amount) {
+		return nil, fmt.Errorf("%w: insufficient available balance in source account", ErrInsufficientFunds)
+	}
+
+	lifetime := req.ExpiresIn
+	if lifetime == 0 {
+		lifetime = defaultHoldLifetime
+	}
+
+	now := time.Now()
+	hold := &domain.Hold{
+		HoldID:               uuid.New().String(),
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Currency:             sourceAccount.Currency,
+		Status:               domain.HoldStatusAuthorized,
+		CapturedAmount:       decimal.Zero,
+		IdempotencyKey:       req.IdempotencyKey,
+		RequestFingerprint:   fingerprint,
+		ExpiresAt:            now.Add(lifetime),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	err = s.holdRepo.SaveHold(ctx, tx, hold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save hold: %w", err)
+	}
+
+	err = s.adjustHeldAmount(ctx, tx, sourceBalance, req.Amount, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.recordHoldEvent(ctx, tx, domain.HoldAuthorized{
+		HoldID:               hold.HoldID,
+		SourceAccountID:      hold.SourceAccountID,
+		DestinationAccountID: hold.DestinationAccountID,
+		Amount:               hold.Amount,
+		Currency:             hold.Currency,
+		ExpiresAt:            hold.ExpiresAt,
+	}, hold.SourceAccountID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold posts a HoldCaptured transfer for the captured amount and
+// releases the whole reservation, so a partial capture frees the remainder.
+func (s *holdService) CaptureHold(ctx context.Context, tx *gorm.DB, req CaptureRequest) (*domain.TransferEvent, error) {
+	if req.Amount.IsNegative() {
+		return nil, errors.New("capture amount cannot be negative")
+	}
+
+	fingerprint := captureFingerprint(req)
+	existing, err := s.postings.findIdempotentReplay(ctx, tx, req.IdempotencyKey, fingerprint)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	now := time.Now()
+	hold, err := s.lockAuthorizedHold(ctx, tx, req.HoldID)
+	if err != nil {
+		return nil, err
+	}
+	if !hold.ExpiresAt.After(now) {
+		return nil, ErrHoldExpired
+	}
+
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = hold.Amount
+	}
+	if amount.GreaterThan(hold.Amount) {
+		return nil, fmt.Errorf("%w: requested %s, held %s", ErrCaptureExceedsHold, amount, hold.Amount)
+	}
+
+	sourceBalance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, hold.SourceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source account balance: %w", err)
+	}
+	if sourceBalance == nil {
+		return nil, errors.New("source account balance not found")
+	}
+
+	transferEvent := &domain.TransferEvent{
+		TransferID:         uuid.New().String(),
+		FromAccountID:      hold.SourceAccountID,
+		ToAccountID:        hold.DestinationAccountID,
+		Amount:             amount,
+		Currency:           hold.Currency,
+		EventType:          domain.EventTypeHoldCaptured,
+		RequestFingerprint: fingerprint,
+		IdempotencyKey:     req.IdempotencyKey,
+		CreatedAt:          now,
+	}
+
+	entries, err := s.postings.transferJournalEntries(ctx, tx, transferEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.postings.saveTransferEvent(ctx, tx, transferEvent, sortedAccountIDs(netChanges(entries)))
+	if err != nil {
+		return nil, err
+	}
+
+	err = appendEvent(ctx, tx, s.eventStore, domain.HoldCaptured{
+		TransferID:    transferEvent.TransferID,
+		HoldID:        hold.HoldID,
+		FromAccountID: transferEvent.FromAccountID,
+		ToAccountID:   transferEvent.ToAccountID,
+		Amount:        amount,
+		Currency:      hold.Currency,
+	}, 0, transferEventMetadata(transferEvent), now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		entry.SourceEventID = transferEvent.EventID
+		err = s.postings.journalRepo.SaveJournalEntry(ctx, tx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s journal entry: %w", entry.Type, err)
+		}
+	}
+
+	released := *sourceBalance
+	released.HeldAmount = released.HeldAmount.Sub(hold.Amount)
+	knownBalances := map[uint]*domain.AccountBalance{hold.SourceAccountID: &released}
+	err = s.postings.applyJournalEntries(ctx, tx, entries, knownBalances, transferEvent.EventID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.Status = domain.HoldStatusCaptured
+	hold.CapturedAmount = amount
+	hold.CaptureTransferID = transferEvent.TransferID
+	hold.UpdatedAt = now
+	err = s.holdRepo.UpdateHoldStatus(ctx, tx, hold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update hold: %w", err)
+	}
+
+	return transferEvent, nil
+}
+
+// VoidHold releases an authorized hold without moving any funds.
+func (s *holdService) VoidHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error) {
+	hold, err := s.lockAuthorizedHold(ctx, tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.releaseHold(ctx, tx, hold, domain.HoldStatusVoided, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.recordHoldEvent(ctx, tx, domain.HoldVoided{
+		HoldID:          hold.HoldID,
+		SourceAccountID: hold.SourceAccountID,
+		Amount:          hold.Amount,
+		Currency:        hold.Currency,
+	}, hold.SourceAccountID, now)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// ExpireHold releases the hold if it is still authorized past its expiry. It
+// returns nil when the hold was settled or extended concurrently.
+func (s *holdService) ExpireHold(ctx context.Context, tx *gorm.DB, holdID string, now time.Time) (*domain.Hold, error) {
+	hold, err := s.holdRepo.LockHold(ctx, tx, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	if hold == nil || hold.Status != domain.HoldStatusAuthorized || hold.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	err = s.releaseHold(ctx, tx, hold, domain.HoldStatusExpired, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.recordHoldEvent(ctx, tx, domain.HoldExpired{
+		HoldID:          hold.HoldID,
+		SourceAccountID: hold.SourceAccountID,
+		Amount:          hold.Amount,
+		Currency:        hold.Currency,
+	}, hold.SourceAccountID, now)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (s *holdService) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error) {
+	holds, err := s.holdRepo.ListExpiredHolds(ctx, nil, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired holds: %w", err)
+	}
+	return holds, nil
+}
+
+func (s *holdService) GetHold(ctx context.Context, holdID string) (*domain.Hold, error) {
+	hold, err := s.holdRepo.GetHold(ctx, nil, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	return hold, nil
+}
+
+func (s *holdService) lockAuthorizedHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error) {
+	hold, err := s.holdRepo.LockHold(ctx, tx, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	if hold == nil {
+		return nil, ErrHoldNotFound
+	}
+	if hold.Status != domain.HoldStatusAuthorized {
+		return nil, fmt.Errorf("%w: hold is %s", ErrHoldNotAuthorized, hold.Status)
+	}
+	return hold, nil
+}
+
+func (s *holdService) releaseHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold, status domain.HoldStatus, now time.Time) error {
+	balance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, hold.SourceAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get source account balance: %w", err)
+	}
+	if balance == nil {
+		return errors.New("source account balance not found")
+	}
+
+	err = s.adjustHeldAmount(ctx, tx, balance, hold.Amount.Neg(), now)
+	if err != nil {
+		return err
+	}
+
+	hold.Status = status
+	hold.UpdatedAt = now
+	err = s.holdRepo.UpdateHoldStatus(ctx, tx, hold)
+	if err != nil {
+		return fmt.Errorf("failed to update hold: %w", err)
+	}
+	return nil
+}
+
+// recordHoldEvent appends event to the stream of its hold with its outbox
+// message.
+func (s *holdService) recordHoldEvent(ctx context.Context, tx *gorm.DB, event domain.Event, accountID uint, now time.Time) error {
+	sequence, err := s.eventStore.GetStreamSequence(ctx, tx, event.AggregateType(), event.AggregateID())
+	if err != nil {
+		return fmt.Errorf("failed to get hold stream sequence: %w", err)
+	}
+	err = appendEvent(ctx, tx, s.eventStore, event, sequence, nil, now)
+	if err != nil {
+		return err
+	}
+
+	message, err := domain.NewAccountOutboxMessage(event, accountID, now)
+	if err != nil {
+		return err
+	}
+	err = s.outboxRepo.SaveOutboxMessage(ctx, tx, message)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message: %w", err)
+	}
+	return nil
+}
+
+// adjustHeldAmount moves the reserved amount of an account under the same
+// optimistic version check as ledger postings.
+func (s *holdService) adjustHeldAmount(ctx context.Context, tx *gorm.DB, balance *domain.AccountBalance, delta decimal.Decimal, now time.Time) error {
+	newBalance := &domain.AccountBalance{
+		AccountID:   balance.AccountID,
+		Balance:     balance.Balance,
+		HeldAmount:  balance.HeldAmount.Add(delta),
+		Version:     balance.Version + 1,
+		LastEventID: balance.LastEventID,
+		UpdatedAt:   now,
+	}
+	err := s.accountBalanceRepo.UpdateAccountBalanceWithVersion(ctx, tx, newBalance, balance.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update held amount of account %d: %w", balance.AccountID, err)
+	}
+	return nil
+}
+
+func holdFingerprint(req HoldRequest) string {
+	payload := fmt.Sprintf("hold:%d:%d:%s:%s", req.SourceAccountID, req.DestinationAccountID, req.Amount.String(), req.ExpiresIn)
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+func captureFingerprint(req CaptureRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("capture:%s:%s", req.HoldID, req.Amount.String())))
+	return hex.EncodeToString(sum[:])
+}
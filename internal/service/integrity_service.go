@@ -2,38 +2,867 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
 	"github.com/dirdr/goits/internal/repository"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
+// hashChainBatchSize is how many rows VerifyHashChains reads per query.
+const hashChainBatchSize = 1000
+
+// transactionBatchSize is how many transfer events VerifyTransactions reads,
+// with their journal entries, per query.
+const transactionBatchSize = 500
+
+// linkageEntryLimit caps the journal entries VerifyLinkage lists as pointing
+// to a missing event or account.
+const linkageEntryLimit = 1000
+
+var errOriginalTransferMissing = errors.New("original transfer not found")
+
 type integrityService struct {
-	journalRepo repository.JournalRepository
+	accountRepo        repository.AccountRepository
+	accountBalanceRepo repository.AccountBalanceRepository
+	journalRepo        repository.JournalRepository
+	transferEventRepo  repository.TransferEventRepository
+	hashChainRepo      repository.HashChainRepository
+	eventStore         repository.EventStore
+	integrityRunRepo   repository.IntegrityRunRepository
+	repairRepo         repository.ProjectionRepairRepository
 }
 
-func NewIntegrityService(journalRepo repository.JournalRepository) IntegrityService {
+func NewIntegrityService(accountRepo repository.AccountRepository, accountBalanceRepo repository.AccountBalanceRepository, journalRepo repository.JournalRepository, transferEventRepo repository.TransferEventRepository, hashChainRepo repository.HashChainRepository, eventStore repository.EventStore, integrityRunRepo repository.IntegrityRunRepository, repairRepo repository.ProjectionRepairRepository) IntegrityService {
 	return &integrityService{
-		journalRepo: journalRepo,
+		accountRepo:        accountRepo,
+		accountBalanceRepo: accountBalanceRepo,
+		journalRepo:        journalRepo,
+		transferEventRepo:  transferEventRepo,
+		hashChainRepo:      hashChainRepo,
+		eventStore:         eventStore,
+		integrityRunRepo:   integrityRunRepo,
+		repairRepo:         repairRepo,
 	}
 }
 
+// VerifyDoubleBookkeeping checks that debits equal credits within each
+// currency. Amounts in different currencies are never summed together.
 func (s *integrityService) VerifyDoubleBookkeeping(ctx context.Context) (*IntegrityResult, error) {
-	totals, err := s.journalRepo.GetTotalsByEntryType(ctx, nil)
+	totals, err := s.journalRepo.GetTotalsByCurrencyAndEntryType(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get totals by entry type: %w", err)
+		return nil, fmt.Errorf("failed to get totals by currency and entry type: %w", err)
 	}
 
-	totalDebits := totals[domain.Debit]
-	totalCredits := totals[domain.Credit]
-	difference := totalDebits.Sub(totalCredits)
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
 
 	result := &IntegrityResult{
-		IsValid:      difference.IsZero(),
-		TotalDebits:  totalDebits,
-		TotalCredits: totalCredits,
-		Difference:   difference,
+		IsValid:      true,
+		TotalDebits:  decimal.Zero,
+		TotalCredits: decimal.Zero,
+		Currencies:   make([]CurrencyIntegrity, 0, len(currencies)),
+	}
+
+	for _, currency := range currencies {
+		totalDebits := totals[currency][domain.Debit]
+		totalCredits := totals[currency][domain.Credit]
+		difference := totalDebits.Sub(totalCredits)
+
+		result.Currencies = append(result.Currencies, CurrencyIntegrity{
+			Currency:     currency,
+			IsValid:      difference.IsZero(),
+			TotalDebits:  totalDebits,
+			TotalCredits: totalCredits,
+			Difference:   difference,
+		})
+		if !difference.IsZero() {
+			result.IsValid = false
+		}
+		result.TotalDebits = result.TotalDebits.Add(totalDebits)
+		result.TotalCredits = result.TotalCredits.Add(totalCredits)
+	}
+	result.Difference = result.TotalDebits.Sub(result.TotalCredits)
+
+	return result, nil
+}
+
+func (s *integrityService) SealHashChains(ctx context.Context, tx *gorm.DB, limit int) (*HashChainSealReport, error) {
+	transferEvents, err := s.hashChainRepo.SealHashChain(ctx, tx, domain.HashChainTransferEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	journalEntries, err := s.hashChainRepo.SealHashChain(ctx, tx, domain.HashChainJournalEntries, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &HashChainSealReport{TransferEvents: transferEvents, JournalEntries: journalEntries}, nil
+}
+
+func (s *integrityService) VerifyHashChains(ctx context.Context, tx *gorm.DB) (*HashChainVerification, error) {
+	transferEvents, err := s.verifyHashChain(ctx, tx, domain.HashChainTransferEvents, func(afterSeq uint) ([]chainLink, error) {
+		events, err := s.transferEventRepo.ListSealedTransferEvents(ctx, tx, afterSeq, hashChainBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transfer events: %w", err)
+		}
+		links := make([]chainLink, 0, len(events))
+		for _, event := range events {
+			links = append(links, chainLink{seq: event.ChainSeq, id: event.EventID, prevHash: event.PrevHash, hash: event.Hash, chainHash: event.ChainHash})
+		}
+		return links, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	journalEntries, err := s.verifyHashChain(ctx, tx, domain.HashChainJournalEntries, func(afterSeq uint) ([]chainLink, error) {
+		entries, err := s.journalRepo.ListSealedJournalEntries(ctx, tx, afterSeq, hashChainBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list journal entries: %w", err)
+		}
+		links := make([]chainLink, 0, len(entries))
+		for _, entry := range entries {
+			links = append(links, chainLink{seq: entry.ChainSeq, id: entry.EntryID, prevHash: entry.PrevHash, hash: entry.Hash, chainHash: entry.ChainHash})
+		}
+		return links, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &HashChainVerification{
+		IsValid: transferEvents.IsValid && journalEntries.IsValid,
+		Chains:  []HashChainResult{*transferEvents, *journalEntries},
+	}, nil
+}
+
+// chainLink is a row of a hash chain as read back from its table.
+type chainLink struct {
+	seq       uint
+	id        uint
+	prevHash  string
+	hash      string
+	chainHash func(prevHash string) (string, error)
+}
+
+// verifyHashChain stops at the first row of the chain that is not intact.
+func (s *integrityService) verifyHashChain(ctx context.Context, tx *gorm.DB, chain string, next func(afterSeq uint) ([]chainLink, error)) (*HashChainResult, error) {
+	head, err := s.hashChainRepo.GetHashChainHead(ctx, tx, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hash chain head: %w", err)
+	}
+	if head == nil {
+		head = &domain.HashChainHead{Chain: chain, LastHash: domain.GenesisHash}
+	}
+
+	result := &HashChainResult{Chain: chain, IsValid: true}
+	var lastSeq uint
+	lastHash := domain.GenesisHash
+	for {
+		links, err := next(lastSeq)
+		if err != nil {
+			return nil, err
+		}
+		if len(links) == 0 {
+			break
+		}
+
+		for _, link := range links {
+			if link.seq != lastSeq+1 || link.prevHash != lastHash {
+				result.IsValid = false
+				result.BrokenLink = &BrokenLink{RowID: link.id, PreviousRowID: result.LastID, Reason: BrokenLinkPrevHash, ExpectedHash: lastHash, ActualHash: link.prevHash}
+				return result, nil
+			}
+			hash, err := link.chainHash(link.prevHash)
+			if err != nil {
+				return nil, err
+			}
+			if link.hash != hash {
+				result.IsValid = false
+				result.BrokenLink = &BrokenLink{RowID: link.id, PreviousRowID: result.LastID, Reason: BrokenLinkHash, ExpectedHash: hash, ActualHash: link.hash}
+				return result, nil
+			}
+
+			result.RowsVerified++
+			result.LastID = link.id
+			lastSeq, lastHash = link.seq, link.hash
+		}
+	}
+
+	if head.LastSeq != lastSeq || head.LastID != result.LastID || head.LastHash != lastHash {
+		result.IsValid = false
+		result.BrokenLink = &BrokenLink{RowID: head.LastID, PreviousRowID: result.LastID, Reason: BrokenLinkHead, ExpectedHash: head.LastHash, ActualHash: lastHash}
+	}
+	return result, nil
+}
+
+func (s *integrityService) ReconcileAccounts(ctx context.Context, tx *gorm.DB) (*AccountReconciliationReport, error) {
+	balances, err := s.accountBalanceRepo.ListAccountBalances(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account balances: %w", err)
+	}
+	sums, err := s.journalRepo.SumJournalEntriesByAccount(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum journal entries by account: %w", err)
+	}
+	openings, err := s.listOpenedAccounts(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := make(map[uint]*domain.AccountBalance, len(balances))
+	for i := range balances {
+		projected[balances[i].AccountID] = &balances[i]
+	}
+	accountIDs := make(map[uint]struct{}, len(balances))
+	for accountID := range projected {
+		accountIDs[accountID] = struct{}{}
+	}
+	for accountID := range sums {
+		accountIDs[accountID] = struct{}{}
+	}
+	for accountID := range openings {
+		accountIDs[accountID] = struct{}{}
+	}
+
+	report := &AccountReconciliationReport{IsValid: true, Drifting: []AccountReconciliation{}}
+	for _, accountID := range sortedAccountIDs(accountIDs) {
+		reconciliation := reconcileAccount(accountID, openings[accountID], projected[accountID], sums[accountID])
+		report.AccountsChecked++
+		if !reconciliation.IsValid {
+			report.IsValid = false
+			report.Drifting = append(report.Drifting, reconciliation)
+		}
+	}
+
+	return report, nil
+}
+
+func (s *integrityService) ReconcileAccount(ctx context.Context, tx *gorm.DB, accountID uint) (*AccountReconciliation, error) {
+	exists, err := s.accountRepo.AccountExists(ctx, tx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check account: %w", err)
+	}
+	if !exists {
+		return nil, ErrAccountNotFound
+	}
+
+	balance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	sum, err := s.journalRepo.SumAccountJournalEntries(ctx, tx, domain.AccountJournalRange{AccountID: accountID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum account journal entries: %w", err)
+	}
+
+	events, err := s.eventStore.LoadStream(ctx, tx, domain.AggregateTypeAccount, strconv.FormatUint(uint64(accountID), 10), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account stream: %w", err)
+	}
+	var opened *domain.AccountOpened
+	for i := range events {
+		if events[i].EventType == domain.EventTypeAccountOpened {
+			event, err := events[i].Decode()
+			if err != nil {
+				return nil, err
+			}
+			opened = event.(*domain.AccountOpened)
+			break
+		}
+	}
+
+	reconciliation := reconcileAccount(accountID, opened, balance, *sum)
+	return &reconciliation, nil
+}
+
+// listOpenedAccounts returns the AccountOpened event of every account that has
+// one.
+func (s *integrityService) listOpenedAccounts(ctx context.Context, tx *gorm.DB) (map[uint]*domain.AccountOpened, error) {
+	openings := make(map[uint]*domain.AccountOpened)
+
+	var afterEventID uint
+	for {
+		envelopes, err := s.eventStore.ListEventsByType(ctx, tx, domain.EventTypeAccountOpened, afterEventID, replayBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list opened accounts: %w", err)
+		}
+		for _, envelope := range envelopes {
+			event, err := envelope.Decode()
+			if err != nil {
+				return nil, err
+			}
+			opened := event.(*domain.AccountOpened)
+			openings[opened.AccountID] = opened
+			afterEventID = envelope.EventID
+		}
+		if len(envelopes) < replayBatchSize {
+			return openings, nil
+		}
+	}
+}
+
+// reconcileAccount takes a nil opened or balance when the account has none.
+func reconcileAccount(accountID uint, opened *domain.AccountOpened, balance *domain.AccountBalance, sum domain.JournalSum) AccountReconciliation {
+	reconciliation := AccountReconciliation{
+		AccountID:           accountID,
+		IsValid:             true,
+		OpeningBalance:      decimal.Zero,
+		JournalNet:          sum.Net,
+		JournalEntries:      sum.Count,
+		ProjectedBalance:    decimal.Zero,
+		ExpectedLastEventID: sum.LastEventID,
+	}
+	if opened != nil {
+		reconciliation.OpeningBalance = opened.OpeningBalance
+	}
+	reconciliation.ExpectedBalance = reconciliation.OpeningBalance.Add(sum.Net)
+	if balance != nil {
+		reconciliation.ProjectedBalance = balance.Balance
+		reconciliation.ProjectedLastEventID = balance.LastEventID
+	}
+	reconciliation.Difference = reconciliation.ProjectedBalance.Sub(reconciliation.ExpectedBalance)
+
+	switch {
+	case opened == nil:
+		reconciliation.Reason = ReconciliationMissingOpening
+	case balance == nil:
+		reconciliation.Reason = ReconciliationMissingBalance
+	case !reconciliation.Difference.IsZero():
+		reconciliation.Reason = ReconciliationBalanceMismatch
+	case reconciliation.ProjectedLastEventID != reconciliation.ExpectedLastEventID:
+		reconciliation.Reason = ReconciliationLastEventMismatch
+	}
+	reconciliation.IsValid = reconciliation.Reason == ""
+	return reconciliation
+}
+
+func (s *integrityService) VerifyTransactions(ctx context.Context, tx *gorm.DB) (*TransactionVerificationReport, error) {
+	report := &TransactionVerificationReport{IsValid: true, Invalid: []TransactionIssue{}}
+	fxAccounts := make(map[string]uint)
+
+	var afterID uint
+	for {
+		events, err := s.transferEventRepo.ListTransferEventsAfter(ctx, tx, afterID, transactionBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transfer events: %w", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		transferIDs := make([]string, 0, len(events))
+		for _, event := range events {
+			transferIDs = append(transferIDs, event.TransferID)
+		}
+		entries, err := s.journalRepo.ListJournalEntriesByTransactionIDs(ctx, tx, transferIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list journal entries: %w", err)
+		}
+		entriesByTransaction := make(map[string][]domain.JournalEntry, len(events))
+		for _, entry := range entries {
+			entriesByTransaction[entry.TransactionID] = append(entriesByTransaction[entry.TransactionID], entry)
+		}
+
+		for i := range events {
+			issue, err := s.verifyTransaction(ctx, tx, &events[i], entriesByTransaction[events[i].TransferID], fxAccounts)
+			if err != nil {
+				return nil, err
+			}
+			report.TransactionsChecked++
+			if issue != nil {
+				report.IsValid = false
+				report.Invalid = append(report.Invalid, *issue)
+			}
+		}
+		afterID = events[len(events)-1].EventID
+	}
+
+	return report, nil
+}
+
+// verifyTransaction checks the journal entries of the event's transaction and
+// returns the issue found with them, or nil when they are sound.
+func (s *integrityService) verifyTransaction(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent, entries []domain.JournalEntry, fxAccounts map[string]uint) (*TransactionIssue, error) {
+	issue := &TransactionIssue{TransactionID: event.TransferID, EventID: event.EventID, EventType: event.EventType}
+
+	totals := make(map[string]map[domain.EntryType]decimal.Decimal)
+	actual := make([]TransactionLeg, 0, len(entries))
+	for _, entry := range entries {
+		if totals[entry.Currency] == nil {
+			totals[entry.Currency] = map[domain.EntryType]decimal.Decimal{domain.Debit: decimal.Zero, domain.Credit: decimal.Zero}
+		}
+		totals[entry.Currency][entry.Type] = totals[entry.Currency][entry.Type].Add(entry.Amount)
+		actual = append(actual, TransactionLeg{AccountID: entry.AccountID, Type: entry.Type, Amount: entry.Amount, Currency: entry.Currency})
+	}
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		difference := totals[currency][domain.Debit].Sub(totals[currency][domain.Credit])
+		if !difference.IsZero() {
+			issue.Unbalanced = append(issue.Unbalanced, CurrencyIntegrity{
+				Currency:     currency,
+				TotalDebits:  totals[currency][domain.Debit],
+				TotalCredits: totals[currency][domain.Credit],
+				Difference:   difference,
+			})
+		}
+	}
+	if len(issue.Unbalanced) > 0 {
+		issue.Reasons = append(issue.Reasons, TransactionUnbalanced)
+	}
+
+	expected, err := s.expectedTransactionLegs(ctx, tx, event, fxAccounts)
+	switch {
+	case errors.Is(err, errOriginalTransferMissing):
+		issue.Reasons = append(issue.Reasons, TransactionMissingOriginal)
+	case err != nil:
+		return nil, err
+	default:
+		issue.MissingLegs, issue.UnexpectedLegs = diffTransactionLegs(expected, actual)
+		if len(issue.MissingLegs) > 0 || len(issue.UnexpectedLegs) > 0 {
+			issue.Reasons = append(issue.Reasons, TransactionLegsMismatch)
+		}
+	}
+
+	if len(issue.Reasons) == 0 {
+		return nil, nil
+	}
+	return issue, nil
+}
+
+// expectedTransactionLegs derives the journal legs of event the way they are
+// posted.
+func (s *integrityService) expectedTransactionLegs(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent, fxAccounts map[string]uint) ([]TransactionLeg, error) {
+	if event.EventType != domain.EventTypeTransferReversed {
+		return s.postedTransactionLegs(ctx, tx, event, fxAccounts)
 	}
 
+	original, err := s.transferEventRepo.GetTransferEventByTransferID(ctx, tx, event.ReversesTransferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original transfer: %w", err)
+	}
+	if original == nil {
+		return nil, errOriginalTransferMissing
+	}
+	originalLegs, err := s.postedTransactionLegs(ctx, tx, original, fxAccounts)
+	if err != nil {
+		return nil, err
+	}
+
+	isFull := event.Amount.Equal(original.Amount)
+	legs := make([]TransactionLeg, 0, len(originalLegs))
+	for _, leg := range originalLegs {
+		entryType := domain.Credit
+		if leg.Type == domain.Credit {
+			entryType = domain.Debit
+		}
+		amount := leg.Amount
+		if !isFull {
+			if leg.Currency == original.Currency {
+				amount = event.Amount
+			} else {
+				amount = event.Amount.Mul(original.Conversion.Rate).Round(8)
+			}
+		}
+		legs = append(legs, TransactionLeg{AccountID: leg.AccountID, Type: entryType, Amount: amount, Currency: leg.Currency})
+	}
+	return legs, nil
+}
+
+func (s *integrityService) postedTransactionLegs(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent, fxAccounts map[string]uint) ([]TransactionLeg, error) {
+	if len(event.Legs) > 0 {
+		legs := make([]TransactionLeg, 0, len(event.Legs))
+		for _, posting := range event.Legs {
+			legs = append(legs, TransactionLeg{AccountID: posting.AccountID, Type: posting.Type, Amount: posting.Amount, Currency: event.Currency})
+		}
+		return legs, nil
+	}
+
+	if event.Conversion == nil {
+		return []TransactionLeg{
+			{AccountID: event.FromAccountID, Type: domain.Debit, Amount: event.Amount, Currency: event.Currency},
+			{AccountID: event.ToAccountID, Type: domain.Credit, Amount: event.Amount, Currency: event.Currency},
+		}, nil
+	}
+
+	conversion := event.Conversion
+	sourcePosition, err := s.fxPositionAccountID(ctx, tx, event.Currency, fxAccounts)
+	if err != nil {
+		return nil, err
+	}
+	destinationPosition, err := s.fxPositionAccountID(ctx, tx, conversion.DestinationCurrency, fxAccounts)
+	if err != nil {
+		return nil, err
+	}
+	return []TransactionLeg{
+		{AccountID: event.FromAccountID, Type: domain.Debit, Amount: event.Amount, Currency: event.Currency},
+		{AccountID: sourcePosition, Type: domain.Credit, Amount: event.Amount, Currency: event.Currency},
+		{AccountID: destinationPosition, Type: domain.Debit, Amount: conversion.DestinationAmount, Currency: conversion.DestinationCurrency},
+		{AccountID: event.ToAccountID, Type: domain.Credit, Amount: conversion.DestinationAmount, Currency: conversion.DestinationCurrency},
+	}, nil
+}
+
+// fxPositionAccountID returns the FX position account of the currency, caching
+// it in fxAccounts.
+func (s *integrityService) fxPositionAccountID(ctx context.Context, tx *gorm.DB, currency string, fxAccounts map[string]uint) (uint, error) {
+	if accountID, ok := fxAccounts[currency]; ok {
+		return accountID, nil
+	}
+	account, err := s.accountRepo.GetSystemAccount(ctx, tx, domain.AccountTypeFXPosition, currency)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get FX position account: %w", err)
+	}
+	if account == nil {
+		return 0, fmt.Errorf("no FX position account provisioned for %s", currency)
+	}
+	fxAccounts[currency] = account.ID
+	return account.ID, nil
+}
+
+func (s *integrityService) VerifyLinkage(ctx context.Context, tx *gorm.DB) (*LinkageReport, error) {
+	report := &LinkageReport{IsValid: true, Events: []EventLinkageIssue{}}
+	fxAccounts := make(map[string]uint)
+
+	var afterID uint
+	for {
+		events, err := s.transferEventRepo.ListTransferEventsAfter(ctx, tx, afterID, transactionBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transfer events: %w", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		lastID := events[len(events)-1].EventID
+		entries, err := s.journalRepo.ListJournalEntriesInEventRange(ctx, tx, domain.JournalEventRange{AfterEventID: afterID, UpToEventID: lastID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list journal entries: %w", err)
+		}
+		entriesByEvent := make(map[uint][]domain.JournalEntry, len(events))
+		for _, entry := range entries {
+			entriesByEvent[entry.SourceEventID] = append(entriesByEvent[entry.SourceEventID], entry)
+		}
+
+		for i := range events {
+			issue, err := s.verifyEventLinkage(ctx, tx, &events[i], entriesByEvent[events[i].EventID], fxAccounts)
+			if err != nil {
+				return nil, err
+			}
+			report.EventsChecked++
+			if issue != nil {
+				report.IsValid = false
+				report.Events = append(report.Events, *issue)
+			}
+		}
+		afterID = lastID
+	}
+
+	orphaned, err := s.journalRepo.ListOrphanedJournalEntries(ctx, tx, linkageEntryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orphaned journal entries: %w", err)
+	}
+	missingAccount, err := s.journalRepo.ListJournalEntriesWithMissingAccount(ctx, tx, linkageEntryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal entries with a missing account: %w", err)
+	}
+	report.OrphanedEntries = orphaned
+	report.EntriesWithMissingAccount = missingAccount
+	if len(orphaned) > 0 || len(missingAccount) > 0 {
+		report.IsValid = false
+	}
+
+	return report, nil
+}
+
+// verifyEventLinkage returns nil when the entries match the event. A reversal of
+// a missing transfer only gets its transaction checked.
+func (s *integrityService) verifyEventLinkage(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent, entries []domain.JournalEntry, fxAccounts map[string]uint) (*EventLinkageIssue, error) {
+	issue := &EventLinkageIssue{EventID: event.EventID, TransferID: event.TransferID, EventType: event.EventType}
+	if len(entries) == 0 {
+		issue.Reasons = append(issue.Reasons, LinkageOrphanedEvent)
+		return issue, nil
+	}
+
+	type direction struct {
+		currency  string
+		entryType domain.EntryType
+	}
+	posted := make(map[direction]decimal.Decimal)
+	for _, entry := range entries {
+		if entry.Type == domain.Debit {
+			issue.Debits++
+		} else {
+			issue.Credits++
+		}
+		key := direction{entry.Currency, entry.Type}
+		posted[key] = posted[key].Add(entry.Amount)
+	}
+	for _, entry := range entries {
+		if entry.TransactionID != event.TransferID {
+			issue.Reasons = append(issue.Reasons, LinkageTransactionMismatch)
+			break
+		}
+	}
+
+	expectedLegs, err := s.expectedTransactionLegs(ctx, tx, event, fxAccounts)
+	switch {
+	case errors.Is(err, errOriginalTransferMissing):
+		issue.ExpectedDebits, issue.ExpectedCredits = issue.Debits, issue.Credits
+	case err != nil:
+		return nil, err
+	default:
+		expected := make(map[direction]decimal.Decimal)
+		for _, leg := range expectedLegs {
+			if leg.Type == domain.Debit {
+				issue.ExpectedDebits++
+			} else {
+				issue.ExpectedCredits++
+			}
+			key := direction{leg.Currency, leg.Type}
+			expected[key] = expected[key].Add(leg.Amount)
+		}
+		if issue.Debits != issue.ExpectedDebits || issue.Credits != issue.ExpectedCredits {
+			issue.Reasons = append(issue.Reasons, LinkageLegCountMismatch)
+		}
+
+		keys := make([]direction, 0, len(expected)+len(posted))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		for key := range posted {
+			if _, ok := expected[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].currency != keys[j].currency {
+				return keys[i].currency < keys[j].currency
+			}
+			return keys[i].entryType < keys[j].entryType
+		})
+		for _, key := range keys {
+			if !expected[key].Equal(posted[key]) {
+				issue.Amounts = append(issue.Amounts, LinkageAmount{Currency: key.currency, Type: key.entryType, Expected: expected[key], Posted: posted[key]})
+			}
+		}
+		if len(issue.Amounts) > 0 {
+			issue.Reasons = append(issue.Reasons, LinkageAmountMismatch)
+		}
+	}
+
+	if len(issue.Reasons) == 0 {
+		return nil, nil
+	}
+	return issue, nil
+}
+
+// diffTransactionLegs compares the legs as multisets.
+func diffTransactionLegs(expected, actual []TransactionLeg) (missing, unexpected []TransactionLeg) {
+	key := func(leg TransactionLeg) string {
+		return fmt.Sprintf("%d/%s/%s/%s", leg.AccountID, leg.Type, leg.Currency, leg.Amount.String())
+	}
+
+	remaining := make(map[string]int, len(actual))
+	for _, leg := range actual {
+		remaining[key(leg)]++
+	}
+	for _, leg := range expected {
+		if remaining[key(leg)] > 0 {
+			remaining[key(leg)]--
+			continue
+		}
+		missing = append(missing, leg)
+	}
+	for _, leg := range actual {
+		if remaining[key(leg)] > 0 {
+			remaining[key(leg)]--
+			unexpected = append(unexpected, leg)
+		}
+	}
+	return missing, unexpected
+}
+
+func (s *integrityService) RunIntegrityCheck(ctx context.Context, tx *gorm.DB, check string) (*domain.IntegrityCheckResult, error) {
+	var report any
+	var isValid bool
+	switch check {
+	case domain.IntegrityCheckDoubleBookkeeping:
+		result, err := s.VerifyDoubleBookkeeping(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report, isValid = result, result.IsValid
+	case domain.IntegrityCheckHashChains:
+		result, err := s.VerifyHashChains(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		report, isValid = result, result.IsValid
+	case domain.IntegrityCheckAccounts:
+		result, err := s.ReconcileAccounts(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		report, isValid = result, result.IsValid
+	case domain.IntegrityCheckTransactions:
+		result, err := s.VerifyTransactions(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		report, isValid = result, result.IsValid
+	case domain.IntegrityCheckLinkage:
+		result, err := s.VerifyLinkage(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		report, isValid = result, result.IsValid
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownIntegrityCheck, check)
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s report: %w", check, err)
+	}
+	result := &domain.IntegrityCheckResult{Check: check, Status: domain.IntegrityStatusPassed, Report: encoded}
+	if !isValid {
+		result.Status = domain.IntegrityStatusFailed
+	}
 	return result, nil
 }
+
+func (s *integrityService) RecordIntegrityRun(ctx context.Context, tx *gorm.DB, run *domain.IntegrityRun) error {
+	err := s.integrityRunRepo.SaveIntegrityRun(ctx, tx, run)
+	if err != nil {
+		return fmt.Errorf("failed to record integrity run: %w", err)
+	}
+	return nil
+}
+
+func (s *integrityService) GetIntegrityRun(ctx context.Context, runID uint) (*domain.IntegrityRun, error) {
+	run, err := s.integrityRunRepo.GetIntegrityRun(ctx, nil, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integrity run: %w", err)
+	}
+	return run, nil
+}
+
+func (s *integrityService) ListIntegrityRuns(ctx context.Context, query domain.IntegrityRunQuery) ([]domain.IntegrityRun, error) {
+	runs, err := s.integrityRunRepo.ListIntegrityRuns(ctx, nil, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list integrity runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (s *integrityService) RepairAccounts(ctx context.Context, tx *gorm.DB, req ProjectionRepairRequest) (*ProjectionRepairReport, error) {
+	if !req.DryRun && req.Operator == "" {
+		return nil, ErrRepairOperatorRequired
+	}
+
+	var drifting []AccountReconciliation
+	report := &ProjectionRepairReport{DryRun: req.DryRun, Repairs: []domain.ProjectionRepair{}, Unrepairable: []AccountReconciliation{}}
+	if req.AccountID != 0 {
+		reconciliation, err := s.ReconcileAccount(ctx, tx, req.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		report.AccountsChecked = 1
+		if !reconciliation.IsValid {
+			drifting = append(drifting, *reconciliation)
+		}
+	} else {
+		reconciliations, err := s.ReconcileAccounts(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		report.AccountsChecked = reconciliations.AccountsChecked
+		drifting = reconciliations.Drifting
+	}
+
+	now := time.Now()
+	for _, reconciliation := range drifting {
+		// Without an opening event the expected balance is unknown, and without
+		// a balance row there is no version to update: a projection rebuild
+		// handles both.
+		if reconciliation.Reason == ReconciliationMissingOpening || reconciliation.Reason == ReconciliationMissingBalance {
+			report.Unrepairable = append(report.Unrepairable, reconciliation)
+			continue
+		}
+
+		repair, err := s.repairAccount(ctx, tx, reconciliation, req, now)
+		if err != nil {
+			return nil, err
+		}
+		report.Repairs = append(report.Repairs, *repair)
+	}
+
+	return report, nil
+}
+
+// repairAccount sets the balance and last event ID of the drifting account to
+// the ones its journal implies and, unless req.DryRun is set, audits it.
+func (s *integrityService) repairAccount(ctx context.Context, tx *gorm.DB, reconciliation AccountReconciliation, req ProjectionRepairRequest, now time.Time) (*domain.ProjectionRepair, error) {
+	balance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, reconciliation.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance of account %d: %w", reconciliation.AccountID, err)
+	}
+	if balance == nil {
+		return nil, fmt.Errorf("balance of account %d not found", reconciliation.AccountID)
+	}
+
+	repair := &domain.ProjectionRepair{
+		AccountID:      reconciliation.AccountID,
+		Reason:         string(reconciliation.Reason),
+		OldBalance:     balance.Balance,
+		NewBalance:     reconciliation.ExpectedBalance,
+		OldLastEventID: balance.LastEventID,
+		NewLastEventID: reconciliation.ExpectedLastEventID,
+		OldVersion:     balance.Version,
+		NewVersion:     balance.Version + 1,
+		Operator:       req.Operator,
+		RepairedAt:     now,
+	}
+	if req.DryRun {
+		return repair, nil
+	}
+
+	newBalance := &domain.AccountBalance{
+		AccountID:   balance.AccountID,
+		Balance:     repair.NewBalance,
+		HeldAmount:  balance.HeldAmount,
+		Version:     repair.NewVersion,
+		LastEventID: repair.NewLastEventID,
+		UpdatedAt:   now,
+	}
+	err = s.accountBalanceRepo.UpdateAccountBalanceWithVersion(ctx, tx, newBalance, balance.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair balance of account %d: %w", balance.AccountID, err)
+	}
+	err = s.repairRepo.SaveProjectionRepair(ctx, tx, repair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record repair of account %d: %w", balance.AccountID, err)
+	}
+	return repair, nil
+}
+
+func (s *integrityService) ListProjectionRepairs(ctx context.Context, query domain.ProjectionRepairQuery) ([]domain.ProjectionRepair, error) {
+	repairs, err := s.repairRepo.ListProjectionRepairs(ctx, nil, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projection repairs: %w", err)
+	}
+	return repairs, nil
+}
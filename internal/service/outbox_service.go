@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/publisher"
+	"github.com/dirdr/goits/internal/repository"
+	"gorm.io/gorm"
+)
+
+// outboxClaimTimeout is how long claimed messages are held by their relay. A
+// relay publishes for half of it at most, and messages it never completes are
+// claimed again once it runs out.
+const outboxClaimTimeout = 5 * time.Minute
+
+type outboxService struct {
+	outboxRepo  repository.OutboxRepository
+	webhookRepo repository.WebhookRepository
+	publisher   publisher.Publisher
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+}
+
+// NewOutboxService relays outbox messages to the active webhook subscriptions
+// and through publisher, which may be nil. A failed delivery is retried after
+// minBackoff, doubling with every further failure up to maxBackoff, and the
+// message is dead-lettered after maxAttempts.
+func NewOutboxService(outboxRepo repository.OutboxRepository, webhookRepo repository.WebhookRepository, publisher publisher.Publisher, minBackoff, maxBackoff time.Duration, maxAttempts int) OutboxService {
+	return &outboxService{
+		outboxRepo:  outboxRepo,
+		webhookRepo: webhookRepo,
+		publisher:   publisher,
+		minBackoff:  minBackoff,
+		maxBackoff:  maxBackoff,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// ClaimOutboxMessages walks the oldest pending messages in order. Each due
+// message is queued for delivery to the webhook subscriptions of its event
+// type, which the webhook dispatcher sends on its own schedule, and held for
+// outboxClaimTimeout so that no other relay publishes it meanwhile.
+//
+// A message that is waiting for a retry, or held by another relay, blocks every
+// later message sharing one of its accounts, which keeps each account's
+// messages in order; messages of other accounts go on.
+func (s *outboxService) ClaimOutboxMessages(ctx context.Context, tx *gorm.DB, now time.Time, limit int) (*OutboxBatch, error) {
+	locked, err := s.outboxRepo.TryLockOutboxRelay(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	messages, err := s.outboxRepo.ListPendingOutboxMessages(ctx, tx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &OutboxBatch{}
+	if len(messages) == 0 {
+		return batch, nil
+	}
+
+	subscriptions, err := s.webhookRepo.ListActiveWebhookSubscriptions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[uint]bool)
+	for i := range messages {
+		message := &messages[i]
+
+		if message.NextAttemptAt.After(now) || blocksOn(blocked, message.AccountIDs) {
+			block(blocked, message.AccountIDs)
+			batch.Report.Deferred++
+			continue
+		}
+
+		queued, err := s.queueWebhookDeliveries(ctx, tx, message, subscriptions, now)
+		if err != nil {
+			return nil, err
+		}
+		batch.Report.WebhookDeliveries += queued
+
+		message.NextAttemptAt = now.Add(outboxClaimTimeout)
+		err = s.outboxRepo.UpdateOutboxMessageDelivery(ctx, tx, message)
+		if err != nil {
+			return nil, err
+		}
+		batch.Messages = append(batch.Messages, *message)
+	}
+
+	return batch, nil
+}
+
+// PublishOutboxMessages publishes the batch in order. A message that fails is
+// retried after a backoff, or dead-lettered after its last attempt, and the
+// later messages of its accounts are released for the next run. So are the
+// messages left when half of the claim timeout has passed.
+func (s *outboxService) PublishOutboxMessages(ctx context.Context, batch *OutboxBatch, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, outboxClaimTimeout/2)
+	defer cancel()
+
+	blocked := make(map[uint]bool)
+	for i := range batch.Messages {
+		message := &batch.Messages[i]
+
+		if ctx.Err() != nil || blocksOn(blocked, message.AccountIDs) {
+			block(blocked, message.AccountIDs)
+			message.NextAttemptAt = now
+			batch.Report.Deferred++
+			continue
+		}
+
+		message.Attempts++
+		if err := s.publish(ctx, message); err != nil {
+			message.LastError = err.Error()
+			if message.Attempts >= s.maxAttempts {
+				message.Status = domain.OutboxStatusDead
+				batch.Report.Dead++
+				continue
+			}
+			message.NextAttemptAt = now.Add(exponentialBackoff(s.minBackoff, s.maxBackoff, message.Attempts))
+			block(blocked, message.AccountIDs)
+			batch.Report.Failed++
+			continue
+		}
+
+		message.Status = domain.OutboxStatusPublished
+		message.LastError = ""
+		message.PublishedAt = &now
+		batch.Report.Published++
+	}
+}
+
+func (s *outboxService) CompleteOutboxMessages(ctx context.Context, tx *gorm.DB, batch *OutboxBatch) error {
+	for i := range batch.Messages {
+		err := s.outboxRepo.UpdateOutboxMessageDelivery(ctx, tx, &batch.Messages[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueWebhookDeliveries queues message once for every subscription of its
+// event type.
+func (s *outboxService) queueWebhookDeliveries(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage, subscriptions []domain.WebhookSubscription, now time.Time) (int, error) {
+	var deliveries []*domain.WebhookDelivery
+	for i := range subscriptions {
+		if !subscriptions[i].Subscribes(message.EventType) {
+			continue
+		}
+		delivery, err := domain.NewWebhookDelivery(&subscriptions[i], message, now)
+		if err != nil {
+			return 0, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return s.webhookRepo.SaveWebhookDeliveries(ctx, tx, deliveries)
+}
+
+func (s *outboxService) publish(ctx context.Context, message *domain.OutboxMessage) error {
+	if s.publisher == nil {
+		return nil
+	}
+	return s.publisher.Publish(ctx, *message)
+}
+
+// exponentialBackoff is the delay before the next attempt of a delivery that
+// failed attempts times.
+func exponentialBackoff(minBackoff, maxBackoff time.Duration, attempts int) time.Duration {
+	delay := minBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+func blocksOn(blocked map[uint]bool, accountIDs []uint) bool {
+	for _, accountID := range accountIDs {
+		if blocked[accountID] {
+			return true
+		}
+	}
+	return false
+}
+
+func block(blocked map[uint]bool, accountIDs []uint) {
+	for _, accountID := range accountIDs {
+		blocked[accountID] = true
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"gorm.io/gorm"
+)
+
+const replayBatchSize = 1000
+
+// accountReplay is the state of every account according to the event log, up
+// to some transfer event.
+type accountReplay struct {
+	accounts map[uint]*domain.AccountSnapshot
+	// snapshotEventID is the snapshot the replay started from, 0 when it
+	// started from the beginning of the log.
+	snapshotEventID uint
+	eventsReplayed  int
+}
+
+// eventReplayer replays the event log into account states, starting from the
+// latest snapshot when asked to. Transfers are replayed from their journal
+// entries rather than from transfer_events: a transfer_events row only names
+// one source and one destination, while multi-leg, FX, reversal and funding
+// transfers post to more accounts. Every entry references its transfer event
+// and is replayed in that event's order, so the replay still follows the log.
+type eventReplayer struct {
+	journalRepo  repository.JournalRepository
+	eventStore   repository.EventStore
+	snapshotRepo repository.SnapshotRepository
+}
+
+// replay applies the journal entries in event order, from the AccountOpened
+// events or the latest snapshot, up to untilEventID when set.
+func (r *eventReplayer) replay(ctx context.Context, tx *gorm.DB, untilEventID *uint, fromSnapshot bool) (*accountReplay, error) {
+	replay := &accountReplay{accounts: make(map[uint]*domain.AccountSnapshot)}
+
+	var afterEventID uint
+	for {
+		envelopes, err := r.eventStore.ListEventsByType(ctx, tx, domain.EventTypeAccountOpened, afterEventID, replayBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list opened accounts: %w", err)
+		}
+		for _, envelope := range envelopes {
+			event, err := envelope.Decode()
+			if err != nil {
+				return nil, err
+			}
+			opened := event.(*domain.AccountOpened)
+			replay.accounts[opened.AccountID] = &domain.AccountSnapshot{
+				AccountID: opened.AccountID,
+				Balance:   opened.OpeningBalance,
+			}
+			replay.eventsReplayed++
+			afterEventID = envelope.EventID
+		}
+		if len(envelopes) < replayBatchSize {
+			break
+		}
+	}
+
+	if fromSnapshot {
+		snapshotEventID, err := r.snapshotRepo.GetLatestSnapshotEventID(ctx, tx, untilEventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest snapshot: %w", err)
+		}
+		if snapshotEventID > 0 {
+			snapshots, err := r.snapshotRepo.ListAccountSnapshotsAt(ctx, tx, snapshotEventID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list account snapshots: %w", err)
+			}
+			for i := range snapshots {
+				if _, ok := replay.accounts[snapshots[i].AccountID]; !ok {
+					return nil, fmt.Errorf("%w: snapshot %d covers account %d, which has no %s event",
+						ErrIncompleteEventLog, snapshotEventID, snapshots[i].AccountID, domain.EventTypeAccountOpened)
+				}
+				replay.accounts[snapshots[i].AccountID] = &snapshots[i]
+			}
+			replay.snapshotEventID = snapshotEventID
+		}
+	}
+
+	afterSourceEventID, afterEntryID := replay.snapshotEventID, uint(0)
+	for {
+		entries, err := r.journalRepo.ListJournalEntriesInEventOrder(ctx, tx, afterSourceEventID, afterEntryID, replayBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list journal entries: %w", err)
+		}
+		for _, entry := range entries {
+			if untilEventID != nil && entry.SourceEventID > *untilEventID {
+				return replay, nil
+			}
+			if entry.SourceEventID == replay.snapshotEventID {
+				// Covered by the snapshot, which includes its own event.
+				afterEntryID = entry.EntryID
+				continue
+			}
+
+			account, ok := replay.accounts[entry.AccountID]
+			if !ok {
+				return nil, fmt.Errorf("%w: journal entry %d posts to account %d, which has no %s event",
+					ErrIncompleteEventLog, entry.EntryID, entry.AccountID, domain.EventTypeAccountOpened)
+			}
+
+			if entry.Type == domain.Credit {
+				account.Balance = account.Balance.Add(entry.Amount)
+			} else {
+				account.Balance = account.Balance.Sub(entry.Amount)
+			}
+			if entry.SourceEventID > account.LastEventID {
+				account.LastEventID = entry.SourceEventID
+			}
+			if entry.CreatedAt.After(account.LastEntryAt) {
+				account.LastEntryAt = entry.CreatedAt
+			}
+
+			if entry.SourceEventID != afterSourceEventID {
+				replay.eventsReplayed++
+			}
+			afterSourceEventID, afterEntryID = entry.SourceEventID, entry.EntryID
+		}
+		if len(entries) < replayBatchSize {
+			break
+		}
+	}
+
+	return replay, nil
+}
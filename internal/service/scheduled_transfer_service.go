@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	transferMaxAttempts     = 10
+	transferRetryMinBackoff = 30 * time.Second
+	transferRetryMaxBackoff = time.Hour
+)
+
+type scheduledTransferService struct {
+	accountRepo           repository.AccountRepository
+	scheduledTransferRepo repository.ScheduledTransferRepository
+	transactionService    TransactionService
+}
+
+func NewScheduledTransferService(
+	accountRepo repository.AccountRepository,
+	scheduledTransferRepo repository.ScheduledTransferRepository,
+	transactionService TransactionService,
+) ScheduledTransferService {
+	return &scheduledTransferService{
+		accountRepo:           accountRepo,
+		scheduledTransferRepo: scheduledTransferRepo,
+		transactionService:    transactionService,
+	}
+}
+
+// ScheduleTransfer stores the transfer as pending. Only the request shape and
+// the existence of both accounts are checked now; funds are checked when the
+// transfer executes.
+func (s *scheduledTransferService) ScheduleTransfer(ctx context.Context, tx *gorm.DB, req ScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	transfer := req.Transfer
+	if !transfer.Amount.IsPositive() {
+		return nil, errors.New("transfer amount must be positive")
+	}
+	if transfer.SourceAccountID == transfer.DestinationAccountID {
+		return nil, errors.New("source and destination accounts cannot be the same")
+	}
+	if req.ExecuteAt.IsZero() {
+		return nil, errors.New("execution time is required")
+	}
+
+	fingerprint := scheduledTransferFingerprint(req)
+	if transfer.IdempotencyKey != "" {
+		existing, err := s.scheduledTransferRepo.GetScheduledTransferByIdempotencyKey(ctx, tx, transfer.IdempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+		if existing != nil {
+			if existing.RequestFingerprint != fingerprint {
+				return nil, ErrIdempotencyKeyReused
+			}
+			return existing, nil
+		}
+	}
+
+	for _, accountID := range []uint{transfer.SourceAccountID, transfer.DestinationAccountID} {
+		exists, err := s.accountRepo.AccountExists(ctx, tx, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check account %d: %w", accountID, err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("account %d not found", accountID)
+		}
+	}
+
+	now := time.Now()
+	scheduled := &domain.ScheduledTransfer{
+		ScheduleID:           uuid.New().String(),
+		SourceAccountID:      transfer.SourceAccountID,
+		DestinationAccountID: transfer.DestinationAccountID,
+		Amount:               transfer.Amount,
+		AllowConversion:      transfer.AllowConversion,
+		ExecuteAt:            req.ExecuteAt,
+		Status:               domain.ScheduledTransferStatusPending,
+		IdempotencyKey:       transfer.IdempotencyKey,
+		RequestFingerprint:   fingerprint,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	err := s.scheduledTransferRepo.SaveScheduledTransfer(ctx, tx, scheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save scheduled transfer: %w", err)
+	}
+
+	return scheduled, nil
+}
+
+func (s *scheduledTransferService) GetScheduledTransfer(ctx context.Context, scheduleID string) (*domain.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledTransferRepo.GetScheduledTransfer(ctx, nil, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	return scheduled, nil
+}
+
+func (s *scheduledTransferService) ListScheduledTransfers(ctx context.Context, query domain.ScheduledTransferQuery) ([]domain.ScheduledTransfer, error) {
+	if query.Limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	scheduled, err := s.scheduledTransferRepo.ListScheduledTransfers(ctx, nil, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled transfers: %w", err)
+	}
+	return scheduled, nil
+}
+
+func (s *scheduledTransferService) CancelScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledTransferRepo.LockScheduledTransfer(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	if scheduled == nil {
+		return nil, ErrScheduledTransferNotFound
+	}
+	if scheduled.Status != domain.ScheduledTransferStatusPending {
+		return nil, fmt.Errorf("%w: scheduled transfer is %s", ErrScheduledTransferNotPending, scheduled.Status)
+	}
+
+	scheduled.Status = domain.ScheduledTransferStatusCancelled
+	scheduled.UpdatedAt = time.Now()
+	err = s.scheduledTransferRepo.UpdateScheduledTransferStatus(ctx, tx, scheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+	return scheduled, nil
+}
+
+func (s *scheduledTransferService) ListDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledTransferRepo.ListDueScheduledTransfers(ctx, nil, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled transfers: %w", err)
+	}
+	return scheduled, nil
+}
+
+// ExecuteScheduledTransfer runs a due transfer through ProcessTransfer within
+// tx and marks it executed. It returns nil when the transfer is no longer
+// pending or not due yet, e.g. because it was cancelled concurrently. Errors
+// from ProcessTransfer are returned as is so that the caller can retry or
+// record them with FailScheduledTransfer.
+func (s *scheduledTransferService) ExecuteScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, now time.Time) (*domain.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledTransferRepo.LockScheduledTransfer(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	if scheduled == nil || scheduled.Status != domain.ScheduledTransferStatusPending || scheduled.ExecuteAt.After(now) {
+		return nil, nil
+	}
+	if scheduled.NextAttemptAt != nil && scheduled.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+
+	event, err := s.transactionService.ProcessTransfer(ctx, tx, TransferRequest{
+		SourceAccountID:      scheduled.SourceAccountID,
+		DestinationAccountID: scheduled.DestinationAccountID,
+		Amount:               scheduled.Amount,
+		IdempotencyKey:       scheduledExecutionKey(scheduled.ScheduleID),
+		AllowConversion:      scheduled.AllowConversion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled.Status = domain.ScheduledTransferStatusExecuted
+	scheduled.TransferID = event.TransferID
+	scheduled.ExecutedAt = &now
+	scheduled.UpdatedAt = now
+	err = s.scheduledTransferRepo.UpdateScheduledTransferStatus(ctx, tx, scheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+	return scheduled, nil
+}
+
+// FailScheduledTransfer records why a pending transfer could not be executed.
+// It returns nil when the transfer is no longer pending.
+func (s *scheduledTransferService) FailScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, reason string, now time.Time) (*domain.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledTransferRepo.LockScheduledTransfer(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	if scheduled == nil || scheduled.Status != domain.ScheduledTransferStatusPending {
+		return nil, nil
+	}
+
+	scheduled.Status = domain.ScheduledTransferStatusFailed
+	scheduled.FailureReason = reason
+	scheduled.ExecutedAt = &now
+	scheduled.UpdatedAt = now
+	err = s.scheduledTransferRepo.UpdateScheduledTransferStatus(ctx, tx, scheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+	return scheduled, nil
+}
+
+// PostponeScheduledTransfer records a transient execution failure and backs
+// the next attempt off. After transferMaxAttempts the transfer is
+// marked failed instead. It returns nil when the transfer is no longer pending.
+func (s *scheduledTransferService) PostponeScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, reason string, now time.Time) (*domain.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledTransferRepo.LockScheduledTransfer(ctx, tx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	if scheduled == nil || scheduled.Status != domain.ScheduledTransferStatusPending {
+		return nil, nil
+	}
+
+	scheduled.Attempts++
+	scheduled.LastError = reason
+	scheduled.UpdatedAt = now
+	if scheduled.Attempts >= transferMaxAttempts {
+		scheduled.Status = domain.ScheduledTransferStatusFailed
+		scheduled.FailureReason = reason
+		scheduled.ExecutedAt = &now
+		scheduled.NextAttemptAt = nil
+	} else {
+		nextAttemptAt := now.Add(exponentialBackoff(transferRetryMinBackoff, transferRetryMaxBackoff, scheduled.Attempts))
+		scheduled.NextAttemptAt = &nextAttemptAt
+	}
+	err = s.scheduledTransferRepo.UpdateScheduledTransferStatus(ctx, tx, scheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+	return scheduled, nil
+}
+
+// scheduledExecutionKey is the idempotency key of the transfer produced by a
+// scheduled transfer, so that it can never be executed twice.
+func scheduledExecutionKey(scheduleID string) string {
+	return "scheduled-transfer:" + scheduleID
+}
+
+func scheduledTransferFingerprint(req ScheduledTransferRequest) string {
+	payload := transferFingerprint(req.Transfer) + ":" + req.ExecuteAt.UTC().Format(time.RFC3339Nano)
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
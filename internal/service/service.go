@@ -2,29 +2,654 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+	ErrCurrencyMismatch     = errors.New("source and destination accounts use different currencies")
+	ErrUnbalancedLegs       = errors.New("transfer legs do not balance")
+	ErrInvalidTransfer      = errors.New("invalid transfer")
+	ErrInsufficientFunds    = errors.New("insufficient balance")
+
+	ErrTransferNotFound         = errors.New("transfer not found")
+	ErrInvalidReversal          = errors.New("transfer cannot be reversed")
+	ErrReversalExceedsRemaining = errors.New("reversal amount exceeds the amount left to reverse")
+
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotAuthorized  = errors.New("hold is no longer authorized")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+
+	ErrScheduledTransferNotFound   = errors.New("scheduled transfer not found")
+	ErrScheduledTransferNotPending = errors.New("scheduled transfer is no longer pending")
+
+	ErrStandingOrderNotFound  = errors.New("standing order not found")
+	ErrStandingOrderNotActive = errors.New("standing order is no longer active")
+	ErrInvalidSchedule        = errors.New("invalid schedule")
+
+	ErrIncompleteEventLog = errors.New("event log is incomplete")
+	ErrSnapshotNotFound   = errors.New("snapshot not found")
+	ErrSnapshotDiverged   = errors.New("snapshot diverges from a full replay")
+
+	ErrUnknownIntegrityCheck  = errors.New("unknown integrity check")
+	ErrRepairOperatorRequired = errors.New("an operator is required to apply a repair")
+
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrInvalidAccount      = errors.New("invalid account")
+	ErrAccountExists       = errors.New("account with this ID already exists")
+	ErrAccountNotOpenedYet = errors.New("account was not open at the requested point")
+
+	ErrInvalidWebhookSubscription  = errors.New("invalid webhook subscription")
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookSubscriptionDisabled = errors.New("webhook subscription is disabled")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+)
+
 type AccountService interface {
-	CreateAccount(ctx context.Context, tx *gorm.DB, accountID uint, initialBalance decimal.Decimal) (*domain.Account, error)
+	CreateAccount(ctx context.Context, tx *gorm.DB, accountID uint, initialBalance decimal.Decimal, currency string, accountType domain.AccountType) (*domain.Account, error)
 	GetAccountByID(ctx context.Context, accountID uint) (*domain.Account, error)
 	GetAccountBalance(ctx context.Context, accountID uint) (*domain.AccountBalance, error)
+	ListAccountTransactions(ctx context.Context, tx *gorm.DB, query domain.AccountJournalQuery) (*AccountTransactionsPage, error)
+	GetAccountBalanceAt(ctx context.Context, tx *gorm.DB, accountID uint, point domain.BalancePoint) (*HistoricalBalance, error)
 }
 
 type TransactionService interface {
-	ProcessTransfer(ctx context.Context, tx *gorm.DB, sourceAccountID, destinationAccountID uint, amount decimal.Decimal) error
+	ProcessTransfer(ctx context.Context, tx *gorm.DB, req TransferRequest) (*domain.TransferEvent, error)
+	ProcessMultiLegTransfer(ctx context.Context, tx *gorm.DB, req MultiLegTransferRequest) (*domain.TransferEvent, error)
+	ReverseTransfer(ctx context.Context, tx *gorm.DB, req ReversalRequest) (*domain.TransferEvent, error)
+	GetTransfer(ctx context.Context, transferID string) (*TransferDetails, error)
+	FundAccount(ctx context.Context, tx *gorm.DB, req FundingRequest) (*domain.TransferEvent, error)
+}
+
+type HoldService interface {
+	AuthorizeHold(ctx context.Context, tx *gorm.DB, req HoldRequest) (*domain.Hold, error)
+	CaptureHold(ctx context.Context, tx *gorm.DB, req CaptureRequest) (*domain.TransferEvent, error)
+	VoidHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error)
+	ExpireHold(ctx context.Context, tx *gorm.DB, holdID string, now time.Time) (*domain.Hold, error)
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error)
+	GetHold(ctx context.Context, holdID string) (*domain.Hold, error)
+}
+
+type ScheduledTransferService interface {
+	ScheduleTransfer(ctx context.Context, tx *gorm.DB, req ScheduledTransferRequest) (*domain.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, scheduleID string) (*domain.ScheduledTransfer, error)
+	ListScheduledTransfers(ctx context.Context, query domain.ScheduledTransferQuery) ([]domain.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error)
+	ListDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledTransfer, error)
+	ExecuteScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, now time.Time) (*domain.ScheduledTransfer, error)
+	FailScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, reason string, now time.Time) (*domain.ScheduledTransfer, error)
+	PostponeScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, reason string, now time.Time) (*domain.ScheduledTransfer, error)
+}
+
+type StandingOrderService interface {
+	CreateStandingOrder(ctx context.Context, tx *gorm.DB, req StandingOrderRequest) (*domain.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, standingOrderID string) (*domain.StandingOrder, error)
+	ListStandingOrders(ctx context.Context, query domain.StandingOrderQuery) ([]domain.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, tx *gorm.DB, req StandingOrderUpdate) (*domain.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error)
+	ListStandingOrderExecutions(ctx context.Context, standingOrderID string, limit int) ([]domain.StandingOrderExecution, error)
+	ListDueStandingOrders(ctx context.Context, now time.Time, limit int) ([]domain.StandingOrder, error)
+	ExecuteStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, now time.Time) (*domain.StandingOrderExecution, error)
+	FailStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, reason string, now time.Time) (*domain.StandingOrderExecution, error)
+	PostponeStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, reason string, now time.Time) (*domain.StandingOrder, error)
+}
+
+type ProjectionService interface {
+	// RebuildAccountBalances replays the event log into a fresh projection and,
+	// unless dryRun is set, swaps it in for account_balances within tx.
+	RebuildAccountBalances(ctx context.Context, tx *gorm.DB, dryRun bool) (*ProjectionRebuildReport, error)
+}
+
+// OutboxService relays outbox messages in three steps, so that no transaction
+// stays open while messages are published: a batch is claimed, published, then
+// the outcome of each message is recorded.
+type OutboxService interface {
+	// ClaimOutboxMessages claims up to limit of the oldest pending outbox
+	// messages and queues them for delivery to webhook subscriptions. It
+	// returns nil when another relay is claiming messages.
+	ClaimOutboxMessages(ctx context.Context, tx *gorm.DB, now time.Time, limit int) (*OutboxBatch, error)
+	// PublishOutboxMessages publishes the claimed messages in order and sets
+	// the outcome of each on the batch.
+	PublishOutboxMessages(ctx context.Context, batch *OutboxBatch, now time.Time)
+	// CompleteOutboxMessages records the outcome of every message of the batch.
+	CompleteOutboxMessages(ctx context.Context, tx *gorm.DB, batch *OutboxBatch) error
+}
+
+type WebhookService interface {
+	CreateWebhookSubscription(ctx context.Context, tx *gorm.DB, req WebhookSubscriptionRequest) (*domain.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, subscriptionID string) (*domain.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, tx *gorm.DB, req WebhookSubscriptionUpdate) (*domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) ([]domain.WebhookDelivery, error)
+	// GetWebhookDelivery returns the delivery with its attempt history.
+	GetWebhookDelivery(ctx context.Context, deliveryID uint) (*domain.WebhookDelivery, error)
+	// RedeliverWebhook queues the delivery again, due at now and with a fresh
+	// budget of attempts, whatever its status.
+	RedeliverWebhook(ctx context.Context, tx *gorm.DB, deliveryID uint, now time.Time) (*domain.WebhookDelivery, error)
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	// DeliverWebhook makes one attempt of a due delivery and records its
+	// outcome. It returns nil when the delivery is no longer due, is being
+	// delivered by another dispatcher, or its subscription is disabled.
+	DeliverWebhook(ctx context.Context, tx *gorm.DB, deliveryID uint, now time.Time) (*WebhookDeliveryOutcome, error)
+}
+
+// ActivityService reads the transfer events and balance changes pushed to
+// activity stream subscribers. A stream is polled with PollActivity from the
+// cursor returned by OpenActivityStream.
+type ActivityService interface {
+	// OpenActivityStream returns the cursor a stream starts from: right after
+	// lastChainSeq when the client resumes, or after the latest sealed transfer
+	// event otherwise. A non-zero accountID restricts the stream to that
+	// account.
+	OpenActivityStream(ctx context.Context, tx *gorm.DB, accountID uint, lastChainSeq *uint) (domain.ActivityCursor, error)
+	// PollActivity returns the activity after the query cursor. tx should be
+	// a snapshot (repeatable read) transaction.
+	PollActivity(ctx context.Context, tx *gorm.DB, query ActivityQuery) (*ActivityPage, error)
+}
+
+type SnapshotService interface {
+	// TakeSnapshot snapshots every account at the last transfer event posted
+	// at or before cutoff, once at least frequency events were posted since
+	// the previous snapshot; it returns nil when no snapshot is due. With
+	// verify, the snapshot is checked against a full replay before it is
+	// stored.
+	TakeSnapshot(ctx context.Context, tx *gorm.DB, cutoff time.Time, frequency int, verify bool) (*SnapshotReport, error)
+	// VerifySnapshot checks the snapshot at eventID, or the latest one when
+	// eventID is nil, against a full replay.
+	VerifySnapshot(ctx context.Context, tx *gorm.DB, eventID *uint) (*SnapshotVerification, error)
 }
 
 type IntegrityService interface {
 	VerifyDoubleBookkeeping(ctx context.Context) (*IntegrityResult, error)
+	// SealHashChains appends up to limit committed rows to each hash chain.
+	SealHashChains(ctx context.Context, tx *gorm.DB, limit int) (*HashChainSealReport, error)
+	// VerifyHashChains walks the hash chains of transfer_events and
+	// journal_entries and reports the first broken link of each. tx should be
+	// a snapshot (repeatable read) transaction.
+	VerifyHashChains(ctx context.Context, tx *gorm.DB) (*HashChainVerification, error)
+	// ReconcileAccounts recomputes the balance of every account from its
+	// opening balance and journal entries and reports the accounts whose
+	// account_balances row drifted from it. tx should be a snapshot
+	// (repeatable read) transaction.
+	ReconcileAccounts(ctx context.Context, tx *gorm.DB) (*AccountReconciliationReport, error)
+	// ReconcileAccount reconciles a single account. It returns
+	// ErrAccountNotFound when the account does not exist.
+	ReconcileAccount(ctx context.Context, tx *gorm.DB, accountID uint) (*AccountReconciliation, error)
+	// VerifyTransactions checks, for every transfer event, that the journal
+	// entries of its transaction balance within each currency and are exactly
+	// the legs the event implies. tx should be a snapshot (repeatable read)
+	// transaction.
+	VerifyTransactions(ctx context.Context, tx *gorm.DB) (*TransactionVerificationReport, error)
+	// VerifyLinkage checks that every transfer event has the debit and credit
+	// journal entries it implies, found by source event ID, and that every
+	// journal entry points to an existing event and account. tx should be a
+	// snapshot (repeatable read) transaction.
+	VerifyLinkage(ctx context.Context, tx *gorm.DB) (*LinkageReport, error)
+	// RunIntegrityCheck runs one of domain.IntegrityChecks and returns its
+	// report. An error means the check could not be run.
+	RunIntegrityCheck(ctx context.Context, tx *gorm.DB, check string) (*domain.IntegrityCheckResult, error)
+	RecordIntegrityRun(ctx context.Context, tx *gorm.DB, run *domain.IntegrityRun) error
+	GetIntegrityRun(ctx context.Context, runID uint) (*domain.IntegrityRun, error)
+	ListIntegrityRuns(ctx context.Context, query domain.IntegrityRunQuery) ([]domain.IntegrityRun, error)
+	// RepairAccounts recalculates the balance and last event ID of every
+	// drifting account, or only req.AccountID, from its journal and, unless
+	// req.DryRun is set, applies them and audits each repair. tx should be a
+	// repeatable read transaction, so that an account posted to after the
+	// reconciliation fails the repair instead of being overwritten.
+	RepairAccounts(ctx context.Context, tx *gorm.DB, req ProjectionRepairRequest) (*ProjectionRepairReport, error)
+	ListProjectionRepairs(ctx context.Context, query domain.ProjectionRepairQuery) ([]domain.ProjectionRepair, error)
+}
+
+type TransferRequest struct {
+	SourceAccountID      uint
+	DestinationAccountID uint
+	// Amount is expressed in the source account's currency.
+	Amount         decimal.Decimal
+	IdempotencyKey string
+	// AllowConversion permits a transfer between accounts of different
+	// currencies at the rate quoted by the configured fx.RateProvider.
+	AllowConversion bool
+}
+
+type MultiLegTransferRequest struct {
+	Legs           []domain.Posting
+	IdempotencyKey string
+}
+
+type ReversalRequest struct {
+	TransferID string
+	// Amount to reverse, in the original transfer's currency. Zero reverses
+	// whatever has not been reversed yet.
+	Amount         decimal.Decimal
+	IdempotencyKey string
+}
+
+// FundingRequest books the initial balance of a freshly opened account.
+type FundingRequest struct {
+	TransferID     string
+	FundingAccount *domain.Account
+	// Balance is the zero balance the account was opened with.
+	Balance *domain.AccountBalance
+	Amount  decimal.Decimal
+}
+
+type HoldRequest struct {
+	SourceAccountID      uint
+	DestinationAccountID uint
+	Amount               decimal.Decimal
+	// ExpiresIn is how long the hold stays authorized. Zero applies the
+	// default hold lifetime.
+	ExpiresIn      time.Duration
+	IdempotencyKey string
+}
+
+type CaptureRequest struct {
+	HoldID string
+	// Amount to capture, at most the held amount. Zero captures the full hold.
+	// Whatever is not captured is released.
+	Amount         decimal.Decimal
+	IdempotencyKey string
+}
+
+type ScheduledTransferRequest struct {
+	// Transfer is executed through ProcessTransfer once due. Its idempotency
+	// key deduplicates the scheduling request itself.
+	Transfer  TransferRequest
+	ExecuteAt time.Time
+}
+
+type StandingOrderRequest struct {
+	SourceAccountID      uint
+	DestinationAccountID uint
+	Amount               decimal.Decimal
+	// Schedule is a cron expression, a shorthand such as @monthly, or an
+	// interval written "@every 24h"; see package schedule.
+	Schedule string
+	// StartAt defaults to now. EndAt is optional and inclusive.
+	StartAt time.Time
+	EndAt   *time.Time
+}
+
+// StandingOrderUpdate changes the non-nil fields of an active standing order.
+type StandingOrderUpdate struct {
+	StandingOrderID string
+	Amount          *decimal.Decimal
+	Schedule        *string
+	EndAt           *time.Time
+}
+
+type WebhookSubscriptionRequest struct {
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+// WebhookSubscriptionUpdate changes the non-nil fields of a subscription.
+// Enabling a subscription resets its count of consecutive failures.
+type WebhookSubscriptionUpdate struct {
+	SubscriptionID string
+	URL            *string
+	EventTypes     []string
+	Secret         *string
+	Status         *domain.WebhookSubscriptionStatus
+}
+
+// WebhookDeliveryPolicy controls retries: a failed attempt is retried after
+// MinBackoff, doubling with every further failure up to MaxBackoff, until the
+// delivery failed MaxAttempts times. A subscription is disabled once
+// DisableAfter attempts in a row failed across its deliveries.
+type WebhookDeliveryPolicy struct {
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	MaxAttempts  int
+	DisableAfter int
+}
+
+type AccountTransactionsPage struct {
+	Entries    []domain.AccountJournalEntry `json:"entries"`
+	NextCursor *domain.JournalEntryCursor   `json:"-"`
+}
+
+// ActivityQuery polls the activity of AccountID, or of every account when it
+// is 0, reading at most Limit transfer events past Cursor.
+type ActivityQuery struct {
+	AccountID uint
+	Cursor    domain.ActivityCursor
+	Limit     int
+}
+
+// ActivityPage is the activity found by a poll, in event order. More reports
+// that the poll stopped at Limit and the next one should not wait.
+type ActivityPage struct {
+	Activities []domain.AccountActivity
+	Cursor     domain.ActivityCursor
+	More       bool
+}
+
+// HistoricalBalance is the balance of an account at a point in its history.
+// LastEventID is the last transfer event applied to it, 0 when none is.
+type HistoricalBalance struct {
+	AccountID   uint            `json:"account_id"`
+	Currency    string          `json:"currency"`
+	Balance     decimal.Decimal `json:"balance"`
+	AsOf        *time.Time      `json:"as_of,omitempty"`
+	AsOfEventID *uint           `json:"as_of_event_id,omitempty"`
+	LastEventID uint            `json:"last_event_id"`
+}
+
+type TransferDetails struct {
+	Event     *domain.TransferEvent  `json:"event"`
+	Entries   []domain.JournalEntry  `json:"entries"`
+	Reversals []domain.TransferEvent `json:"reversals,omitempty"`
 }
 
+// IntegrityResult is valid when every currency balances. The top-level totals
+// sum all journal entries regardless of currency, as before accounts had one.
 type IntegrityResult struct {
+	IsValid      bool                `json:"is_valid"`
+	TotalDebits  decimal.Decimal     `json:"total_debits"`
+	TotalCredits decimal.Decimal     `json:"total_credits"`
+	Difference   decimal.Decimal     `json:"difference"`
+	Currencies   []CurrencyIntegrity `json:"currencies"`
+}
+
+type CurrencyIntegrity struct {
+	Currency     string          `json:"currency"`
 	IsValid      bool            `json:"is_valid"`
 	TotalDebits  decimal.Decimal `json:"total_debits"`
 	TotalCredits decimal.Decimal `json:"total_credits"`
 	Difference   decimal.Decimal `json:"difference"`
 }
+
+// HashChainVerification tells whether every row of each hash chain still
+// matches its hash and links to the row before it.
+type HashChainVerification struct {
+	IsValid bool              `json:"is_valid"`
+	Chains  []HashChainResult `json:"chains"`
+}
+
+// HashChainSealReport counts the rows sealed into each chain.
+type HashChainSealReport struct {
+	TransferEvents int
+	JournalEntries int
+}
+
+// HashChainResult is the verification of one chain. RowsVerified counts the
+// rows found intact before the first broken link, and LastID is the last of
+// them.
+type HashChainResult struct {
+	Chain        string      `json:"chain"`
+	IsValid      bool        `json:"is_valid"`
+	RowsVerified int         `json:"rows_verified"`
+	LastID       uint        `json:"last_id"`
+	BrokenLink   *BrokenLink `json:"broken_link,omitempty"`
+}
+
+type BrokenLinkReason string
+
+const (
+	// BrokenLinkPrevHash means the row does not link to the row before it,
+	// which was deleted, or edited and rehashed.
+	BrokenLinkPrevHash BrokenLinkReason = "prev_hash_mismatch"
+	// BrokenLinkHash means the contents of the row no longer match its hash.
+	BrokenLinkHash BrokenLinkReason = "hash_mismatch"
+	// BrokenLinkHead means the chain does not end where its head says, because
+	// rows were deleted at the end or written around the chain.
+	BrokenLinkHead BrokenLinkReason = "head_mismatch"
+)
+
+// AccountReconciliationReport lists the accounts, among AccountsChecked, whose
+// projected balance does not match their journal.
+type AccountReconciliationReport struct {
+	IsValid         bool                    `json:"is_valid"`
+	AccountsChecked int                     `json:"accounts_checked"`
+	Drifting        []AccountReconciliation `json:"drifting"`
+}
+
+type ReconciliationReason string
+
+const (
+	// ReconciliationBalanceMismatch means the projected balance differs from
+	// the opening balance plus the journal entries of the account.
+	ReconciliationBalanceMismatch ReconciliationReason = "balance_mismatch"
+	// ReconciliationLastEventMismatch means the projection does not point to
+	// the last transfer event that posted to the account.
+	ReconciliationLastEventMismatch ReconciliationReason = "last_event_id_mismatch"
+	// ReconciliationMissingBalance means the account has no account_balances
+	// row.
+	ReconciliationMissingBalance ReconciliationReason = "missing_balance"
+	// ReconciliationMissingOpening means the account has no AccountOpened
+	// event, so its opening balance is unknown; accounts opened before the
+	// event store existed cannot be reconciled.
+	ReconciliationMissingOpening ReconciliationReason = "missing_opening_event"
+)
+
+// AccountReconciliation compares the projected balance of an account with the
+// expected one, its opening balance plus the net of its journal entries.
+// Difference is the projected balance minus the expected one.
+type AccountReconciliation struct {
+	AccountID            uint                 `json:"account_id"`
+	IsValid              bool                 `json:"is_valid"`
+	Reason               ReconciliationReason `json:"reason,omitempty"`
+	OpeningBalance       decimal.Decimal      `json:"opening_balance"`
+	JournalNet           decimal.Decimal      `json:"journal_net"`
+	JournalEntries       int                  `json:"journal_entries"`
+	ExpectedBalance      decimal.Decimal      `json:"expected_balance"`
+	ProjectedBalance     decimal.Decimal      `json:"projected_balance"`
+	Difference           decimal.Decimal      `json:"difference"`
+	ExpectedLastEventID  uint                 `json:"expected_last_event_id"`
+	ProjectedLastEventID uint                 `json:"projected_last_event_id"`
+}
+
+// ProjectionRepairRequest selects the accounts to repair: every drifting
+// account, or only AccountID when set. Operator is recorded in the audit of
+// each repair and is required unless DryRun is set.
+type ProjectionRepairRequest struct {
+	AccountID uint
+	Operator  string
+	DryRun    bool
+}
+
+// ProjectionRepairReport lists the repairs made, or that would be made on a
+// dry run, and the drifting accounts a repair cannot fix because their
+// opening event or balance row is missing.
+type ProjectionRepairReport struct {
+	DryRun          bool                      `json:"dry_run"`
+	AccountsChecked int                       `json:"accounts_checked"`
+	Repairs         []domain.ProjectionRepair `json:"repairs"`
+	Unrepairable    []AccountReconciliation   `json:"unrepairable"`
+}
+
+// TransactionVerificationReport lists the transactions, among
+// TransactionsChecked, whose journal entries are unbalanced or malformed.
+type TransactionVerificationReport struct {
+	IsValid             bool               `json:"is_valid"`
+	TransactionsChecked int                `json:"transactions_checked"`
+	Invalid             []TransactionIssue `json:"invalid"`
+}
+
+type TransactionIssueReason string
+
+const (
+	// TransactionUnbalanced means debits and credits of the transaction differ
+	// in at least one currency.
+	TransactionUnbalanced TransactionIssueReason = "unbalanced"
+	// TransactionLegsMismatch means the journal entries of the transaction are
+	// not the legs its transfer event implies.
+	TransactionLegsMismatch TransactionIssueReason = "legs_mismatch"
+	// TransactionMissingOriginal means a reversal points to a transfer that
+	// does not exist, so its legs cannot be derived.
+	TransactionMissingOriginal TransactionIssueReason = "missing_original_transfer"
+)
+
+// TransactionLeg is a journal entry reduced to what its transfer event
+// determines.
+type TransactionLeg struct {
+	AccountID uint             `json:"account_id"`
+	Type      domain.EntryType `json:"type"`
+	Amount    decimal.Decimal  `json:"amount"`
+	Currency  string           `json:"currency"`
+}
+
+// TransactionIssue is a transaction that failed verification. Unbalanced lists
+// the currencies whose debits and credits differ; MissingLegs are implied by
+// the event but absent from the journal, and UnexpectedLegs the other way
+// around.
+type TransactionIssue struct {
+	TransactionID  string                   `json:"transaction_id"`
+	EventID        uint                     `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Reasons        []TransactionIssueReason `json:"reasons"`
+	Unbalanced     []CurrencyIntegrity      `json:"unbalanced,omitempty"`
+	MissingLegs    []TransactionLeg         `json:"missing_legs,omitempty"`
+	UnexpectedLegs []TransactionLeg         `json:"unexpected_legs,omitempty"`
+}
+
+// LinkageReport lists the transfer events, among EventsChecked, whose journal
+// entries are missing or do not match them, and the journal entries pointing
+// to a missing transfer event or account. Each list of entries is capped at
+// 1000.
+type LinkageReport struct {
+	IsValid                   bool                  `json:"is_valid"`
+	EventsChecked             int                   `json:"events_checked"`
+	Events                    []EventLinkageIssue   `json:"events"`
+	OrphanedEntries           []domain.JournalEntry `json:"orphaned_entries"`
+	EntriesWithMissingAccount []domain.JournalEntry `json:"entries_with_missing_account"`
+}
+
+type LinkageIssueReason string
+
+const (
+	// LinkageOrphanedEvent means no journal entry has the event as source.
+	LinkageOrphanedEvent LinkageIssueReason = "orphaned_event"
+	// LinkageLegCountMismatch means the event has more or fewer debit or
+	// credit entries than it implies: one of each for a plain transfer.
+	LinkageLegCountMismatch LinkageIssueReason = "leg_count_mismatch"
+	// LinkageAmountMismatch means the entries of the event debit or credit a
+	// currency by another amount than the event.
+	LinkageAmountMismatch LinkageIssueReason = "amount_mismatch"
+	// LinkageTransactionMismatch means an entry of the event belongs to
+	// another transaction than the event's transfer.
+	LinkageTransactionMismatch LinkageIssueReason = "transaction_id_mismatch"
+)
+
+// EventLinkageIssue is a transfer event whose journal entries, those with its
+// event ID as source, do not match it.
+type EventLinkageIssue struct {
+	EventID         uint                 `json:"event_id"`
+	TransferID      string               `json:"transfer_id"`
+	EventType       string               `json:"event_type"`
+	Reasons         []LinkageIssueReason `json:"reasons"`
+	ExpectedDebits  int                  `json:"expected_debits"`
+	ExpectedCredits int                  `json:"expected_credits"`
+	Debits          int                  `json:"debits"`
+	Credits         int                  `json:"credits"`
+	Amounts         []LinkageAmount      `json:"amounts,omitempty"`
+}
+
+// LinkageAmount is a currency and direction whose posted total differs from
+// the one the event implies.
+type LinkageAmount struct {
+	Currency string           `json:"currency"`
+	Type     domain.EntryType `json:"type"`
+	Expected decimal.Decimal  `json:"expected"`
+	Posted   decimal.Decimal  `json:"posted"`
+}
+
+// BrokenLink is the first place a chain breaks: RowID is the row that fails
+// to verify, or the row the head points to for BrokenLinkHead, and
+// PreviousRowID the last intact row before it.
+type BrokenLink struct {
+	RowID         uint             `json:"row_id"`
+	PreviousRowID uint             `json:"previous_row_id"`
+	Reason        BrokenLinkReason `json:"reason"`
+	ExpectedHash  string           `json:"expected_hash"`
+	ActualHash    string           `json:"actual_hash"`
+}
+
+// ProjectionRebuildReport describes a rebuild. SnapshotEventID is the snapshot
+// the replay started from, 0 when it replayed the whole log.
+type ProjectionRebuildReport struct {
+	AccountsRebuilt int                 `json:"accounts_rebuilt"`
+	EventsReplayed  int                 `json:"events_replayed"`
+	SnapshotEventID uint                `json:"snapshot_event_id"`
+	DryRun          bool                `json:"dry_run"`
+	Swapped         bool                `json:"swapped"`
+	Divergences     []BalanceDivergence `json:"divergences"`
+}
+
+// BalanceDivergence is an account whose live projection differs from the one
+// rebuilt from the event log.
+type BalanceDivergence struct {
+	AccountID          uint            `json:"account_id"`
+	MissingFromLive    bool            `json:"missing_from_live,omitempty"`
+	LiveBalance        decimal.Decimal `json:"live_balance"`
+	RebuiltBalance     decimal.Decimal `json:"rebuilt_balance"`
+	LiveLastEventID    uint            `json:"live_last_event_id"`
+	RebuiltLastEventID uint            `json:"rebuilt_last_event_id"`
+}
+
+// SnapshotReport describes a snapshot taken at EventID, following the one at
+// PreviousEventID (0 for the first snapshot).
+type SnapshotReport struct {
+	EventID             uint `json:"event_id"`
+	PreviousEventID     uint `json:"previous_event_id"`
+	AccountsSnapshotted int  `json:"accounts_snapshotted"`
+	EventsReplayed      int  `json:"events_replayed"`
+	Verified            bool `json:"verified"`
+}
+
+// SnapshotVerification compares the account states recorded by the snapshot at
+// EventID with a full replay of the event log up to the same event.
+type SnapshotVerification struct {
+	EventID         uint                 `json:"event_id"`
+	IsValid         bool                 `json:"is_valid"`
+	AccountsChecked int                  `json:"accounts_checked"`
+	EventsReplayed  int                  `json:"events_replayed"`
+	Divergences     []SnapshotDivergence `json:"divergences"`
+}
+
+// SnapshotDivergence is an account whose state in a snapshot differs from the
+// one obtained by a full replay.
+type SnapshotDivergence struct {
+	AccountID           uint            `json:"account_id"`
+	SnapshotBalance     decimal.Decimal `json:"snapshot_balance"`
+	ReplayedBalance     decimal.Decimal `json:"replayed_balance"`
+	SnapshotLastEventID uint            `json:"snapshot_last_event_id"`
+	ReplayedLastEventID uint            `json:"replayed_last_event_id"`
+}
+
+// OutboxBatch is the messages claimed by a relay run, in publication order,
+// and the report of the run.
+type OutboxBatch struct {
+	Messages []domain.OutboxMessage
+	Report   OutboxRelayReport
+}
+
+// OutboxRelayReport counts the messages of a relay run that were published,
+// failed and scheduled for a retry, dead-lettered after their last attempt, or
+// deferred because they are not due yet or wait behind an earlier message of
+// the same account.
+type OutboxRelayReport struct {
+	Published int
+	Failed    int
+	Dead      int
+	Deferred  int
+	// WebhookDeliveries counts the deliveries queued for webhook subscriptions.
+	WebhookDeliveries int
+}
+
+// WebhookDeliveryOutcome is the state of a delivery after an attempt.
+// SubscriptionDisabled reports that the attempt disabled its subscription.
+type WebhookDeliveryOutcome struct {
+	Delivery             *domain.WebhookDelivery
+	Attempt              *domain.WebhookDeliveryAttempt
+	SubscriptionDisabled bool
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"gorm.io/gorm"
+)
+
+type snapshotService struct {
+	journalRepo  repository.JournalRepository
+	snapshotRepo repository.SnapshotRepository
+	replayer     *eventReplayer
+}
+
+func NewSnapshotService(journalRepo repository.JournalRepository, eventStore repository.EventStore, snapshotRepo repository.SnapshotRepository) SnapshotService {
+	return &snapshotService{
+		journalRepo:  journalRepo,
+		snapshotRepo: snapshotRepo,
+		replayer: &eventReplayer{
+			journalRepo:  journalRepo,
+			eventStore:   eventStore,
+			snapshotRepo: snapshotRepo,
+		},
+	}
+}
+
+// TakeSnapshot replays the events posted since the previous snapshot on top of
+// it, and stores a row for every account they touched. cutoff should lag
+// behind now: a transfer event is dated before its transaction commits, so an
+// event just before now may still be invisible while a later one is not. tx
+// should be a snapshot (repeatable read) transaction.
+func (s *snapshotService) TakeSnapshot(ctx context.Context, tx *gorm.DB, cutoff time.Time, frequency int, verify bool) (*SnapshotReport, error) {
+	if frequency <= 0 {
+		return nil, fmt.Errorf("snapshot frequency must be positive")
+	}
+
+	previousEventID, err := s.snapshotRepo.GetLatestSnapshotEventID(ctx, tx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest snapshot: %w", err)
+	}
+	eventID, err := s.journalRepo.GetLastSourceEventIDBefore(ctx, tx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last posted event: %w", err)
+	}
+	if eventID < previousEventID+uint(frequency) {
+		return nil, nil
+	}
+
+	replay, err := s.replayer.replay(ctx, tx, &eventID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if verify {
+		full, err := s.replayer.replay(ctx, tx, &eventID, false)
+		if err != nil {
+			return nil, err
+		}
+		if divergences := compareReplays(replay, full); len(divergences) > 0 {
+			accountIDs := make([]uint, 0, len(divergences))
+			for _, divergence := range divergences {
+				accountIDs = append(accountIDs, divergence.AccountID)
+			}
+			return nil, fmt.Errorf("%w: snapshot at event %d differs for accounts %v", ErrSnapshotDiverged, eventID, accountIDs)
+		}
+	}
+
+	now := time.Now()
+	var snapshots []domain.AccountSnapshot
+	for _, accountID := range sortedAccountIDs(replay.accounts) {
+		account := replay.accounts[accountID]
+		if account.LastEventID <= previousEventID {
+			continue
+		}
+		snapshot := *account
+		snapshot.EventID = eventID
+		snapshot.CreatedAt = now
+		snapshots = append(snapshots, snapshot)
+	}
+
+	err = s.snapshotRepo.SaveAccountSnapshots(ctx, tx, snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SnapshotReport{
+		EventID:             eventID,
+		PreviousEventID:     previousEventID,
+		AccountsSnapshotted: len(snapshots),
+		EventsReplayed:      replay.eventsReplayed,
+		Verified:            verify,
+	}, nil
+}
+
+func (s *snapshotService) VerifySnapshot(ctx context.Context, tx *gorm.DB, eventID *uint) (*SnapshotVerification, error) {
+	snapshotEventID, err := s.snapshotRepo.GetLatestSnapshotEventID(ctx, tx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if snapshotEventID == 0 || (eventID != nil && snapshotEventID != *eventID) {
+		return nil, ErrSnapshotNotFound
+	}
+
+	snapshot, err := s.replayer.replay(ctx, tx, &snapshotEventID, true)
+	if err != nil {
+		return nil, err
+	}
+	full, err := s.replayer.replay(ctx, tx, &snapshotEventID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	divergences := compareReplays(snapshot, full)
+	return &SnapshotVerification{
+		EventID:         snapshotEventID,
+		IsValid:         len(divergences) == 0,
+		AccountsChecked: len(full.accounts),
+		EventsReplayed:  full.eventsReplayed,
+		Divergences:     divergences,
+	}, nil
+}
+
+// compareReplays lists the accounts on which the two replays disagree.
+func compareReplays(snapshot, full *accountReplay) []SnapshotDivergence {
+	divergences := []SnapshotDivergence{}
+	for _, accountID := range sortedAccountIDs(full.accounts) {
+		replayed := full.accounts[accountID]
+		snapshotted := snapshot.accounts[accountID]
+		if snapshotted.Balance.Equal(replayed.Balance) && snapshotted.LastEventID == replayed.LastEventID {
+			continue
+		}
+		divergences = append(divergences, SnapshotDivergence{
+			AccountID:           accountID,
+			SnapshotBalance:     snapshotted.Balance,
+			ReplayedBalance:     replayed.Balance,
+			SnapshotLastEventID: snapshotted.LastEventID,
+			ReplayedLastEventID: replayed.LastEventID,
+		})
+	}
+	return divergences
+}
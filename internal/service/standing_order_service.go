@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"github.com/dirdr/goits/internal/schedule"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type standingOrderService struct {
+	accountRepo        repository.AccountRepository
+	standingOrderRepo  repository.StandingOrderRepository
+	transactionService TransactionService
+}
+
+func NewStandingOrderService(
+	accountRepo repository.AccountRepository,
+	standingOrderRepo repository.StandingOrderRepository,
+	transactionService TransactionService,
+) StandingOrderService {
+	return &standingOrderService{
+		accountRepo:        accountRepo,
+		standingOrderRepo:  standingOrderRepo,
+		transactionService: transactionService,
+	}
+}
+
+// CreateStandingOrder stores an active standing order whose first run is the
+// first activation at or after both its start and now, so that a start date in
+// the past never triggers retroactive transfers.
+func (s *standingOrderService) CreateStandingOrder(ctx context.Context, tx *gorm.DB, req StandingOrderRequest) (*domain.StandingOrder, error) {
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("transfer amount must be positive")
+	}
+	if req.SourceAccountID == req.DestinationAccountID {
+		return nil, errors.New("source and destination accounts cannot be the same")
+	}
+
+	sched, err := schedule.Parse(req.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	now := time.Now()
+	startAt := req.StartAt
+	if startAt.IsZero() {
+		startAt = now
+	}
+	if req.EndAt != nil && req.EndAt.Before(startAt) {
+		return nil, fmt.Errorf("%w: end must not be before start", ErrInvalidSchedule)
+	}
+
+	sourceAccount, err := s.accountRepo.GetAccountByID(ctx, tx, req.SourceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check source account: %w", err)
+	}
+	if sourceAccount == nil {
+		return nil, errors.New("source account not found")
+	}
+
+	destinationAccount, err := s.accountRepo.GetAccountByID(ctx, tx, req.DestinationAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check destination account: %w", err)
+	}
+	if destinationAccount == nil {
+		return nil, errors.New("destination account not found")
+	}
+	if sourceAccount.Currency != destinationAccount.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	order := &domain.StandingOrder{
+		StandingOrderID:      uuid.New().String(),
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Schedule:             req.Schedule,
+		StartAt:              startAt,
+		EndAt:                req.EndAt,
+		Status:               domain.StandingOrderStatusActive,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	order.NextRunAt, err = firstRun(sched, order, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.standingOrderRepo.SaveStandingOrder(ctx, tx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save standing order: %w", err)
+	}
+
+	return order, nil
+}
+
+func (s *standingOrderService) GetStandingOrder(ctx context.Context, standingOrderID string) (*domain.StandingOrder, error) {
+	order, err := s.standingOrderRepo.GetStandingOrder(ctx, nil, standingOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get standing order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) ListStandingOrders(ctx context.Context, query domain.StandingOrderQuery) ([]domain.StandingOrder, error) {
+	if query.Limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	orders, err := s.standingOrderRepo.ListStandingOrders(ctx, nil, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list standing orders: %w", err)
+	}
+	return orders, nil
+}
+
+// UpdateStandingOrder applies the requested changes. A new schedule restarts
+// from the first activation at or after now; changes never affect activations
+// that were already executed.
+func (s *standingOrderService) UpdateStandingOrder(ctx context.Context, tx *gorm.DB, req StandingOrderUpdate) (*domain.StandingOrder, error) {
+	if req.Amount != nil && !req.Amount.IsPositive() {
+		return nil, errors.New("transfer amount must be positive")
+	}
+
+	var newSchedule schedule.Schedule
+	if req.Schedule != nil {
+		var err error
+		newSchedule, err = schedule.Parse(*req.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+	}
+
+	order, err := s.lockActiveStandingOrder(ctx, tx, req.StandingOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.Amount != nil {
+		order.Amount = *req.Amount
+	}
+	if req.EndAt != nil {
+		if req.EndAt.Before(order.StartAt) {
+			return nil, fmt.Errorf("%w: end must not be before start", ErrInvalidSchedule)
+		}
+		order.EndAt = req.EndAt
+	}
+	if newSchedule != nil {
+		order.Schedule = *req.Schedule
+		order.NextRunAt, err = firstRun(newSchedule, order, now)
+		if err != nil {
+			return nil, err
+		}
+		resetAttempts(order)
+	} else if order.EndAt != nil && order.NextRunAt != nil && order.NextRunAt.After(*order.EndAt) {
+		return nil, fmt.Errorf("%w: no activation left before the end", ErrInvalidSchedule)
+	}
+	order.UpdatedAt = now
+
+	err = s.standingOrderRepo.UpdateStandingOrder(ctx, tx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update standing order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) CancelStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	order, err := s.lockActiveStandingOrder(ctx, tx, standingOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = domain.StandingOrderStatusCancelled
+	order.NextRunAt = nil
+	order.UpdatedAt = time.Now()
+	err = s.standingOrderRepo.UpdateStandingOrder(ctx, tx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update standing order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) ListStandingOrderExecutions(ctx context.Context, standingOrderID string, limit int) ([]domain.StandingOrderExecution, error) {
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	order, err := s.standingOrderRepo.GetStandingOrder(ctx, nil, standingOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get standing order: %w", err)
+	}
+	if order == nil {
+		return nil, ErrStandingOrderNotFound
+	}
+
+	executions, err := s.standingOrderRepo.ListStandingOrderExecutions(ctx, nil, standingOrderID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list standing order executions: %w", err)
+	}
+	return executions, nil
+}
+
+func (s *standingOrderService) ListDueStandingOrders(ctx context.Context, now time.Time, limit int) ([]domain.StandingOrder, error) {
+	orders, err := s.standingOrderRepo.ListDueStandingOrders(ctx, nil, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due standing orders: %w", err)
+	}
+	return orders, nil
+}
+
+// ExecuteStandingOrder runs the standing order's next activation through
+// ProcessTransfer within tx, records the execution and advances the order to
+// its following activation. It returns nil when another replica holds the
+// standing order or when it is not due. Errors from ProcessTransfer are
+// returned as is so that the caller can retry or record them with
+// FailStandingOrder.
+func (s *standingOrderService) ExecuteStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, now time.Time) (*domain.StandingOrderExecution, error) {
+	order, err := s.claimDueStandingOrder(ctx, tx, standingOrderID, now)
+	if err != nil || order == nil {
+		return nil, err
+	}
+	scheduledFor := *order.NextRunAt
+
+	event, err := s.transactionService.ProcessTransfer(ctx, tx, TransferRequest{
+		SourceAccountID:      order.SourceAccountID,
+		DestinationAccountID: order.DestinationAccountID,
+		Amount:               order.Amount,
+		IdempotencyKey:       standingOrderExecutionKey(order.StandingOrderID, scheduledFor),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	execution := &domain.StandingOrderExecution{
+		StandingOrderID: order.StandingOrderID,
+		ScheduledFor:    scheduledFor,
+		Status:          domain.StandingOrderExecutionStatusExecuted,
+		TransferID:      event.TransferID,
+		ExecutedAt:      now,
+	}
+	err = s.recordExecution(ctx, tx, order, execution)
+	if err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+// FailStandingOrder records that the standing order's next activation could
+// not be executed and advances the order past it.
+func (s *standingOrderService) FailStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, reason string, now time.Time) (*domain.StandingOrderExecution, error) {
+	order, err := s.claimDueStandingOrder(ctx, tx, standingOrderID, now)
+	if err != nil || order == nil {
+		return nil, err
+	}
+
+	execution := &domain.StandingOrderExecution{
+		StandingOrderID: order.StandingOrderID,
+		ScheduledFor:    *order.NextRunAt,
+		Status:          domain.StandingOrderExecutionStatusFailed,
+		FailureReason:   reason,
+		ExecutedAt:      now,
+	}
+	err = s.recordExecution(ctx, tx, order, execution)
+	if err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+// PostponeStandingOrder records a transient failure of the standing order's
+// next activation and backs the next attempt off. After transferMaxAttempts
+// the activation is recorded as failed and the order advances past it, which
+// leaves NextAttemptAt nil. It returns nil when another replica holds the
+// standing order or when it is not due.
+func (s *standingOrderService) PostponeStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, reason string, now time.Time) (*domain.StandingOrder, error) {
+	order, err := s.claimDueStandingOrder(ctx, tx, standingOrderID, now)
+	if err != nil || order == nil {
+		return nil, err
+	}
+
+	order.Attempts++
+	if order.Attempts >= transferMaxAttempts {
+		err = s.recordExecution(ctx, tx, order, &domain.StandingOrderExecution{
+			StandingOrderID: order.StandingOrderID,
+			ScheduledFor:    *order.NextRunAt,
+			Status:          domain.StandingOrderExecutionStatusFailed,
+			FailureReason:   reason,
+			ExecutedAt:      now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return order, nil
+	}
+
+	nextAttemptAt := now.Add(exponentialBackoff(transferRetryMinBackoff, transferRetryMaxBackoff, order.Attempts))
+	order.LastError = reason
+	order.NextAttemptAt = &nextAttemptAt
+	order.UpdatedAt = now
+	err = s.standingOrderRepo.UpdateStandingOrder(ctx, tx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update standing order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) lockActiveStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	order, err := s.standingOrderRepo.LockStandingOrder(ctx, tx, standingOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get standing order: %w", err)
+	}
+	if order == nil {
+		return nil, ErrStandingOrderNotFound
+	}
+	if order.Status != domain.StandingOrderStatusActive {
+		return nil, fmt.Errorf("%w: standing order is %s", ErrStandingOrderNotActive, order.Status)
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) claimDueStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, now time.Time) (*domain.StandingOrder, error) {
+	order, err := s.standingOrderRepo.ClaimStandingOrder(ctx, tx, standingOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim standing order: %w", err)
+	}
+	if order == nil || order.Status != domain.StandingOrderStatusActive || order.NextRunAt == nil || order.NextRunAt.After(now) {
+		return nil, nil
+	}
+	if order.NextAttemptAt != nil && order.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) recordExecution(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error {
+	err := s.standingOrderRepo.SaveStandingOrderExecution(ctx, tx, execution)
+	if err != nil {
+		return fmt.Errorf("failed to save standing order execution: %w", err)
+	}
+
+	sched, err := schedule.Parse(order.Schedule)
+	if err != nil {
+		return fmt.Errorf("standing order %s has an invalid schedule: %w", order.StandingOrderID, err)
+	}
+
+	next := sched.Next(execution.ScheduledFor)
+	if next.IsZero() || (order.EndAt != nil && next.After(*order.EndAt)) {
+		order.Status = domain.StandingOrderStatusCompleted
+		order.NextRunAt = nil
+	} else {
+		order.NextRunAt = &next
+	}
+	resetAttempts(order)
+	order.UpdatedAt = execution.ExecutedAt
+
+	err = s.standingOrderRepo.UpdateStandingOrder(ctx, tx, order)
+	if err != nil {
+		return fmt.Errorf("failed to update standing order: %w", err)
+	}
+	return nil
+}
+
+func resetAttempts(order *domain.StandingOrder) {
+	order.Attempts = 0
+	order.LastError = ""
+	order.NextAttemptAt = nil
+}
+
+// firstRun returns the first activation of sched at or after both the order's
+// start and now, rejecting schedules with no activation before the order ends.
+func firstRun(sched schedule.Schedule, order *domain.StandingOrder, now time.Time) (*time.Time, error) {
+	from := order.StartAt
+	if from.Before(now) {
+		from = now
+	}
+
+	first := sched.First(from)
+	if first.IsZero() || (order.EndAt != nil && first.After(*order.EndAt)) {
+		return nil, fmt.Errorf("%w: no activation between start and end", ErrInvalidSchedule)
+	}
+	return &first, nil
+}
+
+// standingOrderExecutionKey is the idempotency key of the transfer produced by
+// one activation of a standing order.
+func standingOrderExecutionKey(standingOrderID string, scheduledFor time.Time) string {
+	return fmt.Sprintf("standing-order:%s:%d", standingOrderID, scheduledFor.Unix())
+}
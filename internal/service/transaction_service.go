@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/fx"
 	"github.com/dirdr/goits/internal/repository"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -19,6 +23,9 @@ type transactionService struct {
 	accountBalanceRepo repository.AccountBalanceRepository
 	transferEventRepo  repository.TransferEventRepository
 	journalRepo        repository.JournalRepository
+	eventStore         repository.EventStore
+	outboxRepo         repository.OutboxRepository
+	rateProvider       fx.RateProvider
 }
 
 func NewTransactionService(
@@ -26,133 +33,705 @@ func NewTransactionService(
 	accountBalanceRepo repository.AccountBalanceRepository,
 	transferEventRepo repository.TransferEventRepository,
 	journalRepo repository.JournalRepository,
+	eventStore repository.EventStore,
+	outboxRepo repository.OutboxRepository,
+	rateProvider fx.RateProvider,
 ) TransactionService {
 	return &transactionService{
 		accountRepo:        accountRepo,
 		accountBalanceRepo: accountBalanceRepo,
 		transferEventRepo:  transferEventRepo,
 		journalRepo:        journalRepo,
+		eventStore:         eventStore,
+		outboxRepo:         outboxRepo,
+		rateProvider:       rateProvider,
 	}
 }
 
-func (s *transactionService) ProcessTransfer(ctx context.Context, tx *gorm.DB, sourceAccountID, destinationAccountID uint, amount decimal.Decimal) error {
-	return s.processTransferWithOptimisticLocking(ctx, tx, sourceAccountID, destinationAccountID, amount)
+func (s *transactionService) ProcessTransfer(ctx context.Context, tx *gorm.DB, req TransferRequest) (*domain.TransferEvent, error) {
+	return s.processTransferWithOptimisticLocking(ctx, tx, req)
 }
 
-func (s *transactionService) processTransferWithOptimisticLocking(ctx context.Context, tx *gorm.DB, sourceAccountID, destinationAccountID uint, amount decimal.Decimal) error {
-	if amount.IsNegative() || amount.IsZero() {
-		return errors.New("transfer amount must be positive")
+func (s *transactionService) processTransferWithOptimisticLocking(ctx context.Context, tx *gorm.DB, req TransferRequest) (*domain.TransferEvent, error) {
+	if req.Amount.IsNegative() || req.Amount.IsZero() {
+		return nil, fmt.Errorf("%w: transfer amount must be positive", ErrInvalidTransfer)
 	}
-	if sourceAccountID == destinationAccountID {
-		return errors.New("source and destination accounts cannot be the same")
+	if req.SourceAccountID == req.DestinationAccountID {
+		return nil, fmt.Errorf("%w: source and destination accounts cannot be the same", ErrInvalidTransfer)
 	}
 
-	sourceExists, err := s.accountRepo.AccountExists(ctx, tx, sourceAccountID)
+	fingerprint := transferFingerprint(req)
+	existing, err := s.findIdempotentReplay(ctx, tx, req.IdempotencyKey, fingerprint)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	sourceAccount, err := s.accountRepo.GetAccountByID(ctx, tx, req.SourceAccountID)
 	if err != nil {
-		return fmt.Errorf("failed to check source account: %w", err)
+		return nil, fmt.Errorf("failed to check source account: %w", err)
 	}
-	if !sourceExists {
-		return errors.New("source account not found")
+	if sourceAccount == nil {
+		return nil, fmt.Errorf("source %w", ErrAccountNotFound)
 	}
 
-	destinationExists, err := s.accountRepo.AccountExists(ctx, tx, destinationAccountID)
+	destinationAccount, err := s.accountRepo.GetAccountByID(ctx, tx, req.DestinationAccountID)
 	if err != nil {
-		return fmt.Errorf("failed to check destination account: %w", err)
+		return nil, fmt.Errorf("failed to check destination account: %w", err)
+	}
+	if destinationAccount == nil {
+		return nil, fmt.Errorf("destination %w", ErrAccountNotFound)
 	}
-	if !destinationExists {
-		return errors.New("destination account not found")
+
+	var conversion *domain.Conversion
+	if sourceAccount.Currency != destinationAccount.Currency {
+		if !req.AllowConversion {
+			return nil, ErrCurrencyMismatch
+		}
+		conversion, err = s.quoteConversion(ctx, req.Amount, sourceAccount.Currency, destinationAccount.Currency)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	sourceBalance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, sourceAccountID)
+	sourceBalance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, req.SourceAccountID)
 	if err != nil {
-		return fmt.Errorf("failed to get source account balance: %w", err)
+		return nil, fmt.Errorf("failed to get source account balance: %w", err)
 	}
 	if sourceBalance == nil {
-		return errors.New("source account balance not found")
+		return nil, fmt.Errorf("%w: source account balance not found", ErrInvalidTransfer)
+	}
+
+	if sourceBalance.AvailableBalance().LessThan(req.Amount) {
+		return nil, fmt.Errorf("%w in source account", ErrInsufficientFunds)
 	}
 
-	if sourceBalance.Balance.LessThan(amount) {
-		return errors.New("insufficient balance in source account")
+	now := time.Now()
+	transferID := uuid.New().String()
+
+	transferEvent := &domain.TransferEvent{
+		TransferID:         transferID,
+		FromAccountID:      req.SourceAccountID,
+		ToAccountID:        req.DestinationAccountID,
+		Amount:             req.Amount,
+		Currency:           sourceAccount.Currency,
+		EventType:          domain.EventTypeTransferProcessed,
+		RequestFingerprint: fingerprint,
+		IdempotencyKey:     req.IdempotencyKey,
+		Conversion:         conversion,
+		CreatedAt:          now,
 	}
 
-	destinationBalance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, destinationAccountID)
+	entries, err := s.transferJournalEntries(ctx, tx, transferEvent)
 	if err != nil {
-		return fmt.Errorf("failed to get destination account balance: %w", err)
+		return nil, err
 	}
-	if destinationBalance == nil {
-		return errors.New("destination account balance not found")
+
+	err = s.saveTransferEvent(ctx, tx, transferEvent, sortedAccountIDs(netChanges(entries)))
+	if err != nil {
+		return nil, err
+	}
+
+	err = appendEvent(ctx, tx, s.eventStore, domain.TransferProcessed{
+		TransferID:    transferEvent.TransferID,
+		FromAccountID: transferEvent.FromAccountID,
+		ToAccountID:   transferEvent.ToAccountID,
+		Amount:        transferEvent.Amount,
+		Currency:      transferEvent.Currency,
+		Conversion:    transferEvent.Conversion,
+	}, 0, transferEventMetadata(transferEvent), now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		entry.SourceEventID = transferEvent.EventID
+		err = s.journalRepo.SaveJournalEntry(ctx, tx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s journal entry: %w", entry.Type, err)
+		}
+	}
+
+	knownBalances := map[uint]*domain.AccountBalance{req.SourceAccountID: sourceBalance}
+	err = s.applyJournalEntries(ctx, tx, entries, knownBalances, transferEvent.EventID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return transferEvent, nil
+}
+
+// ProcessMultiLegTransfer posts N debit and credit legs atomically under a single
+// transfer ID. All accounts must share one currency and the legs must balance.
+func (s *transactionService) ProcessMultiLegTransfer(ctx context.Context, tx *gorm.DB, req MultiLegTransferRequest) (*domain.TransferEvent, error) {
+	if len(req.Legs) < 2 {
+		return nil, errors.New("a multi-leg transfer needs at least two legs")
+	}
+
+	totalDebits, totalCredits := decimal.Zero, decimal.Zero
+	changes := make(map[uint]decimal.Decimal)
+	for i, leg := range req.Legs {
+		if !leg.Amount.IsPositive() {
+			return nil, fmt.Errorf("leg %d: amount must be positive", i)
+		}
+		switch leg.Type {
+		case domain.Debit:
+			totalDebits = totalDebits.Add(leg.Amount)
+			changes[leg.AccountID] = changes[leg.AccountID].Sub(leg.Amount)
+		case domain.Credit:
+			totalCredits = totalCredits.Add(leg.Amount)
+			changes[leg.AccountID] = changes[leg.AccountID].Add(leg.Amount)
+		default:
+			return nil, fmt.Errorf("leg %d: type must be %q or %q", i, domain.Debit, domain.Credit)
+		}
+	}
+	if !totalDebits.Equal(totalCredits) {
+		return nil, fmt.Errorf("%w: debits %s, credits %s", ErrUnbalancedLegs, totalDebits, totalCredits)
+	}
+
+	fingerprint := multiLegFingerprint(req.Legs)
+	existing, err := s.findIdempotentReplay(ctx, tx, req.IdempotencyKey, fingerprint)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	accounts, balances, err := s.checkFunds(ctx, tx, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var currency string
+	for _, account := range accounts {
+		if currency == "" {
+			currency = account.Currency
+		} else if account.Currency != currency {
+			return nil, ErrCurrencyMismatch
+		}
 	}
 
 	now := time.Now()
 	transferID := uuid.New().String()
 
 	transferEvent := &domain.TransferEvent{
-		TransferID:    transferID,
-		FromAccountID: sourceAccountID,
-		ToAccountID:   destinationAccountID,
-		Amount:        amount,
-		EventType:     "TransferProcessed",
-		CreatedAt:     now,
+		TransferID:         transferID,
+		Amount:             totalDebits,
+		Currency:           currency,
+		EventType:          domain.EventTypeMultiLegTransferProcessed,
+		RequestFingerprint: fingerprint,
+		IdempotencyKey:     req.IdempotencyKey,
+		Legs:               req.Legs,
+		CreatedAt:          now,
 	}
 
-	err = s.transferEventRepo.SaveTransferEvent(ctx, tx, transferEvent)
+	legAccountIDs := make([]uint, 0, len(req.Legs))
+	for _, leg := range req.Legs {
+		legAccountIDs = append(legAccountIDs, leg.AccountID)
+	}
+	err = s.saveTransferEvent(ctx, tx, transferEvent, legAccountIDs)
 	if err != nil {
-		return fmt.Errorf("failed to save transfer event: %w", err)
+		return nil, err
 	}
 
-	debitEntry := &domain.JournalEntry{
-		TransactionID: transferID,
-		AccountID:     sourceAccountID,
-		Amount:        amount,
-		Type:          domain.Debit,
-		SourceEventID: transferEvent.EventID,
-		CreatedAt:     now,
+	err = appendEvent(ctx, tx, s.eventStore, domain.MultiLegTransferProcessed{
+		TransferID: transferID,
+		Legs:       req.Legs,
+		Amount:     totalDebits,
+		Currency:   currency,
+	}, 0, transferEventMetadata(transferEvent), now)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*domain.JournalEntry, 0, len(req.Legs))
+	for _, leg := range req.Legs {
+		entry := &domain.JournalEntry{
+			TransactionID: transferID,
+			AccountID:     leg.AccountID,
+			Amount:        leg.Amount,
+			Currency:      currency,
+			Type:          leg.Type,
+			SourceEventID: transferEvent.EventID,
+			CreatedAt:     now,
+		}
+		err = s.journalRepo.SaveJournalEntry(ctx, tx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s journal entry: %w", entry.Type, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	err = s.applyJournalEntries(ctx, tx, entries, balances, transferEvent.EventID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return transferEvent, nil
+}
+
+// ReverseTransfer appends a TransferReversed event whose journal entries mirror
+// the original legs. A zero amount reverses everything not yet reversed. Partial
+// reversals of a conversion reuse the rate recorded on the original event;
+// multi-leg transfers can only be reversed in full.
+func (s *transactionService) ReverseTransfer(ctx context.Context, tx *gorm.DB, req ReversalRequest) (*domain.TransferEvent, error) {
+	if req.Amount.IsNegative() {
+		return nil, errors.New("reversal amount cannot be negative")
+	}
+
+	fingerprint := reversalFingerprint(req)
+	existing, err := s.findIdempotentReplay(ctx, tx, req.IdempotencyKey, fingerprint)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	original, err := s.transferEventRepo.LockTransferEvent(ctx, tx, req.TransferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original transfer: %w", err)
+	}
+	if original == nil {
+		return nil, ErrTransferNotFound
+	}
+	if original.EventType == domain.EventTypeTransferReversed {
+		return nil, fmt.Errorf("%w: a reversal cannot itself be reversed", ErrInvalidReversal)
 	}
-	err = s.journalRepo.SaveJournalEntry(ctx, tx, debitEntry)
+
+	reversals, err := s.transferEventRepo.GetReversalsOfTransfer(ctx, tx, original.TransferID)
 	if err != nil {
-		return fmt.Errorf("failed to save debit journal entry: %w", err)
+		return nil, fmt.Errorf("failed to get previous reversals: %w", err)
+	}
+	reversed := decimal.Zero
+	for _, reversal := range reversals {
+		reversed = reversed.Add(reversal.Amount)
 	}
 
-	creditEntry := &domain.JournalEntry{
-		TransactionID: transferID,
-		AccountID:     destinationAccountID,
-		Amount:        amount,
-		Type:          domain.Credit,
-		SourceEventID: transferEvent.EventID,
+	remaining := original.Amount.Sub(reversed)
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if !amount.IsPositive() || amount.GreaterThan(remaining) {
+		return nil, fmt.Errorf("%w: requested %s, remaining %s", ErrReversalExceedsRemaining, amount, remaining)
+	}
+	isFull := reversed.IsZero() && amount.Equal(original.Amount)
+	if len(original.Legs) > 0 && !isFull {
+		return nil, fmt.Errorf("%w: multi-leg transfers can only be reversed in full", ErrInvalidReversal)
+	}
+
+	originalEntries, err := s.journalRepo.GetJournalEntriesByTransactionID(ctx, tx, original.TransferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original journal entries: %w", err)
+	}
+	if len(originalEntries) == 0 {
+		return nil, fmt.Errorf("original transfer %s has no journal entries", original.TransferID)
+	}
+
+	// The reversal exhausting a partially reversed transfer reverses what is
+	// left of each leg, so that rounding of the converted legs does not leave a
+	// residue on the destination side.
+	var reversedLegs map[uint]decimal.Decimal
+	if !isFull && amount.Equal(remaining) {
+		reversedLegs, err = s.reversedLegAmounts(ctx, tx, reversals)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	transferID := uuid.New().String()
+
+	entries := make([]*domain.JournalEntry, 0, len(originalEntries))
+	for _, originalEntry := range originalEntries {
+		entryType := domain.Credit
+		if originalEntry.Type == domain.Credit {
+			entryType = domain.Debit
+		}
+
+		entryAmount := originalEntry.Amount
+		switch {
+		case isFull:
+		case reversedLegs != nil:
+			entryAmount = originalEntry.Amount.Sub(reversedLegs[originalEntry.AccountID])
+		case originalEntry.Currency == original.Currency:
+			entryAmount = amount
+		default:
+			entryAmount = amount.Mul(original.Conversion.Rate).Round(8)
+		}
+
+		entries = append(entries, &domain.JournalEntry{
+			TransactionID: transferID,
+			AccountID:     originalEntry.AccountID,
+			Amount:        entryAmount,
+			Currency:      originalEntry.Currency,
+			Type:          entryType,
+			CreatedAt:     now,
+		})
+	}
+
+	_, balances, err := s.checkFunds(ctx, tx, netChanges(entries))
+	if err != nil {
+		return nil, err
+	}
+
+	reversalEvent := &domain.TransferEvent{
+		TransferID:         transferID,
+		FromAccountID:      original.ToAccountID,
+		ToAccountID:        original.FromAccountID,
+		Amount:             amount,
+		Currency:           original.Currency,
+		EventType:          domain.EventTypeTransferReversed,
+		IdempotencyKey:     req.IdempotencyKey,
+		RequestFingerprint: fingerprint,
+		ReversesTransferID: original.TransferID,
+		CreatedAt:          now,
+	}
+
+	err = s.saveTransferEvent(ctx, tx, reversalEvent, sortedAccountIDs(netChanges(entries)))
+	if err != nil {
+		return nil, err
+	}
+
+	// The original transfer is locked, so its stream can only have moved if it
+	// was written outside of ReverseTransfer.
+	sequence, err := s.eventStore.GetStreamSequence(ctx, tx, domain.AggregateTypeTransfer, original.TransferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer stream: %w", err)
+	}
+	err = appendEvent(ctx, tx, s.eventStore, domain.TransferReversed{
+		TransferID:         original.TransferID,
+		ReversalTransferID: transferID,
+		Amount:             amount,
+		Currency:           original.Currency,
+	}, sequence, transferEventMetadata(reversalEvent), now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		entry.SourceEventID = reversalEvent.EventID
+		err = s.journalRepo.SaveJournalEntry(ctx, tx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s journal entry: %w", entry.Type, err)
+		}
+	}
+
+	err = s.applyJournalEntries(ctx, tx, entries, balances, reversalEvent.EventID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return reversalEvent, nil
+}
+
+// reversedLegAmounts sums the journal entries of the reversals per account.
+func (s *transactionService) reversedLegAmounts(ctx context.Context, tx *gorm.DB, reversals []domain.TransferEvent) (map[uint]decimal.Decimal, error) {
+	transferIDs := make([]string, len(reversals))
+	for i, reversal := range reversals {
+		transferIDs[i] = reversal.TransferID
+	}
+
+	entries, err := s.journalRepo.ListJournalEntriesByTransactionIDs(ctx, tx, transferIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reversal journal entries: %w", err)
+	}
+
+	reversed := make(map[uint]decimal.Decimal)
+	for _, entry := range entries {
+		reversed[entry.AccountID] = reversed[entry.AccountID].Add(entry.Amount)
+	}
+	return reversed, nil
+}
+
+// FundAccount moves the amount from the funding account to the freshly opened
+// account as an AccountFunded transfer.
+func (s *transactionService) FundAccount(ctx context.Context, tx *gorm.DB, req FundingRequest) (*domain.TransferEvent, error) {
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("funding amount must be positive")
+	}
+
+	now := time.Now()
+	funding := req.FundingAccount
+	accountID := req.Balance.AccountID
+	transferEvent := &domain.TransferEvent{
+		TransferID:    req.TransferID,
+		FromAccountID: funding.ID,
+		ToAccountID:   accountID,
+		Amount:        req.Amount,
+		Currency:      funding.Currency,
+		EventType:     domain.EventTypeAccountFunded,
 		CreatedAt:     now,
 	}
-	err = s.journalRepo.SaveJournalEntry(ctx, tx, creditEntry)
+
+	err := s.saveTransferEvent(ctx, tx, transferEvent, []uint{funding.ID, accountID})
 	if err != nil {
-		return fmt.Errorf("failed to save credit journal entry: %w", err)
+		return nil, err
 	}
 
-	newSourceBalance := &domain.AccountBalance{
-		AccountID:   sourceAccountID,
-		Balance:     sourceBalance.Balance.Sub(amount),
-		Version:     sourceBalance.Version + 1,
-		LastEventID: transferEvent.EventID,
-		UpdatedAt:   now,
+	err = appendEvent(ctx, tx, s.eventStore, domain.AccountFunded{
+		TransferID:       req.TransferID,
+		AccountID:        accountID,
+		FundingAccountID: funding.ID,
+		Amount:           req.Amount,
+		Currency:         funding.Currency,
+	}, 0, transferEventMetadata(transferEvent), now)
+	if err != nil {
+		return nil, err
 	}
-	err = s.accountBalanceRepo.UpdateAccountBalanceWithVersion(ctx, tx, newSourceBalance, sourceBalance.Version)
+
+	entries := []*domain.JournalEntry{
+		{TransactionID: req.TransferID, AccountID: funding.ID, Amount: req.Amount, Currency: funding.Currency, Type: domain.Debit, SourceEventID: transferEvent.EventID, CreatedAt: now},
+		{TransactionID: req.TransferID, AccountID: accountID, Amount: req.Amount, Currency: funding.Currency, Type: domain.Credit, SourceEventID: transferEvent.EventID, CreatedAt: now},
+	}
+	for _, entry := range entries {
+		err = s.journalRepo.SaveJournalEntry(ctx, tx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s journal entry: %w", entry.Type, err)
+		}
+	}
+
+	err = s.applyJournalEntries(ctx, tx, entries, map[uint]*domain.AccountBalance{accountID: req.Balance}, transferEvent.EventID, now)
 	if err != nil {
-		return fmt.Errorf("failed to update source account balance: %w", err)
+		return nil, err
 	}
 
-	newDestinationBalance := &domain.AccountBalance{
-		AccountID:   destinationAccountID,
-		Balance:     destinationBalance.Balance.Add(amount),
-		Version:     destinationBalance.Version + 1,
-		LastEventID: transferEvent.EventID,
-		UpdatedAt:   now,
+	return transferEvent, nil
+}
+
+// findIdempotentReplay returns the event previously recorded under the key, or
+// ErrIdempotencyKeyReused when it was recorded for a different request.
+func (s *transactionService) findIdempotentReplay(ctx context.Context, tx *gorm.DB, idempotencyKey, fingerprint string) (*domain.TransferEvent, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+
+	existing, err := s.transferEventRepo.GetTransferEventByIdempotencyKey(ctx, tx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	if existing != nil && existing.RequestFingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return existing, nil
+}
+
+// checkFunds loads the touched accounts in ascending ID order and rejects an
+// overdraft of a customer account.
+func (s *transactionService) checkFunds(ctx context.Context, tx *gorm.DB, changes map[uint]decimal.Decimal) ([]*domain.Account, map[uint]*domain.AccountBalance, error) {
+	accountIDs := sortedAccountIDs(changes)
+
+	accounts := make([]*domain.Account, 0, len(accountIDs))
+	balances := make(map[uint]*domain.AccountBalance, len(accountIDs))
+	for _, accountID := range accountIDs {
+		account, err := s.accountRepo.GetAccountByID(ctx, tx, accountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check account %d: %w", accountID, err)
+		}
+		if account == nil {
+			return nil, nil, fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+		}
+
+		balance, err := s.accountBalanceRepo.GetAccountBalance(ctx, tx, accountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get balance of account %d: %w", accountID, err)
+		}
+		if balance == nil {
+			return nil, nil, fmt.Errorf("%w: balance of account %d not found", ErrInvalidTransfer, accountID)
+		}
+		if account.Type == domain.AccountTypeCustomer && balance.AvailableBalance().Add(changes[accountID]).IsNegative() {
+			return nil, nil, fmt.Errorf("%w in account %d", ErrInsufficientFunds, accountID)
+		}
+
+		accounts = append(accounts, account)
+		balances[accountID] = balance
+	}
+
+	return accounts, balances, nil
+}
+
+func (s *transactionService) quoteConversion(ctx context.Context, amount decimal.Decimal, sourceCurrency, destinationCurrency string) (*domain.Conversion, error) {
+	if s.rateProvider == nil {
+		return nil, fmt.Errorf("%w: currency conversion is not configured", ErrInvalidTransfer)
+	}
+
+	rate, err := s.rateProvider.GetRate(ctx, sourceCurrency, destinationCurrency)
+	if errors.Is(err, fx.ErrRateNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTransfer, err)
 	}
-	err = s.accountBalanceRepo.UpdateAccountBalanceWithVersion(ctx, tx, newDestinationBalance, destinationBalance.Version)
 	if err != nil {
-		return fmt.Errorf("failed to update destination account balance: %w", err)
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	destinationAmount := rate.Convert(amount)
+	if !destinationAmount.IsPositive() {
+		return nil, fmt.Errorf("%w: converted amount must be positive", ErrInvalidTransfer)
+	}
+
+	return &domain.Conversion{
+		Rate:                rate.Value,
+		RateSource:          rate.Source,
+		RateTimestamp:       rate.Timestamp,
+		DestinationAmount:   destinationAmount,
+		DestinationCurrency: destinationCurrency,
+	}, nil
+}
+
+// transferJournalEntries routes a conversion through the FX position account of
+// each currency so that every currency balances.
+func (s *transactionService) transferJournalEntries(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent) ([]*domain.JournalEntry, error) {
+	leg := func(accountID uint, amount decimal.Decimal, currency string, entryType domain.EntryType) *domain.JournalEntry {
+		return &domain.JournalEntry{
+			TransactionID: event.TransferID,
+			AccountID:     accountID,
+			Amount:        amount,
+			Currency:      currency,
+			Type:          entryType,
+			CreatedAt:     event.CreatedAt,
+		}
+	}
+
+	if event.Conversion == nil {
+		return []*domain.JournalEntry{
+			leg(event.FromAccountID, event.Amount, event.Currency, domain.Debit),
+			leg(event.ToAccountID, event.Amount, event.Currency, domain.Credit),
+		}, nil
+	}
+
+	conversion := event.Conversion
+	sourcePosition, err := s.fxPositionAccount(ctx, tx, event.Currency)
+	if err != nil {
+		return nil, err
+	}
+	destinationPosition, err := s.fxPositionAccount(ctx, tx, conversion.DestinationCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*domain.JournalEntry{
+		leg(event.FromAccountID, event.Amount, event.Currency, domain.Debit),
+		leg(sourcePosition.ID, event.Amount, event.Currency, domain.Credit),
+		leg(destinationPosition.ID, conversion.DestinationAmount, conversion.DestinationCurrency, domain.Debit),
+		leg(event.ToAccountID, conversion.DestinationAmount, conversion.DestinationCurrency, domain.Credit),
+	}, nil
+}
+
+func (s *transactionService) fxPositionAccount(ctx context.Context, tx *gorm.DB, currency string) (*domain.Account, error) {
+	account, err := s.accountRepo.GetSystemAccount(ctx, tx, domain.AccountTypeFXPosition, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FX position account: %w", err)
+	}
+	if account == nil {
+		return nil, fmt.Errorf("%w: no FX position account provisioned for %s", ErrInvalidTransfer, currency)
+	}
+	return account, nil
+}
+
+// applyJournalEntries updates balances in ascending account ID order, so that
+// concurrent transfers lock rows in the same order.
+func (s *transactionService) applyJournalEntries(ctx context.Context, tx *gorm.DB, entries []*domain.JournalEntry, knownBalances map[uint]*domain.AccountBalance, eventID uint, now time.Time) error {
+	changes := netChanges(entries)
+
+	for _, accountID := range sortedAccountIDs(changes) {
+		balance := knownBalances[accountID]
+		if balance == nil {
+			var err error
+			balance, err = s.accountBalanceRepo.GetAccountBalance(ctx, tx, accountID)
+			if err != nil {
+				return fmt.Errorf("failed to get balance of account %d: %w", accountID, err)
+			}
+			if balance == nil {
+				return fmt.Errorf("%w: balance of account %d not found", ErrInvalidTransfer, accountID)
+			}
+		}
+
+		newBalance := &domain.AccountBalance{
+			AccountID:   accountID,
+			Balance:     balance.Balance.Add(changes[accountID]),
+			HeldAmount:  balance.HeldAmount,
+			Version:     balance.Version + 1,
+			LastEventID: eventID,
+			UpdatedAt:   now,
+		}
+		err := s.accountBalanceRepo.UpdateAccountBalanceWithVersion(ctx, tx, newBalance, balance.Version)
+		if err != nil {
+			return fmt.Errorf("failed to update balance of account %d: %w", accountID, err)
+		}
 	}
 
 	return nil
 }
 
+func (s *transactionService) GetTransfer(ctx context.Context, transferID string) (*TransferDetails, error) {
+	event, err := s.transferEventRepo.GetTransferEventByTransferID(ctx, nil, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer event: %w", err)
+	}
+	if event == nil {
+		return nil, nil
+	}
+
+	entries, err := s.journalRepo.GetJournalEntriesByTransactionID(ctx, nil, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal entries: %w", err)
+	}
+
+	reversals, err := s.transferEventRepo.GetReversalsOfTransfer(ctx, nil, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reversals: %w", err)
+	}
+
+	return &TransferDetails{
+		Event:     event,
+		Entries:   entries,
+		Reversals: reversals,
+	}, nil
+}
+
+// transferFingerprint identifies the business content of a transfer request so
+// that a reused idempotency key can be told apart from a genuine replay.
+func transferFingerprint(req TransferRequest) string {
+	payload := fmt.Sprintf("%d:%d:%s", req.SourceAccountID, req.DestinationAccountID, req.Amount.String())
+	if req.AllowConversion {
+		payload += ":convert"
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+func reversalFingerprint(req ReversalRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("reverse:%s:%s", req.TransferID, req.Amount.String())))
+	return hex.EncodeToString(sum[:])
+}
+
+// netChanges returns the signed balance movement of every account touched by
+// the entries: credits increase a balance, debits decrease it.
+func netChanges(entries []*domain.JournalEntry) map[uint]decimal.Decimal {
+	changes := make(map[uint]decimal.Decimal)
+	for _, entry := range entries {
+		if entry.Type == domain.Credit {
+			changes[entry.AccountID] = changes[entry.AccountID].Add(entry.Amount)
+		} else {
+			changes[entry.AccountID] = changes[entry.AccountID].Sub(entry.Amount)
+		}
+	}
+	return changes
+}
+
+func sortedAccountIDs[V any](changes map[uint]V) []uint {
+	accountIDs := make([]uint, 0, len(changes))
+	for accountID := range changes {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+	return accountIDs
+}
+
 func isOptimisticLockingError(err error) bool {
 	return strings.Contains(err.Error(), "optimistic locking failed")
 }
+
+func multiLegFingerprint(legs []domain.Posting) string {
+	var b strings.Builder
+	b.WriteString("multi-leg")
+	for _, leg := range legs {
+		fmt.Fprintf(&b, "|%d:%s:%s", leg.AccountID, leg.Type, leg.Amount.String())
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
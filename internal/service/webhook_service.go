@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/repository"
+	"github.com/dirdr/goits/internal/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const minWebhookSecretLength = 16
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	sender      webhook.Sender
+	policy      WebhookDeliveryPolicy
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, sender webhook.Sender, policy WebhookDeliveryPolicy) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		policy:      policy,
+	}
+}
+
+// CreateWebhookSubscription stores an active subscription. It receives the
+// messages relayed from now on; earlier events are not replayed to it.
+func (s *webhookService) CreateWebhookSubscription(ctx context.Context, tx *gorm.DB, req WebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := validateWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhookSecret(req.Secret); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	subscription := &domain.WebhookSubscription{
+		SubscriptionID: uuid.New().String(),
+		URL:            req.URL,
+		EventTypes:     eventTypes,
+		Secret:         req.Secret,
+		Status:         domain.WebhookSubscriptionStatusActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	err = s.webhookRepo.SaveWebhookSubscription(ctx, tx, subscription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) GetWebhookSubscription(ctx context.Context, subscriptionID string) (*domain.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.GetWebhookSubscription(ctx, nil, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.ListWebhookSubscriptions(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// UpdateWebhookSubscription applies the requested changes. Deliveries already
+// queued keep their payload but are sent to the new URL with the new secret.
+func (s *webhookService) UpdateWebhookSubscription(ctx context.Context, tx *gorm.DB, req WebhookSubscriptionUpdate) (*domain.WebhookSubscription, error) {
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+	}
+	var eventTypes []string
+	if req.EventTypes != nil {
+		var err error
+		eventTypes, err = validateWebhookEventTypes(req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != nil {
+		if err := validateWebhookSecret(*req.Secret); err != nil {
+			return nil, err
+		}
+	}
+	if req.Status != nil && *req.Status != domain.WebhookSubscriptionStatusActive && *req.Status != domain.WebhookSubscriptionStatusDisabled {
+		return nil, fmt.Errorf("%w: status must be active or disabled", ErrInvalidWebhookSubscription)
+	}
+
+	subscription, err := s.webhookRepo.LockWebhookSubscription(ctx, tx, req.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, ErrWebhookSubscriptionNotFound
+	}
+
+	now := time.Now()
+	if req.URL != nil {
+		subscription.URL = *req.URL
+	}
+	if eventTypes != nil {
+		subscription.EventTypes = eventTypes
+	}
+	if req.Secret != nil {
+		subscription.Secret = *req.Secret
+	}
+	if req.Status != nil && *req.Status != subscription.Status {
+		if *req.Status == domain.WebhookSubscriptionStatusActive {
+			subscription.Status = domain.WebhookSubscriptionStatusActive
+			subscription.ConsecutiveFailures = 0
+			subscription.DisabledReason = ""
+			subscription.DisabledAt = nil
+		} else {
+			disable(subscription, "disabled on request", now)
+		}
+	}
+	subscription.UpdatedAt = now
+
+	err = s.webhookRepo.UpdateWebhookSubscription(ctx, tx, subscription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) DeleteWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) error {
+	subscription, err := s.webhookRepo.LockWebhookSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription == nil {
+		return ErrWebhookSubscriptionNotFound
+	}
+
+	err = s.webhookRepo.DeleteWebhookSubscription(ctx, tx, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *webhookService) ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) ([]domain.WebhookDelivery, error) {
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	subscription, err := s.webhookRepo.GetWebhookSubscription(ctx, nil, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, ErrWebhookSubscriptionNotFound
+	}
+
+	deliveries, err := s.webhookRepo.ListWebhookDeliveries(ctx, nil, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) GetWebhookDelivery(ctx context.Context, deliveryID uint) (*domain.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.GetWebhookDelivery(ctx, nil, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return nil, nil
+	}
+
+	delivery.AttemptHistory, err = s.webhookRepo.ListWebhookDeliveryAttempts(ctx, nil, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook delivery attempts: %w", err)
+	}
+	return delivery, nil
+}
+
+func (s *webhookService) RedeliverWebhook(ctx context.Context, tx *gorm.DB, deliveryID uint, now time.Time) (*domain.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.LockWebhookDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	subscription, err := s.webhookRepo.GetWebhookSubscription(ctx, tx, delivery.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if subscription.Status != domain.WebhookSubscriptionStatusActive {
+		return nil, ErrWebhookSubscriptionDisabled
+	}
+
+	delivery.Status = domain.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.DeliveredAt = nil
+
+	err = s.webhookRepo.UpdateWebhookDelivery(ctx, tx, delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (s *webhookService) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	deliveries, err := s.webhookRepo.ListDueWebhookDeliveries(ctx, nil, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// DeliverWebhook sends the delivery while holding the lock of its subscription,
+// so that the subscription's count of consecutive failures is exact across
+// dispatchers. A delivery that failed is retried with exponential backoff
+// until it runs out of attempts; a success resets the subscription's count.
+func (s *webhookService) DeliverWebhook(ctx context.Context, tx *gorm.DB, deliveryID uint, now time.Time) (*WebhookDeliveryOutcome, error) {
+	delivery, err := s.webhookRepo.ClaimWebhookDelivery(ctx, tx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	if delivery == nil || delivery.Status != domain.WebhookDeliveryStatusPending || delivery.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+
+	subscription, err := s.webhookRepo.LockWebhookSubscription(ctx, tx, delivery.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription == nil || subscription.Status != domain.WebhookSubscriptionStatusActive {
+		return nil, nil
+	}
+
+	started := time.Now()
+	statusCode, sendErr := s.sender.Send(ctx, webhook.Request{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		DeliveryID: delivery.DeliveryID,
+		MessageID:  delivery.MessageID,
+		EventType:  delivery.EventType,
+		Body:       delivery.Payload,
+	}, now)
+
+	attempt := &domain.WebhookDeliveryAttempt{
+		DeliveryID:  delivery.DeliveryID,
+		StatusCode:  statusCode,
+		DurationMs:  time.Since(started).Milliseconds(),
+		AttemptedAt: now,
+	}
+	outcome := &WebhookDeliveryOutcome{Delivery: delivery, Attempt: attempt}
+
+	delivery.Attempts++
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		delivery.LastError = sendErr.Error()
+		if delivery.Attempts >= s.policy.MaxAttempts {
+			delivery.Status = domain.WebhookDeliveryStatusFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(exponentialBackoff(s.policy.MinBackoff, s.policy.MaxBackoff, delivery.Attempts))
+		}
+
+		subscription.ConsecutiveFailures++
+		if subscription.ConsecutiveFailures >= s.policy.DisableAfter {
+			disable(subscription, fmt.Sprintf("%d consecutive failed deliveries, last: %s", subscription.ConsecutiveFailures, sendErr), now)
+			outcome.SubscriptionDisabled = true
+		}
+	} else {
+		delivery.Status = domain.WebhookDeliveryStatusDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		subscription.ConsecutiveFailures = 0
+	}
+
+	err = s.webhookRepo.SaveWebhookDeliveryAttempt(ctx, tx, attempt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save webhook delivery attempt: %w", err)
+	}
+	err = s.webhookRepo.UpdateWebhookDelivery(ctx, tx, delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	subscription.UpdatedAt = now
+	err = s.webhookRepo.UpdateWebhookSubscription(ctx, tx, subscription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return outcome, nil
+}
+
+func disable(subscription *domain.WebhookSubscription, reason string, now time.Time) {
+	subscription.Status = domain.WebhookSubscriptionStatusDisabled
+	subscription.DisabledReason = reason
+	subscription.DisabledAt = &now
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhookSubscription)
+	}
+	return nil
+}
+
+// validateWebhookEventTypes returns the event types without duplicates.
+func validateWebhookEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidWebhookSubscription)
+	}
+
+	unique := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhookSubscription, eventType)
+		}
+		if !slices.Contains(unique, eventType) {
+			unique = append(unique, eventType)
+		}
+	}
+	return unique, nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if len(secret) < minWebhookSecretLength {
+		return fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidWebhookSubscription, minWebhookSecretLength)
+	}
+	return nil
+}
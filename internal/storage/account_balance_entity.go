@@ -9,6 +9,7 @@ import (
 type GormAccountBalance struct {
 	AccountID   uint            `gorm:"primaryKey"`
 	Balance     decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	HeldAmount  decimal.Decimal `gorm:"type:numeric(20,8);not null;default:0"`
 	Version     int             `gorm:"not null"`
 	LastEventID uint            `gorm:"not null"`
 	UpdatedAt   time.Time       `gorm:"not null"`
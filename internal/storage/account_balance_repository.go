@@ -37,16 +37,46 @@ func (repo *GormAccountBalanceRepository) GetAccountBalance(ctx context.Context,
 	return &domain.AccountBalance{
 		AccountID:   gormBalance.AccountID,
 		Balance:     gormBalance.Balance,
+		HeldAmount:  gormBalance.HeldAmount,
 		Version:     gormBalance.Version,
 		LastEventID: gormBalance.LastEventID,
 		UpdatedAt:   gormBalance.UpdatedAt,
 	}, nil
 }
 
+// ListAccountBalances returns the balances of every account, by account ID.
+func (repo *GormAccountBalanceRepository) ListAccountBalances(ctx context.Context, tx *gorm.DB) ([]domain.AccountBalance, error) {
+	var gormBalances []GormAccountBalance
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Order("account_id").Find(&gormBalances)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list account balances: %w", result.Error)
+	}
+
+	balances := make([]domain.AccountBalance, 0, len(gormBalances))
+	for _, b := range gormBalances {
+		balances = append(balances, domain.AccountBalance{
+			AccountID:   b.AccountID,
+			Balance:     b.Balance,
+			HeldAmount:  b.HeldAmount,
+			Version:     b.Version,
+			LastEventID: b.LastEventID,
+			UpdatedAt:   b.UpdatedAt,
+		})
+	}
+	return balances, nil
+}
+
 func (repo *GormAccountBalanceRepository) UpsertAccountBalance(ctx context.Context, tx *gorm.DB, balance *domain.AccountBalance) error {
 	gormBalance := GormAccountBalance{
 		AccountID:   balance.AccountID,
 		Balance:     balance.Balance,
+		HeldAmount:  balance.HeldAmount,
 		Version:     balance.Version,
 		LastEventID: balance.LastEventID,
 		UpdatedAt:   balance.UpdatedAt,
@@ -60,7 +90,7 @@ func (repo *GormAccountBalanceRepository) UpsertAccountBalance(ctx context.Conte
 	result := db.WithContext(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "account_id"}},
-			DoUpdates: clause.AssignmentColumns([]string{"balance", "version", "last_event_id", "updated_at"}),
+			DoUpdates: clause.AssignmentColumns([]string{"balance", "held_amount", "version", "last_event_id", "updated_at"}),
 		}).Create(&gormBalance)
 
 	if result.Error != nil {
@@ -79,6 +109,7 @@ func (repo *GormAccountBalanceRepository) UpdateAccountBalanceWithVersion(ctx co
 		Where("account_id = ? AND version = ?", balance.AccountID, expectedVersion).
 		Updates(map[string]interface{}{
 			"balance":       balance.Balance,
+			"held_amount":   balance.HeldAmount,
 			"version":       balance.Version,
 			"last_event_id": balance.LastEventID,
 			"updated_at":    balance.UpdatedAt,
@@ -1,11 +1,17 @@
 package storage
 
-import "time"
+import (
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+)
 
 type GormAccount struct {
-	ID        uint      `gorm:"primaryKey"`
-	CreatedAt time.Time `gorm:"not null"`
-	UpdatedAt time.Time `gorm:"not null"`
+	ID        uint               `gorm:"primaryKey"`
+	Type      domain.AccountType `gorm:"type:varchar(20);not null;default:'customer';uniqueIndex:idx_accounts_system_type_currency,priority:1,where:type <> 'customer'"`
+	Currency  string             `gorm:"type:char(3);not null;default:'USD';uniqueIndex:idx_accounts_system_type_currency,priority:2"`
+	CreatedAt time.Time          `gorm:"not null"`
+	UpdatedAt time.Time          `gorm:"not null"`
 }
 
 func (GormAccount) TableName() string {
@@ -20,6 +20,8 @@ func NewGormAccountRepository(db *gorm.DB) *GormAccountRepository {
 func (repo *GormAccountRepository) CreateAccount(ctx context.Context, tx *gorm.DB, account *domain.Account) error {
 	gormAccount := GormAccount{
 		ID:        account.ID,
+		Type:      account.Type,
+		Currency:  account.Currency,
 		CreatedAt: account.CreatedAt,
 		UpdatedAt: account.UpdatedAt,
 	}
@@ -52,11 +54,7 @@ func (repo *GormAccountRepository) GetAccountByID(ctx context.Context, tx *gorm.
 		return nil, fmt.Errorf("failed to get account by ID: %w", result.Error)
 	}
 
-	return &domain.Account{
-		ID:        gormAccount.ID,
-		CreatedAt: gormAccount.CreatedAt,
-		UpdatedAt: gormAccount.UpdatedAt,
-	}, nil
+	return toDomainAccount(&gormAccount), nil
 }
 
 func (repo *GormAccountRepository) AccountExists(ctx context.Context, tx *gorm.DB, accountID uint) (bool, error) {
@@ -74,3 +72,62 @@ func (repo *GormAccountRepository) AccountExists(ctx context.Context, tx *gorm.D
 
 	return count > 0, nil
 }
+
+// GetSystemAccount returns the unique account of a non-customer type holding the
+// given currency, or nil when none has been provisioned.
+func (repo *GormAccountRepository) GetSystemAccount(ctx context.Context, tx *gorm.DB, accountType domain.AccountType, currency string) (*domain.Account, error) {
+	var gormAccount GormAccount
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormAccount, "type = ? AND currency = ?", accountType, currency)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get system account: %w", result.Error)
+	}
+
+	return toDomainAccount(&gormAccount), nil
+}
+
+func (repo *GormAccountRepository) LockSystemAccounts(ctx context.Context, tx *gorm.DB) error {
+	result := tx.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext('system_accounts'))")
+	if result.Error != nil {
+		return fmt.Errorf("failed to lock system accounts: %w", result.Error)
+	}
+	return nil
+}
+
+// NextSystemAccountID returns the ID after the highest one in use in the range
+// reserved for system accounts. Callers must hold LockSystemAccounts.
+func (repo *GormAccountRepository) NextSystemAccountID(ctx context.Context, tx *gorm.DB) (uint, error) {
+	var nextID uint
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Model(&GormAccount{}).
+		Select("COALESCE(MAX(id) + 1, ?)", domain.SystemAccountIDMin).
+		Where("id >= ?", domain.SystemAccountIDMin).
+		Scan(&nextID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get next system account ID: %w", result.Error)
+	}
+	return nextID, nil
+}
+
+func toDomainAccount(gormAccount *GormAccount) *domain.Account {
+	return &domain.Account{
+		ID:        gormAccount.ID,
+		Type:      gormAccount.Type,
+		Currency:  gormAccount.Currency,
+		CreatedAt: gormAccount.CreatedAt,
+		UpdatedAt: gormAccount.UpdatedAt,
+	}
+}
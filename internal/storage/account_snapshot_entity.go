@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GormAccountSnapshot struct {
+	AccountID   uint            `gorm:"primaryKey;autoIncrement:false"`
+	EventID     uint            `gorm:"primaryKey;autoIncrement:false;index"`
+	Balance     decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	LastEventID uint            `gorm:"not null"`
+	LastEntryAt time.Time       `gorm:"not null"`
+	CreatedAt   time.Time       `gorm:"not null"`
+}
+
+func (GormAccountSnapshot) TableName() string {
+	return "account_snapshots"
+}
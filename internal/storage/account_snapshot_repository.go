@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSnapshotRepository(db *gorm.DB) *GormSnapshotRepository {
+	return &GormSnapshotRepository{db: db}
+}
+
+// SaveAccountSnapshots stores the rows of a snapshot. Rows already written for
+// the same account and event, by a concurrent snapshot of another replica,
+// describe the same state and are left as is.
+func (repo *GormSnapshotRepository) SaveAccountSnapshots(ctx context.Context, tx *gorm.DB, snapshots []domain.AccountSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	gormSnapshots := make([]GormAccountSnapshot, 0, len(snapshots))
+	for _, s := range snapshots {
+		gormSnapshots = append(gormSnapshots, GormAccountSnapshot{
+			AccountID:   s.AccountID,
+			EventID:     s.EventID,
+			Balance:     s.Balance,
+			LastEventID: s.LastEventID,
+			LastEntryAt: s.LastEntryAt,
+			CreatedAt:   s.CreatedAt,
+		})
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(gormSnapshots, 500)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save account snapshots: %w", result.Error)
+	}
+	return nil
+}
+
+// GetLatestSnapshotEventID returns the event ID of the latest snapshot, at or
+// before atOrBefore when set, or 0 when there is none.
+func (repo *GormSnapshotRepository) GetLatestSnapshotEventID(ctx context.Context, tx *gorm.DB, atOrBefore *uint) (uint, error) {
+	var eventID *uint
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Model(&GormAccountSnapshot{}).Select("MAX(event_id)")
+	if atOrBefore != nil {
+		q = q.Where("event_id <= ?", *atOrBefore)
+	}
+
+	result := q.Scan(&eventID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get latest snapshot event ID: %w", result.Error)
+	}
+
+	if eventID == nil {
+		return 0, nil
+	}
+	return *eventID, nil
+}
+
+// ListAccountSnapshotsAt returns the state of every account with a snapshot row
+// at or before eventID: its latest such row.
+func (repo *GormSnapshotRepository) ListAccountSnapshotsAt(ctx context.Context, tx *gorm.DB, eventID uint) ([]domain.AccountSnapshot, error) {
+	var gormSnapshots []GormAccountSnapshot
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (account_id) *
+		FROM account_snapshots
+		WHERE event_id <= ?
+		ORDER BY account_id, event_id DESC`, eventID).Scan(&gormSnapshots)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list account snapshots: %w", result.Error)
+	}
+
+	snapshots := make([]domain.AccountSnapshot, 0, len(gormSnapshots))
+	for i := range gormSnapshots {
+		snapshots = append(snapshots, toDomainAccountSnapshot(&gormSnapshots[i]))
+	}
+	return snapshots, nil
+}
+
+// GetLatestAccountSnapshot returns the latest snapshot row of the account that
+// does not go past the point, or nil when there is none. A row is before a
+// time when all the entries it covers are.
+func (repo *GormSnapshotRepository) GetLatestAccountSnapshot(ctx context.Context, tx *gorm.DB, accountID uint, point domain.BalancePoint) (*domain.AccountSnapshot, error) {
+	var gormSnapshot GormAccountSnapshot
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Where("account_id = ?", accountID)
+	if point.Time != nil {
+		q = q.Where("last_entry_at <= ?", *point.Time)
+	}
+	if point.EventID != nil {
+		q = q.Where("event_id <= ?", *point.EventID)
+	}
+
+	result := q.Order("event_id DESC").First(&gormSnapshot)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account snapshot: %w", result.Error)
+	}
+
+	snapshot := toDomainAccountSnapshot(&gormSnapshot)
+	return &snapshot, nil
+}
+
+func toDomainAccountSnapshot(s *GormAccountSnapshot) domain.AccountSnapshot {
+	return domain.AccountSnapshot{
+		AccountID:   s.AccountID,
+		EventID:     s.EventID,
+		Balance:     s.Balance,
+		LastEventID: s.LastEventID,
+		LastEntryAt: s.LastEntryAt,
+		CreatedAt:   s.CreatedAt,
+	}
+}
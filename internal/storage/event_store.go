@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+)
+
+// GormEventStore keeps events in a single append-only table, in which each
+// aggregate stream is identified by its aggregate type and ID.
+type GormEventStore struct {
+	db *gorm.DB
+}
+
+func NewGormEventStore(db *gorm.DB) *GormEventStore {
+	return &GormEventStore{db: db}
+}
+
+// AppendEvents appends events to the stream of one aggregate, assigning them
+// the sequence numbers following expectedSequence. It fails with an optimistic
+// locking error when the stream has moved past expectedSequence, including
+// when a concurrent transaction appends the same sequence number first.
+func (store *GormEventStore) AppendEvents(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string, expectedSequence int64, events []*domain.EventEnvelope) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	db := store.db
+	if tx != nil {
+		db = tx
+	}
+
+	current, err := store.GetStreamSequence(ctx, db, aggregateType, aggregateID)
+	if err != nil {
+		return err
+	}
+	if current != expectedSequence {
+		return fmt.Errorf("optimistic locking failed: %s stream %s is at sequence %d, expected %d", aggregateType, aggregateID, current, expectedSequence)
+	}
+
+	gormEvents := make([]GormStoredEvent, 0, len(events))
+	for i, event := range events {
+		if event.AggregateType != aggregateType || event.AggregateID != aggregateID {
+			return fmt.Errorf("%s event belongs to %s stream %s, not %s stream %s", event.EventType, event.AggregateType, event.AggregateID, aggregateType, aggregateID)
+		}
+
+		var metadata []byte
+		if len(event.Metadata) > 0 {
+			metadata, err = json.Marshal(event.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to encode event metadata: %w", err)
+			}
+		}
+
+		gormEvents = append(gormEvents, GormStoredEvent{
+			EventType:     event.EventType,
+			AggregateType: aggregateType,
+			AggregateID:   aggregateID,
+			Sequence:      expectedSequence + int64(i) + 1,
+			SchemaVersion: event.SchemaVersion,
+			Payload:       event.Payload,
+			Metadata:      metadata,
+			OccurredAt:    event.OccurredAt,
+		})
+	}
+
+	result := db.WithContext(ctx).Create(&gormEvents)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("optimistic locking failed: %s stream %s was appended to by another transaction", aggregateType, aggregateID)
+		}
+		return fmt.Errorf("failed to append events: %w", result.Error)
+	}
+
+	for i := range events {
+		events[i].EventID = gormEvents[i].EventID
+		events[i].Sequence = gormEvents[i].Sequence
+	}
+	return nil
+}
+
+// BackfillAccountOpenedEvents opens the stream of every account created before
+// accounts had one with an AccountOpened event. Those accounts were opened with
+// their initial balance already on them, so the event carries the balance the
+// account had before any journal entry, its current balance minus the net of
+// its journal entries, as both its initial and opening balance. It returns the
+// number of events appended.
+func BackfillAccountOpenedEvents(ctx context.Context, db *gorm.DB) (int64, error) {
+	schema, ok := domain.LookupEventSchema(domain.EventTypeAccountOpened)
+	if !ok {
+		return 0, fmt.Errorf("no schema registered for %s events", domain.EventTypeAccountOpened)
+	}
+
+	result := db.WithContext(ctx).Exec(`
+		INSERT INTO events (event_type, aggregate_type, aggregate_id, sequence, schema_version, payload, metadata, occurred_at)
+		SELECT ?, ?, a.id::text, 1, ?,
+			jsonb_build_object('account_id', a.id, 'type', a.type, 'currency', a.currency,
+				'initial_balance', (b.balance - COALESCE(j.net, 0))::text, 'opening_balance', (b.balance - COALESCE(j.net, 0))::text),
+			'{"source": "backfill"}'::jsonb,
+			a.created_at
+		FROM accounts a
+		JOIN account_balances b ON b.account_id = a.id
+		LEFT JOIN (
+			SELECT account_id, SUM(CASE WHEN type = ? THEN amount ELSE -amount END) AS net
+			FROM journal_entries
+			GROUP BY account_id
+		) j ON j.account_id = a.id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM events e WHERE e.aggregate_type = ? AND e.aggregate_id = a.id::text
+		)
+		ORDER BY a.id
+		ON CONFLICT DO NOTHING`,
+		domain.EventTypeAccountOpened, domain.AggregateTypeAccount, schema.Version,
+		string(domain.Credit), domain.AggregateTypeAccount)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to backfill %s events: %w", domain.EventTypeAccountOpened, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetStreamSequence returns the sequence number of the last event of the
+// stream, or 0 when the stream is empty.
+func (store *GormEventStore) GetStreamSequence(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string) (int64, error) {
+	var sequence int64
+
+	db := store.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Model(&GormStoredEvent{}).
+		Select("COALESCE(MAX(sequence), 0)").
+		Where("aggregate_type = ? AND aggregate_id = ?", aggregateType, aggregateID).
+		Scan(&sequence)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get stream sequence: %w", result.Error)
+	}
+
+	return sequence, nil
+}
+
+// LoadStream returns the events of one aggregate stream with a sequence number
+// greater than afterSequence, in order.
+func (store *GormEventStore) LoadStream(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string, afterSequence int64) ([]domain.EventEnvelope, error) {
+	var gormEvents []GormStoredEvent
+
+	db := store.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("aggregate_type = ? AND aggregate_id = ? AND sequence > ?", aggregateType, aggregateID, afterSequence).
+		Order("sequence").
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to load event stream: %w", result.Error)
+	}
+
+	return toDomainEventEnvelopes(gormEvents)
+}
+
+// ListEvents returns up to limit events of every stream with an ID greater than
+// afterEventID, in the order they were appended.
+func (store *GormEventStore) ListEvents(ctx context.Context, tx *gorm.DB, afterEventID uint, limit int) ([]domain.EventEnvelope, error) {
+	var gormEvents []GormStoredEvent
+
+	db := store.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("event_id > ?", afterEventID).
+		Order("event_id").
+		Limit(limit).
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list events: %w", result.Error)
+	}
+
+	return toDomainEventEnvelopes(gormEvents)
+}
+
+// ListEventsByType returns up to limit events of the given type with an ID
+// greater than afterEventID, in the order they were appended.
+func (store *GormEventStore) ListEventsByType(ctx context.Context, tx *gorm.DB, eventType string, afterEventID uint, limit int) ([]domain.EventEnvelope, error) {
+	var gormEvents []GormStoredEvent
+
+	db := store.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("event_type = ? AND event_id > ?", eventType, afterEventID).
+		Order("event_id").
+		Limit(limit).
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list %s events: %w", eventType, result.Error)
+	}
+
+	return toDomainEventEnvelopes(gormEvents)
+}
+
+func toDomainEventEnvelopes(gormEvents []GormStoredEvent) ([]domain.EventEnvelope, error) {
+	envelopes := make([]domain.EventEnvelope, 0, len(gormEvents))
+	for _, gormEvent := range gormEvents {
+		envelope := domain.EventEnvelope{
+			EventID:       gormEvent.EventID,
+			EventType:     gormEvent.EventType,
+			AggregateType: gormEvent.AggregateType,
+			AggregateID:   gormEvent.AggregateID,
+			Sequence:      gormEvent.Sequence,
+			SchemaVersion: gormEvent.SchemaVersion,
+			Payload:       gormEvent.Payload,
+			OccurredAt:    gormEvent.OccurredAt,
+		}
+		if len(gormEvent.Metadata) > 0 {
+			if err := json.Unmarshal(gormEvent.Metadata, &envelope.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode metadata of event %d: %w", gormEvent.EventID, err)
+			}
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, nil
+}
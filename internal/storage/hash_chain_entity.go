@@ -0,0 +1,15 @@
+package storage
+
+import "time"
+
+type GormHashChainHead struct {
+	Chain     string    `gorm:"type:varchar(50);primaryKey"`
+	LastSeq   uint      `gorm:"not null;default:0"`
+	LastID    uint      `gorm:"not null;default:0"`
+	LastHash  string    `gorm:"type:varchar(64);not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (GormHashChainHead) TableName() string {
+	return "hash_chain_heads"
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormHashChainRepository struct {
+	db *gorm.DB
+}
+
+func NewGormHashChainRepository(db *gorm.DB) *GormHashChainRepository {
+	return &GormHashChainRepository{db: db}
+}
+
+func (repo *GormHashChainRepository) GetHashChainHead(ctx context.Context, tx *gorm.DB, chain string) (*domain.HashChainHead, error) {
+	var gormHead GormHashChainHead
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormHead, "chain = ?", chain)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get hash chain head: %w", result.Error)
+	}
+
+	return &domain.HashChainHead{Chain: gormHead.Chain, LastSeq: gormHead.LastSeq, LastID: gormHead.LastID, LastHash: gormHead.LastHash}, nil
+}
+
+// SealHashChain chains up to limit committed rows of chain that are not sealed
+// yet, in ID order, and returns how many it sealed. Sealers serialize on the
+// head of the chain; postings never take that lock.
+func (repo *GormHashChainRepository) SealHashChain(ctx context.Context, tx *gorm.DB, chain string, limit int) (int, error) {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	var seal func(ctx context.Context, db *gorm.DB, head *GormHashChainHead, limit int) (int, error)
+	switch chain {
+	case domain.HashChainTransferEvents:
+		seal = sealTransferEvents
+	case domain.HashChainJournalEntries:
+		seal = sealJournalEntries
+	default:
+		return 0, fmt.Errorf("unknown hash chain %s", chain)
+	}
+
+	var head GormHashChainHead
+	result := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&head, "chain = ?", chain)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("hash chain %s is not initialized", chain)
+		}
+		return 0, fmt.Errorf("failed to lock hash chain head: %w", result.Error)
+	}
+
+	sealed, err := seal(ctx, db, &head, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to seal hash chain %s: %w", chain, err)
+	}
+	if sealed == 0 {
+		return 0, nil
+	}
+
+	result = db.WithContext(ctx).
+		Model(&GormHashChainHead{}).
+		Where("chain = ?", chain).
+		Updates(map[string]any{"last_seq": head.LastSeq, "last_id": head.LastID, "last_hash": head.LastHash, "updated_at": time.Now()})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to advance hash chain head: %w", result.Error)
+	}
+	return sealed, nil
+}
+
+// InitHashChains creates the heads of the hash chains that do not exist yet.
+func InitHashChains(ctx context.Context, db *gorm.DB) error {
+	for _, chain := range []string{domain.HashChainTransferEvents, domain.HashChainJournalEntries} {
+		result := db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&GormHashChainHead{Chain: chain, LastHash: domain.GenesisHash, UpdatedAt: time.Now()})
+		if result.Error != nil {
+			return fmt.Errorf("failed to create hash chain head: %w", result.Error)
+		}
+	}
+	return nil
+}
+
+func sealTransferEvents(ctx context.Context, db *gorm.DB, head *GormHashChainHead, limit int) (int, error) {
+	var gormEvents []GormTransferEvent
+	result := db.WithContext(ctx).Where("chain_seq IS NULL").Order("event_id").Limit(limit).Find(&gormEvents)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	for i := range gormEvents {
+		event, err := toStoredTransferEvent(&gormEvents[i])
+		if err != nil {
+			return 0, err
+		}
+		hash, err := event.ChainHash(head.LastHash)
+		if err != nil {
+			return 0, err
+		}
+		result := db.WithContext(ctx).
+			Model(&GormTransferEvent{}).
+			Where("event_id = ?", event.EventID).
+			Updates(map[string]any{"chain_seq": head.LastSeq + 1, "prev_hash": head.LastHash, "hash": hash})
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		head.LastSeq, head.LastID, head.LastHash = head.LastSeq+1, event.EventID, hash
+	}
+
+	return len(gormEvents), nil
+}
+
+func sealJournalEntries(ctx context.Context, db *gorm.DB, head *GormHashChainHead, limit int) (int, error) {
+	var gormEntries []GormJournalEntry
+	result := db.WithContext(ctx).Where("chain_seq IS NULL").Order("entry_id").Limit(limit).Find(&gormEntries)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	for i := range gormEntries {
+		entry := toDomainJournalEntry(&gormEntries[i])
+		hash, err := entry.ChainHash(head.LastHash)
+		if err != nil {
+			return 0, err
+		}
+		result := db.WithContext(ctx).
+			Model(&GormJournalEntry{}).
+			Where("entry_id = ?", entry.EntryID).
+			Updates(map[string]any{"chain_seq": head.LastSeq + 1, "prev_hash": head.LastHash, "hash": hash})
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		head.LastSeq, head.LastID, head.LastHash = head.LastSeq+1, entry.EntryID, hash
+	}
+
+	return len(gormEntries), nil
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GormHold struct {
+	HoldID               string          `gorm:"type:varchar(36);primaryKey"`
+	SourceAccountID      uint            `gorm:"not null;index"`
+	DestinationAccountID uint            `gorm:"not null"`
+	Amount               decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	Currency             string          `gorm:"type:char(3);not null"`
+	Status               string          `gorm:"type:varchar(20);not null;index:idx_holds_status_expires_at,priority:1"`
+	CapturedAmount       decimal.Decimal `gorm:"type:numeric(20,8);not null;default:0"`
+	CaptureTransferID    *string         `gorm:"type:varchar(36)"`
+	IdempotencyKey       *string         `gorm:"type:varchar(255);uniqueIndex"`
+	RequestFingerprint   string          `gorm:"type:varchar(64)"`
+	ExpiresAt            time.Time       `gorm:"not null;index:idx_holds_status_expires_at,priority:2"`
+	CreatedAt            time.Time       `gorm:"not null"`
+	UpdatedAt            time.Time       `gorm:"not null"`
+}
+
+func (GormHold) TableName() string {
+	return "holds"
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormHoldRepository struct {
+	db *gorm.DB
+}
+
+func NewGormHoldRepository(db *gorm.DB) *GormHoldRepository {
+	return &GormHoldRepository{db: db}
+}
+
+func (repo *GormHoldRepository) SaveHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	gormHold := GormHold{
+		HoldID:               hold.HoldID,
+		SourceAccountID:      hold.SourceAccountID,
+		DestinationAccountID: hold.DestinationAccountID,
+		Amount:               hold.Amount,
+		Currency:             hold.Currency,
+		Status:               string(hold.Status),
+		CapturedAmount:       hold.CapturedAmount,
+		RequestFingerprint:   hold.RequestFingerprint,
+		ExpiresAt:            hold.ExpiresAt,
+		CreatedAt:            hold.CreatedAt,
+		UpdatedAt:            hold.UpdatedAt,
+	}
+	if hold.IdempotencyKey != "" {
+		gormHold.IdempotencyKey = &hold.IdempotencyKey
+	}
+	if hold.CaptureTransferID != "" {
+		gormHold.CaptureTransferID = &hold.CaptureTransferID
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormHold)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) && gormHold.IdempotencyKey != nil {
+			return errors.New("optimistic locking failed: idempotency key was claimed by another transaction")
+		}
+		return fmt.Errorf("failed to save hold: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateHoldStatus records the outcome of a hold. Callers are expected to hold
+// the row lock taken by LockHold.
+func (repo *GormHoldRepository) UpdateHoldStatus(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	var captureTransferID *string
+	if hold.CaptureTransferID != "" {
+		captureTransferID = &hold.CaptureTransferID
+	}
+
+	result := db.WithContext(ctx).Model(&GormHold{}).
+		Where("hold_id = ?", hold.HoldID).
+		Updates(map[string]interface{}{
+			"status":              string(hold.Status),
+			"captured_amount":     hold.CapturedAmount,
+			"capture_transfer_id": captureTransferID,
+			"updated_at":          hold.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update hold: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("hold %s not found", hold.HoldID)
+	}
+	return nil
+}
+
+func (repo *GormHoldRepository) GetHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error) {
+	var gormHold GormHold
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormHold, "hold_id = ?", holdID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get hold: %w", result.Error)
+	}
+
+	return toDomainHold(&gormHold), nil
+}
+
+// LockHold reads the hold with a row lock held until tx ends, so that capture,
+// void and expiry of the same hold are serialized.
+func (repo *GormHoldRepository) LockHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error) {
+	var gormHold GormHold
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&gormHold, "hold_id = ?", holdID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock hold: %w", result.Error)
+	}
+
+	return toDomainHold(&gormHold), nil
+}
+
+func (repo *GormHoldRepository) GetHoldByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.Hold, error) {
+	var gormHold GormHold
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormHold, "idempotency_key = ?", idempotencyKey)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get hold by idempotency key: %w", result.Error)
+	}
+
+	return toDomainHold(&gormHold), nil
+}
+
+// ListExpiredHolds returns up to limit authorized holds whose expiry is at or
+// before now, oldest first.
+func (repo *GormHoldRepository) ListExpiredHolds(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.Hold, error) {
+	var gormHolds []GormHold
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", string(domain.HoldStatusAuthorized), now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&gormHolds)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list expired holds: %w", result.Error)
+	}
+
+	holds := make([]domain.Hold, 0, len(gormHolds))
+	for i := range gormHolds {
+		holds = append(holds, *toDomainHold(&gormHolds[i]))
+	}
+	return holds, nil
+}
+
+func toDomainHold(gormHold *GormHold) *domain.Hold {
+	hold := &domain.Hold{
+		HoldID:               gormHold.HoldID,
+		SourceAccountID:      gormHold.SourceAccountID,
+		DestinationAccountID: gormHold.DestinationAccountID,
+		Amount:               gormHold.Amount,
+		Currency:             gormHold.Currency,
+		Status:               domain.HoldStatus(gormHold.Status),
+		CapturedAmount:       gormHold.CapturedAmount,
+		RequestFingerprint:   gormHold.RequestFingerprint,
+		ExpiresAt:            gormHold.ExpiresAt,
+		CreatedAt:            gormHold.CreatedAt,
+		UpdatedAt:            gormHold.UpdatedAt,
+	}
+	if gormHold.IdempotencyKey != nil {
+		hold.IdempotencyKey = *gormHold.IdempotencyKey
+	}
+	if gormHold.CaptureTransferID != nil {
+		hold.CaptureTransferID = *gormHold.CaptureTransferID
+	}
+	return hold
+}
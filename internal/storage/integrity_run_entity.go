@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+type GormIntegrityRun struct {
+	RunID      uint                   `gorm:"primaryKey;autoIncrement"`
+	Status     domain.IntegrityStatus `gorm:"type:varchar(20);not null;index"`
+	Checks     []byte                 `gorm:"type:jsonb;not null"`
+	StartedAt  time.Time              `gorm:"not null"`
+	FinishedAt time.Time              `gorm:"not null"`
+}
+
+func (GormIntegrityRun) TableName() string {
+	return "integrity_runs"
+}
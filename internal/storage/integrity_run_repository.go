@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormIntegrityRunRepository struct {
+	db *gorm.DB
+}
+
+func NewGormIntegrityRunRepository(db *gorm.DB) *GormIntegrityRunRepository {
+	return &GormIntegrityRunRepository{db: db}
+}
+
+func (repo *GormIntegrityRunRepository) SaveIntegrityRun(ctx context.Context, tx *gorm.DB, run *domain.IntegrityRun) error {
+	checks, err := json.Marshal(run.Checks)
+	if err != nil {
+		return fmt.Errorf("failed to encode integrity run checks: %w", err)
+	}
+
+	gormRun := GormIntegrityRun{
+		Status:     run.Status,
+		Checks:     checks,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormRun)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save integrity run: %w", result.Error)
+	}
+
+	run.RunID = gormRun.RunID
+	return nil
+}
+
+func (repo *GormIntegrityRunRepository) GetIntegrityRun(ctx context.Context, tx *gorm.DB, runID uint) (*domain.IntegrityRun, error) {
+	var gormRun GormIntegrityRun
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormRun, "run_id = ?", runID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get integrity run: %w", result.Error)
+	}
+
+	return toDomainIntegrityRun(&gormRun)
+}
+
+// ListIntegrityRuns returns the runs matching the query, most recent first.
+func (repo *GormIntegrityRunRepository) ListIntegrityRuns(ctx context.Context, tx *gorm.DB, query domain.IntegrityRunQuery) ([]domain.IntegrityRun, error) {
+	var gormRuns []GormIntegrityRun
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Model(&GormIntegrityRun{})
+	if query.Status != "" {
+		q = q.Where("status = ?", string(query.Status))
+	}
+
+	result := q.Order("run_id DESC").Limit(query.Limit).Find(&gormRuns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list integrity runs: %w", result.Error)
+	}
+
+	runs := make([]domain.IntegrityRun, 0, len(gormRuns))
+	for i := range gormRuns {
+		run, err := toDomainIntegrityRun(&gormRuns[i])
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, nil
+}
+
+func toDomainIntegrityRun(r *GormIntegrityRun) (*domain.IntegrityRun, error) {
+	var checks []domain.IntegrityCheckResult
+	if err := json.Unmarshal(r.Checks, &checks); err != nil {
+		return nil, fmt.Errorf("failed to decode checks of integrity run %d: %w", r.RunID, err)
+	}
+
+	return &domain.IntegrityRun{
+		RunID:      r.RunID,
+		Status:     r.Status,
+		Checks:     checks,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}, nil
+}
@@ -8,13 +8,17 @@ import (
 )
 
 type GormJournalEntry struct {
-	EntryID       uint             `gorm:"primaryKey;autoIncrement"`
+	EntryID       uint             `gorm:"primaryKey;autoIncrement;index:idx_journal_entries_account_history,priority:3;index:idx_journal_entries_event_order,priority:2;index:idx_journal_entries_unsealed,where:chain_seq IS NULL"`
 	TransactionID string           `gorm:"type:varchar(36);not null;index"`
-	AccountID     uint             `gorm:"not null;index"`
+	AccountID     uint             `gorm:"not null;index:idx_journal_entries_account_history,priority:1"`
 	Amount        decimal.Decimal  `gorm:"type:numeric(20,8);not null"`
+	Currency      string           `gorm:"type:char(3);not null;default:'USD'"`
 	Type          domain.EntryType `gorm:"type:varchar(50);not null"`
-	SourceEventID uint             `gorm:"not null"`
-	CreatedAt     time.Time        `gorm:"not null"`
+	SourceEventID uint             `gorm:"not null;index:idx_journal_entries_event_order,priority:1"`
+	CreatedAt     time.Time        `gorm:"not null;index:idx_journal_entries_account_history,priority:2"`
+	ChainSeq      *uint            `gorm:"uniqueIndex"`
+	PrevHash      string           `gorm:"type:varchar(64);not null;default:''"`
+	Hash          string           `gorm:"type:varchar(64);not null;default:''"`
 }
 
 func (GormJournalEntry) TableName() string {
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
 	"github.com/shopspring/decimal"
@@ -17,33 +18,411 @@ func NewGormJournalRepository(db *gorm.DB) *GormJournalRepository {
 	return &GormJournalRepository{db: db}
 }
 
+// SaveJournalEntry stores the entry unsealed, after bringing its amount and
+// time to the precision of their columns.
 func (repo *GormJournalRepository) SaveJournalEntry(ctx context.Context, tx *gorm.DB, entry *domain.JournalEntry) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	entry.Amount = entry.Amount.Round(8)
+	entry.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+
 	gormEntry := GormJournalEntry{
 		EntryID:       entry.EntryID,
 		TransactionID: entry.TransactionID,
 		AccountID:     entry.AccountID,
 		Amount:        entry.Amount,
+		Currency:      entry.Currency,
 		Type:          entry.Type,
 		SourceEventID: entry.SourceEventID,
 		CreatedAt:     entry.CreatedAt,
 	}
 
+	result := db.WithContext(ctx).Create(&gormEntry)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save journal entry: %w", result.Error)
+	}
+
+	entry.EntryID = gormEntry.EntryID
+	return nil
+}
+
+// ListJournalEntriesAfter returns up to limit journal entries with an ID
+// greater than afterEntryID, in ID order.
+func (repo *GormJournalRepository) ListJournalEntriesAfter(ctx context.Context, tx *gorm.DB, afterEntryID uint, limit int) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
 	db := repo.db
 	if tx != nil {
 		db = tx
 	}
 
-	result := db.WithContext(ctx).Create(&gormEntry)
+	result := db.WithContext(ctx).
+		Where("entry_id > ?", afterEntryID).
+		Order("entry_id").
+		Limit(limit).
+		Find(&gormEntries)
 	if result.Error != nil {
-		return fmt.Errorf("failed to save journal entry: %w", result.Error)
+		return nil, fmt.Errorf("failed to list journal entries: %w", result.Error)
 	}
-	return nil
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListSealedJournalEntries returns up to limit sealed journal entries after
+// afterChainSeq, in chain order.
+func (repo *GormJournalRepository) ListSealedJournalEntries(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("chain_seq > ?", afterChainSeq).
+		Order("chain_seq").
+		Limit(limit).
+		Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list sealed journal entries: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+func (repo *GormJournalRepository) GetJournalEntriesByTransactionID(ctx context.Context, tx *gorm.DB, transactionID string) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("entry_id").
+		Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get journal entries by transaction ID: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListJournalEntriesByTransactionIDs returns the journal entries of all the
+// given transactions, ordered by entry ID.
+func (repo *GormJournalRepository) ListJournalEntriesByTransactionIDs(ctx context.Context, tx *gorm.DB, transactionIDs []string) ([]domain.JournalEntry, error) {
+	if len(transactionIDs) == 0 {
+		return []domain.JournalEntry{}, nil
+	}
+
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("transaction_id IN ?", transactionIDs).
+		Order("entry_id").
+		Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list journal entries by transaction IDs: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListOrphanedJournalEntries returns up to limit journal entries, in entry
+// order, whose source event is not in transfer_events.
+func (repo *GormJournalRepository) ListOrphanedJournalEntries(ctx context.Context, tx *gorm.DB, limit int) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM transfer_events WHERE transfer_events.event_id = journal_entries.source_event_id)").
+		Order("entry_id").
+		Limit(limit).
+		Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list orphaned journal entries: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListJournalEntriesWithMissingAccount returns up to limit journal entries, in
+// entry order, whose account is not in accounts.
+func (repo *GormJournalRepository) ListJournalEntriesWithMissingAccount(ctx context.Context, tx *gorm.DB, limit int) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM accounts WHERE accounts.id = journal_entries.account_id)").
+		Order("entry_id").
+		Limit(limit).
+		Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list journal entries with a missing account: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListJournalEntriesInEventOrder returns up to limit journal entries ordered by
+// the event that produced them, then by entry ID, starting after the given
+// position. It is used to replay the journal event by event.
+func (repo *GormJournalRepository) ListJournalEntriesInEventOrder(ctx context.Context, tx *gorm.DB, afterEventID, afterEntryID uint, limit int) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("(source_event_id, entry_id) > (?, ?)", afterEventID, afterEntryID).
+		Order("source_event_id").
+		Order("entry_id").
+		Limit(limit).
+		Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list journal entries in event order: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListJournalEntriesInEventRange returns the journal entries posted by the
+// transfer events in the range, ordered by event then by entry ID.
+func (repo *GormJournalRepository) ListJournalEntriesInEventRange(ctx context.Context, tx *gorm.DB, r domain.JournalEventRange) ([]domain.JournalEntry, error) {
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Where("source_event_id > ? AND source_event_id <= ?", r.AfterEventID, r.UpToEventID)
+	if r.AccountID != 0 {
+		q = q.Where("account_id = ?", r.AccountID)
+	}
+
+	result := q.Order("source_event_id").Order("entry_id").Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list journal entries in event range: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListJournalEntriesOfEvents returns the journal entries posted by the given
+// transfer events, ordered by entry ID. A non-zero accountID keeps the entries
+// of that account only.
+func (repo *GormJournalRepository) ListJournalEntriesOfEvents(ctx context.Context, tx *gorm.DB, eventIDs []uint, accountID uint) ([]domain.JournalEntry, error) {
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+
+	var gormEntries []GormJournalEntry
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Where("source_event_id IN ?", eventIDs)
+	if accountID != 0 {
+		q = q.Where("account_id = ?", accountID)
+	}
+
+	result := q.Order("entry_id").Find(&gormEntries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list journal entries of events: %w", result.Error)
+	}
+
+	entries := make([]domain.JournalEntry, 0, len(gormEntries))
+	for _, e := range gormEntries {
+		entries = append(entries, toDomainJournalEntry(&e))
+	}
+
+	return entries, nil
+}
+
+// ListAccountJournalEntries returns the account's journal legs newest first,
+// walking the (account_id, created_at, entry_id) index with a keyset cursor so
+// that deep pages cost the same as the first one.
+func (repo *GormJournalRepository) ListAccountJournalEntries(ctx context.Context, tx *gorm.DB, query domain.AccountJournalQuery) ([]domain.AccountJournalEntry, error) {
+	var rows []struct {
+		GormJournalEntry
+		CounterpartyAccountID uint
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).
+		Table("journal_entries AS je").
+		Select(`je.*, COALESCE((SELECT MIN(o.account_id) FROM journal_entries o
+			WHERE o.transaction_id = je.transaction_id AND o.type <> je.type), 0) AS counterparty_account_id`).
+		Where("je.account_id = ?", query.AccountID)
+
+	if query.From != nil {
+		q = q.Where("je.created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		q = q.Where("je.created_at < ?", *query.To)
+	}
+	if query.Before != nil {
+		q = q.Where("(je.created_at, je.entry_id) < (?, ?)", query.Before.CreatedAt, query.Before.EntryID)
+	}
+
+	result := q.Order("je.created_at DESC, je.entry_id DESC").Limit(query.Limit).Find(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list account journal entries: %w", result.Error)
+	}
+
+	entries := make([]domain.AccountJournalEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, domain.AccountJournalEntry{
+			JournalEntry:          toDomainJournalEntry(&r.GormJournalEntry),
+			CounterpartyAccountID: r.CounterpartyAccountID,
+		})
+	}
+
+	return entries, nil
+}
+
+// GetAccountNetChangeAfter sums credits minus debits of the account's journal
+// legs strictly newer than the cursor.
+func (repo *GormJournalRepository) GetAccountNetChangeAfter(ctx context.Context, tx *gorm.DB, accountID uint, cursor domain.JournalEntryCursor) (decimal.Decimal, error) {
+	var net decimal.NullDecimal
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Model(&GormJournalEntry{}).
+		Select("SUM(CASE WHEN type = ? THEN amount ELSE -amount END)", domain.Credit).
+		Where("account_id = ? AND (created_at, entry_id) > (?, ?)", accountID, cursor.CreatedAt, cursor.EntryID).
+		Scan(&net)
+	if result.Error != nil {
+		return decimal.Zero, fmt.Errorf("failed to get account net change: %w", result.Error)
+	}
+
+	if !net.Valid {
+		return decimal.Zero, nil
+	}
+	return net.Decimal, nil
 }
 
-func (repo *GormJournalRepository) GetTotalsByEntryType(ctx context.Context, tx *gorm.DB) (map[domain.EntryType]decimal.Decimal, error) {
+// SumAccountJournalEntries sums credits minus debits of the account's journal
+// legs in the range.
+func (repo *GormJournalRepository) SumAccountJournalEntries(ctx context.Context, tx *gorm.DB, r domain.AccountJournalRange) (*domain.JournalSum, error) {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).
+		Model(&GormJournalEntry{}).
+		Select("SUM(CASE WHEN type = ? THEN amount ELSE -amount END) AS net, COUNT(*) AS count, MAX(source_event_id) AS last_event_id", domain.Credit).
+		Where("account_id = ? AND source_event_id > ?", r.AccountID, r.AfterEventID)
+	if r.Until.Time != nil {
+		q = q.Where("created_at <= ?", *r.Until.Time)
+	}
+	if r.Until.EventID != nil {
+		q = q.Where("source_event_id <= ?", *r.Until.EventID)
+	}
+	if r.AfterChainSeq != nil {
+		q = q.Where("source_event_id IN (?)", db.Model(&GormTransferEvent{}).
+			Select("event_id").
+			Where("chain_seq IS NULL OR chain_seq > ?", *r.AfterChainSeq))
+	}
+
+	var totals struct {
+		Net         decimal.NullDecimal
+		Count       int
+		LastEventID *uint
+	}
+	result := q.Scan(&totals)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to sum account journal entries: %w", result.Error)
+	}
+
+	sum := &domain.JournalSum{Net: decimal.Zero, Count: totals.Count}
+	if totals.Count > 0 {
+		sum.Net = totals.Net.Decimal
+		sum.LastEventID = *totals.LastEventID
+	}
+
+	return sum, nil
+}
+
+// SumJournalEntriesByAccount aggregates the journal entries of every account
+// that has any.
+func (repo *GormJournalRepository) SumJournalEntriesByAccount(ctx context.Context, tx *gorm.DB) (map[uint]domain.JournalSum, error) {
 	var results []struct {
-		Type  domain.EntryType
-		Total decimal.Decimal
+		AccountID   uint
+		Net         decimal.Decimal
+		Count       int
+		LastEventID uint
 	}
 
 	db := repo.db
@@ -53,18 +432,94 @@ func (repo *GormJournalRepository) GetTotalsByEntryType(ctx context.Context, tx
 
 	result := db.WithContext(ctx).
 		Model(&GormJournalEntry{}).
-		Select("type, SUM(amount) as total").
-		Group("type").
+		Select("account_id, SUM(CASE WHEN type = ? THEN amount ELSE -amount END) AS net, COUNT(*) AS count, MAX(source_event_id) AS last_event_id", domain.Credit).
+		Group("account_id").
+		Find(&results)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to sum journal entries by account: %w", result.Error)
+	}
+
+	sums := make(map[uint]domain.JournalSum, len(results))
+	for _, r := range results {
+		sums[r.AccountID] = domain.JournalSum{Net: r.Net, Count: r.Count, LastEventID: r.LastEventID}
+	}
+
+	return sums, nil
+}
+
+// GetLastSourceEventIDBefore returns the highest transfer event ID among the
+// journal entries created at or before cutoff, or 0 when there is none.
+func (repo *GormJournalRepository) GetLastSourceEventIDBefore(ctx context.Context, tx *gorm.DB, cutoff time.Time) (uint, error) {
+	var lastEventID *uint
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Model(&GormJournalEntry{}).
+		Select("MAX(source_event_id)").
+		Where("created_at <= ?", cutoff).
+		Scan(&lastEventID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get last source event ID: %w", result.Error)
+	}
+
+	if lastEventID == nil {
+		return 0, nil
+	}
+	return *lastEventID, nil
+}
+
+func (repo *GormJournalRepository) GetTotalsByCurrencyAndEntryType(ctx context.Context, tx *gorm.DB) (map[string]map[domain.EntryType]decimal.Decimal, error) {
+	var results []struct {
+		Currency string
+		Type     domain.EntryType
+		Total    decimal.Decimal
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Model(&GormJournalEntry{}).
+		Select("currency, type, SUM(amount) as total").
+		Group("currency, type").
 		Find(&results)
 
 	if result.Error != nil {
-		return nil, fmt.Errorf("failed to get totals by entry type: %w", result.Error)
+		return nil, fmt.Errorf("failed to get totals by currency and entry type: %w", result.Error)
 	}
 
-	totals := make(map[domain.EntryType]decimal.Decimal)
+	totals := make(map[string]map[domain.EntryType]decimal.Decimal)
 	for _, r := range results {
-		totals[r.Type] = r.Total
+		if totals[r.Currency] == nil {
+			totals[r.Currency] = make(map[domain.EntryType]decimal.Decimal)
+		}
+		totals[r.Currency][r.Type] = r.Total
 	}
 
 	return totals, nil
 }
+
+func toDomainJournalEntry(gormEntry *GormJournalEntry) domain.JournalEntry {
+	entry := domain.JournalEntry{
+		EntryID:       gormEntry.EntryID,
+		TransactionID: gormEntry.TransactionID,
+		AccountID:     gormEntry.AccountID,
+		Amount:        gormEntry.Amount,
+		Currency:      gormEntry.Currency,
+		Type:          gormEntry.Type,
+		SourceEventID: gormEntry.SourceEventID,
+		CreatedAt:     gormEntry.CreatedAt,
+		PrevHash:      gormEntry.PrevHash,
+		Hash:          gormEntry.Hash,
+	}
+	if gormEntry.ChainSeq != nil {
+		entry.ChainSeq = *gormEntry.ChainSeq
+	}
+	return entry
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+)
+
+type GormOutboxMessage struct {
+	MessageID       uint                `gorm:"primaryKey;autoIncrement;index:idx_outbox_messages_status,priority:2"`
+	TransferEventID *uint               `gorm:"uniqueIndex"`
+	EventType       string              `gorm:"type:varchar(50);not null"`
+	AccountIDs      []byte              `gorm:"type:jsonb;not null"`
+	Payload         []byte              `gorm:"type:jsonb;not null"`
+	Status          domain.OutboxStatus `gorm:"type:varchar(20);not null;index:idx_outbox_messages_status,priority:1"`
+	Attempts        int                 `gorm:"not null;default:0"`
+	NextAttemptAt   time.Time           `gorm:"not null"`
+	LastError       string              `gorm:"type:text"`
+	CreatedAt       time.Time           `gorm:"not null"`
+	PublishedAt     *time.Time
+}
+
+func (GormOutboxMessage) TableName() string {
+	return "outbox_messages"
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepository(db *gorm.DB) *GormOutboxRepository {
+	return &GormOutboxRepository{db: db}
+}
+
+func (repo *GormOutboxRepository) SaveOutboxMessage(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage) error {
+	accountIDs, err := json.Marshal(message.AccountIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message accounts: %w", err)
+	}
+
+	gormMessage := GormOutboxMessage{
+		TransferEventID: message.TransferEventID,
+		EventType:       message.EventType,
+		AccountIDs:      accountIDs,
+		Payload:         message.Payload,
+		Status:          message.Status,
+		Attempts:        message.Attempts,
+		NextAttemptAt:   message.NextAttemptAt,
+		LastError:       message.LastError,
+		CreatedAt:       message.CreatedAt,
+		PublishedAt:     message.PublishedAt,
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormMessage)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save outbox message: %w", result.Error)
+	}
+
+	message.MessageID = gormMessage.MessageID
+	return nil
+}
+
+// TryLockOutboxRelay takes an advisory lock serializing outbox relays until the
+// end of tx, so that several replicas never publish messages out of order. It
+// returns false when another relay holds it.
+func (repo *GormOutboxRepository) TryLockOutboxRelay(ctx context.Context, tx *gorm.DB) (bool, error) {
+	var locked bool
+	result := tx.WithContext(ctx).Raw("SELECT pg_try_advisory_xact_lock(hashtext('outbox_relay'))").Scan(&locked)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to lock outbox relay: %w", result.Error)
+	}
+	return locked, nil
+}
+
+// ListPendingOutboxMessages returns the oldest limit pending messages due at
+// now, together with every pending message not due yet, which holds up the
+// later messages of its accounts, in publication order.
+func (repo *GormOutboxRepository) ListPendingOutboxMessages(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	var gormMessages []GormOutboxMessage
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	due := db.Model(&GormOutboxMessage{}).
+		Select("message_id").
+		Where("status = ? AND next_attempt_at <= ?", domain.OutboxStatusPending, now).
+		Order("message_id").
+		Limit(limit)
+
+	result := db.WithContext(ctx).
+		Where("status = ? AND (next_attempt_at > ? OR message_id IN (?))", domain.OutboxStatusPending, now, due).
+		Order("message_id").
+		Find(&gormMessages)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list pending outbox messages: %w", result.Error)
+	}
+
+	messages := make([]domain.OutboxMessage, 0, len(gormMessages))
+	for i := range gormMessages {
+		message, err := toDomainOutboxMessage(&gormMessages[i])
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+	return messages, nil
+}
+
+// UpdateOutboxMessageDelivery records the outcome of a delivery attempt.
+func (repo *GormOutboxRepository) UpdateOutboxMessageDelivery(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Model(&GormOutboxMessage{}).
+		Where("message_id = ?", message.MessageID).
+		Updates(map[string]interface{}{
+			"status":          string(message.Status),
+			"attempts":        message.Attempts,
+			"next_attempt_at": message.NextAttemptAt,
+			"last_error":      message.LastError,
+			"published_at":    message.PublishedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update outbox message: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("outbox message %d not found", message.MessageID)
+	}
+	return nil
+}
+
+func toDomainOutboxMessage(m *GormOutboxMessage) (*domain.OutboxMessage, error) {
+	var accountIDs []uint
+	if err := json.Unmarshal(m.AccountIDs, &accountIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode accounts of outbox message %d: %w", m.MessageID, err)
+	}
+
+	return &domain.OutboxMessage{
+		MessageID:       m.MessageID,
+		TransferEventID: m.TransferEventID,
+		EventType:       m.EventType,
+		AccountIDs:      accountIDs,
+		Payload:         m.Payload,
+		Status:          m.Status,
+		Attempts:        m.Attempts,
+		NextAttemptAt:   m.NextAttemptAt,
+		LastError:       m.LastError,
+		CreatedAt:       m.CreatedAt,
+		PublishedAt:     m.PublishedAt,
+	}, nil
+}
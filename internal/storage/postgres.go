@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
@@ -29,6 +30,7 @@ func NewPostgresDB(cfg Config, appLogger *slog.Logger) (*gorm.DB, error) {
 		Logger:                 logger.Default.LogMode(logger.Warn),
 		SkipDefaultTransaction: true,
 		PrepareStmt:            true,
+		TranslateError:         true,
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
@@ -37,10 +39,20 @@ func NewPostgresDB(cfg Config, appLogger *slog.Logger) (*gorm.DB, error) {
 	}
 
 	appLogger.Info("Running database migrations...")
-	err = db.AutoMigrate(&GormAccount{}, &GormTransferEvent{}, &GormJournalEntry{}, &GormAccountBalance{})
+	err = db.AutoMigrate(&GormAccount{}, &GormTransferEvent{}, &GormJournalEntry{}, &GormAccountBalance{}, &GormHold{}, &GormScheduledTransfer{}, &GormStandingOrder{}, &GormStandingOrderExecution{}, &GormStoredEvent{}, &GormAccountSnapshot{}, &GormOutboxMessage{}, &GormWebhookSubscription{}, &GormWebhookDelivery{}, &GormWebhookDeliveryAttempt{}, &GormHashChainHead{}, &GormIntegrityRun{}, &GormProjectionRepair{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to auto migrate database: %w", err)
 	}
+	if err := InitHashChains(context.Background(), db); err != nil {
+		return nil, fmt.Errorf("failed to initialize hash chains: %w", err)
+	}
+	backfilled, err := BackfillAccountOpenedEvents(context.Background(), db)
+	if err != nil {
+		return nil, err
+	}
+	if backfilled > 0 {
+		appLogger.Info("Backfilled account opening events", "count", backfilled)
+	}
 	appLogger.Info("Database migrations completed.")
 
 	return db, nil
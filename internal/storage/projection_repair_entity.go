@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GormProjectionRepair struct {
+	RepairID       uint            `gorm:"primaryKey;autoIncrement"`
+	AccountID      uint            `gorm:"not null;index"`
+	Reason         string          `gorm:"type:varchar(50);not null"`
+	OldBalance     decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	NewBalance     decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	OldLastEventID uint            `gorm:"not null"`
+	NewLastEventID uint            `gorm:"not null"`
+	OldVersion     int             `gorm:"not null"`
+	NewVersion     int             `gorm:"not null"`
+	Operator       string          `gorm:"type:varchar(255);not null"`
+	RepairedAt     time.Time       `gorm:"not null"`
+}
+
+func (GormProjectionRepair) TableName() string {
+	return "projection_repairs"
+}
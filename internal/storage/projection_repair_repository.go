@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormProjectionRepairRepository struct {
+	db *gorm.DB
+}
+
+func NewGormProjectionRepairRepository(db *gorm.DB) *GormProjectionRepairRepository {
+	return &GormProjectionRepairRepository{db: db}
+}
+
+func (repo *GormProjectionRepairRepository) SaveProjectionRepair(ctx context.Context, tx *gorm.DB, repair *domain.ProjectionRepair) error {
+	gormRepair := GormProjectionRepair{
+		AccountID:      repair.AccountID,
+		Reason:         repair.Reason,
+		OldBalance:     repair.OldBalance,
+		NewBalance:     repair.NewBalance,
+		OldLastEventID: repair.OldLastEventID,
+		NewLastEventID: repair.NewLastEventID,
+		OldVersion:     repair.OldVersion,
+		NewVersion:     repair.NewVersion,
+		Operator:       repair.Operator,
+		RepairedAt:     repair.RepairedAt,
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormRepair)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save projection repair: %w", result.Error)
+	}
+
+	repair.RepairID = gormRepair.RepairID
+	return nil
+}
+
+// ListProjectionRepairs returns the repairs matching the query, most recent
+// first.
+func (repo *GormProjectionRepairRepository) ListProjectionRepairs(ctx context.Context, tx *gorm.DB, query domain.ProjectionRepairQuery) ([]domain.ProjectionRepair, error) {
+	var gormRepairs []GormProjectionRepair
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Model(&GormProjectionRepair{})
+	if query.AccountID != 0 {
+		q = q.Where("account_id = ?", query.AccountID)
+	}
+
+	result := q.Order("repair_id DESC").Limit(query.Limit).Find(&gormRepairs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list projection repairs: %w", result.Error)
+	}
+
+	repairs := make([]domain.ProjectionRepair, 0, len(gormRepairs))
+	for _, r := range gormRepairs {
+		repairs = append(repairs, domain.ProjectionRepair{
+			RepairID:       r.RepairID,
+			AccountID:      r.AccountID,
+			Reason:         r.Reason,
+			OldBalance:     r.OldBalance,
+			NewBalance:     r.NewBalance,
+			OldLastEventID: r.OldLastEventID,
+			NewLastEventID: r.NewLastEventID,
+			OldVersion:     r.OldVersion,
+			NewVersion:     r.NewVersion,
+			Operator:       r.Operator,
+			RepairedAt:     r.RepairedAt,
+		})
+	}
+	return repairs, nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+)
+
+const accountBalancesRebuildTable = "account_balances_rebuild"
+
+// GormProjectionRepository manages the rebuild of the account_balances
+// projection. Every method needs a transaction: the rebuild table only lives
+// until that transaction ends.
+type GormProjectionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormProjectionRepository(db *gorm.DB) *GormProjectionRepository {
+	return &GormProjectionRepository{db: db}
+}
+
+// LockAccountBalances blocks every write to account_balances until the
+// transaction ends, while still letting readers through. Postings update
+// balances in the same transaction as their events, so no new event can
+// commit while the lock is held.
+func (repo *GormProjectionRepository) LockAccountBalances(ctx context.Context, tx *gorm.DB) error {
+	if tx == nil {
+		return errors.New("locking account balances requires a transaction")
+	}
+
+	result := tx.WithContext(ctx).Exec("LOCK TABLE account_balances IN EXCLUSIVE MODE")
+	if result.Error != nil {
+		return fmt.Errorf("failed to lock account balances: %w", result.Error)
+	}
+	return nil
+}
+
+// CreateAccountBalancesRebuildTable creates an empty temporary copy of
+// account_balances, dropped when the transaction commits.
+func (repo *GormProjectionRepository) CreateAccountBalancesRebuildTable(ctx context.Context, tx *gorm.DB) error {
+	if tx == nil {
+		return errors.New("creating the rebuild table requires a transaction")
+	}
+
+	result := tx.WithContext(ctx).Exec("CREATE TEMPORARY TABLE " + accountBalancesRebuildTable + " (LIKE account_balances INCLUDING DEFAULTS) ON COMMIT DROP")
+	if result.Error != nil {
+		return fmt.Errorf("failed to create rebuild table: %w", result.Error)
+	}
+	return nil
+}
+
+func (repo *GormProjectionRepository) SaveRebuiltAccountBalances(ctx context.Context, tx *gorm.DB, balances []domain.AccountBalance) error {
+	if tx == nil {
+		return errors.New("saving rebuilt balances requires a transaction")
+	}
+	if len(balances) == 0 {
+		return nil
+	}
+
+	gormBalances := make([]GormAccountBalance, 0, len(balances))
+	for _, b := range balances {
+		gormBalances = append(gormBalances, GormAccountBalance{
+			AccountID:   b.AccountID,
+			Balance:     b.Balance,
+			HeldAmount:  b.HeldAmount,
+			Version:     b.Version,
+			LastEventID: b.LastEventID,
+			UpdatedAt:   b.UpdatedAt,
+		})
+	}
+
+	result := tx.WithContext(ctx).Table(accountBalancesRebuildTable).CreateInBatches(&gormBalances, 500)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save rebuilt account balances: %w", result.Error)
+	}
+	return nil
+}
+
+// SwapRebuiltAccountBalances replaces the content of account_balances with the
+// rebuild table. Readers keep seeing the previous balances until the
+// transaction commits.
+func (repo *GormProjectionRepository) SwapRebuiltAccountBalances(ctx context.Context, tx *gorm.DB) error {
+	if tx == nil {
+		return errors.New("swapping account balances requires a transaction")
+	}
+
+	db := tx.WithContext(ctx)
+	result := db.Exec("DELETE FROM account_balances")
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear account balances: %w", result.Error)
+	}
+
+	result = db.Exec(`INSERT INTO account_balances (account_id, balance, held_amount, version, last_event_id, updated_at)
+		SELECT account_id, balance, held_amount, version, last_event_id, updated_at FROM ` + accountBalancesRebuildTable)
+	if result.Error != nil {
+		return fmt.Errorf("failed to copy rebuilt account balances: %w", result.Error)
+	}
+	return nil
+}
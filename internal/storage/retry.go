@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxRetries = 3
+	baseDelay  = 10 * time.Millisecond
+)
+
+// RunInTransactionWithRetry runs process in its own database transaction,
+// retrying with exponential backoff when it loses an optimistic locking race.
+func RunInTransactionWithRetry[T any](ctx context.Context, db *gorm.DB, log *slog.Logger, process func(tx *gorm.DB) (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		var result T
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			result, err = process(tx)
+			return err
+		})
+
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+
+		if !IsRetryableError(err) {
+			return zero, err
+		}
+
+		if attempt == maxRetries-1 {
+			return zero, fmt.Errorf("transaction failed after %d attempts due to concurrent modifications: %w", maxRetries, lastErr)
+		}
+
+		delay := calculateBackoffDelay(attempt)
+		log.Debug("Retrying transaction after optimistic locking conflict",
+			"attempt", attempt+1,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return zero, fmt.Errorf("unexpected error: retry loop exited without return")
+}
+
+// IsRetryableError reports whether err stems from an optimistic locking
+// conflict, in which case the whole transaction can safely be run again.
+func IsRetryableError(err error) bool {
+	return strings.Contains(err.Error(), "optimistic locking failed")
+}
+
+func calculateBackoffDelay(attempt int) time.Duration {
+	return time.Duration(1<<attempt) * baseDelay
+}
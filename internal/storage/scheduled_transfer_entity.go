@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GormScheduledTransfer struct {
+	ScheduleID           string          `gorm:"type:varchar(36);primaryKey"`
+	SourceAccountID      uint            `gorm:"not null;index"`
+	DestinationAccountID uint            `gorm:"not null"`
+	Amount               decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	AllowConversion      bool            `gorm:"not null;default:false"`
+	ExecuteAt            time.Time       `gorm:"not null;index:idx_scheduled_transfers_status_execute_at,priority:2"`
+	Status               string          `gorm:"type:varchar(20);not null;index:idx_scheduled_transfers_status_execute_at,priority:1"`
+	TransferID           *string         `gorm:"type:varchar(36)"`
+	FailureReason        *string         `gorm:"type:text"`
+	ExecutedAt           *time.Time
+	Attempts             int     `gorm:"not null;default:0"`
+	LastError            *string `gorm:"type:text"`
+	NextAttemptAt        *time.Time
+	IdempotencyKey       *string   `gorm:"type:varchar(255);uniqueIndex"`
+	RequestFingerprint   string    `gorm:"type:varchar(64)"`
+	CreatedAt            time.Time `gorm:"not null"`
+	UpdatedAt            time.Time `gorm:"not null"`
+}
+
+func (GormScheduledTransfer) TableName() string {
+	return "scheduled_transfers"
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormScheduledTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewGormScheduledTransferRepository(db *gorm.DB) *GormScheduledTransferRepository {
+	return &GormScheduledTransferRepository{db: db}
+}
+
+func (repo *GormScheduledTransferRepository) SaveScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer) error {
+	gormScheduled := GormScheduledTransfer{
+		ScheduleID:           scheduled.ScheduleID,
+		SourceAccountID:      scheduled.SourceAccountID,
+		DestinationAccountID: scheduled.DestinationAccountID,
+		Amount:               scheduled.Amount,
+		AllowConversion:      scheduled.AllowConversion,
+		ExecuteAt:            scheduled.ExecuteAt,
+		Status:               string(scheduled.Status),
+		RequestFingerprint:   scheduled.RequestFingerprint,
+		CreatedAt:            scheduled.CreatedAt,
+		UpdatedAt:            scheduled.UpdatedAt,
+	}
+	if scheduled.IdempotencyKey != "" {
+		gormScheduled.IdempotencyKey = &scheduled.IdempotencyKey
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormScheduled)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) && gormScheduled.IdempotencyKey != nil {
+			return errors.New("optimistic locking failed: idempotency key was claimed by another transaction")
+		}
+		return fmt.Errorf("failed to save scheduled transfer: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateScheduledTransferStatus records the outcome of a scheduled transfer.
+// Callers are expected to hold the row lock taken by LockScheduledTransfer.
+func (repo *GormScheduledTransferRepository) UpdateScheduledTransferStatus(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	var transferID, failureReason, lastError *string
+	if scheduled.TransferID != "" {
+		transferID = &scheduled.TransferID
+	}
+	if scheduled.FailureReason != "" {
+		failureReason = &scheduled.FailureReason
+	}
+	if scheduled.LastError != "" {
+		lastError = &scheduled.LastError
+	}
+
+	result := db.WithContext(ctx).Model(&GormScheduledTransfer{}).
+		Where("schedule_id = ?", scheduled.ScheduleID).
+		Updates(map[string]interface{}{
+			"status":          string(scheduled.Status),
+			"transfer_id":     transferID,
+			"failure_reason":  failureReason,
+			"executed_at":     scheduled.ExecutedAt,
+			"attempts":        scheduled.Attempts,
+			"last_error":      lastError,
+			"next_attempt_at": scheduled.NextAttemptAt,
+			"updated_at":      scheduled.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update scheduled transfer: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("scheduled transfer %s not found", scheduled.ScheduleID)
+	}
+	return nil
+}
+
+func (repo *GormScheduledTransferRepository) GetScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error) {
+	var gormScheduled GormScheduledTransfer
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormScheduled, "schedule_id = ?", scheduleID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", result.Error)
+	}
+
+	return toDomainScheduledTransfer(&gormScheduled), nil
+}
+
+// LockScheduledTransfer reads the scheduled transfer with a row lock held until
+// tx ends, so that execution and cancellation never overlap.
+func (repo *GormScheduledTransferRepository) LockScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error) {
+	var gormScheduled GormScheduledTransfer
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&gormScheduled, "schedule_id = ?", scheduleID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock scheduled transfer: %w", result.Error)
+	}
+
+	return toDomainScheduledTransfer(&gormScheduled), nil
+}
+
+func (repo *GormScheduledTransferRepository) GetScheduledTransferByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.ScheduledTransfer, error) {
+	var gormScheduled GormScheduledTransfer
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormScheduled, "idempotency_key = ?", idempotencyKey)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scheduled transfer by idempotency key: %w", result.Error)
+	}
+
+	return toDomainScheduledTransfer(&gormScheduled), nil
+}
+
+// ListScheduledTransfers returns scheduled transfers matching the query in
+// execution order. Zero-valued filters are ignored.
+func (repo *GormScheduledTransferRepository) ListScheduledTransfers(ctx context.Context, tx *gorm.DB, query domain.ScheduledTransferQuery) ([]domain.ScheduledTransfer, error) {
+	var gormScheduled []GormScheduledTransfer
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Model(&GormScheduledTransfer{})
+	if query.Status != "" {
+		q = q.Where("status = ?", string(query.Status))
+	}
+	if query.SourceAccountID != 0 {
+		q = q.Where("source_account_id = ?", query.SourceAccountID)
+	}
+
+	result := q.Order("execute_at").Order("schedule_id").Limit(query.Limit).Find(&gormScheduled)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list scheduled transfers: %w", result.Error)
+	}
+
+	return toDomainScheduledTransfers(gormScheduled), nil
+}
+
+// ListDueScheduledTransfers returns up to limit pending transfers whose
+// execution time, or next attempt after a transient failure, is at or before
+// now, oldest first.
+func (repo *GormScheduledTransferRepository) ListDueScheduledTransfers(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	var gormScheduled []GormScheduledTransfer
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("status = ? AND COALESCE(next_attempt_at, execute_at) <= ?", string(domain.ScheduledTransferStatusPending), now).
+		Order("COALESCE(next_attempt_at, execute_at)").
+		Limit(limit).
+		Find(&gormScheduled)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list due scheduled transfers: %w", result.Error)
+	}
+
+	return toDomainScheduledTransfers(gormScheduled), nil
+}
+
+func toDomainScheduledTransfers(gormScheduled []GormScheduledTransfer) []domain.ScheduledTransfer {
+	scheduled := make([]domain.ScheduledTransfer, 0, len(gormScheduled))
+	for i := range gormScheduled {
+		scheduled = append(scheduled, *toDomainScheduledTransfer(&gormScheduled[i]))
+	}
+	return scheduled
+}
+
+func toDomainScheduledTransfer(gormScheduled *GormScheduledTransfer) *domain.ScheduledTransfer {
+	scheduled := &domain.ScheduledTransfer{
+		ScheduleID:           gormScheduled.ScheduleID,
+		SourceAccountID:      gormScheduled.SourceAccountID,
+		DestinationAccountID: gormScheduled.DestinationAccountID,
+		Amount:               gormScheduled.Amount,
+		AllowConversion:      gormScheduled.AllowConversion,
+		ExecuteAt:            gormScheduled.ExecuteAt,
+		Status:               domain.ScheduledTransferStatus(gormScheduled.Status),
+		ExecutedAt:           gormScheduled.ExecutedAt,
+		Attempts:             gormScheduled.Attempts,
+		NextAttemptAt:        gormScheduled.NextAttemptAt,
+		RequestFingerprint:   gormScheduled.RequestFingerprint,
+		CreatedAt:            gormScheduled.CreatedAt,
+		UpdatedAt:            gormScheduled.UpdatedAt,
+	}
+	if gormScheduled.TransferID != nil {
+		scheduled.TransferID = *gormScheduled.TransferID
+	}
+	if gormScheduled.FailureReason != nil {
+		scheduled.FailureReason = *gormScheduled.FailureReason
+	}
+	if gormScheduled.LastError != nil {
+		scheduled.LastError = *gormScheduled.LastError
+	}
+	if gormScheduled.IdempotencyKey != nil {
+		scheduled.IdempotencyKey = *gormScheduled.IdempotencyKey
+	}
+	return scheduled
+}
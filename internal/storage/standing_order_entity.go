@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type GormStandingOrder struct {
+	StandingOrderID      string          `gorm:"type:varchar(36);primaryKey"`
+	SourceAccountID      uint            `gorm:"not null;index"`
+	DestinationAccountID uint            `gorm:"not null"`
+	Amount               decimal.Decimal `gorm:"type:numeric(20,8);not null"`
+	Schedule             string          `gorm:"type:varchar(100);not null"`
+	StartAt              time.Time       `gorm:"not null"`
+	EndAt                *time.Time
+	NextRunAt            *time.Time `gorm:"index:idx_standing_orders_status_next_run_at,priority:2"`
+	Status               string     `gorm:"type:varchar(20);not null;index:idx_standing_orders_status_next_run_at,priority:1"`
+	Attempts             int        `gorm:"not null;default:0"`
+	LastError            *string    `gorm:"type:text"`
+	NextAttemptAt        *time.Time
+	CreatedAt            time.Time `gorm:"not null"`
+	UpdatedAt            time.Time `gorm:"not null"`
+}
+
+func (GormStandingOrder) TableName() string {
+	return "standing_orders"
+}
+
+type GormStandingOrderExecution struct {
+	ExecutionID     uint      `gorm:"primaryKey;autoIncrement"`
+	StandingOrderID string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_standing_order_executions_occurrence,priority:1"`
+	ScheduledFor    time.Time `gorm:"not null;uniqueIndex:idx_standing_order_executions_occurrence,priority:2"`
+	Status          string    `gorm:"type:varchar(20);not null"`
+	TransferID      *string   `gorm:"type:varchar(36)"`
+	FailureReason   *string   `gorm:"type:text"`
+	ExecutedAt      time.Time `gorm:"not null"`
+}
+
+func (GormStandingOrderExecution) TableName() string {
+	return "standing_order_executions"
+}
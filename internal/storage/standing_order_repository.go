@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormStandingOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewGormStandingOrderRepository(db *gorm.DB) *GormStandingOrderRepository {
+	return &GormStandingOrderRepository{db: db}
+}
+
+func (repo *GormStandingOrderRepository) SaveStandingOrder(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder) error {
+	gormOrder := toGormStandingOrder(order)
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormOrder)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save standing order: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateStandingOrder overwrites the mutable fields of the standing order.
+// Callers are expected to hold the row lock taken by LockStandingOrder or
+// ClaimStandingOrder.
+func (repo *GormStandingOrderRepository) UpdateStandingOrder(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	var lastError *string
+	if order.LastError != "" {
+		lastError = &order.LastError
+	}
+
+	result := db.WithContext(ctx).Model(&GormStandingOrder{}).
+		Where("standing_order_id = ?", order.StandingOrderID).
+		Updates(map[string]interface{}{
+			"amount":          order.Amount,
+			"schedule":        order.Schedule,
+			"end_at":          order.EndAt,
+			"next_run_at":     order.NextRunAt,
+			"attempts":        order.Attempts,
+			"last_error":      lastError,
+			"next_attempt_at": order.NextAttemptAt,
+			"status":          string(order.Status),
+			"updated_at":      order.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update standing order: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("standing order %s not found", order.StandingOrderID)
+	}
+	return nil
+}
+
+func (repo *GormStandingOrderRepository) GetStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	var gormOrder GormStandingOrder
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormOrder, "standing_order_id = ?", standingOrderID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get standing order: %w", result.Error)
+	}
+
+	return toDomainStandingOrder(&gormOrder), nil
+}
+
+// LockStandingOrder reads the standing order with a row lock held until tx
+// ends, waiting for any scheduler currently executing it.
+func (repo *GormStandingOrderRepository) LockStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	return repo.lockStandingOrder(ctx, tx, standingOrderID, clause.Locking{Strength: "UPDATE"})
+}
+
+// ClaimStandingOrder locks the standing order like LockStandingOrder but
+// returns nil instead of waiting when another transaction holds the lock, so
+// that scheduler replicas never execute the same standing order concurrently.
+func (repo *GormStandingOrderRepository) ClaimStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	return repo.lockStandingOrder(ctx, tx, standingOrderID, clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+}
+
+func (repo *GormStandingOrderRepository) lockStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, locking clause.Locking) (*domain.StandingOrder, error) {
+	var gormOrder GormStandingOrder
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Clauses(locking).
+		First(&gormOrder, "standing_order_id = ?", standingOrderID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock standing order: %w", result.Error)
+	}
+
+	return toDomainStandingOrder(&gormOrder), nil
+}
+
+// ListStandingOrders returns standing orders matching the query, oldest first.
+// Zero-valued filters are ignored.
+func (repo *GormStandingOrderRepository) ListStandingOrders(ctx context.Context, tx *gorm.DB, query domain.StandingOrderQuery) ([]domain.StandingOrder, error) {
+	var gormOrders []GormStandingOrder
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Model(&GormStandingOrder{})
+	if query.Status != "" {
+		q = q.Where("status = ?", string(query.Status))
+	}
+	if query.SourceAccountID != 0 {
+		q = q.Where("source_account_id = ?", query.SourceAccountID)
+	}
+
+	result := q.Order("created_at").Order("standing_order_id").Limit(query.Limit).Find(&gormOrders)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list standing orders: %w", result.Error)
+	}
+
+	return toDomainStandingOrders(gormOrders), nil
+}
+
+// ListDueStandingOrders returns up to limit active standing orders whose next
+// run, or next attempt after a transient failure, is at or before now, most
+// overdue first.
+func (repo *GormStandingOrderRepository) ListDueStandingOrders(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.StandingOrder, error) {
+	var gormOrders []GormStandingOrder
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("status = ? AND COALESCE(next_attempt_at, next_run_at) <= ?", string(domain.StandingOrderStatusActive), now).
+		Order("COALESCE(next_attempt_at, next_run_at)").
+		Limit(limit).
+		Find(&gormOrders)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list due standing orders: %w", result.Error)
+	}
+
+	return toDomainStandingOrders(gormOrders), nil
+}
+
+func (repo *GormStandingOrderRepository) SaveStandingOrderExecution(ctx context.Context, tx *gorm.DB, execution *domain.StandingOrderExecution) error {
+	gormExecution := GormStandingOrderExecution{
+		StandingOrderID: execution.StandingOrderID,
+		ScheduledFor:    execution.ScheduledFor,
+		Status:          string(execution.Status),
+		ExecutedAt:      execution.ExecutedAt,
+	}
+	if execution.TransferID != "" {
+		gormExecution.TransferID = &execution.TransferID
+	}
+	if execution.FailureReason != "" {
+		gormExecution.FailureReason = &execution.FailureReason
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormExecution)
+	if result.Error != nil {
+		// Another replica already recorded this activation; retrying rereads
+		// the standing order and finds it advanced.
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return errors.New("optimistic locking failed: standing order activation was executed by another transaction")
+		}
+		return fmt.Errorf("failed to save standing order execution: %w", result.Error)
+	}
+
+	execution.ExecutionID = gormExecution.ExecutionID
+	return nil
+}
+
+// ListStandingOrderExecutions returns up to limit executions of the standing
+// order, most recent activation first.
+func (repo *GormStandingOrderRepository) ListStandingOrderExecutions(ctx context.Context, tx *gorm.DB, standingOrderID string, limit int) ([]domain.StandingOrderExecution, error) {
+	var gormExecutions []GormStandingOrderExecution
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("standing_order_id = ?", standingOrderID).
+		Order("scheduled_for DESC").
+		Limit(limit).
+		Find(&gormExecutions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list standing order executions: %w", result.Error)
+	}
+
+	executions := make([]domain.StandingOrderExecution, 0, len(gormExecutions))
+	for _, gormExecution := range gormExecutions {
+		execution := domain.StandingOrderExecution{
+			ExecutionID:     gormExecution.ExecutionID,
+			StandingOrderID: gormExecution.StandingOrderID,
+			ScheduledFor:    gormExecution.ScheduledFor,
+			Status:          domain.StandingOrderExecutionStatus(gormExecution.Status),
+			ExecutedAt:      gormExecution.ExecutedAt,
+		}
+		if gormExecution.TransferID != nil {
+			execution.TransferID = *gormExecution.TransferID
+		}
+		if gormExecution.FailureReason != nil {
+			execution.FailureReason = *gormExecution.FailureReason
+		}
+		executions = append(executions, execution)
+	}
+	return executions, nil
+}
+
+func toGormStandingOrder(order *domain.StandingOrder) GormStandingOrder {
+	return GormStandingOrder{
+		StandingOrderID:      order.StandingOrderID,
+		SourceAccountID:      order.SourceAccountID,
+		DestinationAccountID: order.DestinationAccountID,
+		Amount:               order.Amount,
+		Schedule:             order.Schedule,
+		StartAt:              order.StartAt,
+		EndAt:                order.EndAt,
+		NextRunAt:            order.NextRunAt,
+		Status:               string(order.Status),
+		CreatedAt:            order.CreatedAt,
+		UpdatedAt:            order.UpdatedAt,
+	}
+}
+
+func toDomainStandingOrders(gormOrders []GormStandingOrder) []domain.StandingOrder {
+	orders := make([]domain.StandingOrder, 0, len(gormOrders))
+	for i := range gormOrders {
+		orders = append(orders, *toDomainStandingOrder(&gormOrders[i]))
+	}
+	return orders
+}
+
+func toDomainStandingOrder(gormOrder *GormStandingOrder) *domain.StandingOrder {
+	order := &domain.StandingOrder{
+		StandingOrderID:      gormOrder.StandingOrderID,
+		SourceAccountID:      gormOrder.SourceAccountID,
+		DestinationAccountID: gormOrder.DestinationAccountID,
+		Amount:               gormOrder.Amount,
+		Schedule:             gormOrder.Schedule,
+		StartAt:              gormOrder.StartAt,
+		EndAt:                gormOrder.EndAt,
+		NextRunAt:            gormOrder.NextRunAt,
+		Attempts:             gormOrder.Attempts,
+		NextAttemptAt:        gormOrder.NextAttemptAt,
+		Status:               domain.StandingOrderStatus(gormOrder.Status),
+		CreatedAt:            gormOrder.CreatedAt,
+		UpdatedAt:            gormOrder.UpdatedAt,
+	}
+	if gormOrder.LastError != nil {
+		order.LastError = *gormOrder.LastError
+	}
+	return order
+}
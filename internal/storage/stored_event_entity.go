@@ -0,0 +1,19 @@
+package storage
+
+import "time"
+
+type GormStoredEvent struct {
+	EventID       uint      `gorm:"primaryKey;autoIncrement"`
+	EventType     string    `gorm:"type:varchar(100);not null"`
+	AggregateType string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_events_stream,priority:1"`
+	AggregateID   string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_events_stream,priority:2"`
+	Sequence      int64     `gorm:"not null;uniqueIndex:idx_events_stream,priority:3"`
+	SchemaVersion int       `gorm:"not null;default:1"`
+	Payload       []byte    `gorm:"type:jsonb;not null"`
+	Metadata      []byte    `gorm:"type:jsonb"`
+	OccurredAt    time.Time `gorm:"not null"`
+}
+
+func (GormStoredEvent) TableName() string {
+	return "events"
+}
@@ -7,13 +7,27 @@ import (
 )
 
 type GormTransferEvent struct {
-	EventID       uint            `gorm:"primaryKey;autoIncrement"`
-	TransferID    string          `gorm:"type:varchar(36);not null"`
-	FromAccountID uint            `gorm:"not null"`
-	ToAccountID   uint            `gorm:"not null"`
-	Amount        decimal.Decimal `gorm:"type:numeric(20,8);not null"`
-	EventType     string          `gorm:"type:varchar(100);not null"`
-	CreatedAt     time.Time       `gorm:"not null"`
+	EventID            uint                `gorm:"primaryKey;autoIncrement;index:idx_transfer_events_unsealed,where:chain_seq IS NULL"`
+	TransferID         string              `gorm:"type:varchar(36);not null;index"`
+	FromAccountID      uint                `gorm:"not null"`
+	ToAccountID        uint                `gorm:"not null"`
+	Amount             decimal.Decimal     `gorm:"type:numeric(20,8);not null"`
+	Currency           string              `gorm:"type:char(3);not null;default:'USD'"`
+	EventType          string              `gorm:"type:varchar(100);not null"`
+	IdempotencyKey     *string             `gorm:"type:varchar(255);uniqueIndex"`
+	RequestFingerprint string              `gorm:"type:varchar(64)"`
+	FXRate             decimal.NullDecimal `gorm:"type:numeric(30,12)"`
+	FXRateSource       *string             `gorm:"type:varchar(100)"`
+	FXRateTimestamp    *time.Time
+	ConvertedAmount    decimal.NullDecimal `gorm:"type:numeric(20,8)"`
+	ConvertedCurrency  *string             `gorm:"type:char(3)"`
+	Legs               []byte              `gorm:"type:jsonb"`
+	ReversesTransferID *string             `gorm:"type:varchar(36);index"`
+	CreatedAt          time.Time           `gorm:"not null"`
+	ChainSeq           *uint               `gorm:"uniqueIndex"`
+	PrevHash           string              `gorm:"type:varchar(64);not null;default:''"`
+	Hash               string              `gorm:"type:varchar(64);not null;default:''"`
+	SchemaVersion      int                 `gorm:"not null;default:1"`
 }
 
 func (GormTransferEvent) TableName() string {
@@ -2,10 +2,15 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormTransferEventRepository struct {
@@ -16,27 +21,334 @@ func NewGormTransferEventRepository(db *gorm.DB) *GormTransferEventRepository {
 	return &GormTransferEventRepository{db: db}
 }
 
+// SaveTransferEvent stores the event unsealed, after bringing its amounts and
+// times to the precision of their columns. SealHashChain chains it once its
+// transaction commits.
 func (repo *GormTransferEventRepository) SaveTransferEvent(ctx context.Context, tx *gorm.DB, event *domain.TransferEvent) error {
-	gormEvent := GormTransferEvent{
-		EventID:       event.EventID,
-		TransferID:    event.TransferID,
-		FromAccountID: event.FromAccountID,
-		ToAccountID:   event.ToAccountID,
-		Amount:        event.Amount,
-		EventType:     event.EventType,
-		CreatedAt:     event.CreatedAt,
-	}
-
 	db := repo.db
 	if tx != nil {
 		db = tx
 	}
 
+	event.Amount = event.Amount.Round(8)
+	event.CreatedAt = event.CreatedAt.Truncate(time.Microsecond)
+	if c := event.Conversion; c != nil {
+		c.Rate = c.Rate.Round(12)
+		c.RateTimestamp = c.RateTimestamp.Truncate(time.Microsecond)
+		c.DestinationAmount = c.DestinationAmount.Round(8)
+	}
+
+	gormEvent := GormTransferEvent{
+		EventID:            event.EventID,
+		TransferID:         event.TransferID,
+		FromAccountID:      event.FromAccountID,
+		ToAccountID:        event.ToAccountID,
+		Amount:             event.Amount,
+		Currency:           event.Currency,
+		EventType:          event.EventType,
+		RequestFingerprint: event.RequestFingerprint,
+		CreatedAt:          event.CreatedAt,
+		SchemaVersion:      domain.TransferEventSchema.Version,
+	}
+	if event.IdempotencyKey != "" {
+		gormEvent.IdempotencyKey = &event.IdempotencyKey
+	}
+	if c := event.Conversion; c != nil {
+		gormEvent.FXRate = decimal.NewNullDecimal(c.Rate)
+		gormEvent.FXRateSource = &c.RateSource
+		gormEvent.FXRateTimestamp = &c.RateTimestamp
+		gormEvent.ConvertedAmount = decimal.NewNullDecimal(c.DestinationAmount)
+		gormEvent.ConvertedCurrency = &c.DestinationCurrency
+	}
+	if event.ReversesTransferID != "" {
+		gormEvent.ReversesTransferID = &event.ReversesTransferID
+	}
+	if len(event.Legs) > 0 {
+		legs, err := json.Marshal(event.Legs)
+		if err != nil {
+			return fmt.Errorf("failed to encode transfer legs: %w", err)
+		}
+		gormEvent.Legs = legs
+	}
+
 	result := db.WithContext(ctx).Create(&gormEvent)
 	if result.Error != nil {
+		// A concurrent request claimed the same idempotency key first. Surface it
+		// as a locking conflict so the caller retries and replays the winner.
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) && gormEvent.IdempotencyKey != nil {
+			return errors.New("optimistic locking failed: idempotency key was claimed by another transaction")
+		}
 		return fmt.Errorf("failed to save transfer event: %w", result.Error)
 	}
 
 	event.EventID = gormEvent.EventID
+	event.SchemaVersion = gormEvent.SchemaVersion
 	return nil
 }
+
+func (repo *GormTransferEventRepository) GetTransferEventByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.TransferEvent, error) {
+	var gormEvent GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).First(&gormEvent, "idempotency_key = ?", idempotencyKey)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transfer event by idempotency key: %w", result.Error)
+	}
+
+	return toDomainTransferEvent(&gormEvent)
+}
+
+func (repo *GormTransferEventRepository) GetTransferEventByTransferID(ctx context.Context, tx *gorm.DB, transferID string) (*domain.TransferEvent, error) {
+	var gormEvent GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Order("event_id").First(&gormEvent, "transfer_id = ?", transferID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transfer event by transfer ID: %w", result.Error)
+	}
+
+	return toDomainTransferEvent(&gormEvent)
+}
+
+// LockTransferEvent reads the transfer's event with a row lock held until tx
+// ends, serializing operations such as reversals on the same transfer.
+func (repo *GormTransferEventRepository) LockTransferEvent(ctx context.Context, tx *gorm.DB, transferID string) (*domain.TransferEvent, error) {
+	var gormEvent GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("event_id").
+		First(&gormEvent, "transfer_id = ?", transferID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock transfer event: %w", result.Error)
+	}
+
+	return toDomainTransferEvent(&gormEvent)
+}
+
+func (repo *GormTransferEventRepository) GetReversalsOfTransfer(ctx context.Context, tx *gorm.DB, transferID string) ([]domain.TransferEvent, error) {
+	var gormEvents []GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("reverses_transfer_id = ?", transferID).
+		Order("event_id").
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get reversals of transfer: %w", result.Error)
+	}
+
+	events := make([]domain.TransferEvent, 0, len(gormEvents))
+	for i := range gormEvents {
+		event, err := toDomainTransferEvent(&gormEvents[i])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, nil
+}
+
+// GetLastTransferEventChainSeq returns the chain sequence of the last sealed
+// transfer event, or 0 when none is sealed yet.
+func (repo *GormTransferEventRepository) GetLastTransferEventChainSeq(ctx context.Context, tx *gorm.DB) (uint, error) {
+	var lastChainSeq *uint
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Model(&GormTransferEvent{}).
+		Select("MAX(chain_seq)").
+		Scan(&lastChainSeq)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get last transfer event chain sequence: %w", result.Error)
+	}
+
+	if lastChainSeq == nil {
+		return 0, nil
+	}
+	return *lastChainSeq, nil
+}
+
+// ListTransferEventsInChainOrder returns up to limit sealed transfer events
+// after afterChainSeq, in chain order.
+func (repo *GormTransferEventRepository) ListTransferEventsInChainOrder(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.TransferEvent, error) {
+	var gormEvents []GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("chain_seq > ?", afterChainSeq).
+		Order("chain_seq").
+		Limit(limit).
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list transfer events in chain order: %w", result.Error)
+	}
+
+	events := make([]domain.TransferEvent, 0, len(gormEvents))
+	for i := range gormEvents {
+		event, err := toDomainTransferEvent(&gormEvents[i])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, nil
+}
+
+// ListTransferEventsAfter returns up to limit transfer events with an ID
+// greater than afterEventID, in ID order.
+func (repo *GormTransferEventRepository) ListTransferEventsAfter(ctx context.Context, tx *gorm.DB, afterEventID uint, limit int) ([]domain.TransferEvent, error) {
+	var gormEvents []GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("event_id > ?", afterEventID).
+		Order("event_id").
+		Limit(limit).
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list transfer events: %w", result.Error)
+	}
+
+	events := make([]domain.TransferEvent, 0, len(gormEvents))
+	for i := range gormEvents {
+		event, err := toDomainTransferEvent(&gormEvents[i])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, nil
+}
+
+// ListSealedTransferEvents returns up to limit sealed transfer events after
+// afterChainSeq, in chain order and as stored.
+func (repo *GormTransferEventRepository) ListSealedTransferEvents(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.TransferEvent, error) {
+	var gormEvents []GormTransferEvent
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("chain_seq > ?", afterChainSeq).
+		Order("chain_seq").
+		Limit(limit).
+		Find(&gormEvents)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list sealed transfer events: %w", result.Error)
+	}
+
+	events := make([]domain.TransferEvent, 0, len(gormEvents))
+	for i := range gormEvents {
+		event, err := toStoredTransferEvent(&gormEvents[i])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, nil
+}
+
+func toDomainTransferEvent(gormEvent *GormTransferEvent) (*domain.TransferEvent, error) {
+	event, err := toStoredTransferEvent(gormEvent)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.UpcastTransferEvent(gormEvent.SchemaVersion, event); err != nil {
+		return nil, fmt.Errorf("failed to upcast transfer event %d: %w", gormEvent.EventID, err)
+	}
+	return event, nil
+}
+
+// toStoredTransferEvent reads the event as stored, without upcasting it, which
+// is what its hash covers.
+func toStoredTransferEvent(gormEvent *GormTransferEvent) (*domain.TransferEvent, error) {
+	event := &domain.TransferEvent{
+		EventID:            gormEvent.EventID,
+		TransferID:         gormEvent.TransferID,
+		FromAccountID:      gormEvent.FromAccountID,
+		ToAccountID:        gormEvent.ToAccountID,
+		Amount:             gormEvent.Amount,
+		Currency:           gormEvent.Currency,
+		EventType:          gormEvent.EventType,
+		RequestFingerprint: gormEvent.RequestFingerprint,
+		CreatedAt:          gormEvent.CreatedAt,
+		SchemaVersion:      gormEvent.SchemaVersion,
+		PrevHash:           gormEvent.PrevHash,
+		Hash:               gormEvent.Hash,
+	}
+	if gormEvent.ChainSeq != nil {
+		event.ChainSeq = *gormEvent.ChainSeq
+	}
+	if gormEvent.IdempotencyKey != nil {
+		event.IdempotencyKey = *gormEvent.IdempotencyKey
+	}
+	if gormEvent.FXRate.Valid {
+		event.Conversion = &domain.Conversion{
+			Rate:              gormEvent.FXRate.Decimal,
+			DestinationAmount: gormEvent.ConvertedAmount.Decimal,
+		}
+		if gormEvent.FXRateSource != nil {
+			event.Conversion.RateSource = *gormEvent.FXRateSource
+		}
+		if gormEvent.FXRateTimestamp != nil {
+			event.Conversion.RateTimestamp = *gormEvent.FXRateTimestamp
+		}
+		if gormEvent.ConvertedCurrency != nil {
+			event.Conversion.DestinationCurrency = *gormEvent.ConvertedCurrency
+		}
+	}
+	if gormEvent.ReversesTransferID != nil {
+		event.ReversesTransferID = *gormEvent.ReversesTransferID
+	}
+	if len(gormEvent.Legs) > 0 {
+		if err := json.Unmarshal(gormEvent.Legs, &event.Legs); err != nil {
+			return nil, fmt.Errorf("failed to decode legs of transfer event %d: %w", gormEvent.EventID, err)
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"time"
+)
+
+type GormWebhookSubscription struct {
+	SubscriptionID      string `gorm:"type:varchar(36);primaryKey"`
+	URL                 string `gorm:"type:text;not null"`
+	EventTypes          []byte `gorm:"type:jsonb;not null"`
+	Secret              string `gorm:"type:text;not null"`
+	Status              string `gorm:"type:varchar(20);not null;index"`
+	ConsecutiveFailures int    `gorm:"not null;default:0"`
+	DisabledReason      string `gorm:"type:text"`
+	DisabledAt          *time.Time
+	CreatedAt           time.Time `gorm:"not null"`
+	UpdatedAt           time.Time `gorm:"not null"`
+}
+
+func (GormWebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+type GormWebhookDelivery struct {
+	DeliveryID     uint      `gorm:"primaryKey;autoIncrement"`
+	SubscriptionID string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_webhook_deliveries_message,priority:1"`
+	MessageID      uint      `gorm:"not null;uniqueIndex:idx_webhook_deliveries_message,priority:2"`
+	EventType      string    `gorm:"type:varchar(50);not null"`
+	Payload        []byte    `gorm:"type:jsonb;not null"`
+	Status         string    `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_status_next_attempt_at,priority:1"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_deliveries_status_next_attempt_at,priority:2"`
+	LastError      string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"not null"`
+	DeliveredAt    *time.Time
+}
+
+func (GormWebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+type GormWebhookDeliveryAttempt struct {
+	AttemptID   uint      `gorm:"primaryKey;autoIncrement"`
+	DeliveryID  uint      `gorm:"not null;index"`
+	StatusCode  int       `gorm:"not null;default:0"`
+	Error       string    `gorm:"type:text"`
+	DurationMs  int64     `gorm:"not null"`
+	AttemptedAt time.Time `gorm:"not null"`
+}
+
+func (GormWebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
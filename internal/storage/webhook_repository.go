@@ -0,0 +1,446 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormWebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWebhookRepository(db *gorm.DB) *GormWebhookRepository {
+	return &GormWebhookRepository{db: db}
+}
+
+func (repo *GormWebhookRepository) SaveWebhookSubscription(ctx context.Context, tx *gorm.DB, subscription *domain.WebhookSubscription) error {
+	gormSubscription, err := toGormWebhookSubscription(subscription)
+	if err != nil {
+		return err
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(gormSubscription)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateWebhookSubscription overwrites the mutable fields of the subscription.
+// Callers are expected to hold the row lock taken by LockWebhookSubscription.
+func (repo *GormWebhookRepository) UpdateWebhookSubscription(ctx context.Context, tx *gorm.DB, subscription *domain.WebhookSubscription) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook subscription event types: %w", err)
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Model(&GormWebhookSubscription{}).
+		Where("subscription_id = ?", subscription.SubscriptionID).
+		Updates(map[string]interface{}{
+			"url":                  subscription.URL,
+			"event_types":          eventTypes,
+			"secret":               subscription.Secret,
+			"status":               string(subscription.Status),
+			"consecutive_failures": subscription.ConsecutiveFailures,
+			"disabled_reason":      subscription.DisabledReason,
+			"disabled_at":          subscription.DisabledAt,
+			"updated_at":           subscription.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("webhook subscription %s not found", subscription.SubscriptionID)
+	}
+	return nil
+}
+
+func (repo *GormWebhookRepository) GetWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) (*domain.WebhookSubscription, error) {
+	return repo.getWebhookSubscription(ctx, tx, subscriptionID, nil)
+}
+
+// LockWebhookSubscription reads the subscription with a row lock held until tx
+// ends.
+func (repo *GormWebhookRepository) LockWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) (*domain.WebhookSubscription, error) {
+	return repo.getWebhookSubscription(ctx, tx, subscriptionID, &clause.Locking{Strength: "UPDATE"})
+}
+
+func (repo *GormWebhookRepository) getWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string, locking *clause.Locking) (*domain.WebhookSubscription, error) {
+	var gormSubscription GormWebhookSubscription
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx)
+	if locking != nil {
+		q = q.Clauses(*locking)
+	}
+	result := q.First(&gormSubscription, "subscription_id = ?", subscriptionID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", result.Error)
+	}
+
+	return toDomainWebhookSubscription(&gormSubscription)
+}
+
+// ListWebhookSubscriptions returns every subscription, oldest first.
+func (repo *GormWebhookRepository) ListWebhookSubscriptions(ctx context.Context, tx *gorm.DB) ([]domain.WebhookSubscription, error) {
+	return repo.listWebhookSubscriptions(ctx, tx, "")
+}
+
+func (repo *GormWebhookRepository) ListActiveWebhookSubscriptions(ctx context.Context, tx *gorm.DB) ([]domain.WebhookSubscription, error) {
+	return repo.listWebhookSubscriptions(ctx, tx, domain.WebhookSubscriptionStatusActive)
+}
+
+func (repo *GormWebhookRepository) listWebhookSubscriptions(ctx context.Context, tx *gorm.DB, status domain.WebhookSubscriptionStatus) ([]domain.WebhookSubscription, error) {
+	var gormSubscriptions []GormWebhookSubscription
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx).Model(&GormWebhookSubscription{})
+	if status != "" {
+		q = q.Where("status = ?", string(status))
+	}
+
+	result := q.Order("created_at").Order("subscription_id").Find(&gormSubscriptions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", result.Error)
+	}
+
+	subscriptions := make([]domain.WebhookSubscription, 0, len(gormSubscriptions))
+	for i := range gormSubscriptions {
+		subscription, err := toDomainWebhookSubscription(&gormSubscriptions[i])
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, nil
+}
+
+// DeleteWebhookSubscription deletes the subscription with its deliveries and
+// their attempts.
+func (repo *GormWebhookRepository) DeleteWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	deliveries := db.Model(&GormWebhookDelivery{}).Select("delivery_id").Where("subscription_id = ?", subscriptionID)
+	result := db.WithContext(ctx).Where("delivery_id IN (?)", deliveries).Delete(&GormWebhookDeliveryAttempt{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook delivery attempts: %w", result.Error)
+	}
+
+	result = db.WithContext(ctx).Where("subscription_id = ?", subscriptionID).Delete(&GormWebhookDelivery{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", result.Error)
+	}
+
+	result = db.WithContext(ctx).Where("subscription_id = ?", subscriptionID).Delete(&GormWebhookSubscription{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", result.Error)
+	}
+	return nil
+}
+
+// SaveWebhookDeliveries inserts the deliveries, skipping those of a message
+// already queued for the same subscription. It returns the number inserted.
+func (repo *GormWebhookRepository) SaveWebhookDeliveries(ctx context.Context, tx *gorm.DB, deliveries []*domain.WebhookDelivery) (int, error) {
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	gormDeliveries := make([]GormWebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		gormDeliveries = append(gormDeliveries, toGormWebhookDelivery(delivery))
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&gormDeliveries)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to save webhook deliveries: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (repo *GormWebhookRepository) GetWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error) {
+	return repo.getWebhookDelivery(ctx, tx, deliveryID, nil)
+}
+
+// LockWebhookDelivery reads the delivery with a row lock held until tx ends,
+// waiting for a dispatcher currently delivering it.
+func (repo *GormWebhookRepository) LockWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error) {
+	return repo.getWebhookDelivery(ctx, tx, deliveryID, &clause.Locking{Strength: "UPDATE"})
+}
+
+// ClaimWebhookDelivery locks the delivery like LockWebhookDelivery but returns
+// nil instead of waiting when another transaction holds the lock, so that
+// dispatcher replicas never send the same delivery concurrently.
+func (repo *GormWebhookRepository) ClaimWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error) {
+	return repo.getWebhookDelivery(ctx, tx, deliveryID, &clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+}
+
+func (repo *GormWebhookRepository) getWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint, locking *clause.Locking) (*domain.WebhookDelivery, error) {
+	var gormDelivery GormWebhookDelivery
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	q := db.WithContext(ctx)
+	if locking != nil {
+		q = q.Clauses(*locking)
+	}
+	result := q.First(&gormDelivery, "delivery_id = ?", deliveryID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", result.Error)
+	}
+
+	return toDomainWebhookDelivery(&gormDelivery), nil
+}
+
+// ListWebhookDeliveries returns up to limit deliveries of the subscription,
+// most recent first.
+func (repo *GormWebhookRepository) ListWebhookDeliveries(ctx context.Context, tx *gorm.DB, subscriptionID string, limit int) ([]domain.WebhookDelivery, error) {
+	var gormDeliveries []GormWebhookDelivery
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("delivery_id DESC").
+		Limit(limit).
+		Find(&gormDeliveries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", result.Error)
+	}
+
+	return toDomainWebhookDeliveries(gormDeliveries), nil
+}
+
+// ListDueWebhookDeliveries returns up to limit pending deliveries of active
+// subscriptions whose next attempt is at or before now, oldest first.
+func (repo *GormWebhookRepository) ListDueWebhookDeliveries(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var gormDeliveries []GormWebhookDelivery
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	active := db.Model(&GormWebhookSubscription{}).
+		Select("subscription_id").
+		Where("status = ?", string(domain.WebhookSubscriptionStatusActive))
+
+	result := db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", string(domain.WebhookDeliveryStatusPending), now).
+		Where("subscription_id IN (?)", active).
+		Order("delivery_id").
+		Limit(limit).
+		Find(&gormDeliveries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list due webhook deliveries: %w", result.Error)
+	}
+
+	return toDomainWebhookDeliveries(gormDeliveries), nil
+}
+
+// UpdateWebhookDelivery records the state of a delivery after an attempt or a
+// redelivery request.
+func (repo *GormWebhookRepository) UpdateWebhookDelivery(ctx context.Context, tx *gorm.DB, delivery *domain.WebhookDelivery) error {
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Model(&GormWebhookDelivery{}).
+		Where("delivery_id = ?", delivery.DeliveryID).
+		Updates(map[string]interface{}{
+			"status":          string(delivery.Status),
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("webhook delivery %d not found", delivery.DeliveryID)
+	}
+	return nil
+}
+
+func (repo *GormWebhookRepository) SaveWebhookDeliveryAttempt(ctx context.Context, tx *gorm.DB, attempt *domain.WebhookDeliveryAttempt) error {
+	gormAttempt := GormWebhookDeliveryAttempt{
+		DeliveryID:  attempt.DeliveryID,
+		StatusCode:  attempt.StatusCode,
+		Error:       attempt.Error,
+		DurationMs:  attempt.DurationMs,
+		AttemptedAt: attempt.AttemptedAt,
+	}
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).Create(&gormAttempt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save webhook delivery attempt: %w", result.Error)
+	}
+
+	attempt.AttemptID = gormAttempt.AttemptID
+	return nil
+}
+
+// ListWebhookDeliveryAttempts returns the attempts of the delivery in the order
+// they were made.
+func (repo *GormWebhookRepository) ListWebhookDeliveryAttempts(ctx context.Context, tx *gorm.DB, deliveryID uint) ([]domain.WebhookDeliveryAttempt, error) {
+	var gormAttempts []GormWebhookDeliveryAttempt
+
+	db := repo.db
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.WithContext(ctx).
+		Where("delivery_id = ?", deliveryID).
+		Order("attempt_id").
+		Find(&gormAttempts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list webhook delivery attempts: %w", result.Error)
+	}
+
+	attempts := make([]domain.WebhookDeliveryAttempt, 0, len(gormAttempts))
+	for _, a := range gormAttempts {
+		attempts = append(attempts, domain.WebhookDeliveryAttempt{
+			AttemptID:   a.AttemptID,
+			DeliveryID:  a.DeliveryID,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMs:  a.DurationMs,
+			AttemptedAt: a.AttemptedAt,
+		})
+	}
+	return attempts, nil
+}
+
+func toGormWebhookSubscription(s *domain.WebhookSubscription) (*GormWebhookSubscription, error) {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook subscription event types: %w", err)
+	}
+
+	return &GormWebhookSubscription{
+		SubscriptionID:      s.SubscriptionID,
+		URL:                 s.URL,
+		EventTypes:          eventTypes,
+		Secret:              s.Secret,
+		Status:              string(s.Status),
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		DisabledReason:      s.DisabledReason,
+		DisabledAt:          s.DisabledAt,
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	}, nil
+}
+
+func toDomainWebhookSubscription(s *GormWebhookSubscription) (*domain.WebhookSubscription, error) {
+	var eventTypes []string
+	if err := json.Unmarshal(s.EventTypes, &eventTypes); err != nil {
+		return nil, fmt.Errorf("failed to decode event types of webhook subscription %s: %w", s.SubscriptionID, err)
+	}
+
+	return &domain.WebhookSubscription{
+		SubscriptionID:      s.SubscriptionID,
+		URL:                 s.URL,
+		EventTypes:          eventTypes,
+		Secret:              s.Secret,
+		Status:              domain.WebhookSubscriptionStatus(s.Status),
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		DisabledReason:      s.DisabledReason,
+		DisabledAt:          s.DisabledAt,
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	}, nil
+}
+
+func toGormWebhookDelivery(d *domain.WebhookDelivery) GormWebhookDelivery {
+	return GormWebhookDelivery{
+		DeliveryID:     d.DeliveryID,
+		SubscriptionID: d.SubscriptionID,
+		MessageID:      d.MessageID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+func toDomainWebhookDelivery(d *GormWebhookDelivery) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		DeliveryID:     d.DeliveryID,
+		SubscriptionID: d.SubscriptionID,
+		MessageID:      d.MessageID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         domain.WebhookDeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+func toDomainWebhookDeliveries(gormDeliveries []GormWebhookDelivery) []domain.WebhookDelivery {
+	deliveries := make([]domain.WebhookDelivery, 0, len(gormDeliveries))
+	for i := range gormDeliveries {
+		deliveries = append(deliveries, *toDomainWebhookDelivery(&gormDeliveries[i]))
+	}
+	return deliveries
+}
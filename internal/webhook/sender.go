@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	MessageIDHeader  = "X-Webhook-Message-ID"
+	DeliveryIDHeader = "X-Webhook-Delivery-ID"
+	EventTypeHeader  = "X-Webhook-Event"
+
+	sendTimeout = 10 * time.Second
+)
+
+// Request is one signed webhook request. Receivers should deduplicate on
+// MessageID: a message is delivered at least once, and redeliveries reuse it.
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID uint
+	MessageID  uint
+	EventType  string
+	Body       []byte
+}
+
+// Sender POSTs webhook requests. It returns the status code of the response,
+// zero when none was received, and an error unless the status is 2xx.
+type Sender interface {
+	Send(ctx context.Context, request Request, now time.Time) (int, error)
+}
+
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(client *http.Client) *HTTPSender {
+	if client == nil {
+		client = &http.Client{Timeout: sendTimeout}
+	}
+	return &HTTPSender{client: client}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, request Request, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(MessageIDHeader, strconv.FormatUint(uint64(request.MessageID), 10))
+	req.Header.Set(DeliveryIDHeader, strconv.FormatUint(uint64(request.DeliveryID), 10))
+	req.Header.Set(EventTypeHeader, request.EventType)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(request.Secret, timestamp, request.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+const hashChainSealBatchSize = 500
+
+// HashChainSealer periodically appends committed transfer events and journal
+// entries to their hash chains, so postings never wait on the chain heads.
+type HashChainSealer struct {
+	integrityService service.IntegrityService
+	db               *gorm.DB
+	log              *slog.Logger
+	interval         time.Duration
+}
+
+func NewHashChainSealer(integrityService service.IntegrityService, db *gorm.DB, log *slog.Logger, interval time.Duration) *HashChainSealer {
+	return &HashChainSealer{
+		integrityService: integrityService,
+		db:               db,
+		log:              log,
+		interval:         interval,
+	}
+}
+
+// Run seals committed rows every interval until ctx is cancelled.
+func (w *HashChainSealer) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.seal(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// seal seals batches until one is not full, so that a backlog drains without
+// waiting for the next tick.
+func (w *HashChainSealer) seal(ctx context.Context) {
+	for ctx.Err() == nil {
+		var report *service.HashChainSealReport
+		err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			report, err = w.integrityService.SealHashChains(ctx, tx, hashChainSealBatchSize)
+			return err
+		})
+		if err != nil {
+			w.log.Error("Failed to seal hash chains", "error", err)
+			return
+		}
+		if report.TransferEvents < hashChainSealBatchSize && report.JournalEntries < hashChainSealBatchSize {
+			return
+		}
+	}
+}
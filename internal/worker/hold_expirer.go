@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+const holdExpiryBatchSize = 100
+
+// HoldExpirer periodically releases authorized holds that outlived their
+// expiry. Each hold is expired in its own transaction; a hold that loses a race
+// against a capture or void is simply skipped.
+type HoldExpirer struct {
+	holdService service.HoldService
+	db          *gorm.DB
+	log         *slog.Logger
+	interval    time.Duration
+}
+
+func NewHoldExpirer(holdService service.HoldService, db *gorm.DB, log *slog.Logger, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		holdService: holdService,
+		db:          db,
+		log:         log,
+		interval:    interval,
+	}
+}
+
+// Run expires due holds every interval until ctx is cancelled.
+func (w *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.expireDueHolds(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *HoldExpirer) expireDueHolds(ctx context.Context) {
+	now := time.Now()
+	holds, err := w.holdService.ListExpiredHolds(ctx, now, holdExpiryBatchSize)
+	if err != nil {
+		w.log.Error("Failed to list expired holds", "error", err)
+		return
+	}
+
+	for _, due := range holds {
+		var expired *domain.Hold
+		err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			expired, err = w.holdService.ExpireHold(ctx, tx, due.HoldID, now)
+			return err
+		})
+		if err != nil {
+			w.log.Error("Failed to expire hold", "hold_id", due.HoldID, "error", err)
+			continue
+		}
+		if expired != nil {
+			w.log.Info("Hold expired", "hold_id", expired.HoldID, "source_account_id", expired.SourceAccountID, "amount", expired.Amount)
+		}
+	}
+}
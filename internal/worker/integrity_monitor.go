@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/alert"
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+// IntegrityMonitor periodically runs the integrity checks, records every run
+// in integrity_runs and alerts on the runs that did not pass.
+type IntegrityMonitor struct {
+	integrityService service.IntegrityService
+	alerter          alert.Alerter
+	db               *gorm.DB
+	log              *slog.Logger
+	interval         time.Duration
+	checks           []string
+}
+
+func NewIntegrityMonitor(integrityService service.IntegrityService, alerter alert.Alerter, db *gorm.DB, log *slog.Logger, interval time.Duration, checks []string) *IntegrityMonitor {
+	return &IntegrityMonitor{
+		integrityService: integrityService,
+		alerter:          alerter,
+		db:               db,
+		log:              log,
+		interval:         interval,
+		checks:           checks,
+	}
+}
+
+// Run runs the checks every interval until ctx is cancelled.
+func (w *IntegrityMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.runChecks(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runChecks runs every check in its own snapshot transaction, so that a check
+// that fails to run does not abort the others.
+func (w *IntegrityMonitor) runChecks(ctx context.Context) {
+	startedAt := time.Now()
+	results := make([]domain.IntegrityCheckResult, 0, len(w.checks))
+	for _, check := range w.checks {
+		var result *domain.IntegrityCheckResult
+		err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			result, err = w.integrityService.RunIntegrityCheck(ctx, tx, check)
+			return err
+		}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			w.log.Error("Failed to run integrity check", "check", check, "error", err)
+			result = &domain.IntegrityCheckResult{Check: check, Status: domain.IntegrityStatusError, Error: err.Error()}
+		}
+		results = append(results, *result)
+	}
+	run := domain.NewIntegrityRun(results, startedAt, time.Now())
+
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return w.integrityService.RecordIntegrityRun(ctx, tx, run)
+	})
+	if err != nil {
+		// The alert still goes out, without a run ID to look up.
+		w.log.Error("Failed to record integrity run", "status", run.Status, "error", err)
+		run.RunID = 0
+	}
+
+	if run.Status == domain.IntegrityStatusPassed {
+		w.log.Info("Integrity run passed", "run_id", run.RunID, "checks", len(run.Checks))
+		return
+	}
+	if err := w.alerter.Alert(ctx, *run); err != nil {
+		w.log.Error("Failed to send integrity alert", "run_id", run.RunID, "error", err)
+	}
+}
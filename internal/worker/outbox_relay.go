@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+const outboxRelayBatchSize = 100
+
+// OutboxRelay periodically publishes pending outbox messages. Only one relay
+// runs at a time across replicas; the others skip their turn.
+type OutboxRelay struct {
+	outboxService service.OutboxService
+	db            *gorm.DB
+	log           *slog.Logger
+	interval      time.Duration
+}
+
+func NewOutboxRelay(outboxService service.OutboxService, db *gorm.DB, log *slog.Logger, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		outboxService: outboxService,
+		db:            db,
+		log:           log,
+		interval:      interval,
+	}
+}
+
+// Run relays pending messages every interval until ctx is cancelled.
+func (w *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.relay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay claims, publishes and completes batches until one is not fully
+// published.
+func (w *OutboxRelay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		var batch *service.OutboxBatch
+		err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			batch, err = w.outboxService.ClaimOutboxMessages(ctx, tx, time.Now(), outboxRelayBatchSize)
+			return err
+		})
+		if err != nil {
+			w.log.Error("Failed to claim outbox messages", "error", err)
+			return
+		}
+		if batch == nil || len(batch.Messages) == 0 {
+			return
+		}
+
+		w.outboxService.PublishOutboxMessages(ctx, batch, time.Now())
+
+		err = w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return w.outboxService.CompleteOutboxMessages(ctx, tx, batch)
+		})
+		if err != nil {
+			w.log.Error("Failed to record outbox deliveries", "error", err)
+			return
+		}
+
+		report := batch.Report
+		if report.Dead > 0 {
+			w.log.Error("Outbox messages dead-lettered", "dead", report.Dead, "failed", report.Failed, "published", report.Published, "deferred", report.Deferred)
+		}
+		if report.Failed > 0 {
+			w.log.Warn("Outbox messages failed to publish", "failed", report.Failed, "published", report.Published, "deferred", report.Deferred, "webhook_deliveries", report.WebhookDeliveries)
+		} else if report.Published > 0 {
+			w.log.Info("Outbox messages published", "published", report.Published, "deferred", report.Deferred, "webhook_deliveries", report.WebhookDeliveries)
+		}
+		if report.Published < outboxRelayBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+// snapshotLag keeps snapshots clear of the events still being written: a
+// transfer event is dated before its transaction commits, so a snapshot taken
+// right up to now could miss an event numbered before the last one it covers.
+const snapshotLag = time.Minute
+
+// Snapshotter periodically snapshots the state of every account once enough
+// transfer events were posted since the previous snapshot, so that event
+// replay starts from it.
+type Snapshotter struct {
+	snapshotService service.SnapshotService
+	db              *gorm.DB
+	log             *slog.Logger
+	interval        time.Duration
+	frequency       int
+	verify          bool
+}
+
+func NewSnapshotter(snapshotService service.SnapshotService, db *gorm.DB, log *slog.Logger, interval time.Duration, frequency int, verify bool) *Snapshotter {
+	return &Snapshotter{
+		snapshotService: snapshotService,
+		db:              db,
+		log:             log,
+		interval:        interval,
+		frequency:       frequency,
+		verify:          verify,
+	}
+}
+
+// Run takes due snapshots every interval until ctx is cancelled.
+func (w *Snapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.takeSnapshot(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Snapshotter) takeSnapshot(ctx context.Context) {
+	var report *service.SnapshotReport
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = w.snapshotService.TakeSnapshot(ctx, tx, time.Now().Add(-snapshotLag), w.frequency, w.verify)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		w.log.Error("Failed to take snapshot", "error", err)
+		return
+	}
+	if report != nil {
+		w.log.Info("Snapshot taken", "event_id", report.EventID, "previous_event_id", report.PreviousEventID, "accounts", report.AccountsSnapshotted, "events", report.EventsReplayed, "verified", report.Verified)
+	}
+}
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/storage"
+	"gorm.io/gorm"
+)
+
+const standingOrderBatchSize = 100
+
+// StandingOrderScheduler periodically executes the due activations of standing
+// orders. Several replicas may run it: each standing order is claimed with a
+// skip-locked row lock and every activation is recorded at most once, so a
+// replica simply skips the orders another one is working on. An overdue
+// standing order catches up one activation per run. An activation the ledger
+// rejects is recorded as failed; any other error postpones it with a backoff
+// until it has failed too many times, and is then recorded as failed as well.
+type StandingOrderScheduler struct {
+	standingOrderService service.StandingOrderService
+	db                   *gorm.DB
+	log                  *slog.Logger
+	interval             time.Duration
+}
+
+func NewStandingOrderScheduler(standingOrderService service.StandingOrderService, db *gorm.DB, log *slog.Logger, interval time.Duration) *StandingOrderScheduler {
+	return &StandingOrderScheduler{
+		standingOrderService: standingOrderService,
+		db:                   db,
+		log:                  log,
+		interval:             interval,
+	}
+}
+
+// Run executes due standing orders every interval until ctx is cancelled.
+func (w *StandingOrderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.executeDueStandingOrders(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *StandingOrderScheduler) executeDueStandingOrders(ctx context.Context) {
+	now := time.Now()
+	due, err := w.standingOrderService.ListDueStandingOrders(ctx, now, standingOrderBatchSize)
+	if err != nil {
+		w.log.Error("Failed to list due standing orders", "error", err)
+		return
+	}
+
+	for _, order := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		w.execute(ctx, order.StandingOrderID, now)
+	}
+}
+
+func (w *StandingOrderScheduler) execute(ctx context.Context, standingOrderID string, now time.Time) {
+	executed, err := storage.RunInTransactionWithRetry(ctx, w.db, w.log, func(tx *gorm.DB) (*domain.StandingOrderExecution, error) {
+		return w.standingOrderService.ExecuteStandingOrder(ctx, tx, standingOrderID, now)
+	})
+	if err == nil {
+		if executed != nil {
+			w.log.Info("Standing order executed", "standing_order_id", standingOrderID, "scheduled_for", executed.ScheduledFor, "transfer_id", executed.TransferID)
+		}
+		return
+	}
+	if !isTransferRejection(err) {
+		w.postpone(ctx, standingOrderID, err, now)
+		return
+	}
+
+	reason := err.Error()
+	var failed *domain.StandingOrderExecution
+	failErr := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		failed, err = w.standingOrderService.FailStandingOrder(ctx, tx, standingOrderID, reason, now)
+		return err
+	})
+	if failErr != nil {
+		w.log.Error("Failed to record standing order failure", "standing_order_id", standingOrderID, "error", failErr)
+		return
+	}
+	if failed != nil {
+		w.log.Warn("Standing order execution failed", "standing_order_id", standingOrderID, "scheduled_for", failed.ScheduledFor, "reason", failed.FailureReason)
+	}
+}
+
+func (w *StandingOrderScheduler) postpone(ctx context.Context, standingOrderID string, cause error, now time.Time) {
+	var postponed *domain.StandingOrder
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		postponed, err = w.standingOrderService.PostponeStandingOrder(ctx, tx, standingOrderID, cause.Error(), now)
+		return err
+	})
+	if err != nil {
+		w.log.Error("Failed to postpone standing order", "standing_order_id", standingOrderID, "error", err)
+		return
+	}
+	if postponed == nil {
+		return
+	}
+	if postponed.NextAttemptAt == nil {
+		w.log.Warn("Standing order execution failed after retries", "standing_order_id", standingOrderID, "reason", cause)
+		return
+	}
+	w.log.Warn("Standing order postponed", "standing_order_id", standingOrderID, "attempts", postponed.Attempts, "next_attempt_at", postponed.NextAttemptAt, "error", cause)
+}
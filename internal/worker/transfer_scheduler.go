@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/storage"
+	"gorm.io/gorm"
+)
+
+const scheduledTransferBatchSize = 100
+
+// TransferScheduler periodically executes scheduled transfers that are due.
+// Each transfer runs in its own transaction with the same optimistic locking
+// retries as the API. A transfer the ledger rejects is marked failed with the
+// error as reason; any other error postpones it with a backoff, so that it
+// does not hold up the rest of the batch.
+type TransferScheduler struct {
+	scheduledTransferService service.ScheduledTransferService
+	db                       *gorm.DB
+	log                      *slog.Logger
+	interval                 time.Duration
+}
+
+func NewTransferScheduler(scheduledTransferService service.ScheduledTransferService, db *gorm.DB, log *slog.Logger, interval time.Duration) *TransferScheduler {
+	return &TransferScheduler{
+		scheduledTransferService: scheduledTransferService,
+		db:                       db,
+		log:                      log,
+		interval:                 interval,
+	}
+}
+
+// Run executes due transfers every interval until ctx is cancelled.
+func (w *TransferScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.executeDueTransfers(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *TransferScheduler) executeDueTransfers(ctx context.Context) {
+	now := time.Now()
+	due, err := w.scheduledTransferService.ListDueScheduledTransfers(ctx, now, scheduledTransferBatchSize)
+	if err != nil {
+		w.log.Error("Failed to list due scheduled transfers", "error", err)
+		return
+	}
+
+	for _, scheduled := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		w.execute(ctx, scheduled.ScheduleID, now)
+	}
+}
+
+func (w *TransferScheduler) execute(ctx context.Context, scheduleID string, now time.Time) {
+	executed, err := storage.RunInTransactionWithRetry(ctx, w.db, w.log, func(tx *gorm.DB) (*domain.ScheduledTransfer, error) {
+		return w.scheduledTransferService.ExecuteScheduledTransfer(ctx, tx, scheduleID, now)
+	})
+	if err == nil {
+		if executed != nil {
+			w.log.Info("Scheduled transfer executed", "schedule_id", scheduleID, "transfer_id", executed.TransferID)
+		}
+		return
+	}
+
+	rejected := isTransferRejection(err)
+	reason := err.Error()
+	var recorded *domain.ScheduledTransfer
+	recordErr := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if rejected {
+			recorded, err = w.scheduledTransferService.FailScheduledTransfer(ctx, tx, scheduleID, reason, now)
+		} else {
+			recorded, err = w.scheduledTransferService.PostponeScheduledTransfer(ctx, tx, scheduleID, reason, now)
+		}
+		return err
+	})
+	if recordErr != nil {
+		w.log.Error("Failed to record scheduled transfer failure", "schedule_id", scheduleID, "error", recordErr)
+		return
+	}
+	if recorded == nil {
+		return
+	}
+	if recorded.Status == domain.ScheduledTransferStatusFailed {
+		w.log.Warn("Scheduled transfer failed", "schedule_id", scheduleID, "reason", recorded.FailureReason)
+		return
+	}
+	w.log.Warn("Scheduled transfer postponed", "schedule_id", scheduleID, "attempts", recorded.Attempts, "next_attempt_at", recorded.NextAttemptAt, "error", err)
+}
+
+// isTransferRejection reports whether err is the ledger rejecting the transfer,
+// which retrying cannot fix.
+func isTransferRejection(err error) bool {
+	return errors.Is(err, service.ErrInvalidTransfer) ||
+		errors.Is(err, service.ErrInsufficientFunds) ||
+		errors.Is(err, service.ErrAccountNotFound) ||
+		errors.Is(err, service.ErrCurrencyMismatch) ||
+		errors.Is(err, service.ErrIdempotencyKeyReused)
+}
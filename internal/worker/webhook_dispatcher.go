@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"gorm.io/gorm"
+)
+
+const webhookDeliveryBatchSize = 100
+
+// WebhookDispatcher periodically sends the webhook deliveries that are due.
+// Each delivery is attempted in its own transaction; replicas skip deliveries
+// another one is sending.
+type WebhookDispatcher struct {
+	webhookService service.WebhookService
+	db             *gorm.DB
+	log            *slog.Logger
+	interval       time.Duration
+}
+
+func NewWebhookDispatcher(webhookService service.WebhookService, db *gorm.DB, log *slog.Logger, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookService: webhookService,
+		db:             db,
+		log:            log,
+		interval:       interval,
+	}
+}
+
+// Run sends due deliveries every interval until ctx is cancelled.
+func (w *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.dispatchDueDeliveries(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WebhookDispatcher) dispatchDueDeliveries(ctx context.Context) {
+	due, err := w.webhookService.ListDueWebhookDeliveries(ctx, time.Now(), webhookDeliveryBatchSize)
+	if err != nil {
+		w.log.Error("Failed to list due webhook deliveries", "error", err)
+		return
+	}
+
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		w.deliver(ctx, delivery.DeliveryID)
+	}
+}
+
+// deliver attempts one delivery. The time is taken per delivery since it
+// signs the request, which receivers check against their clock.
+func (w *WebhookDispatcher) deliver(ctx context.Context, deliveryID uint) {
+	var outcome *service.WebhookDeliveryOutcome
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		outcome, err = w.webhookService.DeliverWebhook(ctx, tx, deliveryID, time.Now())
+		return err
+	})
+	if err != nil {
+		w.log.Error("Failed to deliver webhook", "delivery_id", deliveryID, "error", err)
+		return
+	}
+	if outcome == nil {
+		return
+	}
+
+	delivery := outcome.Delivery
+	switch delivery.Status {
+	case domain.WebhookDeliveryStatusDelivered:
+		w.log.Info("Webhook delivered", "delivery_id", deliveryID, "subscription_id", delivery.SubscriptionID, "status_code", outcome.Attempt.StatusCode)
+	case domain.WebhookDeliveryStatusFailed:
+		w.log.Warn("Webhook delivery failed permanently", "delivery_id", deliveryID, "subscription_id", delivery.SubscriptionID, "attempts", delivery.Attempts, "error", delivery.LastError)
+	default:
+		w.log.Warn("Webhook delivery failed, will retry", "delivery_id", deliveryID, "subscription_id", delivery.SubscriptionID, "attempts", delivery.Attempts, "next_attempt_at", delivery.NextAttemptAt, "error", delivery.LastError)
+	}
+	if outcome.SubscriptionDisabled {
+		w.log.Warn("Webhook subscription disabled after consecutive failures", "subscription_id", delivery.SubscriptionID)
+	}
+}
@@ -0,0 +1,90 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dirdr/goits/internal/alert"
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func failedRun() domain.IntegrityRun {
+	return domain.IntegrityRun{
+		RunID:  3,
+		Status: domain.IntegrityStatusFailed,
+		Checks: []domain.IntegrityCheckResult{
+			{Check: domain.IntegrityCheckDoubleBookkeeping, Status: domain.IntegrityStatusPassed},
+			{Check: domain.IntegrityCheckAccounts, Status: domain.IntegrityStatusFailed, Report: json.RawMessage(`{"is_valid":false}`)},
+		},
+	}
+}
+
+func TestWebhookAlerter_Alert(t *testing.T) {
+	var received domain.IntegrityRun
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	a := alert.NewWebhookAlerter(server.URL, server.Client())
+
+	err := a.Alert(context.Background(), failedRun())
+
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, uint(3), received.RunID)
+	assert.Equal(t, domain.IntegrityStatusFailed, received.Status)
+	assert.Equal(t, []string{domain.IntegrityCheckAccounts}, received.UnhealthyChecks())
+}
+
+func TestWebhookAlerter_Alert_RejectedByServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	a := alert.NewWebhookAlerter(server.URL, server.Client())
+
+	err := a.Alert(context.Background(), failedRun())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestMemoryAlerter_Alert(t *testing.T) {
+	a := alert.NewMemoryAlerter()
+
+	require.NoError(t, a.Alert(context.Background(), failedRun()))
+
+	runs := a.Runs()
+	require.Len(t, runs, 1)
+	assert.Equal(t, uint(3), runs[0].RunID)
+}
+
+func TestWebhookAlerter_Alert_UnrecordedRun(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	run := failedRun()
+	run.RunID = 0
+	a := alert.NewWebhookAlerter(server.URL, server.Client())
+
+	err := a.Alert(context.Background(), run)
+
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "run_id")
+}
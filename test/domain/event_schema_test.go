@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventFixtures lists, for every event type and every schema version it was
+// ever stored with, the event its fixture in testdata/events/<type>/v<N>.json
+// must decode into today. Add a fixture whenever a schema version is bumped and
+// keep the old ones: they stand for rows that are never rewritten.
+var eventFixtures = []struct {
+	eventType string
+	version   int
+	want      domain.Event
+}{
+	{domain.EventTypeAccountOpened, 1, &domain.AccountOpened{
+		AccountID:      42,
+		Type:           domain.AccountTypeCustomer,
+		Currency:       "EUR",
+		InitialBalance: decimal.RequireFromString("100.5"),
+		OpeningBalance: decimal.RequireFromString("100.5"),
+	}},
+	{domain.EventTypeAccountOpened, 2, &domain.AccountOpened{
+		AccountID:         42,
+		Type:              domain.AccountTypeCustomer,
+		Currency:          "EUR",
+		InitialBalance:    decimal.RequireFromString("100.5"),
+		OpeningBalance:    decimal.RequireFromString("0"),
+		FundingTransferID: "6e5d4c3b-2a1f-4e0d-9c8b-7a6f5e4d3c2b",
+	}},
+	{domain.EventTypeAccountFrozen, 1, &domain.AccountFrozen{AccountID: 42, Reason: "suspected fraud"}},
+	{domain.EventTypeAccountUnfrozen, 1, &domain.AccountUnfrozen{AccountID: 42}},
+	{domain.EventTypeTransferProcessed, 1, &domain.TransferProcessed{
+		TransferID:    "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.RequireFromString("12.5"),
+		Currency:      "EUR",
+		Conversion: &domain.Conversion{
+			Rate:                decimal.RequireFromString("1.08"),
+			RateSource:          "static",
+			RateTimestamp:       time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC),
+			DestinationAmount:   decimal.RequireFromString("13.5"),
+			DestinationCurrency: "USD",
+		},
+	}},
+	{domain.EventTypeMultiLegTransferProcessed, 1, &domain.MultiLegTransferProcessed{
+		TransferID: "5b0e2a91-7c3d-4f1e-9a8b-6c5d4e3f2a1b",
+		Legs: []domain.Posting{
+			{AccountID: 1, Type: domain.Debit, Amount: decimal.NewFromInt(30)},
+			{AccountID: 2, Type: domain.Credit, Amount: decimal.NewFromInt(20)},
+			{AccountID: 3, Type: domain.Credit, Amount: decimal.NewFromInt(10)},
+		},
+		Amount:   decimal.NewFromInt(30),
+		Currency: "USD",
+	}},
+	{domain.EventTypeHoldCaptured, 1, &domain.HoldCaptured{
+		TransferID:    "0f9e8d7c-6b5a-4c3d-2e1f-0a9b8c7d6e5f",
+		HoldID:        "1a2b3c4d-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.RequireFromString("7.25"),
+		Currency:      "USD",
+	}},
+	{domain.EventTypeHoldAuthorized, 1, &domain.HoldAuthorized{
+		HoldID:               "1a2b3c4d-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.RequireFromString("7.25"),
+		Currency:             "USD",
+		ExpiresAt:            time.Date(2030, 3, 8, 12, 0, 0, 0, time.UTC),
+	}},
+	{domain.EventTypeHoldVoided, 1, &domain.HoldVoided{
+		HoldID:          "1a2b3c4d-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+		SourceAccountID: 1,
+		Amount:          decimal.RequireFromString("7.25"),
+		Currency:        "USD",
+	}},
+	{domain.EventTypeHoldExpired, 1, &domain.HoldExpired{
+		HoldID:          "1a2b3c4d-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+		SourceAccountID: 1,
+		Amount:          decimal.RequireFromString("7.25"),
+		Currency:        "USD",
+	}},
+	{domain.EventTypeTransferReversed, 1, &domain.TransferReversed{
+		TransferID:         "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		ReversalTransferID: "3c4d5e6f-7a8b-4c9d-0e1f-2a3b4c5d6e7f",
+		Amount:             decimal.NewFromInt(5),
+		Currency:           "EUR",
+	}},
+	{domain.EventTypeAccountFunded, 1, &domain.AccountFunded{
+		TransferID:       "6e5d4c3b-2a1f-4e0d-9c8b-7a6f5e4d3c2b",
+		AccountID:        42,
+		FundingAccountID: 900,
+		Amount:           decimal.RequireFromString("100.5"),
+		Currency:         "EUR",
+	}},
+}
+
+func TestEventFixtures_CoverEverySchemaVersion(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, f := range eventFixtures {
+		covered[fmt.Sprintf("%s/v%d", f.eventType, f.version)] = true
+	}
+
+	for _, eventType := range domain.RegisteredEventTypes() {
+		schema, ok := domain.LookupEventSchema(eventType)
+		require.True(t, ok)
+		for version := 1; version <= schema.Version; version++ {
+			assert.True(t, covered[fmt.Sprintf("%s/v%d", eventType, version)], "no fixture for %s schema version %d", eventType, version)
+		}
+	}
+}
+
+func TestEventFixtures_Decode(t *testing.T) {
+	for _, f := range eventFixtures {
+		t.Run(fmt.Sprintf("%s/v%d", f.eventType, f.version), func(t *testing.T) {
+			payload, err := os.ReadFile(filepath.Join("testdata", "events", f.eventType, fmt.Sprintf("v%d.json", f.version)))
+			require.NoError(t, err)
+
+			envelope := &domain.EventEnvelope{EventID: 1, EventType: f.eventType, SchemaVersion: f.version, Payload: payload}
+			event, err := envelope.Decode()
+
+			require.NoError(t, err)
+			assert.Equal(t, f.want, event)
+		})
+	}
+}
+
+func TestEventEnvelope_WritesCurrentSchemaVersion(t *testing.T) {
+	for _, f := range eventFixtures {
+		schema, ok := domain.LookupEventSchema(f.eventType)
+		require.True(t, ok)
+
+		envelope, err := domain.NewEventEnvelope(f.want, nil, time.Now())
+
+		require.NoError(t, err)
+		assert.Equal(t, schema.Version, envelope.SchemaVersion, f.eventType)
+	}
+}
+
+func TestEventEnvelope_DecodeUnsupportedSchemaVersion(t *testing.T) {
+	schema, _ := domain.LookupEventSchema(domain.EventTypeAccountUnfrozen)
+
+	for _, version := range []int{0, schema.Version + 1} {
+		envelope := &domain.EventEnvelope{EventType: domain.EventTypeAccountUnfrozen, SchemaVersion: version, Payload: []byte(`{"account_id":1}`)}
+
+		_, err := envelope.Decode()
+		assert.Error(t, err, "version %d", version)
+	}
+}
+
+func TestEventSchema_Upcast(t *testing.T) {
+	// A schema at version 3: version 1 stored the amount in cents, version 2
+	// renamed it.
+	schema := domain.EventSchema{
+		Version: 3,
+		Upcasters: map[int]domain.Upcaster{
+			1: func(payload json.RawMessage) (json.RawMessage, error) {
+				var v1 struct {
+					AmountCents int64 `json:"amount_cents"`
+				}
+				if err := json.Unmarshal(payload, &v1); err != nil {
+					return nil, err
+				}
+				return json.Marshal(map[string]any{"value": decimal.New(v1.AmountCents, -2)})
+			},
+			2: func(payload json.RawMessage) (json.RawMessage, error) {
+				var v2 map[string]json.RawMessage
+				if err := json.Unmarshal(payload, &v2); err != nil {
+					return nil, err
+				}
+				v2["amount"] = v2["value"]
+				delete(v2, "value")
+				return json.Marshal(v2)
+			},
+		},
+	}
+
+	tests := []struct {
+		version int
+		payload string
+		want    string
+	}{
+		{1, `{"amount_cents":1250}`, `{"amount":"12.5"}`},
+		{2, `{"value":"12.5"}`, `{"amount":"12.5"}`},
+		{3, `{"amount":"12.5"}`, `{"amount":"12.5"}`},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("v%d", tt.version), func(t *testing.T) {
+			payload, err := schema.Upcast(tt.version, json.RawMessage(tt.payload))
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(payload))
+		})
+	}
+}
+
+func TestEventSchema_Upcast_Failures(t *testing.T) {
+	failure := errors.New("malformed payload")
+	schema := domain.EventSchema{
+		Version: 3,
+		Upcasters: map[int]domain.Upcaster{
+			2: func(json.RawMessage) (json.RawMessage, error) { return nil, failure },
+		},
+	}
+
+	_, err := schema.Upcast(1, json.RawMessage(`{}`))
+	assert.ErrorContains(t, err, "no upcaster from schema version 1")
+
+	_, err = schema.Upcast(2, json.RawMessage(`{}`))
+	assert.ErrorIs(t, err, failure)
+
+	_, err = schema.Upcast(4, json.RawMessage(`{}`))
+	assert.Error(t, err)
+}
+
+// transferEventFixtures lists, for every schema version transfer_events rows
+// were ever written with, the event its fixture in
+// testdata/transfer_events/v<N>.json must read as today.
+var transferEventFixtures = []struct {
+	version int
+	want    domain.TransferEvent
+}{
+	{1, domain.TransferEvent{
+		EventID:            7,
+		TransferID:         "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		FromAccountID:      1,
+		ToAccountID:        2,
+		Amount:             decimal.RequireFromString("12.5"),
+		Currency:           "EUR",
+		EventType:          domain.EventTypeTransferProcessed,
+		IdempotencyKey:     "order-42",
+		RequestFingerprint: "5f2b",
+		Conversion: &domain.Conversion{
+			Rate:                decimal.RequireFromString("1.08"),
+			RateSource:          "static",
+			RateTimestamp:       time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC),
+			DestinationAmount:   decimal.RequireFromString("13.5"),
+			DestinationCurrency: "USD",
+		},
+		CreatedAt:     time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC),
+		SchemaVersion: domain.TransferEventSchema.Version,
+		PrevHash:      "prev",
+		Hash:          "hash",
+	}},
+}
+
+func TestTransferEventFixtures_CoverEverySchemaVersion(t *testing.T) {
+	covered := make(map[int]bool)
+	for _, f := range transferEventFixtures {
+		covered[f.version] = true
+	}
+
+	for version := 1; version <= domain.TransferEventSchema.Version; version++ {
+		assert.True(t, covered[version], "no fixture for transfer event schema version %d", version)
+	}
+}
+
+func TestUpcastTransferEvent_Fixtures(t *testing.T) {
+	for _, f := range transferEventFixtures {
+		t.Run(fmt.Sprintf("v%d", f.version), func(t *testing.T) {
+			row, err := os.ReadFile(filepath.Join("testdata", "transfer_events", fmt.Sprintf("v%d.json", f.version)))
+			require.NoError(t, err)
+			var event domain.TransferEvent
+			require.NoError(t, json.Unmarshal(row, &event))
+			event.PrevHash, event.Hash = "prev", "hash"
+
+			err = domain.UpcastTransferEvent(f.version, &event)
+
+			require.NoError(t, err)
+			assert.Equal(t, f.want, event)
+		})
+	}
+}
+
+func TestUpcastTransferEvent_UnsupportedSchemaVersion(t *testing.T) {
+	for _, version := range []int{0, domain.TransferEventSchema.Version + 1} {
+		event := domain.TransferEvent{TransferID: "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e"}
+
+		err := domain.UpcastTransferEvent(version, &event)
+		assert.Error(t, err, "version %d", version)
+	}
+}
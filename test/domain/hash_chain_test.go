@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferEvent_ChainHash(t *testing.T) {
+	createdAt := time.Date(2030, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	event := domain.TransferEvent{
+		EventID:       7,
+		TransferID:    "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.RequireFromString("12.5"),
+		Currency:      "EUR",
+		EventType:     domain.EventTypeTransferProcessed,
+		CreatedAt:     createdAt,
+	}
+
+	hash, err := event.ChainHash(domain.GenesisHash)
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	t.Run("matches the row read back from Postgres", func(t *testing.T) {
+		stored := event
+		stored.Amount = decimal.RequireFromString("12.50000000")
+		stored.CreatedAt = createdAt.Truncate(time.Microsecond).In(time.FixedZone("CET", 3600))
+
+		storedHash, err := stored.ChainHash(domain.GenesisHash)
+		require.NoError(t, err)
+		assert.Equal(t, hash, storedHash)
+	})
+
+	t.Run("depends on the previous hash", func(t *testing.T) {
+		chained, err := event.ChainHash(hash)
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, chained)
+	})
+
+	t.Run("depends on the contents", func(t *testing.T) {
+		edits := map[string]func(e *domain.TransferEvent){
+			"amount":         func(e *domain.TransferEvent) { e.Amount = decimal.RequireFromString("125") },
+			"account":        func(e *domain.TransferEvent) { e.ToAccountID = 3 },
+			"event ID":       func(e *domain.TransferEvent) { e.EventID = 8 },
+			"schema version": func(e *domain.TransferEvent) { e.SchemaVersion = 2 },
+			"created at":     func(e *domain.TransferEvent) { e.CreatedAt = e.CreatedAt.Add(time.Millisecond) },
+			"legs": func(e *domain.TransferEvent) {
+				e.Legs = []domain.Posting{{AccountID: 1, Type: domain.Debit, Amount: e.Amount}}
+			},
+			"conversion": func(e *domain.TransferEvent) {
+				e.Conversion = &domain.Conversion{Rate: decimal.RequireFromString("1.08"), DestinationCurrency: "USD"}
+			},
+		}
+		for name, edit := range edits {
+			edited := event
+			edit(&edited)
+
+			editedHash, err := edited.ChainHash(domain.GenesisHash)
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, editedHash, name)
+		}
+	})
+}
+
+func TestJournalEntry_ChainHash(t *testing.T) {
+	entry := domain.JournalEntry{
+		EntryID:       3,
+		TransactionID: "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		AccountID:     1,
+		Amount:        decimal.RequireFromString("12.5"),
+		Currency:      "EUR",
+		Type:          domain.Debit,
+		SourceEventID: 7,
+		CreatedAt:     time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	hash, err := entry.ChainHash(domain.GenesisHash)
+	require.NoError(t, err)
+
+	credit := entry
+	credit.Type = domain.Credit
+	creditHash, err := credit.ChainHash(domain.GenesisHash)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, hash, creditHash)
+}
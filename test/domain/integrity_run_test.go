@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIntegrityRun_Status(t *testing.T) {
+	passed := domain.IntegrityCheckResult{Check: domain.IntegrityCheckDoubleBookkeeping, Status: domain.IntegrityStatusPassed}
+	failed := domain.IntegrityCheckResult{Check: domain.IntegrityCheckAccounts, Status: domain.IntegrityStatusFailed}
+	errored := domain.IntegrityCheckResult{Check: domain.IntegrityCheckHashChains, Status: domain.IntegrityStatusError, Error: "timeout"}
+
+	tests := []struct {
+		name   string
+		checks []domain.IntegrityCheckResult
+		want   domain.IntegrityStatus
+	}{
+		{"no checks", nil, domain.IntegrityStatusPassed},
+		{"all passed", []domain.IntegrityCheckResult{passed, passed}, domain.IntegrityStatusPassed},
+		{"one failed", []domain.IntegrityCheckResult{passed, failed}, domain.IntegrityStatusFailed},
+		{"error beats failed", []domain.IntegrityCheckResult{errored, failed}, domain.IntegrityStatusError},
+		{"error after failed", []domain.IntegrityCheckResult{failed, errored}, domain.IntegrityStatusError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := domain.NewIntegrityRun(tt.checks, time.Now(), time.Now())
+			assert.Equal(t, tt.want, run.Status)
+		})
+	}
+}
+
+func TestIntegrityRun_UnhealthyChecks(t *testing.T) {
+	run := domain.NewIntegrityRun([]domain.IntegrityCheckResult{
+		{Check: domain.IntegrityCheckDoubleBookkeeping, Status: domain.IntegrityStatusPassed},
+		{Check: domain.IntegrityCheckHashChains, Status: domain.IntegrityStatusError},
+		{Check: domain.IntegrityCheckAccounts, Status: domain.IntegrityStatusFailed},
+	}, time.Now(), time.Now())
+
+	assert.Equal(t, []string{domain.IntegrityCheckHashChains, domain.IntegrityCheckAccounts}, run.UnhealthyChecks())
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOutboxMessage(t *testing.T) {
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := &domain.TransferEvent{
+		EventID:            42,
+		TransferID:         "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		FromAccountID:      2,
+		ToAccountID:        1,
+		Amount:             decimal.RequireFromString("12.50"),
+		Currency:           "EUR",
+		EventType:          domain.EventTypeTransferProcessed,
+		IdempotencyKey:     "key-1",
+		RequestFingerprint: "fingerprint",
+		CreatedAt:          now,
+	}
+
+	message, err := domain.NewOutboxMessage(event, []uint{2, 1, 2})
+
+	require.NoError(t, err)
+	require.NotNil(t, message.TransferEventID)
+	assert.Equal(t, uint(42), *message.TransferEventID)
+	assert.Equal(t, domain.EventTypeTransferProcessed, message.EventType)
+	assert.Equal(t, []uint{1, 2}, message.AccountIDs)
+	assert.Equal(t, domain.OutboxStatusPending, message.Status)
+	assert.Equal(t, now, message.NextAttemptAt)
+
+	var payload domain.TransferEvent
+	require.NoError(t, json.Unmarshal(message.Payload, &payload))
+	assert.Equal(t, event.TransferID, payload.TransferID)
+	assert.Equal(t, "key-1", payload.IdempotencyKey)
+	assert.Empty(t, payload.RequestFingerprint)
+	assert.Equal(t, "fingerprint", event.RequestFingerprint)
+}
+
+func TestNewAccountOutboxMessage(t *testing.T) {
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := domain.AccountOpened{
+		AccountID:      7,
+		Type:           domain.AccountTypeCustomer,
+		Currency:       "USD",
+		InitialBalance: decimal.NewFromInt(100),
+	}
+
+	message, err := domain.NewAccountOutboxMessage(event, 7, now)
+
+	require.NoError(t, err)
+	assert.Nil(t, message.TransferEventID)
+	assert.Equal(t, domain.EventTypeAccountOpened, message.EventType)
+	assert.Equal(t, []uint{7}, message.AccountIDs)
+	assert.Equal(t, now, message.CreatedAt)
+
+	var payload domain.AccountOpened
+	require.NoError(t, json.Unmarshal(message.Payload, &payload))
+	assert.Equal(t, uint(7), payload.AccountID)
+	assert.True(t, decimal.NewFromInt(100).Equal(payload.InitialBalance))
+}
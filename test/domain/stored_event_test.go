@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventEnvelope_RoundTrip(t *testing.T) {
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := domain.TransferProcessed{
+		TransferID:    "9d1c3b7e-5a2f-4c68-8e0d-1f2a3b4c5d6e",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.RequireFromString("12.50"),
+		Currency:      "EUR",
+	}
+
+	envelope, err := domain.NewEventEnvelope(event, map[string]string{"transfer_event_id": "7"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventTypeTransferProcessed, envelope.EventType)
+	assert.Equal(t, domain.AggregateTypeTransfer, envelope.AggregateType)
+	assert.Equal(t, event.TransferID, envelope.AggregateID)
+	assert.Zero(t, envelope.Sequence)
+
+	decoded, err := envelope.Decode()
+	require.NoError(t, err)
+	processed, ok := decoded.(*domain.TransferProcessed)
+	require.True(t, ok)
+	assert.Equal(t, event.TransferID, processed.TransferID)
+	assert.True(t, event.Amount.Equal(processed.Amount))
+	assert.Equal(t, event.Currency, processed.Currency)
+}
+
+func TestEventEnvelope_AccountAggregateID(t *testing.T) {
+	envelope, err := domain.NewEventEnvelope(domain.AccountOpened{AccountID: 42, Currency: "USD"}, nil, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, domain.AggregateTypeAccount, envelope.AggregateType)
+	assert.Equal(t, "42", envelope.AggregateID)
+}
+
+func TestEventEnvelope_DecodeUnknownType(t *testing.T) {
+	envelope := &domain.EventEnvelope{EventType: "SomethingHappened", Payload: []byte(`{}`)}
+
+	_, err := envelope.Decode()
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/publisher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func outboxMessage(messageID uint) domain.OutboxMessage {
+	transferEventID := messageID + 100
+	return domain.OutboxMessage{
+		MessageID:       messageID,
+		TransferEventID: &transferEventID,
+		EventType:       domain.EventTypeTransferProcessed,
+		AccountIDs:      []uint{1, 2},
+		Payload:         json.RawMessage(`{"transfer_id":"t-1"}`),
+	}
+}
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	var received domain.OutboxMessage
+	var messageID, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		messageID = r.Header.Get("X-Message-ID")
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p := publisher.NewWebhookPublisher(server.URL, server.Client())
+
+	err := p.Publish(context.Background(), outboxMessage(7))
+
+	require.NoError(t, err)
+	assert.Equal(t, "7", messageID)
+	assert.Equal(t, "application/json", contentType)
+	require.NotNil(t, received.TransferEventID)
+	assert.Equal(t, uint(107), *received.TransferEventID)
+	assert.Equal(t, []uint{1, 2}, received.AccountIDs)
+	assert.JSONEq(t, `{"transfer_id":"t-1"}`, string(received.Payload))
+}
+
+func TestWebhookPublisher_Publish_RejectedByServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	p := publisher.NewWebhookPublisher(server.URL, server.Client())
+
+	err := p.Publish(context.Background(), outboxMessage(7))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestFilePublisher_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.ndjson")
+
+	p, err := publisher.NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, p.Publish(context.Background(), outboxMessage(1)))
+	require.NoError(t, p.Publish(context.Background(), outboxMessage(2)))
+	require.NoError(t, p.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var messageIDs []uint
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var message domain.OutboxMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+		messageIDs = append(messageIDs, message.MessageID)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []uint{1, 2}, messageIDs)
+}
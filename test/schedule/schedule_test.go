@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse_Cron(t *testing.T) {
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		{"0 9 1 * *", "2025-01-15T10:00:00Z", "2025-02-01T09:00:00Z"},
+		{"*/15 * * * *", "2025-01-15T10:07:30Z", "2025-01-15T10:15:00Z"},
+		{"30 8 * * 1-5", "2025-01-17T09:00:00Z", "2025-01-20T08:30:00Z"},
+		{"0 0 * * 7", "2025-01-15T00:00:00Z", "2025-01-19T00:00:00Z"},
+		{"0 12 29 2 *", "2025-03-01T00:00:00Z", "2028-02-29T12:00:00Z"},
+		{"0 0 13 * 5", "2025-06-01T00:00:00Z", "2025-06-06T00:00:00Z"},
+		{"@monthly", "2025-01-31T23:59:00Z", "2025-02-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := schedule.Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, date(tt.want), s.Next(date(tt.after)))
+		})
+	}
+}
+
+func TestParse_CronFirstIncludesStart(t *testing.T) {
+	s, err := schedule.Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	assert.Equal(t, date("2025-01-15T09:00:00Z"), s.First(date("2025-01-15T09:00:00Z")))
+	assert.Equal(t, date("2025-01-16T09:00:00Z"), s.Next(date("2025-01-15T09:00:00Z")))
+}
+
+func TestParse_Every(t *testing.T) {
+	s, err := schedule.Parse("@every 36h")
+	require.NoError(t, err)
+
+	start := date("2025-01-15T10:00:00Z")
+	assert.Equal(t, start, s.First(start))
+	assert.Equal(t, date("2025-01-16T22:00:00Z"), s.Next(start))
+}
+
+func TestParse_CronNeverFires(t *testing.T) {
+	s, err := schedule.Parse("0 0 31 2 *")
+	require.NoError(t, err)
+
+	assert.True(t, s.Next(date("2025-01-01T00:00:00Z")).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "@every 10s", "@every soon"} {
+		_, err := schedule.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -17,40 +17,188 @@ import (
 func TestAccountService_CreateAccount_Success(t *testing.T) {
 	mockAccountRepo := &MockAccountRepository{}
 	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockTransactionService := &MockTransactionService{}
 	tx := &gorm.DB{}
 
+	var opened *domain.AccountOpened
+	var funding service.FundingRequest
+	equity := &domain.Account{ID: 900, Type: domain.AccountTypeEquity, Currency: "EUR"}
+
 	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(false, nil)
+	mockAccountRepo.On("GetSystemAccount", mock.Anything, tx, domain.AccountTypeEquity, "EUR").Return(equity, nil)
 	mockAccountRepo.On("CreateAccount", mock.Anything, tx, mock.AnythingOfType("*domain.Account")).Return(nil)
-	mockBalanceRepo.On("UpsertAccountBalance", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance")).Return(nil)
+	mockBalanceRepo.On("UpsertAccountBalance", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.AccountID == 1 && b.Balance.IsZero() && b.Version == 1
+	})).Return(nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0), mock.MatchedBy(func(events []*domain.EventEnvelope) bool {
+		return len(events) == 1 && events[0].EventType == domain.EventTypeAccountOpened
+	})).Run(func(args mock.Arguments) {
+		event, err := args.Get(5).([]*domain.EventEnvelope)[0].Decode()
+		require.NoError(t, err)
+		opened = event.(*domain.AccountOpened)
+	}).Return(nil)
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.EventType == domain.EventTypeAccountOpened && m.TransferEventID == nil && assert.ObjectsAreEqual([]uint{1}, m.AccountIDs)
+	})).Return(nil)
+	mockTransactionService.On("FundAccount", mock.Anything, tx, mock.AnythingOfType("service.FundingRequest")).
+		Run(func(args mock.Arguments) { funding = args.Get(2).(service.FundingRequest) }).
+		Return(&domain.TransferEvent{EventID: 5}, nil).Once()
 
-	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo)
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, &MockJournalRepository{}, mockEventStore, &MockSnapshotRepository{}, mockOutboxRepo, mockTransactionService)
 
-	account, err := svc.CreateAccount(context.Background(), tx, 1, decimal.NewFromInt(100))
+	account, err := svc.CreateAccount(context.Background(), tx, 1, decimal.NewFromInt(100), "eur", "")
 
 	require.NoError(t, err)
 	assert.NotNil(t, account)
 	assert.Equal(t, uint(1), account.ID)
+	assert.Equal(t, "EUR", account.Currency)
+
+	require.NotNil(t, opened)
+	assert.True(t, opened.OpeningBalance.IsZero())
+	assert.Equal(t, opened.FundingTransferID, funding.TransferID)
+	assert.Equal(t, equity, funding.FundingAccount)
+	assert.Equal(t, uint(1), funding.Balance.AccountID)
+	assert.Equal(t, "100", funding.Amount.String())
+
 	mockAccountRepo.AssertExpectations(t)
 	mockBalanceRepo.AssertExpectations(t)
+	mockEventStore.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestAccountService_CreateAccount_ZeroBalanceIsNotFunded(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(false, nil)
+	mockAccountRepo.On("CreateAccount", mock.Anything, tx, mock.AnythingOfType("*domain.Account")).Return(nil)
+	mockBalanceRepo.On("UpsertAccountBalance", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance")).Return(nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0), mock.Anything).Return(nil)
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil).Once()
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	_, err := svc.CreateAccount(context.Background(), tx, 1, decimal.Zero, "EUR", domain.AccountTypeCustomer)
+
+	require.NoError(t, err)
+	mockAccountRepo.AssertNotCalled(t, "GetSystemAccount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+	mockJournalRepo.AssertNotCalled(t, "SaveJournalEntry", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountService_CreateAccount_ProvisionsEquityAccount(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	equityID := domain.SystemAccountIDMin
+	var created []*domain.Account
+
+	mockAccountRepo.On("AccountExists", mock.Anything, tx, mock.AnythingOfType("uint")).Return(false, nil)
+	mockAccountRepo.On("GetSystemAccount", mock.Anything, tx, domain.AccountTypeEquity, "USD").Return(nil, nil)
+	mockAccountRepo.On("LockSystemAccounts", mock.Anything, tx).Return(nil).Once()
+	mockAccountRepo.On("NextSystemAccountID", mock.Anything, tx).Return(equityID, nil).Once()
+	mockAccountRepo.On("CreateAccount", mock.Anything, tx, mock.AnythingOfType("*domain.Account")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(2).(*domain.Account)) }).
+		Return(nil)
+	mockBalanceRepo.On("UpsertAccountBalance", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance")).Return(nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, mock.Anything, mock.Anything, int64(0), mock.Anything).Return(nil)
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+	mockTransactionService.On("FundAccount", mock.Anything, tx, mock.MatchedBy(func(req service.FundingRequest) bool {
+		return req.TransferID != "" && req.FundingAccount.ID == equityID && req.Balance.AccountID == 1 && req.Amount.Equal(decimal.NewFromInt(10))
+	})).Return(&domain.TransferEvent{EventID: 1}, nil).Once()
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, &MockJournalRepository{}, mockEventStore, &MockSnapshotRepository{}, mockOutboxRepo, mockTransactionService)
+
+	account, err := svc.CreateAccount(context.Background(), tx, 1, decimal.NewFromInt(10), "USD", domain.AccountTypeCustomer)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), account.ID)
+	require.Len(t, created, 2)
+	assert.Equal(t, uint(1), created[0].ID)
+	assert.Equal(t, equityID, created[1].ID)
+	assert.Equal(t, domain.AccountTypeEquity, created[1].Type)
+	assert.Equal(t, "USD", created[1].Currency)
+	mockAccountRepo.AssertExpectations(t)
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestAccountService_CreateAccount_RejectsReservedID(t *testing.T) {
+	svc := service.NewAccountService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, &MockJournalRepository{}, &MockEventStore{}, &MockSnapshotRepository{}, &MockOutboxRepository{}, &MockTransactionService{})
+
+	account, err := svc.CreateAccount(context.Background(), &gorm.DB{}, domain.SystemAccountIDMin+3, decimal.Zero, "USD", domain.AccountTypeCustomer)
+
+	assert.ErrorIs(t, err, service.ErrInvalidAccount)
+	assert.ErrorContains(t, err, "reserved")
+	assert.Nil(t, account)
+}
+
+func TestAccountService_CreateAccount_EquityOpensEmpty(t *testing.T) {
+	svc := service.NewAccountService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, &MockJournalRepository{}, &MockEventStore{}, &MockSnapshotRepository{}, &MockOutboxRepository{}, &MockTransactionService{})
+
+	account, err := svc.CreateAccount(context.Background(), &gorm.DB{}, 900, decimal.NewFromInt(10), "USD", domain.AccountTypeEquity)
+
+	assert.ErrorIs(t, err, service.ErrInvalidAccount)
+	assert.ErrorContains(t, err, "zero balance")
+	assert.Nil(t, account)
 }
 
 func TestAccountService_CreateAccount_NegativeBalance(t *testing.T) {
 	mockAccountRepo := &MockAccountRepository{}
 	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
-	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo)
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
 
-	account, err := svc.CreateAccount(context.Background(), tx, 1, decimal.NewFromInt(-10))
+	account, err := svc.CreateAccount(context.Background(), tx, 1, decimal.NewFromInt(-10), "USD", domain.AccountTypeCustomer)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "initial balance cannot be negative")
 	assert.Nil(t, account)
 }
 
+func TestAccountService_CreateAccount_InvalidCurrency(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	account, err := svc.CreateAccount(context.Background(), tx, 1, decimal.NewFromInt(10), "XYZ", domain.AccountTypeCustomer)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ISO 4217")
+	assert.Nil(t, account)
+	mockAccountRepo.AssertNotCalled(t, "CreateAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestAccountService_GetAccountByID_Success(t *testing.T) {
 	mockAccountRepo := &MockAccountRepository{}
 	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
 
 	expectedAccount := &domain.Account{
 		ID:        1,
@@ -60,7 +208,7 @@ func TestAccountService_GetAccountByID_Success(t *testing.T) {
 
 	mockAccountRepo.On("GetAccountByID", mock.Anything, (*gorm.DB)(nil), uint(1)).Return(expectedAccount, nil)
 
-	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo)
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
 
 	account, err := svc.GetAccountByID(context.Background(), 1)
 
@@ -73,6 +221,10 @@ func TestAccountService_GetAccountByID_Success(t *testing.T) {
 func TestAccountService_GetAccountBalance_Success(t *testing.T) {
 	mockAccountRepo := &MockAccountRepository{}
 	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
 
 	expectedBalance := &domain.AccountBalance{
 		AccountID:   1,
@@ -84,7 +236,7 @@ func TestAccountService_GetAccountBalance_Success(t *testing.T) {
 
 	mockBalanceRepo.On("GetAccountBalance", mock.Anything, (*gorm.DB)(nil), uint(1)).Return(expectedBalance, nil)
 
-	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo)
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
 
 	balance, err := svc.GetAccountBalance(context.Background(), 1)
 
@@ -94,3 +246,199 @@ func TestAccountService_GetAccountBalance_Success(t *testing.T) {
 	assert.Equal(t, decimal.NewFromInt(100), balance.Balance)
 	mockBalanceRepo.AssertExpectations(t)
 }
+
+func TestAccountService_ListAccountTransactions_RunningBalance(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	entries := []domain.AccountJournalEntry{
+		{JournalEntry: domain.JournalEntry{EntryID: 9, TransactionID: "t3", AccountID: 1, Amount: decimal.NewFromInt(30), Type: domain.Debit, CreatedAt: now}, CounterpartyAccountID: 2},
+		{JournalEntry: domain.JournalEntry{EntryID: 6, TransactionID: "t2", AccountID: 1, Amount: decimal.NewFromInt(50), Type: domain.Credit, CreatedAt: now.Add(-time.Minute)}, CounterpartyAccountID: 3},
+		{JournalEntry: domain.JournalEntry{EntryID: 3, TransactionID: "t1", AccountID: 1, Amount: decimal.NewFromInt(20), Type: domain.Debit, CreatedAt: now.Add(-2 * time.Minute)}, CounterpartyAccountID: 2},
+	}
+	query := domain.AccountJournalQuery{AccountID: 1, Limit: 2}
+
+	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
+	mockJournalRepo.On("ListAccountJournalEntries", mock.Anything, tx, domain.AccountJournalQuery{AccountID: 1, Limit: 3}).Return(entries, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(110)}, nil)
+	mockJournalRepo.On("GetAccountNetChangeAfter", mock.Anything, tx, uint(1), domain.JournalEntryCursor{CreatedAt: now, EntryID: 9}).Return(decimal.NewFromInt(10), nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	page, err := svc.ListAccountTransactions(context.Background(), tx, query)
+
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Len(t, page.Entries, 2)
+	assert.True(t, decimal.NewFromInt(100).Equal(page.Entries[0].RunningBalance))
+	assert.True(t, decimal.NewFromInt(130).Equal(page.Entries[1].RunningBalance))
+	require.NotNil(t, page.NextCursor)
+	assert.Equal(t, uint(6), page.NextCursor.EntryID)
+	mockAccountRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+	mockJournalRepo.AssertExpectations(t)
+}
+
+func TestAccountService_ListAccountTransactions_AccountNotFound(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(false, nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	page, err := svc.ListAccountTransactions(context.Background(), tx, domain.AccountJournalQuery{AccountID: 1, Limit: 10})
+
+	require.NoError(t, err)
+	assert.Nil(t, page)
+	mockJournalRepo.AssertNotCalled(t, "ListAccountJournalEntries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func accountOpenedAt(t *testing.T, accountID uint, initialBalance int64, openedAt time.Time) domain.EventEnvelope {
+	envelope := accountOpenedEnvelope(t, accountID, accountID, initialBalance)
+	envelope.OccurredAt = openedAt
+	return envelope
+}
+
+func TestAccountService_GetAccountBalanceAt_FromSnapshot(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+	openedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	asOf := openedAt.Add(48 * time.Hour)
+	point := domain.BalancePoint{Time: &asOf}
+	snapshot := &domain.AccountSnapshot{
+		AccountID:   1,
+		EventID:     25,
+		Balance:     decimal.NewFromInt(250),
+		LastEventID: 20,
+		LastEntryAt: openedAt.Add(24 * time.Hour),
+	}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockEventStore.On("LoadStream", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0)).Return([]domain.EventEnvelope{accountOpenedAt(t, 1, 100, openedAt)}, nil)
+	mockSnapshotRepo.On("GetLatestAccountSnapshot", mock.Anything, tx, uint(1), point).Return(snapshot, nil)
+	mockJournalRepo.On("SumAccountJournalEntries", mock.Anything, tx, domain.AccountJournalRange{AccountID: 1, AfterEventID: 25, Until: point}).
+		Return(&domain.JournalSum{Net: decimal.NewFromInt(-30), Count: 2, LastEventID: 31}, nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	balance, err := svc.GetAccountBalanceAt(context.Background(), tx, 1, point)
+
+	require.NoError(t, err)
+	require.NotNil(t, balance)
+	assert.True(t, decimal.NewFromInt(220).Equal(balance.Balance))
+	assert.Equal(t, uint(31), balance.LastEventID)
+	assert.Equal(t, "USD", balance.Currency)
+	assert.Equal(t, &asOf, balance.AsOf)
+	mockJournalRepo.AssertExpectations(t)
+	mockSnapshotRepo.AssertExpectations(t)
+}
+
+func TestAccountService_GetAccountBalanceAt_WithoutSnapshot(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+	eventID := uint(7)
+	point := domain.BalancePoint{EventID: &eventID}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockEventStore.On("LoadStream", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0)).Return([]domain.EventEnvelope{accountOpenedAt(t, 1, 100, time.Now())}, nil)
+	mockSnapshotRepo.On("GetLatestAccountSnapshot", mock.Anything, tx, uint(1), point).Return(nil, nil)
+	mockJournalRepo.On("SumAccountJournalEntries", mock.Anything, tx, domain.AccountJournalRange{AccountID: 1, Until: point}).
+		Return(&domain.JournalSum{Net: decimal.Zero}, nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	balance, err := svc.GetAccountBalanceAt(context.Background(), tx, 1, point)
+
+	require.NoError(t, err)
+	require.NotNil(t, balance)
+	assert.True(t, decimal.NewFromInt(100).Equal(balance.Balance))
+	assert.Equal(t, uint(0), balance.LastEventID)
+	assert.Equal(t, &eventID, balance.AsOfEventID)
+}
+
+func TestAccountService_GetAccountBalanceAt_BeforeOpening(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+	openedAt := time.Now()
+	asOf := openedAt.Add(-time.Hour)
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockEventStore.On("LoadStream", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0)).Return([]domain.EventEnvelope{accountOpenedAt(t, 1, 100, openedAt)}, nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	balance, err := svc.GetAccountBalanceAt(context.Background(), tx, 1, domain.BalancePoint{Time: &asOf})
+
+	assert.ErrorIs(t, err, service.ErrAccountNotOpenedYet)
+	assert.Nil(t, balance)
+	mockJournalRepo.AssertNotCalled(t, "SumAccountJournalEntries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountService_GetAccountBalanceAt_MissingOpeningEvent(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockEventStore.On("LoadStream", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0)).Return([]domain.EventEnvelope{}, nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	balance, err := svc.GetAccountBalanceAt(context.Background(), tx, 1, domain.BalancePoint{Time: &now})
+
+	assert.ErrorIs(t, err, service.ErrIncompleteEventLog)
+	assert.Nil(t, balance)
+}
+
+func TestAccountService_GetAccountBalanceAt_AccountNotFound(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(nil, nil)
+
+	svc := service.NewAccountService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockOutboxRepo, &MockTransactionService{})
+
+	balance, err := svc.GetAccountBalanceAt(context.Background(), tx, 1, domain.BalancePoint{Time: &now})
+
+	require.NoError(t, err)
+	assert.Nil(t, balance)
+	mockEventStore.AssertNotCalled(t, "LoadStream", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type activityMocks struct {
+	accountRepo       *MockAccountRepository
+	balanceRepo       *MockAccountBalanceRepository
+	transferEventRepo *MockTransferEventRepository
+	journalRepo       *MockJournalRepository
+}
+
+func newActivityService() (service.ActivityService, activityMocks) {
+	m := activityMocks{
+		accountRepo:       &MockAccountRepository{},
+		balanceRepo:       &MockAccountBalanceRepository{},
+		transferEventRepo: &MockTransferEventRepository{},
+		journalRepo:       &MockJournalRepository{},
+	}
+	return service.NewActivityService(m.accountRepo, m.balanceRepo, m.transferEventRepo, m.journalRepo), m
+}
+
+func activityEntry(eventID, accountID uint, entryType domain.EntryType, amount int64) domain.JournalEntry {
+	return domain.JournalEntry{AccountID: accountID, Amount: decimal.NewFromInt(amount), Currency: "USD", Type: entryType, SourceEventID: eventID}
+}
+
+func TestActivityService_OpenActivityStream(t *testing.T) {
+	tx := &gorm.DB{}
+
+	t.Run("starts after the latest sealed event", func(t *testing.T) {
+		svc, m := newActivityService()
+		m.accountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
+		m.transferEventRepo.On("GetLastTransferEventChainSeq", mock.Anything, tx).Return(uint(41), nil)
+
+		cursor, err := svc.OpenActivityStream(context.Background(), tx, 1, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.ActivityCursor{ChainSeq: 41}, cursor)
+	})
+
+	t.Run("resumes after the last chain sequence", func(t *testing.T) {
+		svc, m := newActivityService()
+		lastChainSeq := uint(12)
+
+		cursor, err := svc.OpenActivityStream(context.Background(), tx, 0, &lastChainSeq)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.ActivityCursor{ChainSeq: 12}, cursor)
+		m.transferEventRepo.AssertNotCalled(t, "GetLastTransferEventChainSeq", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		svc, m := newActivityService()
+		m.accountRepo.On("AccountExists", mock.Anything, tx, uint(9)).Return(false, nil)
+
+		_, err := svc.OpenActivityStream(context.Background(), tx, 9, nil)
+
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+	})
+}
+
+func TestActivityService_PollActivity_AllAccounts(t *testing.T) {
+	svc, m := newActivityService()
+	tx := &gorm.DB{}
+
+	// Events 7, 9 and 8 were committed in that order, so they were sealed at
+	// chain sequences 4, 5 and 6. Event 7 moves 40 from account 1 to 2, event
+	// 9 moves 15 from account 2 back to 1 and event 8 moves 5 from account 3
+	// to 1.
+	entries := []domain.JournalEntry{
+		activityEntry(7, 1, domain.Debit, 40), activityEntry(7, 2, domain.Credit, 40),
+		activityEntry(8, 3, domain.Debit, 5), activityEntry(8, 1, domain.Credit, 5),
+		activityEntry(9, 2, domain.Debit, 15), activityEntry(9, 1, domain.Credit, 15),
+	}
+	m.transferEventRepo.On("ListTransferEventsInChainOrder", mock.Anything, tx, uint(3), 100).Return([]domain.TransferEvent{
+		{EventID: 7, ChainSeq: 4, FromAccountID: 1, ToAccountID: 2, RequestFingerprint: "fingerprint"},
+		{EventID: 9, ChainSeq: 5, FromAccountID: 2, ToAccountID: 1},
+		{EventID: 8, ChainSeq: 6, FromAccountID: 3, ToAccountID: 1},
+	}, nil)
+	m.journalRepo.On("ListJournalEntriesOfEvents", mock.Anything, tx, []uint{7, 9, 8}, uint(0)).Return(entries, nil)
+	chainSeq := uint(6)
+	for accountID, balance := range map[uint]int64{1: 75, 2: 25, 3: 45} {
+		m.balanceRepo.On("GetAccountBalance", mock.Anything, tx, accountID).Return(&domain.AccountBalance{AccountID: accountID, Balance: decimal.NewFromInt(balance), LastEventID: 9}, nil)
+		m.journalRepo.On("SumAccountJournalEntries", mock.Anything, tx, domain.AccountJournalRange{AccountID: accountID, AfterChainSeq: &chainSeq}).Return(&domain.JournalSum{Net: decimal.Zero}, nil)
+	}
+
+	page, err := svc.PollActivity(context.Background(), tx, service.ActivityQuery{Cursor: domain.ActivityCursor{ChainSeq: 3}, Limit: 100})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ActivityCursor{ChainSeq: 6}, page.Cursor)
+	assert.False(t, page.More)
+	require.Len(t, page.Activities, 3)
+
+	balances := func(activity domain.AccountActivity) map[uint][2]string {
+		got := make(map[uint][2]string)
+		for _, b := range activity.Balances {
+			assert.Equal(t, activity.Transfer.EventID, b.EventID)
+			got[b.AccountID] = [2]string{b.Change.String(), b.Balance.String()}
+		}
+		return got
+	}
+	assert.Equal(t, uint(7), page.Activities[0].Transfer.EventID)
+	assert.Empty(t, page.Activities[0].Transfer.RequestFingerprint)
+	assert.Equal(t, map[uint][2]string{1: {"-40", "55"}, 2: {"40", "40"}}, balances(page.Activities[0]))
+	assert.Equal(t, map[uint][2]string{1: {"15", "70"}, 2: {"-15", "25"}}, balances(page.Activities[1]))
+	assert.Equal(t, map[uint][2]string{1: {"5", "75"}, 3: {"-5", "45"}}, balances(page.Activities[2]))
+}
+
+func TestActivityService_PollActivity_OneAccount(t *testing.T) {
+	svc, m := newActivityService()
+	tx := &gorm.DB{}
+
+	// Only event 7 of the page touches account 2, which an event sealed or
+	// committed since debited by 15.
+	m.transferEventRepo.On("ListTransferEventsInChainOrder", mock.Anything, tx, uint(6), 2).Return([]domain.TransferEvent{
+		{EventID: 7, ChainSeq: 7, FromAccountID: 1, ToAccountID: 2},
+		{EventID: 8, ChainSeq: 8, FromAccountID: 3, ToAccountID: 1},
+	}, nil)
+	m.journalRepo.On("ListJournalEntriesOfEvents", mock.Anything, tx, []uint{7, 8}, uint(2)).
+		Return([]domain.JournalEntry{activityEntry(7, 2, domain.Credit, 40)}, nil)
+	m.balanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(&domain.AccountBalance{AccountID: 2, Balance: decimal.NewFromInt(25), LastEventID: 9}, nil)
+	chainSeq := uint(8)
+	m.journalRepo.On("SumAccountJournalEntries", mock.Anything, tx, domain.AccountJournalRange{AccountID: 2, AfterChainSeq: &chainSeq}).
+		Return(&domain.JournalSum{Net: decimal.NewFromInt(-15), Count: 1, LastEventID: 9}, nil)
+
+	page, err := svc.PollActivity(context.Background(), tx, service.ActivityQuery{AccountID: 2, Cursor: domain.ActivityCursor{ChainSeq: 6}, Limit: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ActivityCursor{ChainSeq: 8}, page.Cursor)
+	assert.True(t, page.More)
+	require.Len(t, page.Activities, 1)
+	require.Len(t, page.Activities[0].Balances, 1)
+	assert.Equal(t, uint(2), page.Activities[0].Balances[0].AccountID)
+	assert.Equal(t, "40", page.Activities[0].Balances[0].Change.String())
+	assert.Equal(t, "40", page.Activities[0].Balances[0].Balance.String())
+}
+
+func TestActivityService_PollActivity_NothingSealed(t *testing.T) {
+	svc, m := newActivityService()
+	tx := &gorm.DB{}
+
+	m.transferEventRepo.On("ListTransferEventsInChainOrder", mock.Anything, tx, uint(6), 100).Return([]domain.TransferEvent{}, nil)
+
+	page, err := svc.PollActivity(context.Background(), tx, service.ActivityQuery{Cursor: domain.ActivityCursor{ChainSeq: 6}, Limit: 100})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ActivityCursor{ChainSeq: 6}, page.Cursor)
+	assert.Empty(t, page.Activities)
+	assert.False(t, page.More)
+	m.journalRepo.AssertNotCalled(t, "ListJournalEntriesOfEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,286 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func authorizedHold() *domain.Hold {
+	return &domain.Hold{
+		HoldID:               "8d1c4b9e-6a3f-4f0e-9b2d-7c5e1a0f3b21",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Currency:             "USD",
+		Status:               domain.HoldStatusAuthorized,
+		ExpiresAt:            time.Now().Add(time.Hour),
+	}
+}
+
+func TestHoldService_AuthorizeHold_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(500), HeldAmount: decimal.NewFromInt(50), Version: 4, LastEventID: 9}, nil)
+	mockHoldRepo.On("SaveHold", mock.Anything, tx, mock.AnythingOfType("*domain.Hold")).Return(nil)
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.Balance.Equal(decimal.NewFromInt(500)) && b.HeldAmount.Equal(decimal.NewFromInt(150)) && b.Version == 5 && b.LastEventID == 9
+	}), 4).Return(nil)
+	mockEventStore.On("GetStreamSequence", mock.Anything, tx, domain.AggregateTypeHold, mock.AnythingOfType("string")).Return(int64(0), nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeHold, mock.AnythingOfType("string"), int64(0), mock.MatchedBy(func(events []*domain.EventEnvelope) bool {
+		return len(events) == 1 && events[0].EventType == domain.EventTypeHoldAuthorized
+	})).Return(nil).Once()
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.EventType == domain.EventTypeHoldAuthorized && assert.ObjectsAreEqual([]uint{1}, m.AccountIDs)
+	})).Return(nil).Once()
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	hold, err := svc.AuthorizeHold(context.Background(), tx, service.HoldRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		ExpiresIn:            time.Hour,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldStatusAuthorized, hold.Status)
+	assert.Equal(t, "USD", hold.Currency)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), hold.ExpiresAt, time.Minute)
+	mockBalanceRepo.AssertExpectations(t)
+	mockHoldRepo.AssertExpectations(t)
+	mockEventStore.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockJournalRepo.AssertNotCalled(t, "SaveJournalEntry", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHoldService_AuthorizeHold_InsufficientAvailableBalance(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(150), HeldAmount: decimal.NewFromInt(100), Version: 2}, nil)
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	_, err := svc.AuthorizeHold(context.Background(), tx, service.HoldRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(60),
+	})
+
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+	assert.Contains(t, err.Error(), "insufficient available balance")
+	mockHoldRepo.AssertNotCalled(t, "SaveHold", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHoldService_CaptureHold_PartialReleasesRemainder(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	hold := authorizedHold()
+
+	mockHoldRepo.On("LockHold", mock.Anything, tx, hold.HoldID).Return(hold, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(500), HeldAmount: decimal.NewFromInt(100), Version: 3}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(&domain.AccountBalance{AccountID: 2, Balance: decimal.NewFromInt(10), Version: 1}, nil)
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).Return(nil)
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).Return(nil).Twice()
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.AccountID == 1 && b.Balance.Equal(decimal.NewFromInt(460)) && b.HeldAmount.IsZero()
+	}), 3).Return(nil).Once()
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.AccountID == 2 && b.Balance.Equal(decimal.NewFromInt(50))
+	}), 1).Return(nil).Once()
+	mockHoldRepo.On("UpdateHoldStatus", mock.Anything, tx, mock.MatchedBy(func(h *domain.Hold) bool {
+		return h.Status == domain.HoldStatusCaptured && h.CapturedAmount.Equal(decimal.NewFromInt(40))
+	})).Return(nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	event, err := svc.CaptureHold(context.Background(), tx, service.CaptureRequest{
+		HoldID: hold.HoldID,
+		Amount: decimal.NewFromInt(40),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventTypeHoldCaptured, event.EventType)
+	assert.True(t, event.Amount.Equal(decimal.NewFromInt(40)))
+	assert.Equal(t, event.TransferID, hold.CaptureTransferID)
+	mockBalanceRepo.AssertExpectations(t)
+	mockHoldRepo.AssertExpectations(t)
+}
+
+func TestHoldService_CaptureHold_ExceedsHold(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	hold := authorizedHold()
+	mockHoldRepo.On("LockHold", mock.Anything, tx, hold.HoldID).Return(hold, nil)
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	_, err := svc.CaptureHold(context.Background(), tx, service.CaptureRequest{
+		HoldID: hold.HoldID,
+		Amount: decimal.NewFromInt(101),
+	})
+
+	assert.ErrorIs(t, err, service.ErrCaptureExceedsHold)
+}
+
+func TestHoldService_CaptureHold_AlreadyVoided(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	hold := authorizedHold()
+	hold.Status = domain.HoldStatusVoided
+	mockHoldRepo.On("LockHold", mock.Anything, tx, hold.HoldID).Return(hold, nil)
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	_, err := svc.CaptureHold(context.Background(), tx, service.CaptureRequest{HoldID: hold.HoldID})
+
+	assert.ErrorIs(t, err, service.ErrHoldNotAuthorized)
+}
+
+func TestHoldService_VoidHold_ReleasesFunds(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	hold := authorizedHold()
+
+	mockHoldRepo.On("LockHold", mock.Anything, tx, hold.HoldID).Return(hold, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(500), HeldAmount: decimal.NewFromInt(100), Version: 3}, nil)
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.Balance.Equal(decimal.NewFromInt(500)) && b.HeldAmount.IsZero()
+	}), 3).Return(nil)
+	mockHoldRepo.On("UpdateHoldStatus", mock.Anything, tx, hold).Return(nil)
+	mockEventStore.On("GetStreamSequence", mock.Anything, tx, domain.AggregateTypeHold, hold.HoldID).Return(int64(1), nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeHold, hold.HoldID, int64(1), mock.MatchedBy(func(events []*domain.EventEnvelope) bool {
+		return len(events) == 1 && events[0].EventType == domain.EventTypeHoldVoided
+	})).Return(nil).Once()
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.EventType == domain.EventTypeHoldVoided && assert.ObjectsAreEqual([]uint{1}, m.AccountIDs)
+	})).Return(nil).Once()
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	voided, err := svc.VoidHold(context.Background(), tx, hold.HoldID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldStatusVoided, voided.Status)
+	mockBalanceRepo.AssertExpectations(t)
+	mockEventStore.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHoldService_ExpireHold_ReleasesFunds(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	hold := authorizedHold()
+	now := hold.ExpiresAt.Add(time.Second)
+
+	mockHoldRepo.On("LockHold", mock.Anything, tx, hold.HoldID).Return(hold, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(500), HeldAmount: decimal.NewFromInt(100), Version: 3}, nil)
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.HeldAmount.IsZero()
+	}), 3).Return(nil)
+	mockHoldRepo.On("UpdateHoldStatus", mock.Anything, tx, hold).Return(nil)
+	// A hold authorized before hold events were recorded has an empty stream.
+	mockEventStore.On("GetStreamSequence", mock.Anything, tx, domain.AggregateTypeHold, hold.HoldID).Return(int64(0), nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeHold, hold.HoldID, int64(0), mock.MatchedBy(func(events []*domain.EventEnvelope) bool {
+		return len(events) == 1 && events[0].EventType == domain.EventTypeHoldExpired && events[0].OccurredAt.Equal(now)
+	})).Return(nil).Once()
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.EventType == domain.EventTypeHoldExpired
+	})).Return(nil).Once()
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	expired, err := svc.ExpireHold(context.Background(), tx, hold.HoldID, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldStatusExpired, expired.Status)
+	mockEventStore.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestHoldService_ExpireHold_SkipsHoldNotYetDue(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockHoldRepo := &MockHoldRepository{}
+	tx := &gorm.DB{}
+
+	hold := authorizedHold()
+	mockHoldRepo.On("LockHold", mock.Anything, tx, hold.HoldID).Return(hold, nil)
+
+	svc := service.NewHoldService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, mockHoldRepo)
+
+	expired, err := svc.ExpireHold(context.Background(), tx, hold.HoldID, time.Now())
+
+	require.NoError(t, err)
+	assert.Nil(t, expired)
+	mockBalanceRepo.AssertNotCalled(t, "UpdateAccountBalanceWithVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,575 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIntegrityService_VerifyDoubleBookkeeping_Valid(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+
+	totals := map[string]map[domain.EntryType]decimal.Decimal{
+		"USD": {domain.Debit: decimal.NewFromInt(300), domain.Credit: decimal.NewFromInt(300)},
+		"EUR": {domain.Debit: decimal.NewFromInt(50), domain.Credit: decimal.NewFromInt(50)},
+	}
+	mockJournalRepo.On("GetTotalsByCurrencyAndEntryType", mock.Anything, (*gorm.DB)(nil)).Return(totals, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	result, err := svc.VerifyDoubleBookkeeping(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.IsValid)
+	require.Len(t, result.Currencies, 2)
+	assert.Equal(t, "EUR", result.Currencies[0].Currency)
+	assert.Equal(t, "USD", result.Currencies[1].Currency)
+	assert.Equal(t, "350", result.TotalDebits.String())
+	assert.Equal(t, "350", result.TotalCredits.String())
+	assert.True(t, result.Difference.IsZero())
+	mockJournalRepo.AssertExpectations(t)
+}
+
+func TestIntegrityService_VerifyDoubleBookkeeping_UnbalancedCurrency(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+
+	// Globally debits equal credits (350 each), but each currency is off.
+	totals := map[string]map[domain.EntryType]decimal.Decimal{
+		"USD": {domain.Debit: decimal.NewFromInt(300), domain.Credit: decimal.NewFromInt(250)},
+		"EUR": {domain.Debit: decimal.NewFromInt(50), domain.Credit: decimal.NewFromInt(100)},
+	}
+	mockJournalRepo.On("GetTotalsByCurrencyAndEntryType", mock.Anything, (*gorm.DB)(nil)).Return(totals, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	result, err := svc.VerifyDoubleBookkeeping(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsValid)
+	require.Len(t, result.Currencies, 2)
+	assert.True(t, decimal.NewFromInt(-50).Equal(result.Currencies[0].Difference))
+	assert.True(t, decimal.NewFromInt(50).Equal(result.Currencies[1].Difference))
+	assert.True(t, result.Difference.IsZero(), "the top-level totals are not split by currency")
+}
+
+// chainedEvents returns n transfer events, with IDs from 1, sealed into a valid
+// hash chain.
+func chainedEvents(t *testing.T, n int) []domain.TransferEvent {
+	events := make([]domain.TransferEvent, 0, n)
+	prevHash := domain.GenesisHash
+	for i := 1; i <= n; i++ {
+		event := domain.TransferEvent{
+			EventID:       uint(i),
+			TransferID:    fmt.Sprintf("transfer-%d", i),
+			FromAccountID: 1,
+			ToAccountID:   2,
+			Amount:        decimal.NewFromInt(int64(10 * i)),
+			Currency:      "USD",
+			EventType:     domain.EventTypeTransferProcessed,
+			CreatedAt:     time.Date(2030, 3, 1, 12, i, 0, 0, time.UTC),
+			SchemaVersion: domain.TransferEventSchema.Version,
+			ChainSeq:      uint(i),
+			PrevHash:      prevHash,
+		}
+		hash, err := event.ChainHash(prevHash)
+		require.NoError(t, err)
+		event.Hash = hash
+		prevHash = hash
+		events = append(events, event)
+	}
+	return events
+}
+
+// verifyTransferEventChain runs VerifyHashChains over events, with an empty
+// journal, and returns the result for the transfer_events chain.
+func verifyTransferEventChain(t *testing.T, events []domain.TransferEvent, head *domain.HashChainHead) service.HashChainResult {
+	mockJournalRepo := &MockJournalRepository{}
+	mockTransferEventRepo := &MockTransferEventRepository{}
+	mockHashChainRepo := &MockHashChainRepository{}
+	tx := &gorm.DB{}
+
+	mockHashChainRepo.On("GetHashChainHead", mock.Anything, tx, domain.HashChainTransferEvents).Return(head, nil)
+	mockHashChainRepo.On("GetHashChainHead", mock.Anything, tx, domain.HashChainJournalEntries).
+		Return(&domain.HashChainHead{Chain: domain.HashChainJournalEntries, LastHash: domain.GenesisHash}, nil)
+	mockTransferEventRepo.On("ListSealedTransferEvents", mock.Anything, tx, uint(0), mock.Anything).Return(events, nil)
+	if len(events) > 0 {
+		mockTransferEventRepo.On("ListSealedTransferEvents", mock.Anything, tx, events[len(events)-1].ChainSeq, mock.Anything).Return([]domain.TransferEvent{}, nil)
+	}
+	mockJournalRepo.On("ListSealedJournalEntries", mock.Anything, tx, uint(0), mock.Anything).Return([]domain.JournalEntry{}, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, mockJournalRepo, mockTransferEventRepo, mockHashChainRepo, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	verification, err := svc.VerifyHashChains(context.Background(), tx)
+
+	require.NoError(t, err)
+	require.Len(t, verification.Chains, 2)
+	assert.Equal(t, domain.HashChainTransferEvents, verification.Chains[0].Chain)
+	assert.True(t, verification.Chains[1].IsValid)
+	assert.Equal(t, verification.Chains[0].IsValid, verification.IsValid)
+	return verification.Chains[0]
+}
+
+func headOf(events []domain.TransferEvent) *domain.HashChainHead {
+	last := events[len(events)-1]
+	return &domain.HashChainHead{Chain: domain.HashChainTransferEvents, LastSeq: last.ChainSeq, LastID: last.EventID, LastHash: last.Hash}
+}
+
+func TestIntegrityService_VerifyHashChains_Intact(t *testing.T) {
+	events := chainedEvents(t, 3)
+
+	result := verifyTransferEventChain(t, events, headOf(events))
+
+	assert.True(t, result.IsValid)
+	assert.Equal(t, 3, result.RowsVerified)
+	assert.Equal(t, uint(3), result.LastID)
+	assert.Nil(t, result.BrokenLink)
+}
+
+func TestIntegrityService_VerifyHashChains_EditedRow(t *testing.T) {
+	events := chainedEvents(t, 3)
+	head := headOf(events)
+	events[1].Amount = decimal.NewFromInt(1000)
+
+	result := verifyTransferEventChain(t, events, head)
+
+	assert.False(t, result.IsValid)
+	assert.Equal(t, 1, result.RowsVerified)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, uint(2), result.BrokenLink.RowID)
+	assert.Equal(t, uint(1), result.BrokenLink.PreviousRowID)
+	assert.Equal(t, service.BrokenLinkHash, result.BrokenLink.Reason)
+	assert.Equal(t, events[1].Hash, result.BrokenLink.ActualHash)
+}
+
+func TestIntegrityService_VerifyHashChains_DeletedRow(t *testing.T) {
+	events := chainedEvents(t, 3)
+	head := headOf(events)
+	events = append(events[:1], events[2:]...)
+
+	result := verifyTransferEventChain(t, events, head)
+
+	assert.False(t, result.IsValid)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, uint(3), result.BrokenLink.RowID)
+	assert.Equal(t, uint(1), result.BrokenLink.PreviousRowID)
+	assert.Equal(t, service.BrokenLinkPrevHash, result.BrokenLink.Reason)
+	assert.Equal(t, events[0].Hash, result.BrokenLink.ExpectedHash)
+}
+
+func TestIntegrityService_VerifyHashChains_EditedSchemaVersion(t *testing.T) {
+	events := chainedEvents(t, 3)
+	head := headOf(events)
+	events[2].SchemaVersion++
+
+	result := verifyTransferEventChain(t, events, head)
+
+	assert.False(t, result.IsValid)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, uint(3), result.BrokenLink.RowID)
+	assert.Equal(t, service.BrokenLinkHash, result.BrokenLink.Reason)
+}
+
+func TestIntegrityService_SealHashChains(t *testing.T) {
+	mockHashChainRepo := &MockHashChainRepository{}
+	tx := &gorm.DB{}
+
+	mockHashChainRepo.On("SealHashChain", mock.Anything, tx, domain.HashChainTransferEvents, 50).Return(3, nil)
+	mockHashChainRepo.On("SealHashChain", mock.Anything, tx, domain.HashChainJournalEntries, 50).Return(6, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, &MockJournalRepository{}, &MockTransferEventRepository{}, mockHashChainRepo, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	report, err := svc.SealHashChains(context.Background(), tx, 50)
+
+	require.NoError(t, err)
+	assert.Equal(t, &service.HashChainSealReport{TransferEvents: 3, JournalEntries: 6}, report)
+	mockHashChainRepo.AssertExpectations(t)
+}
+
+func TestIntegrityService_VerifyHashChains_DeletedLastRow(t *testing.T) {
+	events := chainedEvents(t, 3)
+	head := headOf(events)
+
+	result := verifyTransferEventChain(t, events[:2], head)
+
+	assert.False(t, result.IsValid)
+	assert.Equal(t, 2, result.RowsVerified)
+	require.NotNil(t, result.BrokenLink)
+	assert.Equal(t, uint(3), result.BrokenLink.RowID)
+	assert.Equal(t, uint(2), result.BrokenLink.PreviousRowID)
+	assert.Equal(t, service.BrokenLinkHead, result.BrokenLink.Reason)
+}
+
+// openedEnvelope returns the stored AccountOpened event of an account, funded
+// when fundingTransferID is set.
+func openedEnvelope(t *testing.T, eventID, accountID uint, initialBalance int64, fundingTransferID string) domain.EventEnvelope {
+	opened := domain.AccountOpened{
+		AccountID:         accountID,
+		Type:              domain.AccountTypeCustomer,
+		Currency:          "USD",
+		InitialBalance:    decimal.NewFromInt(initialBalance),
+		OpeningBalance:    decimal.NewFromInt(initialBalance),
+		FundingTransferID: fundingTransferID,
+	}
+	if fundingTransferID != "" {
+		opened.OpeningBalance = decimal.Zero
+	}
+	envelope, err := domain.NewEventEnvelope(opened, nil, time.Now())
+	require.NoError(t, err)
+	envelope.EventID = eventID
+	return *envelope
+}
+
+func TestIntegrityService_ReconcileAccounts(t *testing.T) {
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	tx := &gorm.DB{}
+
+	// Account 1 was funded with 100 and sent 30 to account 2, whose projection
+	// drifted by 5. Account 3 was opened with 50 before initial balances were
+	// journaled, and account 4 before the event store existed.
+	mockBalanceRepo.On("ListAccountBalances", mock.Anything, tx).Return([]domain.AccountBalance{
+		{AccountID: 1, Balance: decimal.NewFromInt(70), LastEventID: 2},
+		{AccountID: 2, Balance: decimal.NewFromInt(35), LastEventID: 2},
+		{AccountID: 3, Balance: decimal.NewFromInt(50)},
+		{AccountID: 4, Balance: decimal.NewFromInt(20)},
+	}, nil)
+	mockJournalRepo.On("SumJournalEntriesByAccount", mock.Anything, tx).Return(map[uint]domain.JournalSum{
+		1:   {Net: decimal.NewFromInt(70), Count: 2, LastEventID: 2},
+		2:   {Net: decimal.NewFromInt(30), Count: 1, LastEventID: 2},
+		900: {Net: decimal.NewFromInt(-100), Count: 1, LastEventID: 1},
+	}, nil)
+	mockEventStore.On("ListEventsByType", mock.Anything, tx, domain.EventTypeAccountOpened, uint(0), mock.Anything).Return([]domain.EventEnvelope{
+		openedEnvelope(t, 1, 900, 0, ""),
+		openedEnvelope(t, 2, 1, 100, "funding-1"),
+		openedEnvelope(t, 3, 2, 0, ""),
+		openedEnvelope(t, 4, 3, 50, ""),
+	}, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, mockBalanceRepo, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, mockEventStore, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	report, err := svc.ReconcileAccounts(context.Background(), tx)
+
+	require.NoError(t, err)
+	assert.False(t, report.IsValid)
+	assert.Equal(t, 5, report.AccountsChecked)
+	require.Len(t, report.Drifting, 3)
+
+	assert.Equal(t, uint(2), report.Drifting[0].AccountID)
+	assert.Equal(t, service.ReconciliationBalanceMismatch, report.Drifting[0].Reason)
+	assert.Equal(t, "30", report.Drifting[0].ExpectedBalance.String())
+	assert.Equal(t, "5", report.Drifting[0].Difference.String())
+
+	assert.Equal(t, uint(4), report.Drifting[1].AccountID)
+	assert.Equal(t, service.ReconciliationMissingOpening, report.Drifting[1].Reason)
+
+	// The equity account has journal entries but no balance row.
+	assert.Equal(t, uint(900), report.Drifting[2].AccountID)
+	assert.Equal(t, service.ReconciliationMissingBalance, report.Drifting[2].Reason)
+	assert.Equal(t, "-100", report.Drifting[2].ExpectedBalance.String())
+}
+
+func TestIntegrityService_ReconcileAccount(t *testing.T) {
+	tx := &gorm.DB{}
+
+	t.Run("stale last event ID", func(t *testing.T) {
+		mockAccountRepo := &MockAccountRepository{}
+		mockBalanceRepo := &MockAccountBalanceRepository{}
+		mockJournalRepo := &MockJournalRepository{}
+		mockEventStore := &MockEventStore{}
+
+		mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
+		mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(70), LastEventID: 1}, nil)
+		mockJournalRepo.On("SumAccountJournalEntries", mock.Anything, tx, domain.AccountJournalRange{AccountID: 1}).
+			Return(&domain.JournalSum{Net: decimal.NewFromInt(70), Count: 2, LastEventID: 2}, nil)
+		mockEventStore.On("LoadStream", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0)).
+			Return([]domain.EventEnvelope{openedEnvelope(t, 2, 1, 100, "funding-1")}, nil)
+
+		svc := service.NewIntegrityService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, mockEventStore, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+		reconciliation, err := svc.ReconcileAccount(context.Background(), tx, 1)
+
+		require.NoError(t, err)
+		assert.False(t, reconciliation.IsValid)
+		assert.Equal(t, service.ReconciliationLastEventMismatch, reconciliation.Reason)
+		assert.True(t, reconciliation.OpeningBalance.IsZero())
+		assert.True(t, reconciliation.Difference.IsZero())
+		assert.Equal(t, uint(2), reconciliation.ExpectedLastEventID)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		mockAccountRepo := &MockAccountRepository{}
+		mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(9)).Return(false, nil)
+
+		svc := service.NewIntegrityService(mockAccountRepo, &MockAccountBalanceRepository{}, &MockJournalRepository{}, &MockTransferEventRepository{}, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+		_, err := svc.ReconcileAccount(context.Background(), tx, 9)
+
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+	})
+}
+
+func transactionEntry(transactionID string, accountID uint, entryType domain.EntryType, amount, currency string) domain.JournalEntry {
+	return domain.JournalEntry{TransactionID: transactionID, AccountID: accountID, Type: entryType, Amount: decimal.RequireFromString(amount), Currency: currency}
+}
+
+func TestIntegrityService_VerifyTransactions(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockTransferEventRepo := &MockTransferEventRepository{}
+	tx := &gorm.DB{}
+
+	conversion := &domain.Conversion{Rate: decimal.RequireFromString("1.1"), DestinationAmount: decimal.RequireFromString("110"), DestinationCurrency: "USD"}
+	events := []domain.TransferEvent{
+		{EventID: 1, TransferID: "t1", FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(50), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+		{EventID: 2, TransferID: "t2", FromAccountID: 1, ToAccountID: 3, Amount: decimal.NewFromInt(100), Currency: "EUR", EventType: domain.EventTypeTransferProcessed, Conversion: conversion},
+		{EventID: 3, TransferID: "t3", FromAccountID: 3, ToAccountID: 1, Amount: decimal.NewFromInt(10), Currency: "EUR", EventType: domain.EventTypeTransferReversed, ReversesTransferID: "t2"},
+		{EventID: 4, TransferID: "t4", FromAccountID: 2, ToAccountID: 1, Amount: decimal.NewFromInt(20), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+		{EventID: 5, TransferID: "t5", FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(20), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+	}
+	// t1, the conversion t2 and its partial reversal t3 are sound. t4 and t5
+	// offset each other globally: t4 lost 5 on its credit and t5 credited the
+	// wrong account.
+	entries := []domain.JournalEntry{
+		transactionEntry("t1", 1, domain.Debit, "50", "EUR"), transactionEntry("t1", 2, domain.Credit, "50", "EUR"),
+		transactionEntry("t2", 1, domain.Debit, "100", "EUR"), transactionEntry("t2", 900, domain.Credit, "100", "EUR"),
+		transactionEntry("t2", 901, domain.Debit, "110", "USD"), transactionEntry("t2", 3, domain.Credit, "110", "USD"),
+		transactionEntry("t3", 1, domain.Credit, "10", "EUR"), transactionEntry("t3", 900, domain.Debit, "10", "EUR"),
+		transactionEntry("t3", 901, domain.Credit, "11", "USD"), transactionEntry("t3", 3, domain.Debit, "11", "USD"),
+		transactionEntry("t4", 2, domain.Debit, "20", "EUR"), transactionEntry("t4", 1, domain.Credit, "15", "EUR"),
+		transactionEntry("t5", 1, domain.Debit, "20", "EUR"), transactionEntry("t5", 4, domain.Credit, "25", "EUR"),
+	}
+
+	mockTransferEventRepo.On("ListTransferEventsAfter", mock.Anything, tx, uint(0), mock.Anything).Return(events, nil)
+	mockTransferEventRepo.On("ListTransferEventsAfter", mock.Anything, tx, uint(5), mock.Anything).Return([]domain.TransferEvent{}, nil)
+	mockTransferEventRepo.On("GetTransferEventByTransferID", mock.Anything, tx, "t2").Return(&events[1], nil)
+	mockJournalRepo.On("ListJournalEntriesByTransactionIDs", mock.Anything, tx, []string{"t1", "t2", "t3", "t4", "t5"}).Return(entries, nil)
+	mockAccountRepo.On("GetSystemAccount", mock.Anything, tx, domain.AccountTypeFXPosition, "EUR").Return(&domain.Account{ID: 900}, nil).Once()
+	mockAccountRepo.On("GetSystemAccount", mock.Anything, tx, domain.AccountTypeFXPosition, "USD").Return(&domain.Account{ID: 901}, nil).Once()
+
+	svc := service.NewIntegrityService(mockAccountRepo, &MockAccountBalanceRepository{}, mockJournalRepo, mockTransferEventRepo, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	report, err := svc.VerifyTransactions(context.Background(), tx)
+
+	require.NoError(t, err)
+	assert.False(t, report.IsValid)
+	assert.Equal(t, 5, report.TransactionsChecked)
+	require.Len(t, report.Invalid, 2)
+
+	t4 := report.Invalid[0]
+	assert.Equal(t, "t4", t4.TransactionID)
+	assert.Equal(t, []service.TransactionIssueReason{service.TransactionUnbalanced, service.TransactionLegsMismatch}, t4.Reasons)
+	require.Len(t, t4.Unbalanced, 1)
+	assert.Equal(t, "5", t4.Unbalanced[0].Difference.String())
+	require.Len(t, t4.MissingLegs, 1)
+	assert.Equal(t, "20", t4.MissingLegs[0].Amount.String())
+	require.Len(t, t4.UnexpectedLegs, 1)
+	assert.Equal(t, "15", t4.UnexpectedLegs[0].Amount.String())
+
+	t5 := report.Invalid[1]
+	assert.Equal(t, "t5", t5.TransactionID)
+	require.Len(t, t5.UnexpectedLegs, 1)
+	assert.Equal(t, uint(4), t5.UnexpectedLegs[0].AccountID)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestIntegrityService_VerifyTransactions_MissingOriginal(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockTransferEventRepo := &MockTransferEventRepository{}
+	tx := &gorm.DB{}
+
+	reversal := domain.TransferEvent{EventID: 1, TransferID: "t2", FromAccountID: 2, ToAccountID: 1, Amount: decimal.NewFromInt(5), Currency: "USD", EventType: domain.EventTypeTransferReversed, ReversesTransferID: "t1"}
+	mockTransferEventRepo.On("ListTransferEventsAfter", mock.Anything, tx, uint(0), mock.Anything).Return([]domain.TransferEvent{reversal}, nil)
+	mockTransferEventRepo.On("ListTransferEventsAfter", mock.Anything, tx, uint(1), mock.Anything).Return([]domain.TransferEvent{}, nil)
+	mockTransferEventRepo.On("GetTransferEventByTransferID", mock.Anything, tx, "t1").Return(nil, nil)
+	mockJournalRepo.On("ListJournalEntriesByTransactionIDs", mock.Anything, tx, []string{"t2"}).Return([]domain.JournalEntry{
+		transactionEntry("t2", 2, domain.Debit, "5", "USD"), transactionEntry("t2", 1, domain.Credit, "5", "USD"),
+	}, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, mockJournalRepo, mockTransferEventRepo, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	report, err := svc.VerifyTransactions(context.Background(), tx)
+
+	require.NoError(t, err)
+	require.Len(t, report.Invalid, 1)
+	assert.Equal(t, []service.TransactionIssueReason{service.TransactionMissingOriginal}, report.Invalid[0].Reasons)
+}
+
+func TestIntegrityService_RunIntegrityCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		debits int64
+		want   domain.IntegrityStatus
+	}{
+		{"balanced", 100, domain.IntegrityStatusPassed},
+		{"unbalanced", 90, domain.IntegrityStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJournalRepo := &MockJournalRepository{}
+			totals := map[string]map[domain.EntryType]decimal.Decimal{
+				"USD": {domain.Debit: decimal.NewFromInt(tt.debits), domain.Credit: decimal.NewFromInt(100)},
+			}
+			mockJournalRepo.On("GetTotalsByCurrencyAndEntryType", mock.Anything, (*gorm.DB)(nil)).Return(totals, nil)
+
+			svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+			result, err := svc.RunIntegrityCheck(context.Background(), nil, domain.IntegrityCheckDoubleBookkeeping)
+
+			require.NoError(t, err)
+			assert.Equal(t, domain.IntegrityCheckDoubleBookkeeping, result.Check)
+			assert.Equal(t, tt.want, result.Status)
+			assert.Contains(t, string(result.Report), `"currency":"USD"`)
+		})
+	}
+}
+
+func TestIntegrityService_RunIntegrityCheck_UnknownCheck(t *testing.T) {
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, &MockJournalRepository{}, &MockTransferEventRepository{}, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	result, err := svc.RunIntegrityCheck(context.Background(), nil, "ledger_vibes")
+
+	assert.ErrorIs(t, err, service.ErrUnknownIntegrityCheck)
+	assert.Nil(t, result)
+}
+
+func TestIntegrityService_RepairAccounts(t *testing.T) {
+	tx := &gorm.DB{}
+
+	// Account 1 was funded with 100 and sent 30, but its projection drifted to
+	// 75 and still points to the funding event.
+	drifting := func() (*MockAccountRepository, *MockAccountBalanceRepository, *MockJournalRepository, *MockEventStore) {
+		mockAccountRepo := &MockAccountRepository{}
+		mockBalanceRepo := &MockAccountBalanceRepository{}
+		mockJournalRepo := &MockJournalRepository{}
+		mockEventStore := &MockEventStore{}
+
+		mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
+		mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).
+			Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(75), HeldAmount: decimal.NewFromInt(10), Version: 4, LastEventID: 1}, nil)
+		mockJournalRepo.On("SumAccountJournalEntries", mock.Anything, tx, domain.AccountJournalRange{AccountID: 1}).
+			Return(&domain.JournalSum{Net: decimal.NewFromInt(70), Count: 2, LastEventID: 2}, nil)
+		mockEventStore.On("LoadStream", mock.Anything, tx, domain.AggregateTypeAccount, "1", int64(0)).
+			Return([]domain.EventEnvelope{openedEnvelope(t, 2, 1, 100, "funding-1")}, nil)
+		return mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore := drifting()
+		mockRepairRepo := &MockProjectionRepairRepository{}
+
+		svc := service.NewIntegrityService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, mockEventStore, &MockIntegrityRunRepository{}, mockRepairRepo)
+
+		report, err := svc.RepairAccounts(context.Background(), tx, service.ProjectionRepairRequest{AccountID: 1, DryRun: true})
+
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		require.Len(t, report.Repairs, 1)
+		assert.Equal(t, "75", report.Repairs[0].OldBalance.String())
+		assert.Equal(t, "70", report.Repairs[0].NewBalance.String())
+		assert.Equal(t, uint(2), report.Repairs[0].NewLastEventID)
+		mockBalanceRepo.AssertNotCalled(t, "UpdateAccountBalanceWithVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepairRepo.AssertNotCalled(t, "SaveProjectionRepair", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		mockAccountRepo, mockBalanceRepo, mockJournalRepo, mockEventStore := drifting()
+		mockRepairRepo := &MockProjectionRepairRepository{}
+
+		mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+			return b.AccountID == 1 && b.Balance.Equal(decimal.NewFromInt(70)) && b.HeldAmount.Equal(decimal.NewFromInt(10)) && b.Version == 5 && b.LastEventID == 2
+		}), 4).Return(nil)
+		mockRepairRepo.On("SaveProjectionRepair", mock.Anything, tx, mock.MatchedBy(func(r *domain.ProjectionRepair) bool {
+			return r.AccountID == 1 && r.Operator == "alice" && r.Reason == string(service.ReconciliationBalanceMismatch) && r.OldLastEventID == 1
+		})).Return(nil)
+
+		svc := service.NewIntegrityService(mockAccountRepo, mockBalanceRepo, mockJournalRepo, &MockTransferEventRepository{}, &MockHashChainRepository{}, mockEventStore, &MockIntegrityRunRepository{}, mockRepairRepo)
+
+		report, err := svc.RepairAccounts(context.Background(), tx, service.ProjectionRepairRequest{AccountID: 1, Operator: "alice"})
+
+		require.NoError(t, err)
+		assert.False(t, report.DryRun)
+		require.Len(t, report.Repairs, 1)
+		mockBalanceRepo.AssertExpectations(t)
+		mockRepairRepo.AssertExpectations(t)
+	})
+
+	t.Run("operator required", func(t *testing.T) {
+		svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, &MockJournalRepository{}, &MockTransferEventRepository{}, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+		_, err := svc.RepairAccounts(context.Background(), tx, service.ProjectionRepairRequest{AccountID: 1})
+
+		assert.ErrorIs(t, err, service.ErrRepairOperatorRequired)
+	})
+}
+
+func linkedEntry(sourceEventID uint, entry domain.JournalEntry) domain.JournalEntry {
+	entry.SourceEventID = sourceEventID
+	return entry
+}
+
+func TestIntegrityService_VerifyLinkage(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockTransferEventRepo := &MockTransferEventRepository{}
+	tx := &gorm.DB{}
+
+	events := []domain.TransferEvent{
+		{EventID: 1, TransferID: "t1", FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(50), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+		{EventID: 2, TransferID: "t2", FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(20), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+		{EventID: 3, TransferID: "t3", FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(30), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+		{EventID: 4, TransferID: "t4", FromAccountID: 2, ToAccountID: 1, Amount: decimal.NewFromInt(10), Currency: "EUR", EventType: domain.EventTypeTransferProcessed},
+	}
+	// t1 is sound, t2 posted nothing, t3 has an extra credit and the entries
+	// of t4 were recorded under another transaction.
+	entries := []domain.JournalEntry{
+		linkedEntry(1, transactionEntry("t1", 1, domain.Debit, "50", "EUR")), linkedEntry(1, transactionEntry("t1", 2, domain.Credit, "50", "EUR")),
+		linkedEntry(3, transactionEntry("t3", 1, domain.Debit, "30", "EUR")), linkedEntry(3, transactionEntry("t3", 2, domain.Credit, "30", "EUR")),
+		linkedEntry(3, transactionEntry("t3", 2, domain.Credit, "5", "EUR")),
+		linkedEntry(4, transactionEntry("t9", 2, domain.Debit, "10", "EUR")), linkedEntry(4, transactionEntry("t9", 1, domain.Credit, "10", "EUR")),
+	}
+	orphaned := []domain.JournalEntry{linkedEntry(77, transactionEntry("t77", 1, domain.Debit, "5", "EUR"))}
+
+	mockTransferEventRepo.On("ListTransferEventsAfter", mock.Anything, tx, uint(0), mock.Anything).Return(events, nil)
+	mockTransferEventRepo.On("ListTransferEventsAfter", mock.Anything, tx, uint(4), mock.Anything).Return([]domain.TransferEvent{}, nil)
+	mockJournalRepo.On("ListJournalEntriesInEventRange", mock.Anything, tx, domain.JournalEventRange{AfterEventID: 0, UpToEventID: 4}).Return(entries, nil)
+	mockJournalRepo.On("ListOrphanedJournalEntries", mock.Anything, tx, mock.Anything).Return(orphaned, nil)
+	mockJournalRepo.On("ListJournalEntriesWithMissingAccount", mock.Anything, tx, mock.Anything).Return([]domain.JournalEntry{}, nil)
+
+	svc := service.NewIntegrityService(&MockAccountRepository{}, &MockAccountBalanceRepository{}, mockJournalRepo, mockTransferEventRepo, &MockHashChainRepository{}, &MockEventStore{}, &MockIntegrityRunRepository{}, &MockProjectionRepairRepository{})
+
+	report, err := svc.VerifyLinkage(context.Background(), tx)
+
+	require.NoError(t, err)
+	assert.False(t, report.IsValid)
+	assert.Equal(t, 4, report.EventsChecked)
+	require.Len(t, report.Events, 3)
+
+	assert.Equal(t, uint(2), report.Events[0].EventID)
+	assert.Equal(t, []service.LinkageIssueReason{service.LinkageOrphanedEvent}, report.Events[0].Reasons)
+
+	t3 := report.Events[1]
+	assert.Equal(t, uint(3), t3.EventID)
+	assert.Equal(t, []service.LinkageIssueReason{service.LinkageLegCountMismatch, service.LinkageAmountMismatch}, t3.Reasons)
+	assert.Equal(t, 1, t3.ExpectedCredits)
+	assert.Equal(t, 2, t3.Credits)
+	require.Len(t, t3.Amounts, 1)
+	assert.Equal(t, domain.Credit, t3.Amounts[0].Type)
+	assert.Equal(t, "30", t3.Amounts[0].Expected.String())
+	assert.Equal(t, "35", t3.Amounts[0].Posted.String())
+
+	assert.Equal(t, uint(4), report.Events[2].EventID)
+	assert.Equal(t, []service.LinkageIssueReason{service.LinkageTransactionMismatch}, report.Events[2].Reasons)
+
+	require.Len(t, report.OrphanedEntries, 1)
+	assert.Equal(t, uint(77), report.OrphanedEntries[0].SourceEventID)
+	assert.Empty(t, report.EntriesWithMissingAccount)
+}
@@ -2,8 +2,10 @@ package unit
 
 import (
 	"context"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
@@ -31,6 +33,24 @@ func (m *MockAccountRepository) AccountExists(ctx context.Context, tx *gorm.DB,
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAccountRepository) GetSystemAccount(ctx context.Context, tx *gorm.DB, accountType domain.AccountType, currency string) (*domain.Account, error) {
+	args := m.Called(ctx, tx, accountType, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) LockSystemAccounts(ctx context.Context, tx *gorm.DB) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) NextSystemAccountID(ctx context.Context, tx *gorm.DB) (uint, error) {
+	args := m.Called(ctx, tx)
+	return args.Get(0).(uint), args.Error(1)
+}
+
 type MockAccountBalanceRepository struct {
 	mock.Mock
 }
@@ -53,6 +73,14 @@ func (m *MockAccountBalanceRepository) UpdateAccountBalanceWithVersion(ctx conte
 	return args.Error(0)
 }
 
+func (m *MockAccountBalanceRepository) ListAccountBalances(ctx context.Context, tx *gorm.DB) ([]domain.AccountBalance, error) {
+	args := m.Called(ctx, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AccountBalance), args.Error(1)
+}
+
 type MockTransferEventRepository struct {
 	mock.Mock
 }
@@ -62,6 +90,246 @@ func (m *MockTransferEventRepository) SaveTransferEvent(ctx context.Context, tx
 	return args.Error(0)
 }
 
+func (m *MockTransferEventRepository) GetTransferEventByTransferID(ctx context.Context, tx *gorm.DB, transferID string) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, transferID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) LockTransferEvent(ctx context.Context, tx *gorm.DB, transferID string) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, transferID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) GetReversalsOfTransfer(ctx context.Context, tx *gorm.DB, transferID string) ([]domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, transferID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) GetTransferEventByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, idempotencyKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) GetLastTransferEventChainSeq(ctx context.Context, tx *gorm.DB) (uint, error) {
+	args := m.Called(ctx, tx)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) ListTransferEventsInChainOrder(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, afterChainSeq, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) ListTransferEventsAfter(ctx context.Context, tx *gorm.DB, afterEventID uint, limit int) ([]domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, afterEventID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransferEventRepository) ListSealedTransferEvents(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, afterChainSeq, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransferEvent), args.Error(1)
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) SaveOutboxMessage(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage) error {
+	args := m.Called(ctx, tx, message)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) TryLockOutboxRelay(ctx context.Context, tx *gorm.DB) (bool, error) {
+	args := m.Called(ctx, tx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) ListPendingOutboxMessages(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) UpdateOutboxMessageDelivery(ctx context.Context, tx *gorm.DB, message *domain.OutboxMessage) error {
+	args := m.Called(ctx, tx, message)
+	return args.Error(0)
+}
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) SaveWebhookSubscription(ctx context.Context, tx *gorm.DB, subscription *domain.WebhookSubscription) error {
+	args := m.Called(ctx, tx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateWebhookSubscription(ctx context.Context, tx *gorm.DB, subscription *domain.WebhookSubscription) error {
+	args := m.Called(ctx, tx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, tx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) LockWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, tx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListWebhookSubscriptions(ctx context.Context, tx *gorm.DB) ([]domain.WebhookSubscription, error) {
+	args := m.Called(ctx, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListActiveWebhookSubscriptions(ctx context.Context, tx *gorm.DB) ([]domain.WebhookSubscription, error) {
+	args := m.Called(ctx, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhookSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string) error {
+	args := m.Called(ctx, tx, subscriptionID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) SaveWebhookDeliveries(ctx context.Context, tx *gorm.DB, deliveries []*domain.WebhookDelivery) (int, error) {
+	args := m.Called(ctx, tx, deliveries)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) LockWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimWebhookDelivery(ctx context.Context, tx *gorm.DB, deliveryID uint) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListWebhookDeliveries(ctx context.Context, tx *gorm.DB, subscriptionID string, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, tx, subscriptionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListDueWebhookDeliveries(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateWebhookDelivery(ctx context.Context, tx *gorm.DB, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, tx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) SaveWebhookDeliveryAttempt(ctx context.Context, tx *gorm.DB, attempt *domain.WebhookDeliveryAttempt) error {
+	args := m.Called(ctx, tx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListWebhookDeliveryAttempts(ctx context.Context, tx *gorm.DB, deliveryID uint) ([]domain.WebhookDeliveryAttempt, error) {
+	args := m.Called(ctx, tx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDeliveryAttempt), args.Error(1)
+}
+
+type MockEventStore struct {
+	mock.Mock
+}
+
+func (m *MockEventStore) AppendEvents(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string, expectedSequence int64, events []*domain.EventEnvelope) error {
+	args := m.Called(ctx, tx, aggregateType, aggregateID, expectedSequence, events)
+	return args.Error(0)
+}
+
+func (m *MockEventStore) GetStreamSequence(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string) (int64, error) {
+	args := m.Called(ctx, tx, aggregateType, aggregateID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockEventStore) LoadStream(ctx context.Context, tx *gorm.DB, aggregateType, aggregateID string, afterSequence int64) ([]domain.EventEnvelope, error) {
+	args := m.Called(ctx, tx, aggregateType, aggregateID, afterSequence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.EventEnvelope), args.Error(1)
+}
+
+func (m *MockEventStore) ListEvents(ctx context.Context, tx *gorm.DB, afterEventID uint, limit int) ([]domain.EventEnvelope, error) {
+	args := m.Called(ctx, tx, afterEventID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.EventEnvelope), args.Error(1)
+}
+
+func (m *MockEventStore) ListEventsByType(ctx context.Context, tx *gorm.DB, eventType string, afterEventID uint, limit int) ([]domain.EventEnvelope, error) {
+	args := m.Called(ctx, tx, eventType, afterEventID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.EventEnvelope), args.Error(1)
+}
+
 type MockJournalRepository struct {
 	mock.Mock
 }
@@ -71,7 +339,437 @@ func (m *MockJournalRepository) SaveJournalEntry(ctx context.Context, tx *gorm.D
 	return args.Error(0)
 }
 
-func (m *MockJournalRepository) GetTotalsByEntryType(ctx context.Context, tx *gorm.DB) (map[domain.EntryType]decimal.Decimal, error) {
+func (m *MockJournalRepository) GetJournalEntriesByTransactionID(ctx context.Context, tx *gorm.DB, transactionID string) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListAccountJournalEntries(ctx context.Context, tx *gorm.DB, query domain.AccountJournalQuery) ([]domain.AccountJournalEntry, error) {
+	args := m.Called(ctx, tx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AccountJournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) GetAccountNetChangeAfter(ctx context.Context, tx *gorm.DB, accountID uint, cursor domain.JournalEntryCursor) (decimal.Decimal, error) {
+	args := m.Called(ctx, tx, accountID, cursor)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockJournalRepository) GetTotalsByCurrencyAndEntryType(ctx context.Context, tx *gorm.DB) (map[string]map[domain.EntryType]decimal.Decimal, error) {
 	args := m.Called(ctx, tx)
-	return args.Get(0).(map[domain.EntryType]decimal.Decimal), args.Error(1)
+	return args.Get(0).(map[string]map[domain.EntryType]decimal.Decimal), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListJournalEntriesInEventOrder(ctx context.Context, tx *gorm.DB, afterEventID, afterEntryID uint, limit int) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, afterEventID, afterEntryID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListJournalEntriesOfEvents(ctx context.Context, tx *gorm.DB, eventIDs []uint, accountID uint) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, eventIDs, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListJournalEntriesInEventRange(ctx context.Context, tx *gorm.DB, r domain.JournalEventRange) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListJournalEntriesAfter(ctx context.Context, tx *gorm.DB, afterEntryID uint, limit int) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, afterEntryID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListSealedJournalEntries(ctx context.Context, tx *gorm.DB, afterChainSeq uint, limit int) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, afterChainSeq, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) SumAccountJournalEntries(ctx context.Context, tx *gorm.DB, r domain.AccountJournalRange) (*domain.JournalSum, error) {
+	args := m.Called(ctx, tx, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.JournalSum), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListOrphanedJournalEntries(ctx context.Context, tx *gorm.DB, limit int) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListJournalEntriesWithMissingAccount(ctx context.Context, tx *gorm.DB, limit int) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) ListJournalEntriesByTransactionIDs(ctx context.Context, tx *gorm.DB, transactionIDs []string) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, tx, transactionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockJournalRepository) SumJournalEntriesByAccount(ctx context.Context, tx *gorm.DB) (map[uint]domain.JournalSum, error) {
+	args := m.Called(ctx, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uint]domain.JournalSum), args.Error(1)
+}
+
+func (m *MockJournalRepository) GetLastSourceEventIDBefore(ctx context.Context, tx *gorm.DB, cutoff time.Time) (uint, error) {
+	args := m.Called(ctx, tx, cutoff)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+type MockHashChainRepository struct {
+	mock.Mock
+}
+
+func (m *MockHashChainRepository) GetHashChainHead(ctx context.Context, tx *gorm.DB, chain string) (*domain.HashChainHead, error) {
+	args := m.Called(ctx, tx, chain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.HashChainHead), args.Error(1)
+}
+
+func (m *MockHashChainRepository) SealHashChain(ctx context.Context, tx *gorm.DB, chain string, limit int) (int, error) {
+	args := m.Called(ctx, tx, chain, limit)
+	return args.Int(0), args.Error(1)
+}
+
+type MockIntegrityRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockIntegrityRunRepository) SaveIntegrityRun(ctx context.Context, tx *gorm.DB, run *domain.IntegrityRun) error {
+	args := m.Called(ctx, tx, run)
+	return args.Error(0)
+}
+
+func (m *MockIntegrityRunRepository) GetIntegrityRun(ctx context.Context, tx *gorm.DB, runID uint) (*domain.IntegrityRun, error) {
+	args := m.Called(ctx, tx, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IntegrityRun), args.Error(1)
+}
+
+func (m *MockIntegrityRunRepository) ListIntegrityRuns(ctx context.Context, tx *gorm.DB, query domain.IntegrityRunQuery) ([]domain.IntegrityRun, error) {
+	args := m.Called(ctx, tx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.IntegrityRun), args.Error(1)
+}
+
+type MockProjectionRepairRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectionRepairRepository) SaveProjectionRepair(ctx context.Context, tx *gorm.DB, repair *domain.ProjectionRepair) error {
+	args := m.Called(ctx, tx, repair)
+	return args.Error(0)
+}
+
+func (m *MockProjectionRepairRepository) ListProjectionRepairs(ctx context.Context, tx *gorm.DB, query domain.ProjectionRepairQuery) ([]domain.ProjectionRepair, error) {
+	args := m.Called(ctx, tx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ProjectionRepair), args.Error(1)
+}
+
+type MockSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotRepository) SaveAccountSnapshots(ctx context.Context, tx *gorm.DB, snapshots []domain.AccountSnapshot) error {
+	args := m.Called(ctx, tx, snapshots)
+	return args.Error(0)
+}
+
+func (m *MockSnapshotRepository) GetLatestSnapshotEventID(ctx context.Context, tx *gorm.DB, atOrBefore *uint) (uint, error) {
+	args := m.Called(ctx, tx, atOrBefore)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockSnapshotRepository) ListAccountSnapshotsAt(ctx context.Context, tx *gorm.DB, eventID uint) ([]domain.AccountSnapshot, error) {
+	args := m.Called(ctx, tx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AccountSnapshot), args.Error(1)
+}
+
+func (m *MockSnapshotRepository) GetLatestAccountSnapshot(ctx context.Context, tx *gorm.DB, accountID uint, point domain.BalancePoint) (*domain.AccountSnapshot, error) {
+	args := m.Called(ctx, tx, accountID, point)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountSnapshot), args.Error(1)
+}
+
+type MockProjectionRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectionRepository) LockAccountBalances(ctx context.Context, tx *gorm.DB) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *MockProjectionRepository) CreateAccountBalancesRebuildTable(ctx context.Context, tx *gorm.DB) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *MockProjectionRepository) SaveRebuiltAccountBalances(ctx context.Context, tx *gorm.DB, balances []domain.AccountBalance) error {
+	args := m.Called(ctx, tx, balances)
+	return args.Error(0)
+}
+
+func (m *MockProjectionRepository) SwapRebuiltAccountBalances(ctx context.Context, tx *gorm.DB) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+type MockHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldRepository) SaveHold(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	args := m.Called(ctx, tx, hold)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) UpdateHoldStatus(ctx context.Context, tx *gorm.DB, hold *domain.Hold) error {
+	args := m.Called(ctx, tx, hold)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) GetHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error) {
+	args := m.Called(ctx, tx, holdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) LockHold(ctx context.Context, tx *gorm.DB, holdID string) (*domain.Hold, error) {
+	args := m.Called(ctx, tx, holdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) GetHoldByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.Hold, error) {
+	args := m.Called(ctx, tx, idempotencyKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) ListExpiredHolds(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.Hold, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Hold), args.Error(1)
+}
+
+type MockScheduledTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduledTransferRepository) SaveScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer) error {
+	args := m.Called(ctx, tx, scheduled)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) UpdateScheduledTransferStatus(ctx context.Context, tx *gorm.DB, scheduled *domain.ScheduledTransfer) error {
+	args := m.Called(ctx, tx, scheduled)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) GetScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, scheduleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) LockScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, scheduleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) GetScheduledTransferByIdempotencyKey(ctx context.Context, tx *gorm.DB, idempotencyKey string) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, idempotencyKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) ListScheduledTransfers(ctx context.Context, tx *gorm.DB, query domain.ScheduledTransferQuery) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) ListDueScheduledTransfers(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+type MockStandingOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockStandingOrderRepository) SaveStandingOrder(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder) error {
+	args := m.Called(ctx, tx, order)
+	return args.Error(0)
+}
+
+func (m *MockStandingOrderRepository) UpdateStandingOrder(ctx context.Context, tx *gorm.DB, order *domain.StandingOrder) error {
+	args := m.Called(ctx, tx, order)
+	return args.Error(0)
+}
+
+func (m *MockStandingOrderRepository) GetStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	args := m.Called(ctx, tx, standingOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) LockStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	args := m.Called(ctx, tx, standingOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) ClaimStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string) (*domain.StandingOrder, error) {
+	args := m.Called(ctx, tx, standingOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) ListStandingOrders(ctx context.Context, tx *gorm.DB, query domain.StandingOrderQuery) ([]domain.StandingOrder, error) {
+	args := m.Called(ctx, tx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) ListDueStandingOrders(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]domain.StandingOrder, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) SaveStandingOrderExecution(ctx context.Context, tx *gorm.DB, execution *domain.StandingOrderExecution) error {
+	args := m.Called(ctx, tx, execution)
+	return args.Error(0)
+}
+
+func (m *MockStandingOrderRepository) ListStandingOrderExecutions(ctx context.Context, tx *gorm.DB, standingOrderID string, limit int) ([]domain.StandingOrderExecution, error) {
+	args := m.Called(ctx, tx, standingOrderID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StandingOrderExecution), args.Error(1)
+}
+
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) ProcessTransfer(ctx context.Context, tx *gorm.DB, req service.TransferRequest) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransactionService) ProcessMultiLegTransfer(ctx context.Context, tx *gorm.DB, req service.MultiLegTransferRequest) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransactionService) ReverseTransfer(ctx context.Context, tx *gorm.DB, req service.ReversalRequest) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransactionService) FundAccount(ctx context.Context, tx *gorm.DB, req service.FundingRequest) (*domain.TransferEvent, error) {
+	args := m.Called(ctx, tx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferEvent), args.Error(1)
+}
+
+func (m *MockTransactionService) GetTransfer(ctx context.Context, transferID string) (*service.TransferDetails, error) {
+	args := m.Called(ctx, transferID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.TransferDetails), args.Error(1)
 }
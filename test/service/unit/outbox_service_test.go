@@ -0,0 +1,232 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/publisher"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func pendingMessage(messageID uint, due time.Time, accountIDs ...uint) domain.OutboxMessage {
+	return domain.OutboxMessage{
+		MessageID:       messageID,
+		TransferEventID: &messageID,
+		EventType:       domain.EventTypeTransferProcessed,
+		AccountIDs:      accountIDs,
+		Status:          domain.OutboxStatusPending,
+		NextAttemptAt:   due,
+	}
+}
+
+func publishedIDs(p *publisher.MemoryPublisher) []uint {
+	var ids []uint
+	for _, message := range p.Messages() {
+		ids = append(ids, message.MessageID)
+	}
+	return ids
+}
+
+// claimed returns the batch ClaimOutboxMessages would return for messages.
+func claimed(now time.Time, messages ...domain.OutboxMessage) *service.OutboxBatch {
+	for i := range messages {
+		messages[i].NextAttemptAt = now.Add(5 * time.Minute)
+	}
+	return &service.OutboxBatch{Messages: messages}
+}
+
+func TestOutboxService_ClaimOutboxMessages_ClaimsDueMessages(t *testing.T) {
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+
+	mockOutboxRepo.On("TryLockOutboxRelay", mock.Anything, tx).Return(true, nil)
+	mockWebhookRepo.On("ListActiveWebhookSubscriptions", mock.Anything, tx).Return([]domain.WebhookSubscription{}, nil)
+	mockWebhookRepo.On("SaveWebhookDeliveries", mock.Anything, tx, mock.Anything).Return(0, nil).Maybe()
+	mockOutboxRepo.On("ListPendingOutboxMessages", mock.Anything, tx, now, 10).Return([]domain.OutboxMessage{
+		pendingMessage(1, now.Add(time.Minute), 1),
+		pendingMessage(2, now, 1),
+		pendingMessage(3, now, 2),
+	}, nil)
+	mockOutboxRepo.On("UpdateOutboxMessageDelivery", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.MessageID == 3 && m.Status == domain.OutboxStatusPending && m.Attempts == 0 && m.NextAttemptAt.Equal(now.Add(5*time.Minute))
+	})).Return(nil).Once()
+
+	svc := service.NewOutboxService(mockOutboxRepo, mockWebhookRepo, nil, time.Second, time.Minute, 5)
+
+	batch, err := svc.ClaimOutboxMessages(context.Background(), tx, now, 10)
+
+	require.NoError(t, err)
+	require.Len(t, batch.Messages, 1)
+	assert.Equal(t, uint(3), batch.Messages[0].MessageID)
+	assert.Equal(t, service.OutboxRelayReport{Deferred: 2}, batch.Report)
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestOutboxService_PublishOutboxMessages_PublishesInOrder(t *testing.T) {
+	sink := publisher.NewMemoryPublisher()
+	now := time.Now()
+	batch := claimed(now, pendingMessage(1, now, 1, 2), pendingMessage(2, now, 2, 3))
+
+	svc := service.NewOutboxService(&MockOutboxRepository{}, &MockWebhookRepository{}, sink, time.Second, time.Minute, 5)
+
+	svc.PublishOutboxMessages(context.Background(), batch, now)
+
+	assert.Equal(t, service.OutboxRelayReport{Published: 2}, batch.Report)
+	assert.Equal(t, []uint{1, 2}, publishedIDs(sink))
+	for _, message := range batch.Messages {
+		assert.Equal(t, domain.OutboxStatusPublished, message.Status)
+		assert.Equal(t, 1, message.Attempts)
+		assert.NotNil(t, message.PublishedAt)
+	}
+}
+
+func TestOutboxService_PublishOutboxMessages_FailureBlocksSameAccount(t *testing.T) {
+	sink := publisher.NewMemoryPublisher()
+	sink.Fail = func(message domain.OutboxMessage) error {
+		if message.MessageID == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	now := time.Now()
+	failed := pendingMessage(1, now, 1, 2)
+	failed.Attempts = 2
+	batch := claimed(now, failed, pendingMessage(2, now, 2, 3), pendingMessage(3, now, 4), pendingMessage(4, now, 3))
+
+	svc := service.NewOutboxService(&MockOutboxRepository{}, &MockWebhookRepository{}, sink, time.Second, time.Minute, 5)
+
+	svc.PublishOutboxMessages(context.Background(), batch, now)
+
+	assert.Equal(t, service.OutboxRelayReport{Published: 1, Failed: 1, Deferred: 2}, batch.Report)
+	assert.Equal(t, []uint{3}, publishedIDs(sink))
+	first := batch.Messages[0]
+	assert.Equal(t, domain.OutboxStatusPending, first.Status)
+	assert.Equal(t, 3, first.Attempts)
+	assert.Equal(t, "connection refused", first.LastError)
+	assert.True(t, first.NextAttemptAt.Equal(now.Add(4*time.Second)))
+	for _, deferred := range []domain.OutboxMessage{batch.Messages[1], batch.Messages[3]} {
+		assert.Equal(t, domain.OutboxStatusPending, deferred.Status)
+		assert.Zero(t, deferred.Attempts)
+		assert.True(t, deferred.NextAttemptAt.Equal(now), "deferred messages are released")
+	}
+}
+
+func TestOutboxService_PublishOutboxMessages_BackoffIsCapped(t *testing.T) {
+	sink := publisher.NewMemoryPublisher()
+	sink.Fail = func(domain.OutboxMessage) error { return errors.New("unavailable") }
+	now := time.Now()
+	failing := pendingMessage(1, now, 1)
+	failing.Attempts = 30
+	batch := claimed(now, failing)
+
+	svc := service.NewOutboxService(&MockOutboxRepository{}, &MockWebhookRepository{}, sink, time.Second, time.Minute, 50)
+
+	svc.PublishOutboxMessages(context.Background(), batch, now)
+
+	assert.Equal(t, 1, batch.Report.Failed)
+	assert.True(t, batch.Messages[0].NextAttemptAt.Equal(now.Add(time.Minute)))
+}
+
+func TestOutboxService_PublishOutboxMessages_DeadLettersAfterMaxAttempts(t *testing.T) {
+	sink := publisher.NewMemoryPublisher()
+	sink.Fail = func(message domain.OutboxMessage) error {
+		if message.MessageID == 1 {
+			return errors.New("payload rejected")
+		}
+		return nil
+	}
+	now := time.Now()
+	poisoned := pendingMessage(1, now, 1)
+	poisoned.Attempts = 4
+	batch := claimed(now, poisoned, pendingMessage(2, now, 1))
+
+	svc := service.NewOutboxService(&MockOutboxRepository{}, &MockWebhookRepository{}, sink, time.Second, time.Minute, 5)
+
+	svc.PublishOutboxMessages(context.Background(), batch, now)
+
+	assert.Equal(t, service.OutboxRelayReport{Published: 1, Dead: 1}, batch.Report)
+	assert.Equal(t, domain.OutboxStatusDead, batch.Messages[0].Status)
+	assert.Equal(t, "payload rejected", batch.Messages[0].LastError)
+	assert.Equal(t, []uint{2}, publishedIDs(sink), "a dead message no longer blocks its accounts")
+}
+
+func TestOutboxService_CompleteOutboxMessages(t *testing.T) {
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+	batch := claimed(now, pendingMessage(1, now, 1), pendingMessage(2, now, 2))
+	batch.Messages[0].Status = domain.OutboxStatusPublished
+
+	mockOutboxRepo.On("UpdateOutboxMessageDelivery", mock.Anything, tx, &batch.Messages[0]).Return(nil).Once()
+	mockOutboxRepo.On("UpdateOutboxMessageDelivery", mock.Anything, tx, &batch.Messages[1]).Return(nil).Once()
+
+	svc := service.NewOutboxService(mockOutboxRepo, &MockWebhookRepository{}, nil, time.Second, time.Minute, 5)
+
+	err := svc.CompleteOutboxMessages(context.Background(), tx, batch)
+
+	require.NoError(t, err)
+	mockOutboxRepo.AssertExpectations(t)
+}
+
+func TestOutboxService_ClaimOutboxMessages_AnotherRelayRunning(t *testing.T) {
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+
+	mockOutboxRepo.On("TryLockOutboxRelay", mock.Anything, tx).Return(false, nil)
+
+	svc := service.NewOutboxService(mockOutboxRepo, mockWebhookRepo, publisher.NewMemoryPublisher(), time.Second, time.Minute, 5)
+
+	batch, err := svc.ClaimOutboxMessages(context.Background(), tx, time.Now(), 10)
+
+	require.NoError(t, err)
+	assert.Nil(t, batch)
+	mockOutboxRepo.AssertNotCalled(t, "ListPendingOutboxMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutboxService_ClaimOutboxMessages_QueuesWebhookDeliveries(t *testing.T) {
+	mockOutboxRepo := &MockOutboxRepository{}
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+	opened := pendingMessage(2, now, 5)
+	opened.TransferEventID = nil
+	opened.EventType = domain.EventTypeAccountOpened
+
+	mockOutboxRepo.On("TryLockOutboxRelay", mock.Anything, tx).Return(true, nil)
+	mockOutboxRepo.On("ListPendingOutboxMessages", mock.Anything, tx, now, 10).Return([]domain.OutboxMessage{
+		pendingMessage(1, now, 1, 2),
+		opened,
+	}, nil)
+	mockWebhookRepo.On("ListActiveWebhookSubscriptions", mock.Anything, tx).Return([]domain.WebhookSubscription{
+		{SubscriptionID: "transfers", EventTypes: []string{domain.EventTypeTransferProcessed}},
+		{SubscriptionID: "all", EventTypes: []string{domain.EventTypeTransferProcessed, domain.EventTypeAccountOpened}},
+	}, nil)
+	mockWebhookRepo.On("SaveWebhookDeliveries", mock.Anything, tx, mock.MatchedBy(func(deliveries []*domain.WebhookDelivery) bool {
+		return len(deliveries) == 2 && deliveries[0].SubscriptionID == "transfers" && deliveries[1].SubscriptionID == "all" &&
+			deliveries[0].MessageID == 1 && deliveries[0].Status == domain.WebhookDeliveryStatusPending
+	})).Return(2, nil).Once()
+	mockWebhookRepo.On("SaveWebhookDeliveries", mock.Anything, tx, mock.MatchedBy(func(deliveries []*domain.WebhookDelivery) bool {
+		return len(deliveries) == 1 && deliveries[0].SubscriptionID == "all" && deliveries[0].MessageID == 2
+	})).Return(1, nil).Once()
+	mockOutboxRepo.On("UpdateOutboxMessageDelivery", mock.Anything, tx, mock.Anything).Return(nil).Twice()
+
+	svc := service.NewOutboxService(mockOutboxRepo, mockWebhookRepo, nil, time.Second, time.Minute, 5)
+
+	batch, err := svc.ClaimOutboxMessages(context.Background(), tx, now, 10)
+
+	require.NoError(t, err)
+	assert.Len(t, batch.Messages, 2)
+	assert.Equal(t, service.OutboxRelayReport{WebhookDeliveries: 3}, batch.Report)
+	mockOutboxRepo.AssertExpectations(t)
+	mockWebhookRepo.AssertExpectations(t)
+}
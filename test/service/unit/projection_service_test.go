@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func accountOpenedEnvelope(t *testing.T, eventID uint, accountID uint, initialBalance int64) domain.EventEnvelope {
+	envelope, err := domain.NewEventEnvelope(domain.AccountOpened{
+		AccountID:      accountID,
+		Type:           domain.AccountTypeCustomer,
+		Currency:       "USD",
+		InitialBalance: decimal.NewFromInt(initialBalance),
+		OpeningBalance: decimal.NewFromInt(initialBalance),
+	}, nil, time.Now())
+	require.NoError(t, err)
+	envelope.EventID = eventID
+	envelope.Sequence = 1
+	return *envelope
+}
+
+// replayLog sets up an event log without snapshots in which accounts 1 and 2
+// open with 100 and 0, then event 7 moves 40 from account 1 to account 2.
+func replayLog(t *testing.T, tx *gorm.DB, eventStore *MockEventStore, journalRepo *MockJournalRepository, snapshotRepo *MockSnapshotRepository) {
+	snapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, mock.Anything).Return(uint(0), nil)
+	eventStore.On("ListEventsByType", mock.Anything, tx, domain.EventTypeAccountOpened, uint(0), mock.Anything).
+		Return([]domain.EventEnvelope{accountOpenedEnvelope(t, 1, 1, 100), accountOpenedEnvelope(t, 2, 2, 0)}, nil)
+	journalRepo.On("ListJournalEntriesInEventOrder", mock.Anything, tx, uint(0), uint(0), mock.Anything).
+		Return([]domain.JournalEntry{
+			{EntryID: 10, AccountID: 1, Amount: decimal.NewFromInt(40), Type: domain.Debit, SourceEventID: 7},
+			{EntryID: 11, AccountID: 2, Amount: decimal.NewFromInt(40), Type: domain.Credit, SourceEventID: 7},
+		}, nil)
+}
+
+func TestProjectionService_RebuildAccountBalances_NoDivergence(t *testing.T) {
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockProjectionRepo := &MockProjectionRepository{}
+	tx := &gorm.DB{}
+
+	replayLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo)
+	mockProjectionRepo.On("LockAccountBalances", mock.Anything, tx).Return(nil)
+	mockBalanceRepo.On("ListAccountBalances", mock.Anything, tx).Return([]domain.AccountBalance{
+		{AccountID: 1, Balance: decimal.NewFromInt(60), HeldAmount: decimal.NewFromInt(5), Version: 3, LastEventID: 7},
+		{AccountID: 2, Balance: decimal.NewFromInt(40), Version: 2, LastEventID: 7},
+	}, nil)
+	mockProjectionRepo.On("CreateAccountBalancesRebuildTable", mock.Anything, tx).Return(nil)
+	mockProjectionRepo.On("SaveRebuiltAccountBalances", mock.Anything, tx, mock.MatchedBy(func(balances []domain.AccountBalance) bool {
+		return len(balances) == 2 &&
+			balances[0].AccountID == 1 && balances[0].Balance.Equal(decimal.NewFromInt(60)) &&
+			balances[0].HeldAmount.Equal(decimal.NewFromInt(5)) && balances[0].Version == 4 &&
+			balances[1].AccountID == 2 && balances[1].LastEventID == 7
+	})).Return(nil)
+	mockProjectionRepo.On("SwapRebuiltAccountBalances", mock.Anything, tx).Return(nil)
+
+	svc := service.NewProjectionService(mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockProjectionRepo)
+
+	report, err := svc.RebuildAccountBalances(context.Background(), tx, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.AccountsRebuilt)
+	assert.Equal(t, 3, report.EventsReplayed)
+	assert.Empty(t, report.Divergences)
+	assert.True(t, report.Swapped)
+	mockProjectionRepo.AssertExpectations(t)
+}
+
+func TestProjectionService_RebuildAccountBalances_ReportsDivergence(t *testing.T) {
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockProjectionRepo := &MockProjectionRepository{}
+	tx := &gorm.DB{}
+
+	replayLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo)
+	mockProjectionRepo.On("LockAccountBalances", mock.Anything, tx).Return(nil)
+	mockBalanceRepo.On("ListAccountBalances", mock.Anything, tx).Return([]domain.AccountBalance{
+		{AccountID: 1, Balance: decimal.NewFromInt(75), Version: 3, LastEventID: 7},
+		{AccountID: 2, Balance: decimal.NewFromInt(40), Version: 2, LastEventID: 5},
+	}, nil)
+
+	svc := service.NewProjectionService(mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockProjectionRepo)
+
+	report, err := svc.RebuildAccountBalances(context.Background(), tx, true)
+
+	require.NoError(t, err)
+	require.Len(t, report.Divergences, 2)
+	assert.Equal(t, uint(1), report.Divergences[0].AccountID)
+	assert.True(t, decimal.NewFromInt(75).Equal(report.Divergences[0].LiveBalance))
+	assert.True(t, decimal.NewFromInt(60).Equal(report.Divergences[0].RebuiltBalance))
+	assert.Equal(t, uint(2), report.Divergences[1].AccountID)
+	assert.Equal(t, uint(5), report.Divergences[1].LiveLastEventID)
+	assert.Equal(t, uint(7), report.Divergences[1].RebuiltLastEventID)
+	assert.False(t, report.Swapped)
+	mockProjectionRepo.AssertNotCalled(t, "SwapRebuiltAccountBalances", mock.Anything, mock.Anything)
+}
+
+func TestProjectionService_RebuildAccountBalances_MissingOpeningEvent(t *testing.T) {
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockProjectionRepo := &MockProjectionRepository{}
+	tx := &gorm.DB{}
+
+	replayLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo)
+	mockProjectionRepo.On("LockAccountBalances", mock.Anything, tx).Return(nil)
+	mockBalanceRepo.On("ListAccountBalances", mock.Anything, tx).Return([]domain.AccountBalance{
+		{AccountID: 1, Balance: decimal.NewFromInt(60), Version: 3, LastEventID: 7},
+		{AccountID: 2, Balance: decimal.NewFromInt(40), Version: 2, LastEventID: 7},
+		{AccountID: 3, Balance: decimal.NewFromInt(500), Version: 1},
+	}, nil)
+
+	svc := service.NewProjectionService(mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockProjectionRepo)
+
+	_, err := svc.RebuildAccountBalances(context.Background(), tx, false)
+
+	assert.ErrorIs(t, err, service.ErrIncompleteEventLog)
+	mockProjectionRepo.AssertNotCalled(t, "SwapRebuiltAccountBalances", mock.Anything, mock.Anything)
+}
+
+func TestProjectionService_RebuildAccountBalances_FromSnapshot(t *testing.T) {
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	mockProjectionRepo := &MockProjectionRepository{}
+	tx := &gorm.DB{}
+
+	mockEventStore.On("ListEventsByType", mock.Anything, tx, domain.EventTypeAccountOpened, uint(0), mock.Anything).
+		Return([]domain.EventEnvelope{accountOpenedEnvelope(t, 1, 1, 100), accountOpenedEnvelope(t, 2, 2, 0)}, nil)
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, (*uint)(nil)).Return(uint(7), nil)
+	mockSnapshotRepo.On("ListAccountSnapshotsAt", mock.Anything, tx, uint(7)).Return([]domain.AccountSnapshot{
+		{AccountID: 1, EventID: 7, Balance: decimal.NewFromInt(60), LastEventID: 7},
+		{AccountID: 2, EventID: 7, Balance: decimal.NewFromInt(40), LastEventID: 7},
+	}, nil)
+	mockJournalRepo.On("ListJournalEntriesInEventOrder", mock.Anything, tx, uint(7), uint(0), mock.Anything).
+		Return([]domain.JournalEntry{
+			{EntryID: 11, AccountID: 2, Amount: decimal.NewFromInt(40), Type: domain.Credit, SourceEventID: 7},
+			{EntryID: 12, AccountID: 2, Amount: decimal.NewFromInt(15), Type: domain.Debit, SourceEventID: 9},
+			{EntryID: 13, AccountID: 1, Amount: decimal.NewFromInt(15), Type: domain.Credit, SourceEventID: 9},
+		}, nil)
+	mockProjectionRepo.On("LockAccountBalances", mock.Anything, tx).Return(nil)
+	mockBalanceRepo.On("ListAccountBalances", mock.Anything, tx).Return([]domain.AccountBalance{
+		{AccountID: 1, Balance: decimal.NewFromInt(75), Version: 3, LastEventID: 9},
+		{AccountID: 2, Balance: decimal.NewFromInt(25), Version: 3, LastEventID: 9},
+	}, nil)
+
+	svc := service.NewProjectionService(mockBalanceRepo, mockJournalRepo, mockEventStore, mockSnapshotRepo, mockProjectionRepo)
+
+	report, err := svc.RebuildAccountBalances(context.Background(), tx, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), report.SnapshotEventID)
+	assert.Equal(t, 3, report.EventsReplayed)
+	assert.Empty(t, report.Divergences)
+	assert.False(t, report.Swapped)
+}
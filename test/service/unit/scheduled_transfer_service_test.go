@@ -0,0 +1,243 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func pendingScheduledTransfer(executeAt time.Time) *domain.ScheduledTransfer {
+	return &domain.ScheduledTransfer{
+		ScheduleID:           "4a6f0d2c-1b7e-4c3a-9f58-2e0d7b1c6a94",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		ExecuteAt:            executeAt,
+		Status:               domain.ScheduledTransferStatusPending,
+	}
+}
+
+func TestScheduledTransferService_ScheduleTransfer_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	executeAt := time.Now().Add(24 * time.Hour)
+
+	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
+	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(2)).Return(true, nil)
+	mockScheduledRepo.On("SaveScheduledTransfer", mock.Anything, tx, mock.AnythingOfType("*domain.ScheduledTransfer")).Return(nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	scheduled, err := svc.ScheduleTransfer(context.Background(), tx, service.ScheduledTransferRequest{
+		Transfer:  service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)},
+		ExecuteAt: executeAt,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduledTransferStatusPending, scheduled.Status)
+	assert.True(t, executeAt.Equal(scheduled.ExecuteAt))
+	mockScheduledRepo.AssertExpectations(t)
+	mockTransactionService.AssertNotCalled(t, "ProcessTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransferService_ScheduleTransfer_IdempotencyKeyReused(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	existing := pendingScheduledTransfer(time.Now().Add(time.Hour))
+	existing.RequestFingerprint = "another request"
+	mockScheduledRepo.On("GetScheduledTransferByIdempotencyKey", mock.Anything, tx, "key-1").Return(existing, nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	_, err := svc.ScheduleTransfer(context.Background(), tx, service.ScheduledTransferRequest{
+		Transfer:  service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), IdempotencyKey: "key-1"},
+		ExecuteAt: time.Now().Add(time.Hour),
+	})
+
+	assert.ErrorIs(t, err, service.ErrIdempotencyKeyReused)
+}
+
+func TestScheduledTransferService_ExecuteScheduledTransfer_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduled := pendingScheduledTransfer(now.Add(-time.Minute))
+
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+	mockTransactionService.On("ProcessTransfer", mock.Anything, tx, mock.MatchedBy(func(req service.TransferRequest) bool {
+		return req.SourceAccountID == 1 && req.DestinationAccountID == 2 && req.Amount.Equal(decimal.NewFromInt(100)) && req.IdempotencyKey != ""
+	})).Return(&domain.TransferEvent{TransferID: "transfer-1"}, nil)
+	mockScheduledRepo.On("UpdateScheduledTransferStatus", mock.Anything, tx, scheduled).Return(nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	executed, err := svc.ExecuteScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduledTransferStatusExecuted, executed.Status)
+	assert.Equal(t, "transfer-1", executed.TransferID)
+	mockTransactionService.AssertExpectations(t)
+	mockScheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransferService_ExecuteScheduledTransfer_NotDue(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduled := pendingScheduledTransfer(now.Add(time.Hour))
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	executed, err := svc.ExecuteScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, now)
+
+	require.NoError(t, err)
+	assert.Nil(t, executed)
+	mockTransactionService.AssertNotCalled(t, "ProcessTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransferService_ExecuteScheduledTransfer_InsufficientFunds(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduled := pendingScheduledTransfer(now.Add(-time.Minute))
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+	mockTransactionService.On("ProcessTransfer", mock.Anything, tx, mock.Anything).Return(nil, errors.New("insufficient balance in source account"))
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	_, err := svc.ExecuteScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, now)
+
+	assert.EqualError(t, err, "insufficient balance in source account")
+	mockScheduledRepo.AssertNotCalled(t, "UpdateScheduledTransferStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransferService_FailScheduledTransfer_RecordsReason(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduled := pendingScheduledTransfer(now.Add(-time.Minute))
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+	mockScheduledRepo.On("UpdateScheduledTransferStatus", mock.Anything, tx, scheduled).Return(nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	failed, err := svc.FailScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, "insufficient balance in source account", now)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduledTransferStatusFailed, failed.Status)
+	assert.Equal(t, "insufficient balance in source account", failed.FailureReason)
+	mockScheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransferService_CancelScheduledTransfer_AlreadyExecuted(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	scheduled := pendingScheduledTransfer(time.Now().Add(-time.Minute))
+	scheduled.Status = domain.ScheduledTransferStatusExecuted
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	_, err := svc.CancelScheduledTransfer(context.Background(), tx, scheduled.ScheduleID)
+
+	assert.ErrorIs(t, err, service.ErrScheduledTransferNotPending)
+}
+
+func TestScheduledTransferService_PostponeScheduledTransfer_BacksOff(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduled := pendingScheduledTransfer(now.Add(-time.Minute))
+	scheduled.Attempts = 2
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+	mockScheduledRepo.On("UpdateScheduledTransferStatus", mock.Anything, tx, scheduled).Return(nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	postponed, err := svc.PostponeScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, "connection reset", now)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduledTransferStatusPending, postponed.Status)
+	assert.Equal(t, 3, postponed.Attempts)
+	assert.Equal(t, "connection reset", postponed.LastError)
+	require.NotNil(t, postponed.NextAttemptAt)
+	assert.True(t, now.Add(2*time.Minute).Equal(*postponed.NextAttemptAt))
+	mockScheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransferService_PostponeScheduledTransfer_FailsAfterMaxAttempts(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduled := pendingScheduledTransfer(now.Add(-time.Hour))
+	scheduled.Attempts = 9
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+	mockScheduledRepo.On("UpdateScheduledTransferStatus", mock.Anything, tx, scheduled).Return(nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	failed, err := svc.PostponeScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, "connection reset", now)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduledTransferStatusFailed, failed.Status)
+	assert.Equal(t, "connection reset", failed.FailureReason)
+	assert.Nil(t, failed.NextAttemptAt)
+}
+
+func TestScheduledTransferService_ExecuteScheduledTransfer_BackingOff(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockScheduledRepo := &MockScheduledTransferRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	nextAttemptAt := now.Add(time.Minute)
+	scheduled := pendingScheduledTransfer(now.Add(-time.Hour))
+	scheduled.NextAttemptAt = &nextAttemptAt
+	mockScheduledRepo.On("LockScheduledTransfer", mock.Anything, tx, scheduled.ScheduleID).Return(scheduled, nil)
+
+	svc := service.NewScheduledTransferService(mockAccountRepo, mockScheduledRepo, mockTransactionService)
+
+	executed, err := svc.ExecuteScheduledTransfer(context.Background(), tx, scheduled.ScheduleID, now)
+
+	require.NoError(t, err)
+	assert.Nil(t, executed)
+	mockTransactionService.AssertNotCalled(t, "ProcessTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
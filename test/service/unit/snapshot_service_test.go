@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// snapshotLog sets up an event log in which accounts 1, 2 and 3 open with 100, 0
+// and 50, event 7 moves 40 from account 1 to account 2 and event 9 moves 15
+// back. The snapshot at event 7 records account 1 with balance1.
+func snapshotLog(t *testing.T, tx *gorm.DB, eventStore *MockEventStore, journalRepo *MockJournalRepository, snapshotRepo *MockSnapshotRepository, balance1 int64) {
+	event7 := []domain.JournalEntry{
+		{EntryID: 10, AccountID: 1, Amount: decimal.NewFromInt(40), Type: domain.Debit, SourceEventID: 7},
+		{EntryID: 11, AccountID: 2, Amount: decimal.NewFromInt(40), Type: domain.Credit, SourceEventID: 7},
+	}
+	event9 := []domain.JournalEntry{
+		{EntryID: 12, AccountID: 2, Amount: decimal.NewFromInt(15), Type: domain.Debit, SourceEventID: 9},
+		{EntryID: 13, AccountID: 1, Amount: decimal.NewFromInt(15), Type: domain.Credit, SourceEventID: 9},
+	}
+
+	eventStore.On("ListEventsByType", mock.Anything, tx, domain.EventTypeAccountOpened, uint(0), mock.Anything).
+		Return([]domain.EventEnvelope{accountOpenedEnvelope(t, 1, 1, 100), accountOpenedEnvelope(t, 2, 2, 0), accountOpenedEnvelope(t, 3, 3, 50)}, nil)
+	snapshotRepo.On("ListAccountSnapshotsAt", mock.Anything, tx, uint(7)).Return([]domain.AccountSnapshot{
+		{AccountID: 1, EventID: 7, Balance: decimal.NewFromInt(balance1), LastEventID: 7},
+		{AccountID: 2, EventID: 7, Balance: decimal.NewFromInt(40), LastEventID: 7},
+	}, nil)
+	journalRepo.On("ListJournalEntriesInEventOrder", mock.Anything, tx, uint(0), uint(0), mock.Anything).
+		Return(append(append([]domain.JournalEntry{}, event7...), event9...), nil)
+	journalRepo.On("ListJournalEntriesInEventOrder", mock.Anything, tx, uint(7), uint(0), mock.Anything).
+		Return(append(event7[1:], event9...), nil)
+}
+
+func TestSnapshotService_TakeSnapshot_NotDue(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	tx := &gorm.DB{}
+	cutoff := time.Now()
+
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, (*uint)(nil)).Return(uint(7), nil)
+	mockJournalRepo.On("GetLastSourceEventIDBefore", mock.Anything, tx, cutoff).Return(uint(9), nil)
+
+	svc := service.NewSnapshotService(mockJournalRepo, mockEventStore, mockSnapshotRepo)
+
+	report, err := svc.TakeSnapshot(context.Background(), tx, cutoff, 10, false)
+
+	require.NoError(t, err)
+	assert.Nil(t, report)
+	mockEventStore.AssertNotCalled(t, "ListEventsByType", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockSnapshotRepo.AssertNotCalled(t, "SaveAccountSnapshots", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSnapshotService_TakeSnapshot_SavesChangedAccounts(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	tx := &gorm.DB{}
+	cutoff := time.Now()
+	eventID := uint(9)
+
+	snapshotLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo, 60)
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, (*uint)(nil)).Return(uint(7), nil)
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, &eventID).Return(uint(7), nil)
+	mockJournalRepo.On("GetLastSourceEventIDBefore", mock.Anything, tx, cutoff).Return(uint(9), nil)
+	mockSnapshotRepo.On("SaveAccountSnapshots", mock.Anything, tx, mock.MatchedBy(func(snapshots []domain.AccountSnapshot) bool {
+		return len(snapshots) == 2 &&
+			snapshots[0].AccountID == 1 && snapshots[0].EventID == 9 && snapshots[0].Balance.Equal(decimal.NewFromInt(75)) && snapshots[0].LastEventID == 9 &&
+			snapshots[1].AccountID == 2 && snapshots[1].EventID == 9 && snapshots[1].Balance.Equal(decimal.NewFromInt(25))
+	})).Return(nil)
+
+	svc := service.NewSnapshotService(mockJournalRepo, mockEventStore, mockSnapshotRepo)
+
+	report, err := svc.TakeSnapshot(context.Background(), tx, cutoff, 2, true)
+
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, uint(9), report.EventID)
+	assert.Equal(t, uint(7), report.PreviousEventID)
+	assert.Equal(t, 2, report.AccountsSnapshotted)
+	assert.True(t, report.Verified)
+	mockSnapshotRepo.AssertExpectations(t)
+}
+
+func TestSnapshotService_TakeSnapshot_VerifyRejectsDivergence(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	tx := &gorm.DB{}
+	cutoff := time.Now()
+
+	snapshotLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo, 70)
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, mock.Anything).Return(uint(7), nil)
+	mockJournalRepo.On("GetLastSourceEventIDBefore", mock.Anything, tx, cutoff).Return(uint(9), nil)
+
+	svc := service.NewSnapshotService(mockJournalRepo, mockEventStore, mockSnapshotRepo)
+
+	report, err := svc.TakeSnapshot(context.Background(), tx, cutoff, 2, true)
+
+	assert.ErrorIs(t, err, service.ErrSnapshotDiverged)
+	assert.Nil(t, report)
+	mockSnapshotRepo.AssertNotCalled(t, "SaveAccountSnapshots", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSnapshotService_VerifySnapshot_Valid(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	tx := &gorm.DB{}
+
+	snapshotLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo, 60)
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, mock.Anything).Return(uint(7), nil)
+
+	svc := service.NewSnapshotService(mockJournalRepo, mockEventStore, mockSnapshotRepo)
+
+	verification, err := svc.VerifySnapshot(context.Background(), tx, nil)
+
+	require.NoError(t, err)
+	assert.True(t, verification.IsValid)
+	assert.Equal(t, uint(7), verification.EventID)
+	assert.Equal(t, 3, verification.AccountsChecked)
+	assert.Empty(t, verification.Divergences)
+}
+
+func TestSnapshotService_VerifySnapshot_ReportsDivergence(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	tx := &gorm.DB{}
+
+	snapshotLog(t, tx, mockEventStore, mockJournalRepo, mockSnapshotRepo, 70)
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, mock.Anything).Return(uint(7), nil)
+
+	svc := service.NewSnapshotService(mockJournalRepo, mockEventStore, mockSnapshotRepo)
+
+	verification, err := svc.VerifySnapshot(context.Background(), tx, nil)
+
+	require.NoError(t, err)
+	assert.False(t, verification.IsValid)
+	require.Len(t, verification.Divergences, 1)
+	assert.Equal(t, uint(1), verification.Divergences[0].AccountID)
+	assert.True(t, decimal.NewFromInt(70).Equal(verification.Divergences[0].SnapshotBalance))
+	assert.True(t, decimal.NewFromInt(60).Equal(verification.Divergences[0].ReplayedBalance))
+}
+
+func TestSnapshotService_VerifySnapshot_NotFound(t *testing.T) {
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockSnapshotRepo := &MockSnapshotRepository{}
+	tx := &gorm.DB{}
+	eventID := uint(5)
+
+	mockSnapshotRepo.On("GetLatestSnapshotEventID", mock.Anything, tx, &eventID).Return(uint(3), nil)
+
+	svc := service.NewSnapshotService(mockJournalRepo, mockEventStore, mockSnapshotRepo)
+
+	verification, err := svc.VerifySnapshot(context.Background(), tx, &eventID)
+
+	assert.ErrorIs(t, err, service.ErrSnapshotNotFound)
+	assert.Nil(t, verification)
+}
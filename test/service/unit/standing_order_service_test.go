@@ -0,0 +1,353 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const standingOrderID = "7c1e5b3a-2f4d-4e8a-b6c9-0d1f2a3b4c5d"
+
+func activeStandingOrder(nextRunAt time.Time) *domain.StandingOrder {
+	return &domain.StandingOrder{
+		StandingOrderID:      standingOrderID,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(25),
+		Schedule:             "@daily",
+		StartAt:              nextRunAt.Add(-72 * time.Hour),
+		NextRunAt:            &nextRunAt,
+		Status:               domain.StandingOrderStatusActive,
+	}
+}
+
+func TestStandingOrderService_CreateStandingOrder_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
+	mockStandingOrderRepo.On("SaveStandingOrder", mock.Anything, tx, mock.AnythingOfType("*domain.StandingOrder")).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	start := time.Date(2030, 1, 15, 12, 0, 0, 0, time.UTC)
+	order, err := svc.CreateStandingOrder(context.Background(), tx, service.StandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(25),
+		Schedule:             "@monthly",
+		StartAt:              start,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.StandingOrderStatusActive, order.Status)
+	require.NotNil(t, order.NextRunAt)
+	assert.True(t, time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC).Equal(*order.NextRunAt))
+	mockStandingOrderRepo.AssertExpectations(t)
+}
+
+func TestStandingOrderService_CreateStandingOrder_InvalidSchedule(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	_, err := svc.CreateStandingOrder(context.Background(), tx, service.StandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(25),
+		Schedule:             "every tuesday",
+	})
+
+	assert.ErrorIs(t, err, service.ErrInvalidSchedule)
+	mockStandingOrderRepo.AssertNotCalled(t, "SaveStandingOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStandingOrderService_CreateStandingOrder_NoActivationBeforeEnd(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	start := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 1, 20, 0, 0, 0, 0, time.UTC)
+	_, err := svc.CreateStandingOrder(context.Background(), tx, service.StandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(25),
+		Schedule:             "@monthly",
+		StartAt:              start,
+		EndAt:                &end,
+	})
+
+	assert.ErrorIs(t, err, service.ErrInvalidSchedule)
+}
+
+func TestStandingOrderService_ExecuteStandingOrder_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduledFor := now.Add(-time.Minute).UTC().Truncate(time.Minute)
+	order := activeStandingOrder(scheduledFor)
+
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+	mockTransactionService.On("ProcessTransfer", mock.Anything, tx, mock.MatchedBy(func(req service.TransferRequest) bool {
+		return req.SourceAccountID == 1 && req.DestinationAccountID == 2 &&
+			req.Amount.Equal(decimal.NewFromInt(25)) && req.IdempotencyKey != ""
+	})).Return(&domain.TransferEvent{TransferID: "transfer-1"}, nil)
+	mockStandingOrderRepo.On("SaveStandingOrderExecution", mock.Anything, tx, mock.AnythingOfType("*domain.StandingOrderExecution")).Return(nil)
+	mockStandingOrderRepo.On("UpdateStandingOrder", mock.Anything, tx, order).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	execution, err := svc.ExecuteStandingOrder(context.Background(), tx, standingOrderID, now)
+
+	require.NoError(t, err)
+	require.NotNil(t, execution)
+	assert.Equal(t, domain.StandingOrderExecutionStatusExecuted, execution.Status)
+	assert.Equal(t, "transfer-1", execution.TransferID)
+	assert.True(t, scheduledFor.Equal(execution.ScheduledFor))
+	assert.Equal(t, domain.StandingOrderStatusActive, order.Status)
+	require.NotNil(t, order.NextRunAt)
+	assert.True(t, order.NextRunAt.After(scheduledFor))
+	mockTransactionService.AssertExpectations(t)
+	mockStandingOrderRepo.AssertExpectations(t)
+}
+
+func TestStandingOrderService_ExecuteStandingOrder_CompletesAfterEnd(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduledFor := now.Add(-time.Minute).UTC().Truncate(time.Minute)
+	order := activeStandingOrder(scheduledFor)
+	order.EndAt = &scheduledFor
+
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+	mockTransactionService.On("ProcessTransfer", mock.Anything, tx, mock.Anything).Return(&domain.TransferEvent{TransferID: "transfer-1"}, nil)
+	mockStandingOrderRepo.On("SaveStandingOrderExecution", mock.Anything, tx, mock.Anything).Return(nil)
+	mockStandingOrderRepo.On("UpdateStandingOrder", mock.Anything, tx, order).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	_, err := svc.ExecuteStandingOrder(context.Background(), tx, standingOrderID, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.StandingOrderStatusCompleted, order.Status)
+	assert.Nil(t, order.NextRunAt)
+}
+
+func TestStandingOrderService_ExecuteStandingOrder_NotDue(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(activeStandingOrder(now.Add(time.Hour)), nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	execution, err := svc.ExecuteStandingOrder(context.Background(), tx, standingOrderID, now)
+
+	require.NoError(t, err)
+	assert.Nil(t, execution)
+	mockTransactionService.AssertNotCalled(t, "ProcessTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStandingOrderService_ExecuteStandingOrder_TransferError(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	transferErr := errors.New("insufficient balance in source account")
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(activeStandingOrder(now.Add(-time.Minute)), nil)
+	mockTransactionService.On("ProcessTransfer", mock.Anything, tx, mock.Anything).Return(nil, transferErr)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	_, err := svc.ExecuteStandingOrder(context.Background(), tx, standingOrderID, now)
+
+	assert.ErrorIs(t, err, transferErr)
+	mockStandingOrderRepo.AssertNotCalled(t, "SaveStandingOrderExecution", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStandingOrderService_FailStandingOrder_AdvancesSchedule(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduledFor := now.Add(-time.Minute).UTC().Truncate(time.Minute)
+	order := activeStandingOrder(scheduledFor)
+	order.Schedule = "@every 6h"
+
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+	mockStandingOrderRepo.On("SaveStandingOrderExecution", mock.Anything, tx, mock.MatchedBy(func(e *domain.StandingOrderExecution) bool {
+		return e.Status == domain.StandingOrderExecutionStatusFailed && e.FailureReason == "insufficient funds"
+	})).Return(nil)
+	mockStandingOrderRepo.On("UpdateStandingOrder", mock.Anything, tx, order).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	execution, err := svc.FailStandingOrder(context.Background(), tx, standingOrderID, "insufficient funds", now)
+
+	require.NoError(t, err)
+	require.NotNil(t, execution)
+	require.NotNil(t, order.NextRunAt)
+	assert.True(t, scheduledFor.Add(6*time.Hour).Equal(*order.NextRunAt))
+	mockStandingOrderRepo.AssertExpectations(t)
+}
+
+func TestStandingOrderService_PostponeStandingOrder_BacksOff(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduledFor := now.Add(-time.Minute).UTC().Truncate(time.Minute)
+	order := activeStandingOrder(scheduledFor)
+
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+	mockStandingOrderRepo.On("UpdateStandingOrder", mock.Anything, tx, order).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	postponed, err := svc.PostponeStandingOrder(context.Background(), tx, standingOrderID, "connection reset", now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, postponed.Attempts)
+	assert.Equal(t, "connection reset", postponed.LastError)
+	require.NotNil(t, postponed.NextAttemptAt)
+	assert.True(t, now.Add(30*time.Second).Equal(*postponed.NextAttemptAt))
+	assert.True(t, scheduledFor.Equal(*postponed.NextRunAt))
+	mockStandingOrderRepo.AssertExpectations(t)
+	mockStandingOrderRepo.AssertNotCalled(t, "SaveStandingOrderExecution", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStandingOrderService_PostponeStandingOrder_FailsAfterMaxAttempts(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	scheduledFor := now.Add(-time.Hour).UTC().Truncate(time.Minute)
+	order := activeStandingOrder(scheduledFor)
+	order.Schedule = "@every 6h"
+	order.Attempts = 9
+	order.LastError = "connection reset"
+	lastAttemptAt := now.Add(-time.Minute)
+	order.NextAttemptAt = &lastAttemptAt
+
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+	mockStandingOrderRepo.On("SaveStandingOrderExecution", mock.Anything, tx, mock.MatchedBy(func(e *domain.StandingOrderExecution) bool {
+		return e.Status == domain.StandingOrderExecutionStatusFailed && e.FailureReason == "connection reset" && e.ScheduledFor.Equal(scheduledFor)
+	})).Return(nil)
+	mockStandingOrderRepo.On("UpdateStandingOrder", mock.Anything, tx, order).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	postponed, err := svc.PostponeStandingOrder(context.Background(), tx, standingOrderID, "connection reset", now)
+
+	require.NoError(t, err)
+	assert.Zero(t, postponed.Attempts)
+	assert.Nil(t, postponed.NextAttemptAt)
+	assert.True(t, scheduledFor.Add(6*time.Hour).Equal(*postponed.NextRunAt))
+	mockStandingOrderRepo.AssertExpectations(t)
+}
+
+func TestStandingOrderService_ExecuteStandingOrder_BackingOff(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	now := time.Now()
+	order := activeStandingOrder(now.Add(-time.Hour))
+	nextAttemptAt := now.Add(time.Minute)
+	order.Attempts = 1
+	order.NextAttemptAt = &nextAttemptAt
+
+	mockStandingOrderRepo.On("ClaimStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	execution, err := svc.ExecuteStandingOrder(context.Background(), tx, standingOrderID, now)
+
+	require.NoError(t, err)
+	assert.Nil(t, execution)
+	mockTransactionService.AssertNotCalled(t, "ProcessTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStandingOrderService_CancelStandingOrder_NotActive(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	order := activeStandingOrder(time.Now())
+	order.Status = domain.StandingOrderStatusCancelled
+	mockStandingOrderRepo.On("LockStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	_, err := svc.CancelStandingOrder(context.Background(), tx, standingOrderID)
+
+	assert.ErrorIs(t, err, service.ErrStandingOrderNotActive)
+}
+
+func TestStandingOrderService_UpdateStandingOrder_NewSchedule(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockStandingOrderRepo := &MockStandingOrderRepository{}
+	mockTransactionService := &MockTransactionService{}
+	tx := &gorm.DB{}
+
+	order := activeStandingOrder(time.Now().Add(time.Hour))
+	mockStandingOrderRepo.On("LockStandingOrder", mock.Anything, tx, standingOrderID).Return(order, nil)
+	mockStandingOrderRepo.On("UpdateStandingOrder", mock.Anything, tx, order).Return(nil)
+
+	svc := service.NewStandingOrderService(mockAccountRepo, mockStandingOrderRepo, mockTransactionService)
+
+	amount := decimal.NewFromInt(40)
+	weekly := "@weekly"
+	updated, err := svc.UpdateStandingOrder(context.Background(), tx, service.StandingOrderUpdate{
+		StandingOrderID: standingOrderID,
+		Amount:          &amount,
+		Schedule:        &weekly,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, amount.Equal(updated.Amount))
+	assert.Equal(t, "@weekly", updated.Schedule)
+	require.NotNil(t, updated.NextRunAt)
+	assert.Equal(t, time.Sunday, updated.NextRunAt.Weekday())
+}
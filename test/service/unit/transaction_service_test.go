@@ -2,9 +2,13 @@ package unit
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/fx"
 	"github.com/dirdr/goits/internal/service"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +22,8 @@ func TestTransactionService_ProcessTransfer_Success(t *testing.T) {
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
 	sourceBalance := &domain.AccountBalance{
@@ -32,23 +38,29 @@ func TestTransactionService_ProcessTransfer_Success(t *testing.T) {
 		Version:   1,
 	}
 
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(2)).Return(true, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
 	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(sourceBalance, nil)
 	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(destBalance, nil)
 	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).Return(nil)
 	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).Return(nil).Twice()
 	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance"), 1).Return(nil).Twice()
+	mockEventStore.On("AppendEvents", mock.Anything, tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Status == domain.OutboxStatusPending && assert.ObjectsAreEqual([]uint{1, 2}, m.AccountIDs)
+	})).Return(nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 2, decimal.NewFromInt(100))
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
 
 	require.NoError(t, err)
 	mockAccountRepo.AssertExpectations(t)
 	mockBalanceRepo.AssertExpectations(t)
 	mockEventRepo.AssertExpectations(t)
 	mockJournalRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
 }
 
 func TestTransactionService_ProcessTransfer_NegativeAmount(t *testing.T) {
@@ -56,11 +68,13 @@ func TestTransactionService_ProcessTransfer_NegativeAmount(t *testing.T) {
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 2, decimal.NewFromInt(-50))
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(-50)})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "transfer amount must be positive")
@@ -71,11 +85,13 @@ func TestTransactionService_ProcessTransfer_ZeroAmount(t *testing.T) {
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 2, decimal.Zero)
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.Zero})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "transfer amount must be positive")
@@ -86,11 +102,13 @@ func TestTransactionService_ProcessTransfer_SameAccount(t *testing.T) {
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 1, decimal.NewFromInt(100))
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 1, Amount: decimal.NewFromInt(100)})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "source and destination accounts cannot be the same")
@@ -101,6 +119,8 @@ func TestTransactionService_ProcessTransfer_InsufficientBalance(t *testing.T) {
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
 	sourceBalance := &domain.AccountBalance{
@@ -109,13 +129,13 @@ func TestTransactionService_ProcessTransfer_InsufficientBalance(t *testing.T) {
 		Version:   1,
 	}
 
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(2)).Return(true, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
 	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(sourceBalance, nil)
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 2, decimal.NewFromInt(100))
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "insufficient balance")
@@ -123,20 +143,52 @@ func TestTransactionService_ProcessTransfer_InsufficientBalance(t *testing.T) {
 	mockBalanceRepo.AssertExpectations(t)
 }
 
+func TestTransactionService_ProcessTransfer_FundsOnHold(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	sourceBalance := &domain.AccountBalance{
+		AccountID:  1,
+		Balance:    decimal.NewFromInt(150),
+		HeldAmount: decimal.NewFromInt(100),
+		Version:    1,
+	}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(sourceBalance, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient balance")
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestTransactionService_ProcessTransfer_SourceAccountNotFound(t *testing.T) {
 	mockAccountRepo := &MockAccountRepository{}
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(false, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(nil, nil)
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 2, decimal.NewFromInt(100))
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
 
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, service.ErrAccountNotFound)
 	assert.Contains(t, err.Error(), "source account not found")
 	mockAccountRepo.AssertExpectations(t)
 }
@@ -146,16 +198,675 @@ func TestTransactionService_ProcessTransfer_DestinationAccountNotFound(t *testin
 	mockBalanceRepo := &MockAccountBalanceRepository{}
 	mockEventRepo := &MockTransferEventRepository{}
 	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
 	tx := &gorm.DB{}
 
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(1)).Return(true, nil)
-	mockAccountRepo.On("AccountExists", mock.Anything, tx, uint(2)).Return(false, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(nil, nil)
 
-	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo)
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
 
-	err := svc.ProcessTransfer(context.Background(), tx, 1, 2, decimal.NewFromInt(100))
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "destination account not found")
 	mockAccountRepo.AssertExpectations(t)
 }
+
+func TestTransactionService_ProcessTransfer_CurrencyMismatch(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "EUR"}, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
+
+	assert.ErrorIs(t, err, service.ErrCurrencyMismatch)
+	mockAccountRepo.AssertExpectations(t)
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_ProcessTransfer_IdempotentReplay(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	var savedEvent *domain.TransferEvent
+	sourceBalance := &domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(500), Version: 1}
+	destBalance := &domain.AccountBalance{AccountID: 2, Balance: decimal.NewFromInt(200), Version: 1}
+
+	mockEventRepo.On("GetTransferEventByIdempotencyKey", mock.Anything, tx, "key-1").Return(nil, nil).Once()
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "USD"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(sourceBalance, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(destBalance, nil)
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).
+		Run(func(args mock.Arguments) { savedEvent = args.Get(2).(*domain.TransferEvent) }).
+		Return(nil).Once()
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).Return(nil).Twice()
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance"), 1).Return(nil).Twice()
+	mockEventStore.On("AppendEvents", mock.Anything, tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	first, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.NotNil(t, savedEvent)
+	assert.Equal(t, "key-1", savedEvent.IdempotencyKey)
+	assert.NotEmpty(t, savedEvent.RequestFingerprint)
+
+	mockEventRepo.On("GetTransferEventByIdempotencyKey", mock.Anything, tx, "key-1").Return(savedEvent, nil).Once()
+
+	replay, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("100.00"), IdempotencyKey: "key-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, first.TransferID, replay.TransferID)
+	mockEventRepo.AssertNumberOfCalls(t, "SaveTransferEvent", 1)
+	mockJournalRepo.AssertNumberOfCalls(t, "SaveJournalEntry", 2)
+}
+
+func TestTransactionService_ProcessTransfer_IdempotencyKeyReused(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	existing := &domain.TransferEvent{
+		EventID:            7,
+		TransferID:         "existing-transfer",
+		FromAccountID:      1,
+		ToAccountID:        2,
+		Amount:             decimal.NewFromInt(100),
+		IdempotencyKey:     "key-1",
+		RequestFingerprint: "fingerprint-of-another-request",
+	}
+
+	mockEventRepo.On("GetTransferEventByIdempotencyKey", mock.Anything, tx, "key-1").Return(existing, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	event, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(250), IdempotencyKey: "key-1"})
+
+	assert.ErrorIs(t, err, service.ErrIdempotencyKeyReused)
+	assert.Nil(t, event)
+	mockEventRepo.AssertExpectations(t)
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_GetTransfer_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+
+	transferID := "5f0c2a4e-0d0b-4a8e-9a53-3c1a3e8b7f10"
+	event := &domain.TransferEvent{
+		EventID:       3,
+		TransferID:    transferID,
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.NewFromInt(100),
+		EventType:     "TransferProcessed",
+	}
+	entries := []domain.JournalEntry{
+		{EntryID: 5, TransactionID: transferID, AccountID: 1, Amount: decimal.NewFromInt(100), Type: domain.Debit, SourceEventID: 3},
+		{EntryID: 6, TransactionID: transferID, AccountID: 2, Amount: decimal.NewFromInt(100), Type: domain.Credit, SourceEventID: 3},
+	}
+
+	mockEventRepo.On("GetTransferEventByTransferID", mock.Anything, (*gorm.DB)(nil), transferID).Return(event, nil)
+	mockJournalRepo.On("GetJournalEntriesByTransactionID", mock.Anything, (*gorm.DB)(nil), transferID).Return(entries, nil)
+	mockEventRepo.On("GetReversalsOfTransfer", mock.Anything, (*gorm.DB)(nil), transferID).Return([]domain.TransferEvent{}, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	details, err := svc.GetTransfer(context.Background(), transferID)
+
+	require.NoError(t, err)
+	require.NotNil(t, details)
+	assert.Equal(t, event, details.Event)
+	assert.Len(t, details.Entries, 2)
+	mockEventRepo.AssertExpectations(t)
+	mockJournalRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_GetTransfer_NotFound(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+
+	mockEventRepo.On("GetTransferEventByTransferID", mock.Anything, (*gorm.DB)(nil), "missing").Return(nil, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	details, err := svc.GetTransfer(context.Background(), "missing")
+
+	require.NoError(t, err)
+	assert.Nil(t, details)
+	mockJournalRepo.AssertNotCalled(t, "GetJournalEntriesByTransactionID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_ProcessTransfer_CrossCurrency(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	ratesPath := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(ratesPath, []byte(`{"source": "test-feed", "as_of": "2026-01-02T16:00:00Z", "rates": {"EUR/USD": "1.25"}}`), 0o600))
+	rateProvider, err := fx.NewFileRateProvider(ratesPath)
+	require.NoError(t, err)
+
+	var savedEvent *domain.TransferEvent
+	var savedEntries []*domain.JournalEntry
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Type: domain.AccountTypeCustomer, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Type: domain.AccountTypeCustomer, Currency: "EUR"}, nil)
+	mockAccountRepo.On("GetSystemAccount", mock.Anything, tx, domain.AccountTypeFXPosition, "USD").Return(&domain.Account{ID: 901, Type: domain.AccountTypeFXPosition, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetSystemAccount", mock.Anything, tx, domain.AccountTypeFXPosition, "EUR").Return(&domain.Account{ID: 902, Type: domain.AccountTypeFXPosition, Currency: "EUR"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(500), Version: 1}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(&domain.AccountBalance{AccountID: 2, Balance: decimal.Zero, Version: 1}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(901)).Return(&domain.AccountBalance{AccountID: 901, Balance: decimal.Zero, Version: 1}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(902)).Return(&domain.AccountBalance{AccountID: 902, Balance: decimal.Zero, Version: 1}, nil)
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).
+		Run(func(args mock.Arguments) { savedEvent = args.Get(2).(*domain.TransferEvent) }).
+		Return(nil)
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).
+		Run(func(args mock.Arguments) { savedEntries = append(savedEntries, args.Get(2).(*domain.JournalEntry)) }).
+		Return(nil).Times(4)
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance"), 1).Return(nil).Times(4)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, rateProvider)
+
+	_, err = svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		AllowConversion:      true,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, savedEvent.Conversion)
+	assert.True(t, decimal.RequireFromString("0.8").Equal(savedEvent.Conversion.Rate))
+	assert.Equal(t, "test-feed", savedEvent.Conversion.RateSource)
+	assert.Equal(t, time.Date(2026, 1, 2, 16, 0, 0, 0, time.UTC), savedEvent.Conversion.RateTimestamp)
+	assert.True(t, decimal.NewFromInt(80).Equal(savedEvent.Conversion.DestinationAmount))
+
+	totals := map[string]decimal.Decimal{}
+	for _, entry := range savedEntries {
+		signed := entry.Amount
+		if entry.Type == domain.Debit {
+			signed = signed.Neg()
+		}
+		totals[entry.Currency] = totals[entry.Currency].Add(signed)
+	}
+	assert.True(t, totals["USD"].IsZero())
+	assert.True(t, totals["EUR"].IsZero())
+	mockAccountRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+	mockJournalRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ProcessTransfer_CrossCurrencyMissingRate(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	rateProvider := fx.NewStaticRateProvider("static", time.Now(), map[string]decimal.Decimal{"GBP/USD": decimal.RequireFromString("1.3")})
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Currency: "EUR"}, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, rateProvider)
+
+	_, err := svc.ProcessTransfer(context.Background(), tx, service.TransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		AllowConversion:      true,
+	})
+
+	assert.ErrorIs(t, err, fx.ErrRateNotFound)
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_ProcessMultiLegTransfer_Success(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	for _, id := range []uint{1, 2, 3, 4} {
+		mockAccountRepo.On("GetAccountByID", mock.Anything, tx, id).Return(&domain.Account{ID: id, Type: domain.AccountTypeCustomer, Currency: "USD"}, nil)
+	}
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(100), Version: 3}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(&domain.AccountBalance{AccountID: 2, Balance: decimal.Zero, Version: 1}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(3)).Return(&domain.AccountBalance{AccountID: 3, Balance: decimal.Zero, Version: 1}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(4)).Return(&domain.AccountBalance{AccountID: 4, Balance: decimal.Zero, Version: 1}, nil)
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).Return(nil)
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).Return(nil).Times(4)
+
+	var updated []*domain.AccountBalance
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance"), mock.Anything).
+		Run(func(args mock.Arguments) { updated = append(updated, args.Get(2).(*domain.AccountBalance)) }).
+		Return(nil).Times(4)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	event, err := svc.ProcessMultiLegTransfer(context.Background(), tx, service.MultiLegTransferRequest{
+		Legs: []domain.Posting{
+			{AccountID: 1, Type: domain.Debit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 4, Type: domain.Credit, Amount: decimal.NewFromInt(80)},
+			{AccountID: 3, Type: domain.Credit, Amount: decimal.NewFromInt(15)},
+			{AccountID: 2, Type: domain.Credit, Amount: decimal.NewFromInt(5)},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventTypeMultiLegTransferProcessed, event.EventType)
+	assert.True(t, decimal.NewFromInt(100).Equal(event.Amount))
+	require.Len(t, updated, 4)
+	for i, accountID := range []uint{1, 2, 3, 4} {
+		assert.Equal(t, accountID, updated[i].AccountID, "balances must be updated in account ID order")
+	}
+	assert.True(t, updated[0].Balance.IsZero())
+	assert.Equal(t, 4, updated[0].Version)
+	assert.True(t, decimal.NewFromInt(80).Equal(updated[3].Balance))
+	mockJournalRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ProcessMultiLegTransfer_Unbalanced(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ProcessMultiLegTransfer(context.Background(), tx, service.MultiLegTransferRequest{
+		Legs: []domain.Posting{
+			{AccountID: 1, Type: domain.Debit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 2, Type: domain.Credit, Amount: decimal.NewFromInt(90)},
+		},
+	})
+
+	assert.ErrorIs(t, err, service.ErrUnbalancedLegs)
+	mockAccountRepo.AssertNotCalled(t, "GetAccountByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_ProcessMultiLegTransfer_InsufficientBalance(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Type: domain.AccountTypeCustomer, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Type: domain.AccountTypeCustomer, Currency: "USD"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(10), Version: 1}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(&domain.AccountBalance{AccountID: 2, Balance: decimal.NewFromInt(10), Version: 1}, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ProcessMultiLegTransfer(context.Background(), tx, service.MultiLegTransferRequest{
+		Legs: []domain.Posting{
+			{AccountID: 1, Type: domain.Debit, Amount: decimal.NewFromInt(5)},
+			{AccountID: 2, Type: domain.Debit, Amount: decimal.NewFromInt(20)},
+			{AccountID: 1, Type: domain.Credit, Amount: decimal.NewFromInt(25)},
+		},
+	})
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+	assert.Contains(t, err.Error(), "insufficient balance in account 2")
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func reversibleTransfer() (*domain.TransferEvent, []domain.JournalEntry) {
+	transferID := "5f0c2a4e-0d0b-4a8e-9a53-3c1a3e8b7f10"
+	event := &domain.TransferEvent{
+		EventID:       3,
+		TransferID:    transferID,
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.NewFromInt(100),
+		Currency:      "USD",
+		EventType:     domain.EventTypeTransferProcessed,
+	}
+	entries := []domain.JournalEntry{
+		{EntryID: 5, TransactionID: transferID, AccountID: 1, Amount: decimal.NewFromInt(100), Currency: "USD", Type: domain.Debit, SourceEventID: 3},
+		{EntryID: 6, TransactionID: transferID, AccountID: 2, Amount: decimal.NewFromInt(100), Currency: "USD", Type: domain.Credit, SourceEventID: 3},
+	}
+	return event, entries
+}
+
+func TestTransactionService_ReverseTransfer_Partial(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	original, originalEntries := reversibleTransfer()
+	previous := []domain.TransferEvent{{TransferID: "earlier-reversal", Amount: decimal.NewFromInt(30), ReversesTransferID: original.TransferID}}
+
+	mockEventRepo.On("LockTransferEvent", mock.Anything, tx, original.TransferID).Return(original, nil)
+	mockEventRepo.On("GetReversalsOfTransfer", mock.Anything, tx, original.TransferID).Return(previous, nil)
+	mockJournalRepo.On("GetJournalEntriesByTransactionID", mock.Anything, tx, original.TransferID).Return(originalEntries, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(1)).Return(&domain.Account{ID: 1, Type: domain.AccountTypeCustomer, Currency: "USD"}, nil)
+	mockAccountRepo.On("GetAccountByID", mock.Anything, tx, uint(2)).Return(&domain.Account{ID: 2, Type: domain.AccountTypeCustomer, Currency: "USD"}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(1)).Return(&domain.AccountBalance{AccountID: 1, Balance: decimal.NewFromInt(400), Version: 2}, nil)
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(2)).Return(&domain.AccountBalance{AccountID: 2, Balance: decimal.NewFromInt(70), Version: 3}, nil)
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).Return(nil)
+
+	var savedEntries []*domain.JournalEntry
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).
+		Run(func(args mock.Arguments) { savedEntries = append(savedEntries, args.Get(2).(*domain.JournalEntry)) }).
+		Return(nil).Twice()
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.AccountID == 1 && b.Balance.Equal(decimal.NewFromInt(460))
+	}), 2).Return(nil).Once()
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.MatchedBy(func(b *domain.AccountBalance) bool {
+		return b.AccountID == 2 && b.Balance.Equal(decimal.NewFromInt(10))
+	}), 3).Return(nil).Once()
+	mockEventStore.On("GetStreamSequence", mock.Anything, tx, domain.AggregateTypeTransfer, original.TransferID).Return(int64(2), nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeTransfer, original.TransferID, int64(2), mock.MatchedBy(func(events []*domain.EventEnvelope) bool {
+		return len(events) == 1 && events[0].EventType == domain.EventTypeTransferReversed
+	})).Return(nil)
+
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	reversal, err := svc.ReverseTransfer(context.Background(), tx, service.ReversalRequest{
+		TransferID: original.TransferID,
+		Amount:     decimal.NewFromInt(60),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventTypeTransferReversed, reversal.EventType)
+	assert.Equal(t, original.TransferID, reversal.ReversesTransferID)
+	assert.NotEqual(t, original.TransferID, reversal.TransferID)
+	require.Len(t, savedEntries, 2)
+	assert.Equal(t, domain.Credit, savedEntries[0].Type)
+	assert.Equal(t, domain.Debit, savedEntries[1].Type)
+	assert.Equal(t, reversal.TransferID, savedEntries[0].TransactionID)
+	mockBalanceRepo.AssertExpectations(t)
+	mockEventStore.AssertExpectations(t)
+}
+
+func TestTransactionService_ReverseTransfer_FinalConversionReversesRemainder(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	// 100 USD converted at 0.3333333333 into 33.33333333 EUR through the FX
+	// position accounts 901 (USD) and 902 (EUR). The first half was reversed
+	// as 16.66666667 EUR, rounded up.
+	transferID := "5f0c2a4e-0d0b-4a8e-9a53-3c1a3e8b7f10"
+	original := &domain.TransferEvent{
+		EventID:       3,
+		TransferID:    transferID,
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        decimal.NewFromInt(100),
+		Currency:      "USD",
+		EventType:     domain.EventTypeTransferProcessed,
+		Conversion: &domain.Conversion{
+			Rate:                decimal.RequireFromString("0.3333333333"),
+			DestinationAmount:   decimal.RequireFromString("33.33333333"),
+			DestinationCurrency: "EUR",
+		},
+	}
+	originalEntries := []domain.JournalEntry{
+		{TransactionID: transferID, AccountID: 1, Amount: decimal.NewFromInt(100), Currency: "USD", Type: domain.Debit},
+		{TransactionID: transferID, AccountID: 901, Amount: decimal.NewFromInt(100), Currency: "USD", Type: domain.Credit},
+		{TransactionID: transferID, AccountID: 902, Amount: decimal.RequireFromString("33.33333333"), Currency: "EUR", Type: domain.Debit},
+		{TransactionID: transferID, AccountID: 2, Amount: decimal.RequireFromString("33.33333333"), Currency: "EUR", Type: domain.Credit},
+	}
+	previous := []domain.TransferEvent{{TransferID: "earlier-reversal", Amount: decimal.NewFromInt(50), ReversesTransferID: transferID}}
+	previousEntries := []domain.JournalEntry{
+		{TransactionID: "earlier-reversal", AccountID: 1, Amount: decimal.NewFromInt(50), Currency: "USD", Type: domain.Credit},
+		{TransactionID: "earlier-reversal", AccountID: 901, Amount: decimal.NewFromInt(50), Currency: "USD", Type: domain.Debit},
+		{TransactionID: "earlier-reversal", AccountID: 902, Amount: decimal.RequireFromString("16.66666667"), Currency: "EUR", Type: domain.Credit},
+		{TransactionID: "earlier-reversal", AccountID: 2, Amount: decimal.RequireFromString("16.66666667"), Currency: "EUR", Type: domain.Debit},
+	}
+
+	mockEventRepo.On("LockTransferEvent", mock.Anything, tx, transferID).Return(original, nil)
+	mockEventRepo.On("GetReversalsOfTransfer", mock.Anything, tx, transferID).Return(previous, nil)
+	mockJournalRepo.On("GetJournalEntriesByTransactionID", mock.Anything, tx, transferID).Return(originalEntries, nil)
+	mockJournalRepo.On("ListJournalEntriesByTransactionIDs", mock.Anything, tx, []string{"earlier-reversal"}).Return(previousEntries, nil).Once()
+	for _, account := range []*domain.Account{
+		{ID: 1, Type: domain.AccountTypeCustomer, Currency: "USD"},
+		{ID: 2, Type: domain.AccountTypeCustomer, Currency: "EUR"},
+		{ID: 901, Type: domain.AccountTypeFXPosition, Currency: "USD"},
+		{ID: 902, Type: domain.AccountTypeFXPosition, Currency: "EUR"},
+	} {
+		mockAccountRepo.On("GetAccountByID", mock.Anything, tx, account.ID).Return(account, nil)
+		mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, account.ID).Return(&domain.AccountBalance{AccountID: account.ID, Balance: decimal.NewFromInt(100), Version: 1}, nil)
+	}
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance"), 1).Return(nil).Times(4)
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).Return(nil)
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.AnythingOfType("*domain.OutboxMessage")).Return(nil)
+	mockEventStore.On("GetStreamSequence", mock.Anything, tx, domain.AggregateTypeTransfer, transferID).Return(int64(2), nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeTransfer, transferID, int64(2), mock.Anything).Return(nil)
+
+	saved := make(map[uint]*domain.JournalEntry)
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).
+		Run(func(args mock.Arguments) {
+			entry := args.Get(2).(*domain.JournalEntry)
+			saved[entry.AccountID] = entry
+		}).Return(nil).Times(4)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ReverseTransfer(context.Background(), tx, service.ReversalRequest{TransferID: transferID})
+
+	require.NoError(t, err)
+	require.Len(t, saved, 4)
+	assert.Equal(t, "50", saved[1].Amount.String())
+	assert.Equal(t, "50", saved[901].Amount.String())
+	// 50 USD converts to 16.66666667 EUR, but only 16.66666666 EUR are left.
+	assert.Equal(t, "16.66666666", saved[902].Amount.String())
+	assert.Equal(t, "16.66666666", saved[2].Amount.String())
+	assert.Equal(t, domain.Debit, saved[2].Type)
+	mockJournalRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_ReverseTransfer_ExceedsRemaining(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	original, _ := reversibleTransfer()
+	previous := []domain.TransferEvent{{TransferID: "earlier-reversal", Amount: decimal.NewFromInt(80), ReversesTransferID: original.TransferID}}
+
+	mockEventRepo.On("LockTransferEvent", mock.Anything, tx, original.TransferID).Return(original, nil)
+	mockEventRepo.On("GetReversalsOfTransfer", mock.Anything, tx, original.TransferID).Return(previous, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ReverseTransfer(context.Background(), tx, service.ReversalRequest{
+		TransferID: original.TransferID,
+		Amount:     decimal.NewFromInt(25),
+	})
+
+	assert.ErrorIs(t, err, service.ErrReversalExceedsRemaining)
+	mockEventRepo.AssertNotCalled(t, "SaveTransferEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_ReverseTransfer_PartialMultiLegRejected(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	original := &domain.TransferEvent{
+		EventID:    4,
+		TransferID: "0b7e0f6c-2f0c-4d53-8d0e-3f1f6f1d2a11",
+		Amount:     decimal.NewFromInt(100),
+		Currency:   "USD",
+		EventType:  domain.EventTypeMultiLegTransferProcessed,
+		Legs: []domain.Posting{
+			{AccountID: 1, Type: domain.Debit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 2, Type: domain.Credit, Amount: decimal.NewFromInt(90)},
+			{AccountID: 3, Type: domain.Credit, Amount: decimal.NewFromInt(10)},
+		},
+	}
+
+	mockEventRepo.On("LockTransferEvent", mock.Anything, tx, original.TransferID).Return(original, nil)
+	mockEventRepo.On("GetReversalsOfTransfer", mock.Anything, tx, original.TransferID).Return([]domain.TransferEvent{}, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ReverseTransfer(context.Background(), tx, service.ReversalRequest{
+		TransferID: original.TransferID,
+		Amount:     decimal.NewFromInt(50),
+	})
+
+	assert.ErrorIs(t, err, service.ErrInvalidReversal)
+}
+
+func TestTransactionService_ReverseTransfer_NotFound(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	mockEventRepo.On("LockTransferEvent", mock.Anything, tx, "missing").Return(nil, nil)
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	_, err := svc.ReverseTransfer(context.Background(), tx, service.ReversalRequest{TransferID: "missing"})
+
+	assert.ErrorIs(t, err, service.ErrTransferNotFound)
+}
+
+func TestTransactionService_FundAccount(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockBalanceRepo := &MockAccountBalanceRepository{}
+	mockEventRepo := &MockTransferEventRepository{}
+	mockJournalRepo := &MockJournalRepository{}
+	mockEventStore := &MockEventStore{}
+	mockOutboxRepo := &MockOutboxRepository{}
+	tx := &gorm.DB{}
+
+	equity := &domain.Account{ID: 900, Type: domain.AccountTypeEquity, Currency: "EUR"}
+	var entries []*domain.JournalEntry
+	updated := make(map[uint]*domain.AccountBalance)
+
+	mockEventRepo.On("SaveTransferEvent", mock.Anything, tx, mock.AnythingOfType("*domain.TransferEvent")).
+		Run(func(args mock.Arguments) { args.Get(2).(*domain.TransferEvent).EventID = 5 }).
+		Return(nil)
+	mockOutboxRepo.On("SaveOutboxMessage", mock.Anything, tx, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.EventType == domain.EventTypeAccountFunded && assert.ObjectsAreEqual([]uint{1, 900}, m.AccountIDs)
+	})).Return(nil)
+	mockEventStore.On("AppendEvents", mock.Anything, tx, domain.AggregateTypeTransfer, "funding-1", int64(0), mock.MatchedBy(func(events []*domain.EventEnvelope) bool {
+		return len(events) == 1 && events[0].EventType == domain.EventTypeAccountFunded && events[0].Metadata["transfer_event_id"] == "5"
+	})).Return(nil)
+	mockJournalRepo.On("SaveJournalEntry", mock.Anything, tx, mock.AnythingOfType("*domain.JournalEntry")).
+		Run(func(args mock.Arguments) { entries = append(entries, args.Get(2).(*domain.JournalEntry)) }).
+		Return(nil).Twice()
+	mockBalanceRepo.On("GetAccountBalance", mock.Anything, tx, uint(900)).Return(&domain.AccountBalance{AccountID: 900, Balance: decimal.NewFromInt(-50), Version: 3}, nil)
+	mockBalanceRepo.On("UpdateAccountBalanceWithVersion", mock.Anything, tx, mock.AnythingOfType("*domain.AccountBalance"), mock.AnythingOfType("int")).
+		Run(func(args mock.Arguments) {
+			balance := args.Get(2).(*domain.AccountBalance)
+			updated[balance.AccountID] = balance
+		}).Return(nil).Twice()
+
+	svc := service.NewTransactionService(mockAccountRepo, mockBalanceRepo, mockEventRepo, mockJournalRepo, mockEventStore, mockOutboxRepo, nil)
+
+	event, err := svc.FundAccount(context.Background(), tx, service.FundingRequest{
+		TransferID:     "funding-1",
+		FundingAccount: equity,
+		Balance:        &domain.AccountBalance{AccountID: 1, Balance: decimal.Zero, Version: 1},
+		Amount:         decimal.NewFromInt(100),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventTypeAccountFunded, event.EventType)
+	assert.Equal(t, uint(900), event.FromAccountID)
+	assert.Equal(t, uint(1), event.ToAccountID)
+
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "funding-1", entry.TransactionID)
+		assert.Equal(t, uint(5), entry.SourceEventID)
+		assert.Equal(t, "100", entry.Amount.String())
+	}
+	assert.Equal(t, domain.Debit, entries[0].Type)
+	assert.Equal(t, uint(900), entries[0].AccountID)
+	assert.Equal(t, domain.Credit, entries[1].Type)
+	assert.Equal(t, uint(1), entries[1].AccountID)
+
+	assert.Equal(t, "100", updated[1].Balance.String())
+	assert.Equal(t, 2, updated[1].Version)
+	assert.Equal(t, uint(5), updated[1].LastEventID)
+	assert.Equal(t, "-150", updated[900].Balance.String())
+	assert.Equal(t, 4, updated[900].Version)
+
+	mockEventRepo.AssertExpectations(t)
+	mockJournalRepo.AssertExpectations(t)
+	mockEventStore.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+}
@@ -0,0 +1,326 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testWebhookSecret = "0123456789abcdef"
+
+var testWebhookPolicy = service.WebhookDeliveryPolicy{
+	MinBackoff:   10 * time.Second,
+	MaxBackoff:   time.Hour,
+	MaxAttempts:  5,
+	DisableAfter: 3,
+}
+
+func activeSubscription(url string) *domain.WebhookSubscription {
+	return &domain.WebhookSubscription{
+		SubscriptionID: "6b0f8f5e-0c7d-4c1e-9b3a-2f1d0e4c5b6a",
+		URL:            url,
+		EventTypes:     []string{domain.EventTypeTransferProcessed},
+		Secret:         testWebhookSecret,
+		Status:         domain.WebhookSubscriptionStatusActive,
+	}
+}
+
+func pendingDelivery(deliveryID uint, due time.Time) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		DeliveryID:     deliveryID,
+		SubscriptionID: "6b0f8f5e-0c7d-4c1e-9b3a-2f1d0e4c5b6a",
+		MessageID:      42,
+		EventType:      domain.EventTypeTransferProcessed,
+		Payload:        json.RawMessage(`{"message_id":42,"event_type":"TransferProcessed"}`),
+		Status:         domain.WebhookDeliveryStatusPending,
+		NextAttemptAt:  due,
+	}
+}
+
+// receivedWebhook is what the test receiver got, and whether the signature
+// verified.
+type receivedWebhook struct {
+	messageID string
+	eventType string
+	body      []byte
+	verifyErr error
+}
+
+func webhookReceiver(t *testing.T, status int, received *receivedWebhook) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*received = receivedWebhook{
+			messageID: r.Header.Get(webhook.MessageIDHeader),
+			eventType: r.Header.Get(webhook.EventTypeHeader),
+			body:      body,
+			verifyErr: webhook.Verify(testWebhookSecret, r.Header.Get(webhook.TimestampHeader), r.Header.Get(webhook.SignatureHeader), body, time.Now(), 5*time.Minute),
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookService_CreateWebhookSubscription_Success(t *testing.T) {
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+
+	mockWebhookRepo.On("SaveWebhookSubscription", mock.Anything, tx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+		return s.Secret == testWebhookSecret && s.Status == domain.WebhookSubscriptionStatusActive
+	})).Return(nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+	subscription, err := svc.CreateWebhookSubscription(context.Background(), tx, service.WebhookSubscriptionRequest{
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{domain.EventTypeTransferProcessed, domain.EventTypeAccountOpened, domain.EventTypeTransferProcessed},
+		Secret:     testWebhookSecret,
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, subscription.SubscriptionID)
+	assert.Equal(t, []string{domain.EventTypeTransferProcessed, domain.EventTypeAccountOpened}, subscription.EventTypes)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_CreateWebhookSubscription_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  service.WebhookSubscriptionRequest
+	}{
+		{"relative url", service.WebhookSubscriptionRequest{URL: "/hooks", EventTypes: []string{domain.EventTypeTransferProcessed}, Secret: testWebhookSecret}},
+		{"unsupported scheme", service.WebhookSubscriptionRequest{URL: "ftp://example.com", EventTypes: []string{domain.EventTypeTransferProcessed}, Secret: testWebhookSecret}},
+		{"no event types", service.WebhookSubscriptionRequest{URL: "https://example.com", Secret: testWebhookSecret}},
+		{"unknown event type", service.WebhookSubscriptionRequest{URL: "https://example.com", EventTypes: []string{"AccountClosed"}, Secret: testWebhookSecret}},
+		{"short secret", service.WebhookSubscriptionRequest{URL: "https://example.com", EventTypes: []string{domain.EventTypeTransferProcessed}, Secret: "short"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWebhookRepo := &MockWebhookRepository{}
+			svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+			subscription, err := svc.CreateWebhookSubscription(context.Background(), &gorm.DB{}, tt.req)
+
+			assert.ErrorIs(t, err, service.ErrInvalidWebhookSubscription)
+			assert.Nil(t, subscription)
+			mockWebhookRepo.AssertNotCalled(t, "SaveWebhookSubscription", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWebhookService_UpdateWebhookSubscription_EnableResetsFailures(t *testing.T) {
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	disabledAt := time.Now().Add(-time.Hour)
+	subscription := activeSubscription("https://example.com/hooks")
+	subscription.Status = domain.WebhookSubscriptionStatusDisabled
+	subscription.ConsecutiveFailures = 3
+	subscription.DisabledReason = "3 consecutive failed deliveries"
+	subscription.DisabledAt = &disabledAt
+
+	mockWebhookRepo.On("LockWebhookSubscription", mock.Anything, tx, subscription.SubscriptionID).Return(subscription, nil)
+	mockWebhookRepo.On("UpdateWebhookSubscription", mock.Anything, tx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+		return s.Status == domain.WebhookSubscriptionStatusActive && s.ConsecutiveFailures == 0 && s.DisabledAt == nil && s.DisabledReason == ""
+	})).Return(nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+	active := domain.WebhookSubscriptionStatusActive
+	updated, err := svc.UpdateWebhookSubscription(context.Background(), tx, service.WebhookSubscriptionUpdate{
+		SubscriptionID: subscription.SubscriptionID,
+		Status:         &active,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookSubscriptionStatusActive, updated.Status)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverWebhook_SignedDelivery(t *testing.T) {
+	var received receivedWebhook
+	server := webhookReceiver(t, http.StatusOK, &received)
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+	subscription := activeSubscription(server.URL)
+	subscription.ConsecutiveFailures = 2
+
+	mockWebhookRepo.On("ClaimWebhookDelivery", mock.Anything, tx, uint(7)).Return(pendingDelivery(7, now), nil)
+	mockWebhookRepo.On("LockWebhookSubscription", mock.Anything, tx, subscription.SubscriptionID).Return(subscription, nil)
+	mockWebhookRepo.On("SaveWebhookDeliveryAttempt", mock.Anything, tx, mock.MatchedBy(func(a *domain.WebhookDeliveryAttempt) bool {
+		return a.DeliveryID == 7 && a.StatusCode == http.StatusOK && a.Error == ""
+	})).Return(nil)
+	mockWebhookRepo.On("UpdateWebhookDelivery", mock.Anything, tx, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		return d.Status == domain.WebhookDeliveryStatusDelivered && d.Attempts == 1 && d.DeliveredAt != nil
+	})).Return(nil)
+	mockWebhookRepo.On("UpdateWebhookSubscription", mock.Anything, tx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+		return s.ConsecutiveFailures == 0 && s.Status == domain.WebhookSubscriptionStatusActive
+	})).Return(nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, webhook.NewHTTPSender(server.Client()), testWebhookPolicy)
+
+	outcome, err := svc.DeliverWebhook(context.Background(), tx, 7, now)
+
+	require.NoError(t, err)
+	require.NotNil(t, outcome)
+	assert.False(t, outcome.SubscriptionDisabled)
+	assert.NoError(t, received.verifyErr)
+	assert.Equal(t, "42", received.messageID)
+	assert.Equal(t, domain.EventTypeTransferProcessed, received.eventType)
+	assert.JSONEq(t, `{"message_id":42,"event_type":"TransferProcessed"}`, string(received.body))
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverWebhook_FailureSchedulesRetry(t *testing.T) {
+	var received receivedWebhook
+	server := webhookReceiver(t, http.StatusServiceUnavailable, &received)
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+	delivery := pendingDelivery(7, now)
+	delivery.Attempts = 2
+
+	mockWebhookRepo.On("ClaimWebhookDelivery", mock.Anything, tx, uint(7)).Return(delivery, nil)
+	mockWebhookRepo.On("LockWebhookSubscription", mock.Anything, tx, delivery.SubscriptionID).Return(activeSubscription(server.URL), nil)
+	mockWebhookRepo.On("SaveWebhookDeliveryAttempt", mock.Anything, tx, mock.MatchedBy(func(a *domain.WebhookDeliveryAttempt) bool {
+		return a.StatusCode == http.StatusServiceUnavailable && a.Error != ""
+	})).Return(nil)
+	mockWebhookRepo.On("UpdateWebhookDelivery", mock.Anything, tx, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		return d.Status == domain.WebhookDeliveryStatusPending && d.Attempts == 3 &&
+			d.NextAttemptAt.Equal(now.Add(40*time.Second)) && d.LastError == "webhook responded with status 503"
+	})).Return(nil)
+	mockWebhookRepo.On("UpdateWebhookSubscription", mock.Anything, tx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+		return s.ConsecutiveFailures == 1 && s.Status == domain.WebhookSubscriptionStatusActive
+	})).Return(nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, webhook.NewHTTPSender(server.Client()), testWebhookPolicy)
+
+	outcome, err := svc.DeliverWebhook(context.Background(), tx, 7, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, outcome.Attempt.StatusCode)
+	assert.False(t, outcome.SubscriptionDisabled)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverWebhook_DisablesFailingSubscription(t *testing.T) {
+	var received receivedWebhook
+	server := webhookReceiver(t, http.StatusInternalServerError, &received)
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+	delivery := pendingDelivery(7, now)
+	delivery.Attempts = 4
+	subscription := activeSubscription(server.URL)
+	subscription.ConsecutiveFailures = 2
+
+	mockWebhookRepo.On("ClaimWebhookDelivery", mock.Anything, tx, uint(7)).Return(delivery, nil)
+	mockWebhookRepo.On("LockWebhookSubscription", mock.Anything, tx, subscription.SubscriptionID).Return(subscription, nil)
+	mockWebhookRepo.On("SaveWebhookDeliveryAttempt", mock.Anything, tx, mock.Anything).Return(nil)
+	mockWebhookRepo.On("UpdateWebhookDelivery", mock.Anything, tx, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		return d.Status == domain.WebhookDeliveryStatusFailed && d.Attempts == 5
+	})).Return(nil)
+	mockWebhookRepo.On("UpdateWebhookSubscription", mock.Anything, tx, mock.MatchedBy(func(s *domain.WebhookSubscription) bool {
+		return s.Status == domain.WebhookSubscriptionStatusDisabled && s.ConsecutiveFailures == 3 &&
+			s.DisabledAt != nil && s.DisabledReason != ""
+	})).Return(nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, webhook.NewHTTPSender(server.Client()), testWebhookPolicy)
+
+	outcome, err := svc.DeliverWebhook(context.Background(), tx, 7, now)
+
+	require.NoError(t, err)
+	assert.True(t, outcome.SubscriptionDisabled)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverWebhook_NotDue(t *testing.T) {
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+
+	mockWebhookRepo.On("ClaimWebhookDelivery", mock.Anything, tx, uint(7)).Return(pendingDelivery(7, now.Add(time.Minute)), nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+	outcome, err := svc.DeliverWebhook(context.Background(), tx, 7, now)
+
+	require.NoError(t, err)
+	assert.Nil(t, outcome)
+	mockWebhookRepo.AssertNotCalled(t, "LockWebhookSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_RedeliverWebhook(t *testing.T) {
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	now := time.Now()
+	deliveredAt := now.Add(-time.Hour)
+	delivery := pendingDelivery(7, now.Add(-time.Hour))
+	delivery.Status = domain.WebhookDeliveryStatusDelivered
+	delivery.Attempts = 1
+	delivery.DeliveredAt = &deliveredAt
+
+	mockWebhookRepo.On("LockWebhookDelivery", mock.Anything, tx, uint(7)).Return(delivery, nil)
+	mockWebhookRepo.On("GetWebhookSubscription", mock.Anything, tx, delivery.SubscriptionID).Return(activeSubscription("https://example.com"), nil)
+	mockWebhookRepo.On("UpdateWebhookDelivery", mock.Anything, tx, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		return d.Status == domain.WebhookDeliveryStatusPending && d.Attempts == 0 && d.NextAttemptAt.Equal(now) && d.DeliveredAt == nil
+	})).Return(nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+	redelivery, err := svc.RedeliverWebhook(context.Background(), tx, 7, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), redelivery.MessageID)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_RedeliverWebhook_SubscriptionDisabled(t *testing.T) {
+	mockWebhookRepo := &MockWebhookRepository{}
+	tx := &gorm.DB{}
+	subscription := activeSubscription("https://example.com")
+	subscription.Status = domain.WebhookSubscriptionStatusDisabled
+
+	mockWebhookRepo.On("LockWebhookDelivery", mock.Anything, tx, uint(7)).Return(pendingDelivery(7, time.Now()), nil)
+	mockWebhookRepo.On("GetWebhookSubscription", mock.Anything, tx, subscription.SubscriptionID).Return(subscription, nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+	_, err := svc.RedeliverWebhook(context.Background(), tx, 7, time.Now())
+
+	assert.ErrorIs(t, err, service.ErrWebhookSubscriptionDisabled)
+	mockWebhookRepo.AssertNotCalled(t, "UpdateWebhookDelivery", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_GetWebhookDelivery_IncludesAttempts(t *testing.T) {
+	mockWebhookRepo := &MockWebhookRepository{}
+	now := time.Now()
+	attempts := []domain.WebhookDeliveryAttempt{
+		{AttemptID: 1, DeliveryID: 7, StatusCode: http.StatusBadGateway, Error: "webhook responded with status 502", AttemptedAt: now.Add(-time.Minute)},
+		{AttemptID: 2, DeliveryID: 7, StatusCode: http.StatusOK, AttemptedAt: now},
+	}
+
+	mockWebhookRepo.On("GetWebhookDelivery", mock.Anything, (*gorm.DB)(nil), uint(7)).Return(pendingDelivery(7, now), nil)
+	mockWebhookRepo.On("ListWebhookDeliveryAttempts", mock.Anything, (*gorm.DB)(nil), uint(7)).Return(attempts, nil)
+
+	svc := service.NewWebhookService(mockWebhookRepo, nil, testWebhookPolicy)
+
+	delivery, err := svc.GetWebhookDelivery(context.Background(), 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, attempts, delivery.AttemptHistory)
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "0123456789abcdef"
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_900_000_000, 0)
+	body := []byte(`{"message_id":1}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := webhook.Sign(secret, now.Unix(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		now       time.Time
+		want      error
+	}{
+		{"valid", secret, timestamp, signature, body, now, nil},
+		{"within tolerance", secret, timestamp, signature, body, now.Add(4 * time.Minute), nil},
+		{"tampered body", secret, timestamp, signature, []byte(`{"message_id":2}`), now, webhook.ErrInvalidSignature},
+		{"wrong secret", "another-secret-value", timestamp, signature, body, now, webhook.ErrInvalidSignature},
+		{"changed timestamp", secret, strconv.FormatInt(now.Unix()+1, 10), signature, body, now, webhook.ErrInvalidSignature},
+		{"malformed timestamp", secret, "yesterday", signature, body, now, webhook.ErrInvalidSignature},
+		{"replayed later", secret, timestamp, signature, body, now.Add(6 * time.Minute), webhook.ErrStaleTimestamp},
+		{"from the future", secret, timestamp, signature, body, now.Add(-6 * time.Minute), webhook.ErrStaleTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Verify(tt.secret, tt.timestamp, tt.signature, tt.body, tt.now, 5*time.Minute)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestSign_KnownVector(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac 0123456789abcdef
+	assert.Equal(t, "v1=e4f8e2ecae2295b2ddb2f0b5584c8275e226c0ebe9b3b819e70156bb67122e3e", webhook.Sign(secret, 1_700_000_000, []byte("{}")))
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	sender := webhook.NewHTTPSender(server.Client())
+
+	status, err := sender.Send(context.Background(), webhook.Request{
+		URL:        server.URL,
+		Secret:     secret,
+		DeliveryID: 3,
+		MessageID:  9,
+		EventType:  "TransferProcessed",
+		Body:       []byte(`{"message_id":9}`),
+	}, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Equal(t, "9", headers.Get(webhook.MessageIDHeader))
+	assert.Equal(t, "3", headers.Get(webhook.DeliveryIDHeader))
+	assert.Equal(t, "TransferProcessed", headers.Get(webhook.EventTypeHeader))
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), headers.Get(webhook.TimestampHeader))
+	assert.NoError(t, webhook.Verify(secret, headers.Get(webhook.TimestampHeader), headers.Get(webhook.SignatureHeader), body, now, time.Minute))
+}
+
+func TestHTTPSender_Send_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	status, err := webhook.NewHTTPSender(server.Client()).Send(context.Background(), webhook.Request{URL: server.URL, Secret: secret}, time.Now())
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusGone, status)
+}
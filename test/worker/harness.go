@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txDriver is a database driver that only opens, commits and rolls back
+// transactions. Workers run mocked services inside them, so no statement is
+// ever sent; one that is fails.
+type txDriver struct {
+	mu        sync.Mutex
+	commits   int
+	rollbacks int
+}
+
+func (d *txDriver) Commits() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.commits
+}
+
+func (d *txDriver) Rollbacks() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rollbacks
+}
+
+func (d *txDriver) Open(string) (driver.Conn, error) { return &txConn{driver: d}, nil }
+
+func (d *txDriver) Connect(context.Context) (driver.Conn, error) { return d.Open("") }
+
+func (d *txDriver) Driver() driver.Driver { return d }
+
+type txConn struct {
+	driver *txDriver
+}
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("unexpected statement: " + query)
+}
+
+func (c *txConn) Close() error { return nil }
+
+func (c *txConn) Begin() (driver.Tx, error) { return &txTx{driver: c.driver}, nil }
+
+func (c *txConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return c.Begin() }
+
+type txTx struct {
+	driver *txDriver
+}
+
+func (t *txTx) Commit() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.commits++
+	return nil
+}
+
+func (t *txTx) Rollback() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.rollbacks++
+	return nil
+}
+
+// newTestDB returns a gorm database backed by a txDriver.
+func newTestDB(t *testing.T) (*gorm.DB, *txDriver) {
+	d := &txDriver{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(d)}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+	return db, d
+}
+
+// runUntil runs a worker until done reports true, then stops it.
+func runUntil(t *testing.T, run func(ctx context.Context), done func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		run(ctx)
+		close(stopped)
+	}()
+
+	require.Eventually(t, done, time.Second, time.Millisecond)
+	cancel()
+	<-stopped
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHashChainSealer_DrainsBacklog(t *testing.T) {
+	db, tx := newTestDB(t)
+	integrityService := &MockIntegrityService{}
+
+	integrityService.On("SealHashChains", mock.Anything, mock.Anything, 500).Return(&service.HashChainSealReport{TransferEvents: 500, JournalEntries: 120}, nil).Once()
+	integrityService.On("SealHashChains", mock.Anything, mock.Anything, 500).Return(&service.HashChainSealReport{TransferEvents: 20, JournalEntries: 40}, nil).Once()
+
+	sealer := worker.NewHashChainSealer(integrityService, db, discardLogger(), time.Hour)
+	runUntil(t, sealer.Run, func() bool { return tx.Commits() == 2 })
+
+	integrityService.AssertExpectations(t)
+}
+
+func TestHashChainSealer_RollsBackOnFailure(t *testing.T) {
+	db, tx := newTestDB(t)
+	integrityService := &MockIntegrityService{}
+
+	integrityService.On("SealHashChains", mock.Anything, mock.Anything, 500).Return(nil, errors.New("connection reset")).Once()
+
+	sealer := worker.NewHashChainSealer(integrityService, db, discardLogger(), time.Hour)
+	runUntil(t, sealer.Run, func() bool { return tx.Rollbacks() == 1 })
+
+	integrityService.AssertExpectations(t)
+	assert.Zero(t, tx.Commits())
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHoldExpirer_ContinuesPastFailedHolds(t *testing.T) {
+	db, tx := newTestDB(t)
+	holdService := &MockHoldService{}
+
+	holdService.On("ListExpiredHolds", mock.Anything, mock.Anything, 100).
+		Return([]domain.Hold{{HoldID: "h1"}, {HoldID: "h2"}}, nil).Once()
+	holdService.On("ExpireHold", mock.Anything, mock.Anything, "h1", mock.Anything).Return(nil, errors.New("connection reset")).Once()
+	holdService.On("ExpireHold", mock.Anything, mock.Anything, "h2", mock.Anything).
+		Return(&domain.Hold{HoldID: "h2", Status: domain.HoldStatusExpired}, nil).Once()
+
+	expirer := worker.NewHoldExpirer(holdService, db, discardLogger(), time.Hour)
+	runUntil(t, expirer.Run, func() bool { return tx.Commits() == 1 })
+
+	holdService.AssertExpectations(t)
+	assert.Equal(t, 1, tx.Rollbacks(), "the failed hold is rolled back on its own")
+}
+
+func TestHoldExpirer_SkipsHoldsThatLostTheRace(t *testing.T) {
+	db, tx := newTestDB(t)
+	holdService := &MockHoldService{}
+
+	holdService.On("ListExpiredHolds", mock.Anything, mock.Anything, 100).Return([]domain.Hold{{HoldID: "h1"}}, nil).Once()
+	holdService.On("ExpireHold", mock.Anything, mock.Anything, "h1", mock.Anything).Return(nil, nil).Once()
+
+	expirer := worker.NewHoldExpirer(holdService, db, discardLogger(), time.Hour)
+	runUntil(t, expirer.Run, func() bool { return tx.Commits() == 1 })
+
+	holdService.AssertExpectations(t)
+	assert.Zero(t, tx.Rollbacks())
+}
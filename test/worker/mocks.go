@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// The service mocks embed their interface so that they only implement the
+// methods the workers call; calling any other one panics.
+
+type MockIntegrityService struct {
+	service.IntegrityService
+	mock.Mock
+}
+
+func (m *MockIntegrityService) SealHashChains(ctx context.Context, tx *gorm.DB, limit int) (*service.HashChainSealReport, error) {
+	args := m.Called(ctx, tx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.HashChainSealReport), args.Error(1)
+}
+
+type MockScheduledTransferService struct {
+	service.ScheduledTransferService
+	mock.Mock
+}
+
+func (m *MockScheduledTransferService) ListDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferService) ExecuteScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, now time.Time) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, scheduleID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferService) FailScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, reason string, now time.Time) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, scheduleID, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferService) PostponeScheduledTransfer(ctx context.Context, tx *gorm.DB, scheduleID string, reason string, now time.Time) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, scheduleID, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduledTransfer), args.Error(1)
+}
+
+type MockStandingOrderService struct {
+	service.StandingOrderService
+	mock.Mock
+}
+
+func (m *MockStandingOrderService) ListDueStandingOrders(ctx context.Context, now time.Time, limit int) ([]domain.StandingOrder, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]domain.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderService) ExecuteStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, now time.Time) (*domain.StandingOrderExecution, error) {
+	args := m.Called(ctx, tx, standingOrderID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StandingOrderExecution), args.Error(1)
+}
+
+func (m *MockStandingOrderService) FailStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, reason string, now time.Time) (*domain.StandingOrderExecution, error) {
+	args := m.Called(ctx, tx, standingOrderID, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StandingOrderExecution), args.Error(1)
+}
+
+func (m *MockStandingOrderService) PostponeStandingOrder(ctx context.Context, tx *gorm.DB, standingOrderID string, reason string, now time.Time) (*domain.StandingOrder, error) {
+	args := m.Called(ctx, tx, standingOrderID, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StandingOrder), args.Error(1)
+}
+
+type MockOutboxService struct {
+	service.OutboxService
+	mock.Mock
+}
+
+func (m *MockOutboxService) ClaimOutboxMessages(ctx context.Context, tx *gorm.DB, now time.Time, limit int) (*service.OutboxBatch, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.OutboxBatch), args.Error(1)
+}
+
+func (m *MockOutboxService) PublishOutboxMessages(ctx context.Context, batch *service.OutboxBatch, now time.Time) {
+	m.Called(ctx, batch, now)
+}
+
+func (m *MockOutboxService) CompleteOutboxMessages(ctx context.Context, tx *gorm.DB, batch *service.OutboxBatch) error {
+	args := m.Called(ctx, tx, batch)
+	return args.Error(0)
+}
+
+type MockHoldService struct {
+	service.HoldService
+	mock.Mock
+}
+
+func (m *MockHoldService) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]domain.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) ExpireHold(ctx context.Context, tx *gorm.DB, holdID string, now time.Time) (*domain.Hold, error) {
+	args := m.Called(ctx, tx, holdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+type MockWebhookService struct {
+	service.WebhookService
+	mock.Mock
+}
+
+func (m *MockWebhookService) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) DeliverWebhook(ctx context.Context, tx *gorm.DB, deliveryID uint, now time.Time) (*service.WebhookDeliveryOutcome, error) {
+	args := m.Called(ctx, tx, deliveryID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.WebhookDeliveryOutcome), args.Error(1)
+}
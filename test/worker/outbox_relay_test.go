@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxRelay_PublishesBetweenClaimAndComplete(t *testing.T) {
+	db, tx := newTestDB(t)
+	outboxService := &MockOutboxService{}
+
+	batch := &service.OutboxBatch{Messages: []domain.OutboxMessage{{MessageID: 1}}}
+	commitsAtPublish := -1
+	outboxService.On("ClaimOutboxMessages", mock.Anything, mock.Anything, mock.Anything, 100).Return(batch, nil).Once()
+	outboxService.On("PublishOutboxMessages", mock.Anything, batch, mock.Anything).Run(func(mock.Arguments) {
+		commitsAtPublish = tx.Commits()
+		batch.Report.Published = 1
+	}).Once()
+	outboxService.On("CompleteOutboxMessages", mock.Anything, mock.Anything, batch).Return(nil).Once()
+
+	relay := worker.NewOutboxRelay(outboxService, db, discardLogger(), time.Hour)
+	runUntil(t, relay.Run, func() bool { return tx.Commits() == 2 })
+
+	outboxService.AssertExpectations(t)
+	assert.Equal(t, 1, commitsAtPublish, "messages are published after the claim is committed")
+}
+
+func TestOutboxRelay_StopsWhenAnotherRelayHoldsTheLock(t *testing.T) {
+	db, tx := newTestDB(t)
+	outboxService := &MockOutboxService{}
+
+	outboxService.On("ClaimOutboxMessages", mock.Anything, mock.Anything, mock.Anything, 100).Return(nil, nil).Once()
+
+	relay := worker.NewOutboxRelay(outboxService, db, discardLogger(), time.Hour)
+	runUntil(t, relay.Run, func() bool { return tx.Commits() == 1 })
+
+	outboxService.AssertExpectations(t)
+	outboxService.AssertNotCalled(t, "PublishOutboxMessages", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/worker"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStandingOrderScheduler_FailsRejectedActivations(t *testing.T) {
+	db, tx := newTestDB(t)
+	standingOrderService := &MockStandingOrderService{}
+
+	rejection := fmt.Errorf("%w: exchange rate not found", service.ErrInvalidTransfer)
+	standingOrderService.On("ListDueStandingOrders", mock.Anything, mock.Anything, 100).Return([]domain.StandingOrder{{StandingOrderID: "o1"}}, nil).Once()
+	standingOrderService.On("ExecuteStandingOrder", mock.Anything, mock.Anything, "o1", mock.Anything).Return(nil, rejection).Once()
+	standingOrderService.On("FailStandingOrder", mock.Anything, mock.Anything, "o1", rejection.Error(), mock.Anything).
+		Return(&domain.StandingOrderExecution{StandingOrderID: "o1", Status: domain.StandingOrderExecutionStatusFailed}, nil).Once()
+
+	scheduler := worker.NewStandingOrderScheduler(standingOrderService, db, discardLogger(), time.Hour)
+	runUntil(t, scheduler.Run, func() bool { return tx.Commits() == 1 })
+
+	standingOrderService.AssertExpectations(t)
+}
+
+func TestStandingOrderScheduler_PostponesTransientFailures(t *testing.T) {
+	db, tx := newTestDB(t)
+	standingOrderService := &MockStandingOrderService{}
+
+	nextAttemptAt := time.Now().Add(time.Minute)
+	standingOrderService.On("ListDueStandingOrders", mock.Anything, mock.Anything, 100).
+		Return([]domain.StandingOrder{{StandingOrderID: "o1"}, {StandingOrderID: "o2"}}, nil).Once()
+	standingOrderService.On("ExecuteStandingOrder", mock.Anything, mock.Anything, "o1", mock.Anything).Return(nil, errors.New("connection reset")).Once()
+	standingOrderService.On("PostponeStandingOrder", mock.Anything, mock.Anything, "o1", "connection reset", mock.Anything).
+		Return(&domain.StandingOrder{StandingOrderID: "o1", Attempts: 1, NextAttemptAt: &nextAttemptAt}, nil).Once()
+	standingOrderService.On("ExecuteStandingOrder", mock.Anything, mock.Anything, "o2", mock.Anything).
+		Return(&domain.StandingOrderExecution{StandingOrderID: "o2", Status: domain.StandingOrderExecutionStatusExecuted}, nil).Once()
+
+	scheduler := worker.NewStandingOrderScheduler(standingOrderService, db, discardLogger(), time.Hour)
+	runUntil(t, scheduler.Run, func() bool { return tx.Commits() == 2 })
+
+	standingOrderService.AssertExpectations(t)
+	standingOrderService.AssertNotCalled(t, "FailStandingOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/worker"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransferScheduler_FailsRejectedTransfers(t *testing.T) {
+	db, tx := newTestDB(t)
+	scheduledTransferService := &MockScheduledTransferService{}
+
+	rejection := fmt.Errorf("%w in account 1", service.ErrInsufficientFunds)
+	scheduledTransferService.On("ListDueScheduledTransfers", mock.Anything, mock.Anything, 100).Return([]domain.ScheduledTransfer{{ScheduleID: "s1"}}, nil).Once()
+	scheduledTransferService.On("ExecuteScheduledTransfer", mock.Anything, mock.Anything, "s1", mock.Anything).Return(nil, rejection).Once()
+	scheduledTransferService.On("FailScheduledTransfer", mock.Anything, mock.Anything, "s1", rejection.Error(), mock.Anything).
+		Return(&domain.ScheduledTransfer{ScheduleID: "s1", Status: domain.ScheduledTransferStatusFailed}, nil).Once()
+
+	scheduler := worker.NewTransferScheduler(scheduledTransferService, db, discardLogger(), time.Hour)
+	runUntil(t, scheduler.Run, func() bool { return tx.Commits() == 1 })
+
+	scheduledTransferService.AssertExpectations(t)
+}
+
+func TestTransferScheduler_PostponesTransientFailures(t *testing.T) {
+	db, tx := newTestDB(t)
+	scheduledTransferService := &MockScheduledTransferService{}
+
+	nextAttemptAt := time.Now().Add(time.Minute)
+	scheduledTransferService.On("ListDueScheduledTransfers", mock.Anything, mock.Anything, 100).
+		Return([]domain.ScheduledTransfer{{ScheduleID: "s1"}, {ScheduleID: "s2"}}, nil).Once()
+	scheduledTransferService.On("ExecuteScheduledTransfer", mock.Anything, mock.Anything, "s1", mock.Anything).Return(nil, errors.New("connection reset")).Once()
+	scheduledTransferService.On("PostponeScheduledTransfer", mock.Anything, mock.Anything, "s1", "connection reset", mock.Anything).
+		Return(&domain.ScheduledTransfer{ScheduleID: "s1", Status: domain.ScheduledTransferStatusPending, Attempts: 1, NextAttemptAt: &nextAttemptAt}, nil).Once()
+	scheduledTransferService.On("ExecuteScheduledTransfer", mock.Anything, mock.Anything, "s2", mock.Anything).
+		Return(&domain.ScheduledTransfer{ScheduleID: "s2", Status: domain.ScheduledTransferStatusExecuted}, nil).Once()
+
+	scheduler := worker.NewTransferScheduler(scheduledTransferService, db, discardLogger(), time.Hour)
+	runUntil(t, scheduler.Run, func() bool { return tx.Commits() == 2 })
+
+	scheduledTransferService.AssertExpectations(t)
+	scheduledTransferService.AssertNotCalled(t, "FailScheduledTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dirdr/goits/internal/domain"
+	"github.com/dirdr/goits/internal/service"
+	"github.com/dirdr/goits/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookDispatcher_DeliversEachInItsOwnTransaction(t *testing.T) {
+	db, tx := newTestDB(t)
+	webhookService := &MockWebhookService{}
+
+	nextAttemptAt := time.Now().Add(time.Minute)
+	webhookService.On("ListDueWebhookDeliveries", mock.Anything, mock.Anything, 100).
+		Return([]domain.WebhookDelivery{{DeliveryID: 1}, {DeliveryID: 2}, {DeliveryID: 3}}, nil).Once()
+	webhookService.On("DeliverWebhook", mock.Anything, mock.Anything, uint(1), mock.Anything).Return(nil, errors.New("connection reset")).Once()
+	webhookService.On("DeliverWebhook", mock.Anything, mock.Anything, uint(2), mock.Anything).Return(&service.WebhookDeliveryOutcome{
+		Delivery: &domain.WebhookDelivery{DeliveryID: 2, Status: domain.WebhookDeliveryStatusPending, Attempts: 1, NextAttemptAt: nextAttemptAt, LastError: "status 503"},
+		Attempt:  &domain.WebhookDeliveryAttempt{StatusCode: 503},
+	}, nil).Once()
+	webhookService.On("DeliverWebhook", mock.Anything, mock.Anything, uint(3), mock.Anything).Return(&service.WebhookDeliveryOutcome{
+		Delivery: &domain.WebhookDelivery{DeliveryID: 3, Status: domain.WebhookDeliveryStatusDelivered, Attempts: 1},
+		Attempt:  &domain.WebhookDeliveryAttempt{StatusCode: 200},
+	}, nil).Once()
+
+	dispatcher := worker.NewWebhookDispatcher(webhookService, db, discardLogger(), time.Hour)
+	runUntil(t, dispatcher.Run, func() bool { return tx.Commits() == 2 })
+
+	webhookService.AssertExpectations(t)
+	assert.Equal(t, 1, tx.Rollbacks(), "a failed delivery does not hold back the rest of the batch")
+}
+
+func TestWebhookDispatcher_SkipsDeliveriesClaimedElsewhere(t *testing.T) {
+	db, tx := newTestDB(t)
+	webhookService := &MockWebhookService{}
+
+	webhookService.On("ListDueWebhookDeliveries", mock.Anything, mock.Anything, 100).Return([]domain.WebhookDelivery{{DeliveryID: 1}}, nil).Once()
+	webhookService.On("DeliverWebhook", mock.Anything, mock.Anything, uint(1), mock.Anything).Return(nil, nil).Once()
+
+	dispatcher := worker.NewWebhookDispatcher(webhookService, db, discardLogger(), time.Hour)
+	runUntil(t, dispatcher.Run, func() bool { return tx.Commits() == 1 })
+
+	webhookService.AssertExpectations(t)
+	assert.Zero(t, tx.Rollbacks())
+}